@@ -0,0 +1,234 @@
+// Package audit stores the host API audit log as hash-chained JSON lines
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+)
+
+// DefaultPath is the audit log location relative to the project root
+const DefaultPath = ".okra/audit.log"
+
+// maxRecordSize bounds a single audit line when reading the log
+const maxRecordSize = 16 * 1024 * 1024
+
+// Record is one line of the audit log. Hash covers every other field,
+// including the previous record's hash, so editing, removing or reordering
+// records breaks the chain.
+type Record struct {
+	Seq uint64 `json:"seq"`
+	hostapi.AuditEvent
+	PrevHash string `json:"prevHash"`
+	Hash     string `json:"hash"`
+}
+
+// ComputeHash returns the chain hash of the record
+func (r Record) ComputeHash() (string, error) {
+	unhashed := r
+	unhashed.Hash = ""
+	data, err := json.Marshal(unhashed)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// FileSink appends audit events to a JSON-lines file
+type FileSink struct {
+	file     *os.File
+	seq      uint64
+	lastHash string
+	mu       sync.Mutex
+}
+
+// Compile-time interface compliance check
+var _ hostapi.AuditSink = (*FileSink)(nil)
+
+// NewFileSink opens (or creates) the audit log at path and continues its chain
+func NewFileSink(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	sink := &FileSink{file: file}
+	end, err := readRecords(file, func(record Record) error {
+		sink.seq = record.Seq
+		sink.lastHash = record.Hash
+		return nil
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// A crash mid-write leaves a last line without its newline. Drop it so
+	// the chain continues from the last whole record.
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	if info.Size() > end {
+		if err := file.Truncate(end); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to drop partial audit record: %w", err)
+		}
+		slog.Warn("dropped partial audit record", "path", path, "bytes", info.Size()-end, "after_seq", sink.seq)
+	}
+
+	return sink, nil
+}
+
+// Record appends an event to the chain
+func (s *FileSink) Record(ctx context.Context, event hostapi.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return errors.New("audit log is closed")
+	}
+
+	record := Record{
+		Seq:        s.seq + 1,
+		AuditEvent: event,
+		PrevHash:   s.lastHash,
+	}
+	hash, err := record.ComputeHash()
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+
+	s.seq = record.Seq
+	s.lastHash = record.Hash
+	return nil
+}
+
+// Close flushes and closes the audit log
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Sync()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	s.file = nil
+	return err
+}
+
+// Verify checks the hash chain of an audit log and returns the number of
+// records verified. The error identifies the first record that breaks the chain.
+func Verify(r io.Reader) (int, error) {
+	count := 0
+	var prev Record
+	_, err := readRecords(&partialLineReader{r: r}, func(record Record) error {
+		if record.Seq != prev.Seq+1 {
+			return fmt.Errorf("audit record %d follows record %d", record.Seq, prev.Seq)
+		}
+		if record.PrevHash != prev.Hash {
+			return fmt.Errorf("audit record %d does not chain to record %d", record.Seq, prev.Seq)
+		}
+		hash, err := record.ComputeHash()
+		if err != nil {
+			return err
+		}
+		if hash != record.Hash {
+			return fmt.Errorf("audit record %d has been modified", record.Seq)
+		}
+
+		prev = record
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// readRecords decodes every complete line of an audit log and returns the
+// offset just past the last one. A last line without a newline is a record
+// still being written, or torn by a crash, and is not decoded.
+func readRecords(r io.Reader, fn func(Record) error) (int64, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	scanner.Split(scanCompleteLines)
+
+	var end int64
+	line := 0
+	for scanner.Scan() {
+		line++
+		end += int64(len(scanner.Bytes())) + 1
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return end, fmt.Errorf("invalid audit record on line %d: %w", line, err)
+		}
+		if err := fn(record); err != nil {
+			return end, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return end, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return end, nil
+}
+
+// scanCompleteLines splits newline-terminated lines, leaving a last line
+// without a newline unread
+func scanCompleteLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	return 0, nil, nil
+}
+
+// partialLineReader fails at the end of a log whose last line has no
+// newline, so Verify reports a torn record rather than skipping it
+type partialLineReader struct {
+	r    io.Reader
+	last byte
+}
+
+func (p *partialLineReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.last = b[n-1]
+	}
+	if errors.Is(err, io.EOF) && p.last != 0 && p.last != '\n' {
+		return n, errors.New("audit log ends in a partial record")
+	}
+	return n, err
+}
@@ -0,0 +1,264 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test events are written as hash-chained JSON lines
+// 2. Test reopening a log continues its chain
+// 3. Test Verify detects modified, removed and reordered records
+// 4. Test Tail shows the last records and follows appended ones
+// 5. Test a record torn by a crash is dropped on open, reported by Verify and
+//    completed by Tail
+
+func testEvent(method string) hostapi.AuditEvent {
+	return hostapi.AuditEvent{
+		Time:       time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Service:    "acme/orders",
+		API:        "okra.http",
+		Method:     method,
+		Parameters: json.RawMessage(`{"url":"https://api.example.com?q=<x>"}`),
+		Allowed:    true,
+	}
+}
+
+func writeEvents(t *testing.T, path string, methods ...string) {
+	t.Helper()
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	for _, method := range methods {
+		require.NoError(t, sink.Record(context.Background(), testEvent(method)))
+	}
+	require.NoError(t, sink.Close())
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func verifyLines(lines []string) (int, error) {
+	return Verify(strings.NewReader(strings.Join(lines, "\n") + "\n"))
+}
+
+func TestFileSink_Chain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.log")
+	writeEvents(t, path, "fetch", "stream")
+	writeEvents(t, path, "fetch")
+
+	lines := readLines(t, path)
+	require.Len(t, lines, 3)
+
+	var records []Record
+	for _, line := range lines {
+		var record Record
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+
+	assert.Equal(t, uint64(1), records[0].Seq)
+	assert.Empty(t, records[0].PrevHash)
+	assert.Equal(t, "acme/orders", records[0].Service)
+	assert.Equal(t, "fetch", records[0].Method)
+	for i := 1; i < len(records); i++ {
+		assert.Equal(t, uint64(i+1), records[i].Seq)
+		assert.Equal(t, records[i-1].Hash, records[i].PrevHash, "reopened log continues the chain")
+	}
+
+	count, err := verifyLines(lines)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestFileSink_ConcurrentRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				assert.NoError(t, sink.Record(context.Background(), testEvent("fetch")))
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, sink.Close())
+
+	count, err := verifyLines(readLines(t, path))
+	require.NoError(t, err)
+	assert.Equal(t, 200, count)
+
+	assert.Error(t, sink.Record(context.Background(), testEvent("fetch")), "closed sinks reject events")
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeEvents(t, path, "fetch", "stream", "fetch", "stream")
+	lines := readLines(t, path)
+
+	modified := append([]string{}, lines...)
+	modified[1] = strings.Replace(modified[1], `"allowed":true`, `"allowed":false`, 1)
+	_, err := verifyLines(modified)
+	assert.ErrorContains(t, err, "audit record 2 has been modified")
+
+	removed := append(append([]string{}, lines[:2]...), lines[3:]...)
+	_, err = verifyLines(removed)
+	assert.ErrorContains(t, err, "audit record 4 follows record 2")
+
+	reordered := []string{lines[0], lines[2], lines[1], lines[3]}
+	count, err := verifyLines(reordered)
+	assert.Error(t, err)
+	assert.Equal(t, 1, count)
+
+	truncatedStart := lines[1:]
+	_, err = verifyLines(truncatedStart)
+	assert.Error(t, err)
+
+	_, err = Verify(strings.NewReader("not json\n"))
+	assert.ErrorContains(t, err, "invalid audit record on line 1")
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	defer sink.Close()
+	for _, method := range []string{"a", "b", "c"} {
+		require.NoError(t, sink.Record(context.Background(), testEvent(method)))
+	}
+
+	var methods []string
+	collect := func(record Record) error {
+		methods = append(methods, record.Method)
+		return nil
+	}
+
+	require.NoError(t, Tail(context.Background(), path, TailOptions{Lines: 2}, collect))
+	assert.Equal(t, []string{"b", "c"}, methods)
+
+	methods = nil
+	require.NoError(t, Tail(context.Background(), path, TailOptions{Lines: -1}, collect))
+	assert.Equal(t, []string{"a", "b", "c"}, methods)
+
+	// Follow records appended after the tail starts
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var followed bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- Tail(ctx, path, TailOptions{Follow: true, PollInterval: 10 * time.Millisecond}, func(record Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			followed.WriteString(record.Method)
+			return nil
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, sink.Record(context.Background(), testEvent("d")))
+	require.NoError(t, sink.Record(context.Background(), testEvent("e")))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return followed.String() == "de"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+
+	err = Tail(context.Background(), filepath.Join(t.TempDir(), "missing.log"), TailOptions{}, collect)
+	assert.Error(t, err)
+}
+
+func TestFileSink_PartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeEvents(t, path, "a", "b")
+	whole, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// A crash mid-write leaves half of the next record
+	lines := readLines(t, path)
+	torn := lines[1][:len(lines[1])/2]
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString(torn)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	// Test: Verify reports the torn record after the whole ones
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	count, err := Verify(bytes.NewReader(data))
+	assert.ErrorContains(t, err, "partial record")
+	assert.Equal(t, 2, count)
+
+	// Test: Tail shows the whole records, and completes the torn one once the
+	// rest of it is written
+	var methods []string
+	collect := func(record Record) error {
+		methods = append(methods, record.Method)
+		return nil
+	}
+	require.NoError(t, Tail(context.Background(), path, TailOptions{Lines: -1}, collect))
+	assert.Equal(t, []string{"a", "b"}, methods)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var followed []string
+	done := make(chan error, 1)
+	go func() {
+		done <- Tail(ctx, path, TailOptions{Follow: true, PollInterval: 10 * time.Millisecond}, func(record Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			followed = append(followed, record.Method)
+			return nil
+		})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	file, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString(lines[1][len(torn):] + "\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(followed) == 1 && followed[0] == "b"
+	}, 2*time.Second, 10*time.Millisecond)
+	cancel()
+	assert.NoError(t, <-done)
+
+	// Test: Opening the log drops a torn record and continues the chain
+	require.NoError(t, os.WriteFile(path, append(append([]byte{}, whole...), torn...), 0600))
+	writeEvents(t, path, "c")
+	lines = readLines(t, path)
+	count, err = verifyLines(lines)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	var record Record
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &record))
+	assert.Equal(t, "c", record.Method)
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// TailOptions controls Tail
+type TailOptions struct {
+	Lines        int           // Existing records to show first (0 = none, negative = all)
+	Follow       bool          // Keep reading records as they are appended
+	PollInterval time.Duration // How often to check for new records when following (default: 250ms)
+}
+
+// Tail calls fn for the last records of the audit log at path and, when
+// following, for every record appended until ctx is cancelled.
+func Tail(ctx context.Context, path string, opts TailOptions, fn func(Record) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var recent []Record
+	end, err := readRecords(file, func(record Record) error {
+		if opts.Lines == 0 {
+			return nil
+		}
+		recent = append(recent, record)
+		if opts.Lines > 0 && len(recent) > opts.Lines {
+			recent = recent[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, record := range recent {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	if !opts.Follow {
+		return nil
+	}
+
+	// Pick up a last line that was still being written where reading stopped
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = 250 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Records are appended whole, but a poll can still see a partial line
+	var pending []byte
+	buf := make([]byte, 64*1024)
+	for {
+		for {
+			n, err := file.Read(buf)
+			pending = append(pending, buf[:n]...)
+			if errors.Is(err, io.EOF) || n == 0 {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read audit log: %w", err)
+			}
+		}
+
+		for {
+			i := bytes.IndexByte(pending, '\n')
+			if i < 0 {
+				break
+			}
+			line := pending[:i]
+			pending = pending[i+1:]
+			if len(line) == 0 {
+				continue
+			}
+
+			var record Record
+			if err := json.Unmarshal(line, &record); err != nil {
+				return fmt.Errorf("invalid audit record: %w", err)
+			}
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/hostapi"
 	"github.com/okra-platform/okra/internal/schema"
 	"github.com/rs/zerolog"
 )
@@ -65,6 +66,7 @@ type ServiceBuilder struct {
 	config      *config.Config
 	projectRoot string
 	logger      zerolog.Logger
+	hostAPIs    hostapi.Catalog // Host APIs offered by the target runtime
 
 	// Build state
 	schema     *schema.Schema
@@ -78,14 +80,27 @@ func NewServiceBuilder(cfg *config.Config, projectRoot string, logger zerolog.Lo
 		config:      cfg,
 		projectRoot: projectRoot,
 		logger:      logger,
+		hostAPIs:    hostapi.DescribeRegistry(hostapi.NewDefaultHostAPIRegistry()),
 		okraDir:     filepath.Join(projectRoot, ".okra"),
 	}
 }
 
+// WithHostAPICatalog sets the host APIs offered by the runtime the service is
+// built for. It defaults to the APIs built into this okra binary.
+func (b *ServiceBuilder) WithHostAPICatalog(catalog hostapi.Catalog) *ServiceBuilder {
+	b.hostAPIs = catalog
+	return b
+}
+
 // GenerateCode generates interface code from the schema
 func (b *ServiceBuilder) GenerateCode(schemaPath string) error {
 	b.buildStart = time.Now()
 
+	// Fail before generating anything if the runtime lacks a declared host API
+	if err := b.checkHostAPIs(); err != nil {
+		return err
+	}
+
 	// Check schema file exists
 	if _, err := os.Stat(schemaPath); os.IsNotExist(err) {
 		return fmt.Errorf("schema file not found: %s", schemaPath)
@@ -133,6 +148,11 @@ func (b *ServiceBuilder) GenerateCode(schemaPath string) error {
 		return fmt.Errorf("failed to generate interface: %w", err)
 	}
 
+	// Generate typed host API clients
+	if err := b.generateHostAPIClients(); err != nil {
+		return fmt.Errorf("failed to generate host API clients: %w", err)
+	}
+
 	// Generate protobuf descriptor
 	if err := b.generateProtobufDescriptor(parsedSchema); err != nil {
 		return fmt.Errorf("failed to generate protobuf: %w", err)
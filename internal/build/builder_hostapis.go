@@ -0,0 +1,89 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/okra-platform/okra/internal/codegen/golang"
+	"github.com/okra-platform/okra/internal/codegen/typescript"
+	"github.com/okra-platform/okra/internal/hostapi"
+)
+
+// checkHostAPIs verifies the target runtime offers every host API the
+// service declares in okra.json, at a version in the declared range
+func (b *ServiceBuilder) checkHostAPIs() error {
+	_, err := b.hostAPIs.CheckRequirements(b.config.HostAPIs)
+	return err
+}
+
+// clientFactories picks one version of each host API to generate a client
+// for: the one the service's declared range resolves to, or the latest
+func clientFactories(registry hostapi.HostAPIRegistry, declared map[string]string) []hostapi.HostAPIFactory {
+	seen := make(map[string]bool)
+	var factories []hostapi.HostAPIFactory
+	for _, factory := range registry.List() {
+		name := factory.Name()
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		resolved, err := registry.Resolve(name, declared[name])
+		if err != nil {
+			// The target runtime may offer versions this binary lacks
+			resolved, _ = registry.Get(name)
+		}
+		factories = append(factories, resolved)
+	}
+	return factories
+}
+
+// generateHostAPIClients generates typed guest clients for the host APIs
+// next to the service interface, so services call them instead of building
+// okra.run_host_api envelopes by hand
+func (b *ServiceBuilder) generateHostAPIClients() error {
+	factories := clientFactories(hostapi.NewDefaultHostAPIRegistry(), b.config.HostAPIs)
+
+	typesDir := filepath.Join(b.projectRoot, "types")
+	var clientsPath string
+
+	if b.config.Language == "go" {
+		clientsPath = filepath.Join(typesDir, "hostapis")
+		if err := os.MkdirAll(clientsPath, 0755); err != nil {
+			return fmt.Errorf("failed to create host API clients directory: %w", err)
+		}
+
+		files, err := golang.NewHostAPIGenerator("hostapis").Generate(factories)
+		if err != nil {
+			return fmt.Errorf("failed to generate Go host API clients: %w", err)
+		}
+		for name, code := range files {
+			if err := os.WriteFile(filepath.Join(clientsPath, name), code, 0644); err != nil {
+				return fmt.Errorf("failed to write host API client file %s: %w", name, err)
+			}
+		}
+	} else {
+		if err := os.MkdirAll(typesDir, 0755); err != nil {
+			return fmt.Errorf("failed to create types directory: %w", err)
+		}
+
+		code, err := typescript.NewHostAPIGenerator().Generate(factories)
+		if err != nil {
+			return fmt.Errorf("failed to generate TypeScript host API clients: %w", err)
+		}
+		clientsPath = filepath.Join(typesDir, "hostapis.ts")
+		if err := os.WriteFile(clientsPath, code, 0644); err != nil {
+			return fmt.Errorf("failed to write host API clients: %w", err)
+		}
+	}
+
+	b.logger.Info().
+		Str("path", clientsPath).
+		Int("host_apis", len(factories)).
+		Dur("duration", time.Since(b.buildStart)).
+		Msg("generated host API clients")
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/hostapi"
 	"github.com/okra-platform/okra/internal/schema"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +19,8 @@ import (
 // 3. Test GenerateCode with missing schema file
 // 4. Test GenerateCode with empty schema file
 // 5. Test GenerateCode with no services in schema
+// 5a. Test GenerateCode fails early when the runtime lacks a declared host API
+// 5b. Test host API clients are generated for the declared version of each API
 // 6. Test BuildWASM requires GenerateCode to be called first
 // 7. Test GetArtifacts returns correct paths
 
@@ -116,6 +119,39 @@ type GreetRequest {
 	assert.Contains(t, err.Error(), "no services defined in schema")
 }
 
+func TestServiceBuilder_GenerateCode_UndeclaredHostAPI(t *testing.T) {
+	cfg := &config.Config{
+		Name:     "test-service",
+		Version:  "1.0.0",
+		Language: "go",
+		HostAPIs: map[string]string{"okra.state": "^1.0.0", "okra.queue": "^1.0.0"},
+	}
+
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+
+	// Test: A runtime without okra.queue fails the build before any output is written
+	catalog := hostapi.Catalog{HostAPIs: []hostapi.HostAPIDescription{{Name: "okra.state", Version: "v1.0.0"}}}
+	builder := NewServiceBuilder(cfg, tempDir, logger).WithHostAPICatalog(catalog)
+
+	err := builder.GenerateCode(filepath.Join(tempDir, "service.okra.gql"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "okra.queue: not offered by the runtime")
+	assert.NoDirExists(t, filepath.Join(tempDir, "types"))
+
+	// Test: A version outside the declared range fails the build
+	cfg.HostAPIs = map[string]string{"okra.state": "^2.0.0"}
+	err = builder.GenerateCode(filepath.Join(tempDir, "service.okra.gql"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "okra.state: runtime offers v1.0.0, service needs ^2.0.0")
+
+	// Test: The default catalog offers every built-in API
+	cfg.HostAPIs = map[string]string{"okra.state": "^1.0.0", "okra.queue": "^1.0.0"}
+	err = NewServiceBuilder(cfg, tempDir, logger).GenerateCode(filepath.Join(tempDir, "service.okra.gql"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema file not found")
+}
+
 func TestServiceBuilder_BuildWASM_RequiresGenerateCode(t *testing.T) {
 	// Test: BuildWASM fails if GenerateCode hasn't been called
 
@@ -216,6 +252,11 @@ type GreetResponse {
 	interfacePath := filepath.Join(tempDir, "types", "interface.go")
 	assert.FileExists(t, interfacePath)
 	
+	// Verify host API clients were generated
+	assert.FileExists(t, filepath.Join(tempDir, "types", "hostapis", "hostapis.go"))
+	assert.FileExists(t, filepath.Join(tempDir, "types", "hostapis", "transport_wasm.go"))
+	assert.FileExists(t, filepath.Join(tempDir, "types", "hostapis", "transport_other.go"))
+	
 	// Verify protobuf descriptor was generated
 	descPath := filepath.Join(tempDir, ".okra", "service.pb.desc")
 	assert.FileExists(t, descPath)
@@ -265,6 +306,9 @@ type GreetResponse {
 	interfacePath := filepath.Join(tempDir, "types", "interface.ts")
 	assert.FileExists(t, interfacePath)
 	
+	// Verify host API clients were generated
+	assert.FileExists(t, filepath.Join(tempDir, "types", "hostapis.ts"))
+	
 	// Verify protobuf descriptor was generated
 	descPath := filepath.Join(tempDir, ".okra", "service.pb.desc")
 	assert.FileExists(t, descPath)
@@ -507,3 +551,32 @@ func TestServiceBuilder_BuildWASM_UnsupportedLanguage(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unsupported language")
 }
+
+// stateV2Factory is okra.state re-registered as a second version
+type stateV2Factory struct {
+	hostapi.HostAPIFactory
+}
+
+func (stateV2Factory) Version() string { return "v2.0.0" }
+
+func TestClientFactories_DeclaredVersion(t *testing.T) {
+	// Test: One client per API, at the version the declared range resolves to
+	registry := hostapi.NewDefaultHostAPIRegistry()
+	state, ok := registry.Get("okra.state")
+	require.True(t, ok)
+	require.NoError(t, registry.Register(stateV2Factory{state}))
+
+	versionOf := func(factories []hostapi.HostAPIFactory, name string) []string {
+		var versions []string
+		for _, factory := range factories {
+			if factory.Name() == name {
+				versions = append(versions, factory.Version())
+			}
+		}
+		return versions
+	}
+
+	assert.Equal(t, []string{"v2.0.0"}, versionOf(clientFactories(registry, nil), "okra.state"))
+	assert.Equal(t, []string{"v1.0.0"}, versionOf(clientFactories(registry, map[string]string{"okra.state": "^1.0.0"}), "okra.state"))
+	assert.Len(t, clientFactories(registry, nil), len(registry.List())-1)
+}
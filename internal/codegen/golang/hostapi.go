@@ -0,0 +1,489 @@
+package golang
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/okra-platform/okra/internal/codegen/writer"
+	"github.com/okra-platform/okra/internal/hostapi"
+)
+
+// HostAPIGenerator generates typed TinyGo clients for host APIs from the
+// MethodMetadata their factories publish, so guests never build
+// okra.run_host_api envelopes by hand
+type HostAPIGenerator struct {
+	packageName string
+	types       []string        // Generated type declarations, in order
+	typeNames   map[string]bool // Type names already declared
+	imports     map[string]bool // Imports needed by the client file
+}
+
+// NewHostAPIGenerator creates a new host API client generator
+func NewHostAPIGenerator(packageName string) *HostAPIGenerator {
+	if packageName == "" {
+		packageName = "hostapis"
+	}
+	return &HostAPIGenerator{packageName: packageName}
+}
+
+// Generate returns the client files for the given host APIs keyed by file name.
+// hostapis.go holds the typed clients; the transport files send the envelopes
+// through the okra host module inside WASM and fail elsewhere, so code that
+// uses the clients still builds and tests natively.
+func (g *HostAPIGenerator) Generate(factories []hostapi.HostAPIFactory) (map[string][]byte, error) {
+	g.types = nil
+	g.typeNames = make(map[string]bool)
+	g.imports = map[string]bool{"encoding/json": true}
+
+	sorted := append([]hostapi.HostAPIFactory(nil), factories...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+
+	clients := writer.NewWriter("\t")
+	for _, factory := range sorted {
+		g.generateClient(clients, factory)
+		clients.BlankLine()
+	}
+
+	w := writer.NewWriter("\t")
+	w.WriteLine("// Code generated by OKRA. DO NOT EDIT.")
+	w.BlankLine()
+	w.WriteLinef("// Package %s provides typed clients for the OKRA host APIs", g.packageName)
+	w.WriteLinef("package %s", g.packageName)
+	w.BlankLine()
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	w.WriteLine("import (")
+	w.Indent()
+	for _, imp := range imports {
+		w.WriteLinef("%q", imp)
+	}
+	w.Dedent()
+	w.WriteLine(")")
+	w.BlankLine()
+	w.Write(hostAPIRuntime)
+	w.BlankLine()
+	w.Write(clients.String())
+	for _, typ := range g.types {
+		w.Write(typ)
+		w.BlankLine()
+	}
+
+	files := map[string][]byte{
+		"hostapis.go":        w.Bytes(),
+		"transport_wasm.go":  []byte(fmt.Sprintf(hostAPITransportWASM, g.packageName)),
+		"transport_other.go": []byte(fmt.Sprintf(hostAPITransportOther, g.packageName)),
+	}
+	for name, code := range files {
+		formatted, err := format.Source(code)
+		if err != nil {
+			return nil, fmt.Errorf("generated invalid Go code in %s: %w", name, err)
+		}
+		files[name] = formatted
+	}
+	return files, nil
+}
+
+// generateClient writes the client type for one host API and collects the
+// parameter and result types of its methods
+func (g *HostAPIGenerator) generateClient(w *writer.Writer, factory hostapi.HostAPIFactory) {
+	clientName := g.apiTypeName(factory.Name())
+	w.WriteLinef("// %sAPI calls %s %s", clientName, factory.Name(), factory.Version())
+	w.WriteLinef("type %sAPI struct{}", clientName)
+	w.BlankLine()
+	w.WriteLinef("// %s is the client for %s", clientName, factory.Name())
+	w.WriteLinef("var %s %sAPI", clientName, clientName)
+
+	for _, method := range factory.Methods() {
+		w.BlankLine()
+		methodName := exportedName(method.Name)
+		paramsType := clientName + methodName + "Params"
+		resultType := clientName + methodName + "Result"
+		qualified := factory.Name() + "." + method.Name
+		g.declareStruct(paramsType, fmt.Sprintf("are the parameters of %s", qualified), method.Parameters, true)
+		hasResult := method.Streaming || hasProperties(method.Returns)
+		if hasResult {
+			g.declareStruct(resultType, fmt.Sprintf("is the result of %s", qualified), method.Returns, false)
+		}
+
+		if method.Description != "" {
+			w.WriteLinef("// %s: %s", methodName, method.Description)
+		} else {
+			w.WriteLinef("// %s calls %s.%s", methodName, factory.Name(), method.Name)
+		}
+		for _, e := range method.Errors {
+			w.WriteLinef("//   - %s: %s", e.Code, e.Description)
+		}
+
+		switch {
+		case method.Streaming:
+			w.WriteLine("//")
+			w.WriteLine("// The returned iterator reads the rest of the stream with okra.next; close it")
+			w.WriteLine("// if you stop before it is exhausted.")
+			w.WriteLinef("func (%sAPI) %s(params %s) (*%s, *Iterator, error) {", clientName, methodName, paramsType, resultType)
+			w.Indent()
+			w.WriteLinef("var result %s", resultType)
+			w.WriteLinef("if err := call(%q, %q, params, &result); err != nil {", factory.Name(), method.Name)
+			w.WriteLine("\treturn nil, nil, err")
+			w.WriteLine("}")
+			w.WriteLine("return &result, newIterator(result.IteratorId, result.HasData), nil")
+		case hasResult:
+			w.WriteLinef("func (%sAPI) %s(params %s) (*%s, error) {", clientName, methodName, paramsType, resultType)
+			w.Indent()
+			w.WriteLinef("var result %s", resultType)
+			w.WriteLinef("if err := call(%q, %q, params, &result); err != nil {", factory.Name(), method.Name)
+			w.WriteLine("\treturn nil, err")
+			w.WriteLine("}")
+			w.WriteLine("return &result, nil")
+		default:
+			w.WriteLinef("func (%sAPI) %s(params %s) error {", clientName, methodName, paramsType)
+			w.Indent()
+			w.WriteLinef("return call(%q, %q, params, nil)", factory.Name(), method.Name)
+		}
+		w.Dedent()
+		w.WriteLine("}")
+	}
+}
+
+// declareStruct adds a struct type for an object schema. Optional parameters
+// are pointers, so an explicit false or 0 is still sent; results use plain
+// values because an absent field reads the same as its zero value.
+func (g *HostAPIGenerator) declareStruct(name, doc string, schema *spec.Schema, params bool) {
+	if g.typeNames[name] {
+		return
+	}
+	g.typeNames[name] = true
+
+	w := writer.NewWriter("\t")
+	w.WriteLinef("// %s %s", name, doc)
+	w.WriteLinef("type %s struct {", name)
+	w.Indent()
+	if schema != nil {
+		required := make(map[string]bool)
+		for _, field := range schema.Required {
+			required[field] = true
+		}
+		for _, field := range sortedProperties(schema) {
+			prop := schema.Properties[field]
+			if prop.Description != "" {
+				w.WriteLinef("// %s", prop.Description)
+			}
+			if len(prop.Enum) > 0 {
+				w.WriteLinef("// One of: %s", enumValues(prop.Enum))
+			}
+
+			goType := g.goType(name+exportedName(field), "is the "+field+" field of "+name, &prop, params)
+			tag := field
+			if !required[field] {
+				tag += ",omitempty"
+				if params && isValue(&prop) {
+					goType = "*" + goType
+				}
+			}
+			w.WriteLinef("%s %s `json:\"%s\"`", exportedName(field), goType, tag)
+		}
+	}
+	w.Dedent()
+	w.WriteLine("}")
+	g.types = append(g.types, w.String())
+}
+
+// goType maps a JSON schema to a Go type, declaring structs for nested objects.
+// Untyped values are interface{} in parameters, so any Go value can be sent,
+// and json.RawMessage in results, so callers can decode them into their own types.
+func (g *HostAPIGenerator) goType(name, doc string, schema *spec.Schema, params bool) string {
+	switch schemaType(schema) {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			g.imports["time"] = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		var items *spec.Schema
+		if schema.Items != nil {
+			items = schema.Items.Schema
+		}
+		return "[]" + g.goType(name+"Item", "is an element of "+name, items, params)
+	case "object":
+		if hasProperties(schema) {
+			g.declareStruct(name, doc, schema, params)
+			return name
+		}
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			return "map[string]" + g.goType(name+"Value", "is a value of "+name, schema.AdditionalProperties.Schema, params)
+		}
+		return "map[string]interface{}"
+	}
+	if params {
+		return "interface{}"
+	}
+	return "json.RawMessage"
+}
+
+// apiTypeName turns "okra.state" into "State"
+func (g *HostAPIGenerator) apiTypeName(api string) string {
+	name := strings.TrimPrefix(api, "okra.")
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '_' || r == '-' }) {
+		b.WriteString(exportedName(part))
+	}
+	return b.String()
+}
+
+// exportedName capitalizes the first letter of a name, matching the schema generator
+func exportedName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func schemaType(schema *spec.Schema) string {
+	if schema == nil || len(schema.Type) == 0 {
+		return ""
+	}
+	return schema.Type[0]
+}
+
+func hasProperties(schema *spec.Schema) bool {
+	return schema != nil && len(schema.Properties) > 0
+}
+
+// isValue reports whether a schema maps to a Go type whose zero value would
+// still be sent with omitempty, or would be indistinguishable from absent
+func isValue(schema *spec.Schema) bool {
+	switch schemaType(schema) {
+	case "integer", "number", "boolean":
+		return true
+	case "string":
+		return schema.Format == "date-time"
+	}
+	return false
+}
+
+func sortedProperties(schema *spec.Schema) []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func enumValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hostAPIRuntime is the envelope handling shared by every generated client
+const hostAPIRuntime = `// Error is returned when a host API call fails
+type Error struct {
+	Code    string ` + "`json:\"code\"`" + `
+	Message string ` + "`json:\"message\"`" + `
+	Details string ` + "`json:\"details,omitempty\"`" + `
+}
+
+func (e *Error) Error() string {
+	if e.Details != "" {
+		return e.Code + ": " + e.Message + " (" + e.Details + ")"
+	}
+	return e.Code + ": " + e.Message
+}
+
+// Transport sends encoded host call envelopes to the host
+type Transport interface {
+	RunHostAPI(request []byte) ([]byte, error)
+	Next(request []byte) ([]byte, error)
+	CloseIterator(request []byte) ([]byte, error)
+}
+
+var transport Transport = hostTransport{}
+
+// SetTransport replaces how host calls are sent, e.g. with a fake in tests
+func SetTransport(t Transport) {
+	transport = t
+}
+
+type request struct {
+	API        string      ` + "`json:\"api\"`" + `
+	Method     string      ` + "`json:\"method\"`" + `
+	Parameters interface{} ` + "`json:\"parameters\"`" + `
+}
+
+type iteratorRequest struct {
+	IteratorID string ` + "`json:\"iteratorId\"`" + `
+}
+
+type response struct {
+	Success bool            ` + "`json:\"success\"`" + `
+	Data    json.RawMessage ` + "`json:\"data,omitempty\"`" + `
+	HasMore bool            ` + "`json:\"hasMore,omitempty\"`" + `
+	Error   *Error          ` + "`json:\"error,omitempty\"`" + `
+}
+
+func send(fn func([]byte) ([]byte, error), payload interface{}) (*response, error) {
+	req, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := fn(req)
+	if err != nil {
+		return nil, err
+	}
+	var resp response
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		if resp.Error == nil {
+			return nil, &Error{Code: "INTERNAL_ERROR", Message: "host call failed"}
+		}
+		return nil, resp.Error
+	}
+	return &resp, nil
+}
+
+func call(api, method string, params, result interface{}) error {
+	resp, err := send(transport.RunHostAPI, request{API: api, Method: method, Parameters: params})
+	if err != nil {
+		return err
+	}
+	if result == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, result)
+}
+
+// Iterator reads the chunks of a streaming host API result
+type Iterator struct {
+	id   string
+	done bool
+}
+
+func newIterator(id string, hasData bool) *Iterator {
+	return &Iterator{id: id, done: !hasData || id == ""}
+}
+
+// Next returns the next chunk and whether more chunks follow. It returns nil
+// and false once the stream is exhausted.
+func (it *Iterator) Next() (json.RawMessage, bool, error) {
+	if it.done {
+		return nil, false, nil
+	}
+	resp, err := send(transport.Next, iteratorRequest{IteratorID: it.id})
+	if err != nil {
+		it.done = true
+		return nil, false, err
+	}
+	it.done = !resp.HasMore
+	return resp.Data, resp.HasMore, nil
+}
+
+// Close releases the iterator on the host if it is not exhausted
+func (it *Iterator) Close() error {
+	if it.done {
+		return nil
+	}
+	it.done = true
+	_, err := send(transport.CloseIterator, iteratorRequest{IteratorID: it.id})
+	return err
+}
+`
+
+// hostAPITransportWASM calls the packed okra host functions, which return the
+// response as ptr << 32 | len because TinyGo imports cannot return two values
+const hostAPITransportWASM = `//go:build wasi || wasip1
+
+// Code generated by OKRA. DO NOT EDIT.
+
+package %s
+
+// #include <stdlib.h>
+import "C"
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+)
+
+//go:wasmimport okra run_host_api_packed
+func okraRunHostAPI(ptr, len uint32) uint64
+
+//go:wasmimport okra next_packed
+func okraNext(ptr, len uint32) uint64
+
+//go:wasmimport okra close_iterator_packed
+func okraCloseIterator(ptr, len uint32) uint64
+
+type hostTransport struct{}
+
+func (hostTransport) RunHostAPI(request []byte) ([]byte, error) {
+	return hostCall(okraRunHostAPI, request)
+}
+
+func (hostTransport) Next(request []byte) ([]byte, error) {
+	return hostCall(okraNext, request)
+}
+
+func (hostTransport) CloseIterator(request []byte) ([]byte, error) {
+	return hostCall(okraCloseIterator, request)
+}
+
+func hostCall(fn func(ptr, len uint32) uint64, request []byte) ([]byte, error) {
+	if len(request) == 0 {
+		return nil, errors.New("empty host call request")
+	}
+	packed := fn(uint32(uintptr(unsafe.Pointer(&request[0]))), uint32(len(request)))
+	runtime.KeepAlive(request)
+
+	ptr, size := uint32(packed>>32), uint32(packed)
+	if ptr == 0 {
+		return nil, errors.New("host call rejected")
+	}
+
+	// The host allocated the response with the guest's allocate export
+	response := make([]byte, size)
+	copy(response, unsafe.Slice((*byte)(unsafe.Pointer(uintptr(ptr))), size))
+	C.free(unsafe.Pointer(uintptr(ptr)))
+	return response, nil
+}
+`
+
+// hostAPITransportOther lets code that uses the clients build outside WASM
+const hostAPITransportOther = `//go:build !(wasi || wasip1)
+
+// Code generated by OKRA. DO NOT EDIT.
+
+package %s
+
+import "errors"
+
+type hostTransport struct{}
+
+var errNoHost = errors.New("host APIs are only available inside the OKRA runtime; use SetTransport in tests")
+
+func (hostTransport) RunHostAPI([]byte) ([]byte, error)    { return nil, errNoHost }
+func (hostTransport) Next([]byte) ([]byte, error)          { return nil, errNoHost }
+func (hostTransport) CloseIterator([]byte) ([]byte, error) { return nil, errNoHost }
+`
@@ -0,0 +1,83 @@
+package golang
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test clients are generated for every registered host API
+// 2. Test method signatures follow the method metadata
+// 3. Test the generated package type-checks outside WASM
+
+func generateHostAPIClients(t *testing.T) map[string][]byte {
+	t.Helper()
+	registry := hostapi.NewHostAPIRegistry()
+	require.NoError(t, hostapi.InitializeHostAPIs(registry))
+
+	files, err := NewHostAPIGenerator("").Generate(registry.List())
+	require.NoError(t, err)
+	return files
+}
+
+func TestHostAPIGenerator_Files(t *testing.T) {
+	// Test: The client file and both transports are generated
+	files := generateHostAPIClients(t)
+	require.Len(t, files, 3)
+
+	assert.Contains(t, string(files["hostapis.go"]), "package hostapis")
+	assert.Contains(t, string(files["transport_wasm.go"]), "//go:build wasi || wasip1")
+	assert.Contains(t, string(files["transport_wasm.go"]), "//go:wasmimport okra run_host_api_packed")
+	assert.Contains(t, string(files["transport_other.go"]), "//go:build !(wasi || wasip1)")
+}
+
+func TestHostAPIGenerator_Signatures(t *testing.T) {
+	code := string(generateHostAPIClients(t)["hostapis.go"])
+
+	// Test: Every host API gets a client value
+	for _, client := range []string{"State", "Log", "Env", "Secrets", "Http", "Sql", "Cache", "Queue", "Time", "Metrics"} {
+		assert.Contains(t, code, "var "+client+" "+client+"API")
+	}
+
+	// Test: Unary methods return a typed result
+	assert.Contains(t, code, "func (StateAPI) Get(params StateGetParams) (*StateGetResult, error)")
+
+	// Test: Streaming methods also return an iterator
+	assert.Contains(t, code, "func (StateAPI) List(params StateListParams) (*StateListResult, *Iterator, error)")
+	assert.Contains(t, code, "newIterator(result.IteratorId, result.HasData)")
+
+	// Test: Methods without result fields only return an error
+	assert.Contains(t, code, "func (LogAPI) Write(params LogWriteParams) error")
+
+	// Test: Optional scalar parameters are pointers, required ones are values
+	assert.Contains(t, code, "IfAbsent *bool `json:\"ifAbsent,omitempty\"`")
+	assert.Contains(t, code, "Key string `json:\"key\"`")
+
+	// Test: Declared error codes are documented on the method
+	assert.Contains(t, code, "KEY_TOO_LONG")
+}
+
+func TestHostAPIGenerator_TypeChecks(t *testing.T) {
+	// Test: The clients and the non-WASM transport form a valid package
+	files := generateHostAPIClients(t)
+
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for _, name := range []string{"hostapis.go", "transport_other.go"} {
+		f, err := parser.ParseFile(fset, name, files[name], parser.ParseComments)
+		require.NoError(t, err)
+		parsed = append(parsed, f)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err := conf.Check("hostapis", fset, parsed, nil)
+	require.NoError(t, err)
+}
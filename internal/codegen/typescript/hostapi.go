@@ -0,0 +1,291 @@
+package typescript
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/okra-platform/okra/internal/codegen/writer"
+	"github.com/okra-platform/okra/internal/hostapi"
+)
+
+// HostAPIGenerator generates typed TypeScript clients for host APIs from the
+// MethodMetadata their factories publish
+type HostAPIGenerator struct {
+	g *Generator
+}
+
+// NewHostAPIGenerator creates a new host API client generator
+func NewHostAPIGenerator() *HostAPIGenerator {
+	return &HostAPIGenerator{g: NewGenerator("")}
+}
+
+// FileExtension returns the file extension for generated files
+func (h *HostAPIGenerator) FileExtension() string {
+	return ".ts"
+}
+
+// Generate generates a TypeScript module with a client object per host API.
+// Calls go through a HostTransport, which the runtime provides as
+// globalThis.okraHost and tests can replace with setHostTransport.
+func (h *HostAPIGenerator) Generate(factories []hostapi.HostAPIFactory) ([]byte, error) {
+	sorted := append([]hostapi.HostAPIFactory(nil), factories...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+
+	w := writer.NewWriter("  ")
+	w.WriteLine("// This file is auto-generated by OKRA. Do not edit.")
+	w.BlankLine()
+	w.Write(hostAPIRuntime)
+
+	for _, factory := range sorted {
+		w.BlankLine()
+		if err := h.generateClient(w, factory); err != nil {
+			return nil, err
+		}
+	}
+
+	return w.Bytes(), nil
+}
+
+// generateClient writes the parameter and result types for one host API
+// followed by its client object
+func (h *HostAPIGenerator) generateClient(w *writer.Writer, factory hostapi.HostAPIFactory) error {
+	typePrefix := h.apiTypeName(factory.Name())
+	clientName := h.g.toCamelCase(typePrefix)
+	if clientName == "" {
+		return fmt.Errorf("host API %q has no client name", factory.Name())
+	}
+
+	methods := factory.Methods()
+	for _, method := range methods {
+		name := typePrefix + exportedName(method.Name)
+		h.writeType(w, name+"Params", "Parameters of "+factory.Name()+"."+method.Name, method.Parameters)
+		w.BlankLine()
+		h.writeType(w, name+"Result", "Result of "+factory.Name()+"."+method.Name, method.Returns)
+		w.BlankLine()
+	}
+
+	h.g.writeJSDoc(w, fmt.Sprintf("Client for %s %s", factory.Name(), factory.Version()))
+	w.WriteLinef("export const %s = {", clientName)
+	w.Indent()
+	for i, method := range methods {
+		name := typePrefix + exportedName(method.Name)
+		doc := []string{}
+		if method.Description != "" {
+			doc = append(doc, method.Description)
+		}
+		for _, e := range method.Errors {
+			doc = append(doc, fmt.Sprintf("@throws %s %s", e.Code, e.Description))
+		}
+		h.g.writeJSDoc(w, strings.Join(doc, "\n"))
+
+		if method.Streaming {
+			w.WriteLinef("%s(params: %sParams): { result: %sResult; iterator: HostIterator } {", method.Name, name, name)
+			w.Indent()
+			w.WriteLinef("const result = call<%sResult>(%q, %q, params);", name, factory.Name(), method.Name)
+			w.WriteLine("return { result, iterator: new HostIterator(result.iteratorId, result.hasData) };")
+		} else {
+			w.WriteLinef("%s(params: %sParams): %sResult {", method.Name, name, name)
+			w.Indent()
+			w.WriteLinef("return call<%sResult>(%q, %q, params);", name, factory.Name(), method.Name)
+		}
+		w.Dedent()
+		if i < len(methods)-1 {
+			w.WriteLine("},")
+		} else {
+			w.WriteLine("}")
+		}
+	}
+	w.Dedent()
+	w.WriteLine("};")
+	return nil
+}
+
+// writeType writes an exported type alias for a schema
+func (h *HostAPIGenerator) writeType(w *writer.Writer, name, doc string, schema *spec.Schema) {
+	h.g.writeJSDoc(w, doc)
+	w.WriteLinef("export type %s = %s;", name, h.tsType(schema))
+}
+
+// tsType maps a JSON schema to a TypeScript type; nested objects are inlined
+func (h *HostAPIGenerator) tsType(schema *spec.Schema) string {
+	if schema == nil || len(schema.Type) == 0 {
+		if schema != nil && len(schema.Properties) > 0 {
+			return h.objectType(schema)
+		}
+		return "unknown"
+	}
+
+	switch schema.Type[0] {
+	case "string":
+		if len(schema.Enum) > 0 {
+			values := make([]string, len(schema.Enum))
+			for i, v := range schema.Enum {
+				values[i] = fmt.Sprintf("%q", fmt.Sprint(v))
+			}
+			return strings.Join(values, " | ")
+		}
+		// date-time and byte (base64) values stay strings in JSON
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		var items *spec.Schema
+		if schema.Items != nil {
+			items = schema.Items.Schema
+		}
+		item := h.tsType(items)
+		if strings.Contains(item, " | ") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if len(schema.Properties) > 0 {
+			return h.objectType(schema)
+		}
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			return "Record<string, " + h.tsType(schema.AdditionalProperties.Schema) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+// objectType renders an object schema as an inline type literal
+func (h *HostAPIGenerator) objectType(schema *spec.Schema) string {
+	required := make(map[string]bool)
+	for _, field := range schema.Required {
+		required[field] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]string, 0, len(names))
+	for _, name := range names {
+		prop := schema.Properties[name]
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fields = append(fields, fmt.Sprintf("%s%s: %s;", name, optional, h.tsType(&prop)))
+	}
+	if len(fields) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(fields, " ") + " }"
+}
+
+// apiTypeName turns "okra.state" into "State"
+func (h *HostAPIGenerator) apiTypeName(api string) string {
+	name := strings.TrimPrefix(api, "okra.")
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '_' || r == '-' }) {
+		b.WriteString(exportedName(part))
+	}
+	return b.String()
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// hostAPIRuntime is the envelope handling shared by every generated client
+const hostAPIRuntime = `/** Error details returned by a failed host API call */
+export interface HostApiError {
+  code: string;
+  message: string;
+  details?: string;
+}
+
+/** Thrown when a host API call fails */
+export class HostApiCallError extends Error {
+  readonly code: string;
+  readonly details?: string;
+
+  constructor(error: HostApiError) {
+    super(error.code + ": " + error.message);
+    this.code = error.code;
+    this.details = error.details;
+  }
+}
+
+/** Sends JSON host call envelopes to the okra host module */
+export interface HostTransport {
+  runHostApi(request: string): string;
+  next(request: string): string;
+  closeIterator(request: string): string;
+}
+
+let transport: HostTransport | undefined = (globalThis as any).okraHost;
+
+/** Replaces how host calls are sent, e.g. with a fake in tests */
+export function setHostTransport(t: HostTransport): void {
+  transport = t;
+}
+
+function send(fn: keyof HostTransport, payload: unknown): any {
+  if (!transport) {
+    throw new Error("no OKRA host transport is installed; call setHostTransport");
+  }
+  const response = JSON.parse(transport[fn](JSON.stringify(payload)));
+  if (!response.success) {
+    throw new HostApiCallError(response.error ?? { code: "INTERNAL_ERROR", message: "host call failed" });
+  }
+  return response;
+}
+
+function call<T>(api: string, method: string, params: unknown): T {
+  return send("runHostApi", { api, method, parameters: params }).data as T;
+}
+
+/** Reads the chunks of a streaming host API result */
+export class HostIterator implements Iterable<unknown> {
+  private done: boolean;
+
+  constructor(private readonly id: string | undefined, hasData: boolean | undefined) {
+    this.done = !hasData || !id;
+  }
+
+  /** Returns the next chunk, or undefined once the stream is exhausted */
+  next(): unknown {
+    if (this.done) {
+      return undefined;
+    }
+    try {
+      const response = send("next", { iteratorId: this.id });
+      this.done = !response.hasMore;
+      return response.data;
+    } catch (error) {
+      this.done = true;
+      throw error;
+    }
+  }
+
+  /** Releases the iterator on the host if it is not exhausted */
+  close(): void {
+    if (!this.done) {
+      this.done = true;
+      send("closeIterator", { iteratorId: this.id });
+    }
+  }
+
+  *[Symbol.iterator](): Iterator<unknown> {
+    try {
+      while (!this.done) {
+        yield this.next();
+      }
+    } finally {
+      this.close();
+    }
+  }
+}
+`
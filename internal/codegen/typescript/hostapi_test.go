@@ -0,0 +1,56 @@
+package typescript
+
+import (
+	"testing"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test a client object is generated for every registered host API
+// 2. Test parameter and result types follow the method metadata
+// 3. Test streaming methods return a HostIterator
+
+func TestHostAPIGenerator_Generate(t *testing.T) {
+	registry := hostapi.NewHostAPIRegistry()
+	require.NoError(t, hostapi.InitializeHostAPIs(registry))
+
+	code, err := NewHostAPIGenerator().Generate(registry.List())
+	require.NoError(t, err)
+	result := string(code)
+
+	// Test: The shared runtime is emitted once
+	assert.Contains(t, result, "export function setHostTransport(t: HostTransport): void")
+	assert.Contains(t, result, "export class HostIterator")
+
+	// Test: Every host API gets a client object
+	for _, client := range []string{"state", "log", "env", "secrets", "http", "sql", "cache", "queue", "time", "metrics"} {
+		assert.Contains(t, result, "export const "+client+" = {")
+	}
+
+	// Test: Required and optional fields follow the schema
+	assert.Contains(t, result, "export type StateGetParams = { key: string; };")
+	assert.Contains(t, result, `method?: "GET" | "HEAD" | "POST" | "PUT" | "PATCH" | "DELETE" | "OPTIONS";`)
+	assert.Contains(t, result, "headers?: Record<string, string>;")
+
+	// Test: Unary methods return their result type
+	assert.Contains(t, result, "get(params: StateGetParams): StateGetResult {")
+	assert.Contains(t, result, `return call<StateGetResult>("okra.state", "get", params);`)
+
+	// Test: Streaming methods wrap the iterator
+	assert.Contains(t, result, "list(params: StateListParams): { result: StateListResult; iterator: HostIterator } {")
+	assert.Contains(t, result, "new HostIterator(result.iteratorId, result.hasData)")
+
+	// Test: Declared error codes are documented
+	assert.Contains(t, result, "@throws KEY_TOO_LONG")
+}
+
+func TestHostAPIGenerator_Empty(t *testing.T) {
+	// Test: No factories still produces the runtime
+	code, err := NewHostAPIGenerator().Generate(nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(code), "export interface HostTransport")
+	assert.NotContains(t, string(code), "export const")
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/okra-platform/okra/internal/audit"
+	"github.com/okra-platform/okra/internal/config"
+)
+
+// AuditTailOptions contains options for the audit tail command
+type AuditTailOptions struct {
+	File   string // Audit log path (default: .okra/audit.log in the project root)
+	Lines  int    // Existing records to show
+	Follow bool   // Keep printing records as they are appended
+	JSON   bool   // Print raw JSON records
+}
+
+// AuditTail prints the end of the host API audit log
+func (c *Controller) AuditTail(ctx context.Context, opts AuditTailOptions) error {
+	path := resolveAuditPath(opts.File)
+	if opts.Follow {
+		fmt.Printf("📜 Following %s (Ctrl+C to stop)\n", path)
+	}
+
+	return audit.Tail(ctx, path, audit.TailOptions{Lines: opts.Lines, Follow: opts.Follow}, func(record audit.Record) error {
+		if opts.JSON {
+			line, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(line))
+			return nil
+		}
+		fmt.Println(formatAuditRecord(record))
+		return nil
+	})
+}
+
+// AuditVerify checks the hash chain of the host API audit log
+func (c *Controller) AuditVerify(ctx context.Context, file string) error {
+	path := resolveAuditPath(file)
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	count, err := audit.Verify(f)
+	if err != nil {
+		return fmt.Errorf("audit log verification failed after %d records: %w", count, err)
+	}
+	fmt.Printf("✅ Verified %d audit records in %s\n", count, path)
+	return nil
+}
+
+// resolveAuditPath defaults to the audit log of the enclosing project
+func resolveAuditPath(file string) string {
+	if file != "" {
+		return file
+	}
+	if _, root, err := config.LoadConfig(); err == nil {
+		return filepath.Join(root, audit.DefaultPath)
+	}
+	return audit.DefaultPath
+}
+
+// formatAuditRecord renders a record as a single human-readable line
+func formatAuditRecord(record audit.Record) string {
+	outcome := "✓"
+	switch {
+	case !record.Allowed:
+		outcome = fmt.Sprintf("✗ denied: %s", record.Reason)
+	case record.ErrorCode != "":
+		outcome = fmt.Sprintf("! %s", record.ErrorCode)
+	}
+
+	call := fmt.Sprintf("%s.%s", record.API, record.Method)
+	if record.IteratorID != "" {
+		call += fmt.Sprintf(" [iterator %s]", record.IteratorID)
+	}
+
+	line := fmt.Sprintf("%s #%d %s %s %s %dms",
+		record.Time.Local().Format(time.DateTime), record.Seq, record.Service, call, outcome, record.DurationMs)
+	if len(record.Parameters) > 0 {
+		line += " " + string(record.Parameters)
+	}
+	return line
+}
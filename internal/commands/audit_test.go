@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okra-platform/okra/internal/audit"
+	"github.com/okra-platform/okra/internal/hostapi"
+)
+
+// Test plan for audit commands:
+// 1. Test tail and verify read the project's audit log by default
+// 2. Test verify reports a tampered log
+// 3. Test records are formatted on one line
+
+func TestAudit_TailAndVerify(t *testing.T) {
+	tempDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(oldWd)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "okra.json"), []byte(`{"name": "orders", "language": "go"}`), 0644))
+	path := filepath.Join(tempDir, audit.DefaultPath)
+	sink, err := audit.NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Record(context.Background(), hostapi.AuditEvent{Service: "acme/orders", API: "okra.state", Method: "get", Allowed: true}))
+	require.NoError(t, sink.Close())
+
+	require.NoError(t, os.Chdir(tempDir))
+	controller := &Controller{Flags: &Flags{}}
+	ctx := context.Background()
+
+	require.NoError(t, controller.AuditTail(ctx, AuditTailOptions{Lines: 10}))
+	require.NoError(t, controller.AuditTail(ctx, AuditTailOptions{Lines: 10, JSON: true}))
+	require.NoError(t, controller.AuditVerify(ctx, ""))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), "okra.state", "okra.cache", 1)), 0600))
+	err = controller.AuditVerify(ctx, path)
+	assert.ErrorContains(t, err, "has been modified")
+
+	err = controller.AuditTail(ctx, AuditTailOptions{File: filepath.Join(tempDir, "missing.log")})
+	assert.ErrorContains(t, err, "failed to open audit log")
+}
+
+func TestFormatAuditRecord(t *testing.T) {
+	when := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	record := audit.Record{
+		Seq: 7,
+		AuditEvent: hostapi.AuditEvent{
+			Time:       when,
+			Service:    "acme/orders",
+			API:        "okra.http",
+			Method:     "fetch",
+			Allowed:    false,
+			Reason:     "domain not allowed",
+			DurationMs: 3,
+		},
+	}
+	prefix := when.Local().Format(time.DateTime) + " #7 acme/orders okra.http.fetch "
+	assert.Equal(t, prefix+"✗ denied: domain not allowed 3ms", formatAuditRecord(record))
+
+	record.Allowed = true
+	record.ErrorCode = "TIMEOUT"
+	record.IteratorID = "it-1"
+	record.Parameters = []byte(`{"url":"https://x"}`)
+	assert.Equal(t, when.Local().Format(time.DateTime)+` #7 acme/orders okra.http.fetch [iterator it-1] ! TIMEOUT 3ms {"url":"https://x"}`, formatAuditRecord(record))
+}
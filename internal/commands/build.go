@@ -11,8 +11,18 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// BuildOptions contains options for the build command
+type BuildOptions struct {
+	Runtime string // Admin URL of the target runtime whose host APIs the service needs
+}
+
 // Build compiles OKRA services into packages
-func (c *Controller) Build(ctx context.Context) error {
+func (c *Controller) Build(ctx context.Context, opts ...BuildOptions) error {
+	var buildOpts BuildOptions
+	if len(opts) > 0 {
+		buildOpts = opts[0]
+	}
+
 	// Set up logger
 	logLevel := zerolog.InfoLevel
 	if c.Flags.LogLevel != "" {
@@ -58,6 +68,13 @@ func (c *Controller) Build(ctx context.Context) error {
 
 	// Create builder
 	builder := build.NewServiceBuilder(cfg, projectRoot, logger)
+	if buildOpts.Runtime != "" {
+		catalog, err := loadHostAPICatalog(ctx, buildOpts.Runtime)
+		if err != nil {
+			return fmt.Errorf("failed to load host APIs from %s: %w", buildOpts.Runtime, err)
+		}
+		builder.WithHostAPICatalog(catalog)
+	}
 
 	// Generate code from schema
 	schemaPath := filepath.Join(projectRoot, cfg.Schema)
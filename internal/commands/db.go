@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/migrations"
+	"github.com/okra-platform/okra/internal/schema"
+)
+
+// migrationProject is the configuration and paths shared by the db:migrate commands
+type migrationProject struct {
+	cfg           *config.Config
+	root          string
+	migrationsDir string
+}
+
+// DBMigrateGenerate writes a migration for model changes since the last one
+func (c *Controller) DBMigrateGenerate(ctx context.Context, name string) error {
+	project, err := loadMigrationProject()
+	if err != nil {
+		return err
+	}
+
+	current, err := project.loadSchema()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🔍 Comparing models with the last migration...")
+	migration, err := migrations.Generate(project.migrationsDir, current, name, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to generate migration: %w", err)
+	}
+	if migration == nil {
+		fmt.Println("✅ No schema changes, nothing to generate")
+		return nil
+	}
+
+	for _, change := range migration.Changes {
+		fmt.Printf("   %s\n", change)
+	}
+	fmt.Printf("\n✅ Generated migration %s\n", migration.ID)
+	fmt.Printf("📁 %s\n", project.relative(migration.Dir))
+	fmt.Println("\n💡 Review the SQL, then apply it with:")
+	fmt.Println("   okra db:migrate apply")
+	return nil
+}
+
+// DBMigrateApply applies pending migrations to the configured database
+func (c *Controller) DBMigrateApply(ctx context.Context) error {
+	project, err := loadMigrationProject()
+	if err != nil {
+		return err
+	}
+
+	db, err := project.openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	fmt.Printf("🗄️  Applying migrations to %s\n", project.cfg.Database.URL)
+	applied, err := migrations.NewMigrator(db, project.migrationsDir).Apply(ctx)
+	for _, id := range applied {
+		fmt.Printf("   ✓ %s\n", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("✅ Database is up to date")
+		return nil
+	}
+	fmt.Printf("\n✅ Applied %d migration(s)\n", len(applied))
+	return nil
+}
+
+// DBMigrateStatus lists migrations and whether the models have unrecorded changes
+func (c *Controller) DBMigrateStatus(ctx context.Context) error {
+	project, err := loadMigrationProject()
+	if err != nil {
+		return err
+	}
+
+	db, err := project.openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	statuses, err := migrations.NewMigrator(db, project.migrationsDir).Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read migration status: %w", err)
+	}
+
+	fmt.Printf("🗄️  Database: %s\n\n", project.cfg.Database.URL)
+	if len(statuses) == 0 {
+		fmt.Println("   No migrations")
+	}
+	pending := 0
+	for _, status := range statuses {
+		switch {
+		case status.Missing:
+			fmt.Printf("   ⚠️  %s (applied, missing from %s)\n", status.ID, project.cfg.Database.Migrations.Dir)
+		case status.Modified:
+			fmt.Printf("   ⚠️  %s (applied %s, modified since)\n", status.ID, status.AppliedAt.Local().Format(time.DateTime))
+		case status.Applied:
+			fmt.Printf("   ✓ %s (applied %s)\n", status.ID, status.AppliedAt.Local().Format(time.DateTime))
+		default:
+			pending++
+			fmt.Printf("   • %s (pending)\n", status.ID)
+		}
+	}
+
+	current, err := project.loadSchema()
+	if err != nil {
+		return err
+	}
+	plan, err := migrations.Pending(project.migrationsDir, current)
+	if err != nil {
+		return fmt.Errorf("failed to compare schema: %w", err)
+	}
+	if !plan.Empty() {
+		fmt.Println("\n📝 Model changes not captured in a migration:")
+		for _, change := range plan.Changes {
+			fmt.Printf("   %s\n", change)
+		}
+		fmt.Println("\n💡 Generate a migration with:")
+		fmt.Println("   okra db:migrate generate --name <name>")
+	} else if pending > 0 {
+		fmt.Println("\n💡 Apply pending migrations with:")
+		fmt.Println("   okra db:migrate apply")
+	}
+	return nil
+}
+
+// loadMigrationProject loads okra.json and resolves the migrations directory
+func loadMigrationProject() (*migrationProject, error) {
+	cfg, root, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w\n💡 Run 'okra init' to create a new project", err)
+	}
+	return &migrationProject{
+		cfg:           cfg,
+		root:          root,
+		migrationsDir: filepath.Join(root, cfg.Database.Migrations.Dir),
+	}, nil
+}
+
+// loadSchema parses the model files into a single schema
+func (p *migrationProject) loadSchema() (*schema.Schema, error) {
+	paths := p.cfg.Database.Schemas
+	if len(paths) == 0 {
+		paths = []string{p.cfg.Schema}
+	}
+
+	merged := &schema.Schema{}
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Join(p.root, path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema %s: %w", path, err)
+		}
+		parsed, err := schema.ParseSchema(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+		}
+		merged.Types = append(merged.Types, parsed.Types...)
+		merged.Enums = append(merged.Enums, parsed.Enums...)
+	}
+	return merged, nil
+}
+
+// openDatabase opens the configured database, resolving relative paths against the project root
+func (p *migrationProject) openDatabase() (*sql.DB, error) {
+	path, err := p.cfg.Database.Path(p.root)
+	if err != nil {
+		return nil, err
+	}
+	return migrations.OpenSQLite(path)
+}
+
+// relative formats a path relative to the project root for display
+func (p *migrationProject) relative(path string) string {
+	if rel, err := filepath.Rel(p.root, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okra-platform/okra/internal/migrations"
+)
+
+// Test plan for db:migrate commands:
+// 1. Test generate, apply and status against a project with models
+// 2. Test commands fail gracefully without a configuration
+
+func TestDBMigrate_Workflow(t *testing.T) {
+	tempDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(oldWd)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "okra.json"), []byte(`{
+		"name": "users",
+		"version": "1.0.0",
+		"language": "go",
+		"database": {"schemas": ["./models.okra.gql"]}
+	}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "models.okra.gql"), []byte(`
+model User {
+  id: ID!
+  email: String! @unique
+}`), 0644))
+
+	// Run from a subdirectory to check paths resolve against the project root
+	subDir := filepath.Join(tempDir, "service")
+	require.NoError(t, os.Mkdir(subDir, 0755))
+	require.NoError(t, os.Chdir(subDir))
+
+	controller := &Controller{Flags: &Flags{}}
+	ctx := context.Background()
+
+	require.NoError(t, controller.DBMigrateGenerate(ctx, "create_users"))
+	ids, err := migrations.ListMigrations(filepath.Join(tempDir, "migrations"))
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	assert.Contains(t, ids[0], "_create_users")
+
+	require.NoError(t, controller.DBMigrateStatus(ctx))
+	require.NoError(t, controller.DBMigrateApply(ctx))
+	assert.FileExists(t, filepath.Join(tempDir, ".okra", "dev.db"))
+
+	// No model changes, so nothing new is generated
+	require.NoError(t, controller.DBMigrateGenerate(ctx, ""))
+	ids, err = migrations.ListMigrations(filepath.Join(tempDir, "migrations"))
+	require.NoError(t, err)
+	assert.Len(t, ids, 1)
+}
+
+func TestDBMigrate_MissingConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(oldWd)
+	require.NoError(t, os.Chdir(tempDir))
+
+	controller := &Controller{Flags: &Flags{}}
+	err = controller.DBMigrateApply(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load configuration")
+}
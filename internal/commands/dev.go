@@ -5,8 +5,13 @@ import (
 )
 
 // Dev runs the development server with hot-reloading for OKRA services
-func (c *Controller) Dev(ctx context.Context) error {
+func (c *Controller) Dev(ctx context.Context, opts ...DevOptions) error {
+	var devOpts DevOptions
+	if len(opts) > 0 {
+		devOpts = opts[0]
+	}
+
 	// Use the refactored command
 	cmd := NewDevCommand()
-	return cmd.Execute(ctx)
+	return cmd.Execute(ctx, devOpts)
 }
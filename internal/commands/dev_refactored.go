@@ -11,6 +11,11 @@ import (
 	"github.com/okra-platform/okra/internal/dev"
 )
 
+// DevOptions configures the dev command
+type DevOptions struct {
+	PolicyDir string // Directory of capability policy files, watched for changes
+}
+
 // DevDependencies for the dev command
 type DevDependencies struct {
 	ConfigLoader   ConfigLoader
@@ -25,7 +30,7 @@ type ConfigLoader interface {
 }
 
 type DevServerFactory interface {
-	NewServer(cfg *config.Config, projectRoot string) DevServer
+	NewServer(cfg *config.Config, projectRoot string, opts DevOptions) DevServer
 }
 
 type DevServer interface {
@@ -42,8 +47,12 @@ func (l *defaultConfigLoader) LoadConfig() (*config.Config, string, error) {
 
 type defaultDevServerFactory struct{}
 
-func (f *defaultDevServerFactory) NewServer(cfg *config.Config, projectRoot string) DevServer {
-	return dev.NewServer(cfg, projectRoot)
+func (f *defaultDevServerFactory) NewServer(cfg *config.Config, projectRoot string, opts DevOptions) DevServer {
+	var serverOpts []dev.ServerOption
+	if opts.PolicyDir != "" {
+		serverOpts = append(serverOpts, dev.WithPolicyDir(opts.PolicyDir))
+	}
+	return dev.NewServer(cfg, projectRoot, serverOpts...)
 }
 
 // DevCommand encapsulates the dev logic with injected dependencies
@@ -70,7 +79,7 @@ func (dc *DevCommand) WithDependencies(deps DevDependencies) *DevCommand {
 }
 
 // Execute runs the dev command
-func (dc *DevCommand) Execute(ctx context.Context) error {
+func (dc *DevCommand) Execute(ctx context.Context, opts DevOptions) error {
 	// Load project configuration
 	cfg, projectRoot, err := dc.deps.ConfigLoader.LoadConfig()
 	if err != nil {
@@ -103,7 +112,7 @@ func (dc *DevCommand) Execute(ctx context.Context) error {
 	}()
 
 	// Create and start the dev server
-	server := dc.deps.ServerFactory.NewServer(cfg, projectRoot)
+	server := dc.deps.ServerFactory.NewServer(cfg, projectRoot, opts)
 	if err := server.Start(ctx); err != nil {
 		if err == context.Canceled {
 			return nil
@@ -117,5 +126,5 @@ func (dc *DevCommand) Execute(ctx context.Context) error {
 // Update the Controller's Dev method to use the refactored command
 func (c *Controller) DevRefactored(ctx context.Context) error {
 	cmd := NewDevCommand()
-	return cmd.Execute(ctx)
+	return cmd.Execute(ctx, DevOptions{})
 }
\ No newline at end of file
@@ -45,8 +45,8 @@ type mockDevServerFactory struct {
 	mock.Mock
 }
 
-func (m *mockDevServerFactory) NewServer(cfg *config.Config, projectRoot string) DevServer {
-	args := m.Called(cfg, projectRoot)
+func (m *mockDevServerFactory) NewServer(cfg *config.Config, projectRoot string, opts DevOptions) DevServer {
+	args := m.Called(cfg, projectRoot, opts)
 	return args.Get(0).(DevServer)
 }
 
@@ -71,7 +71,7 @@ func TestDevCommand_Execute_Success(t *testing.T) {
 
 	// Set up expectations
 	mockLoader.On("LoadConfig").Return(mockConfig, "/test/project", nil)
-	mockFactory.On("NewServer", mockConfig, "/test/project").Return(mockServer)
+	mockFactory.On("NewServer", mockConfig, "/test/project", DevOptions{}).Return(mockServer)
 	mockServer.On("Start", mock.Anything).Return(nil)
 	mockSigNotifier.On("Notify", mock.Anything, mock.Anything).Return()
 	mockSigNotifier.On("Stop", mock.Anything).Return()
@@ -87,7 +87,7 @@ func TestDevCommand_Execute_Success(t *testing.T) {
 	}
 
 	// Execute
-	err := cmd.Execute(ctx)
+	err := cmd.Execute(ctx, DevOptions{})
 	assert.NoError(t, err)
 
 	// Verify output
@@ -119,7 +119,7 @@ func TestDevCommand_Execute_ConfigLoadError(t *testing.T) {
 		},
 	}
 
-	err := cmd.Execute(ctx)
+	err := cmd.Execute(ctx, DevOptions{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to load project config")
 	assert.Contains(t, err.Error(), "config not found")
@@ -143,7 +143,7 @@ func TestDevCommand_Execute_ServerStartError(t *testing.T) {
 	output := &mockOutput{}
 
 	mockLoader.On("LoadConfig").Return(mockConfig, "/test/project", nil)
-	mockFactory.On("NewServer", mockConfig, "/test/project").Return(mockServer)
+	mockFactory.On("NewServer", mockConfig, "/test/project", DevOptions{}).Return(mockServer)
 	mockServer.On("Start", mock.Anything).Return(errors.New("server start failed"))
 	mockSigNotifier.On("Notify", mock.Anything, mock.Anything).Return()
 	mockSigNotifier.On("Stop", mock.Anything).Return()
@@ -157,12 +157,48 @@ func TestDevCommand_Execute_ServerStartError(t *testing.T) {
 		},
 	}
 
-	err := cmd.Execute(ctx)
+	err := cmd.Execute(ctx, DevOptions{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dev server error")
 	assert.Contains(t, err.Error(), "server start failed")
 }
 
+func TestDevCommand_Execute_PassesOptions(t *testing.T) {
+	// Test: The policy directory reaches the dev server
+	ctx := context.Background()
+
+	mockConfig := &config.Config{
+		Name:     "test-service",
+		Language: "go",
+		Schema:   "./service.okra.gql",
+	}
+	mockLoader := new(mockConfigLoader)
+	mockFactory := new(mockDevServerFactory)
+	mockServer := new(mockDevServer)
+	mockSigNotifier := new(mockSignalNotifier)
+	output := &mockOutput{}
+
+	opts := DevOptions{PolicyDir: "/test/policies"}
+	mockLoader.On("LoadConfig").Return(mockConfig, "/test/project", nil)
+	mockFactory.On("NewServer", mockConfig, "/test/project", opts).Return(mockServer)
+	mockServer.On("Start", mock.Anything).Return(nil)
+	mockSigNotifier.On("Notify", mock.Anything, mock.Anything).Return()
+	mockSigNotifier.On("Stop", mock.Anything).Return()
+
+	cmd := &DevCommand{
+		deps: DevDependencies{
+			ConfigLoader:   mockLoader,
+			ServerFactory:  mockFactory,
+			SignalNotifier: mockSigNotifier,
+			Output:         output,
+		},
+	}
+
+	err := cmd.Execute(ctx, opts)
+	assert.NoError(t, err)
+	mockFactory.AssertExpectations(t)
+}
+
 func TestDevCommand_Execute_ContextCancelled(t *testing.T) {
 	// Test: Context cancelled returns nil
 	ctx, cancel := context.WithCancel(context.Background())
@@ -179,7 +215,7 @@ func TestDevCommand_Execute_ContextCancelled(t *testing.T) {
 	output := &mockOutput{}
 
 	mockLoader.On("LoadConfig").Return(mockConfig, "/test/project", nil)
-	mockFactory.On("NewServer", mockConfig, "/test/project").Return(mockServer)
+	mockFactory.On("NewServer", mockConfig, "/test/project", DevOptions{}).Return(mockServer)
 	
 	// Simulate context cancelled error
 	mockServer.On("Start", mock.Anything).Run(func(args mock.Arguments) {
@@ -198,7 +234,7 @@ func TestDevCommand_Execute_ContextCancelled(t *testing.T) {
 		},
 	}
 
-	err := cmd.Execute(ctx)
+	err := cmd.Execute(ctx, DevOptions{})
 	assert.NoError(t, err) // context.Canceled is handled gracefully
 }
 
@@ -229,7 +265,7 @@ func TestDevCommand_Execute_SignalHandling(t *testing.T) {
 	mockSigNotifier.On("Stop", mock.Anything).Return()
 
 	mockLoader.On("LoadConfig").Return(mockConfig, "/test/project", nil)
-	mockFactory.On("NewServer", mockConfig, "/test/project").Return(mockServer)
+	mockFactory.On("NewServer", mockConfig, "/test/project", DevOptions{}).Return(mockServer)
 	
 	// Server should block until signal
 	mockServer.On("Start", mock.Anything).Run(func(args mock.Arguments) {
@@ -246,7 +282,7 @@ func TestDevCommand_Execute_SignalHandling(t *testing.T) {
 		},
 	}
 
-	err := cmd.Execute(ctx)
+	err := cmd.Execute(ctx, DevOptions{})
 	assert.NoError(t, err)
 
 	// Verify signal handling output - check in combined messages
@@ -315,7 +351,7 @@ func TestDevCommand_Execute_DifferentLanguages(t *testing.T) {
 			output := &mockOutput{}
 
 			mockLoader.On("LoadConfig").Return(mockConfig, "/test/project", nil)
-			mockFactory.On("NewServer", mockConfig, "/test/project").Return(mockServer)
+			mockFactory.On("NewServer", mockConfig, "/test/project", DevOptions{}).Return(mockServer)
 			mockServer.On("Start", mock.Anything).Return(nil)
 			mockSigNotifier.On("Notify", mock.Anything, mock.Anything).Return()
 			mockSigNotifier.On("Stop", mock.Anything).Return()
@@ -329,7 +365,7 @@ func TestDevCommand_Execute_DifferentLanguages(t *testing.T) {
 				},
 			}
 
-			err := cmd.Execute(ctx)
+			err := cmd.Execute(ctx, DevOptions{})
 			assert.NoError(t, err)
 			assert.Contains(t, output.messages, fmt.Sprintf("🔧 Language: %s\n", tc.language))
 		})
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+)
+
+// HostAPIsOptions contains options for the hostapis command
+type HostAPIsOptions struct {
+	Runtime string // Admin URL of a running runtime (default: the APIs built into this binary)
+	Format  string // "table" (default), "json", "jsonschema" or "openapi"
+	Output  string // File to write instead of stdout
+}
+
+// HostAPIs prints the host APIs a runtime offers
+func (c *Controller) HostAPIs(ctx context.Context, opts HostAPIsOptions) error {
+	catalog, err := loadHostAPICatalog(ctx, opts.Runtime)
+	if err != nil {
+		return err
+	}
+
+	out := io.Writer(os.Stdout)
+	if opts.Output != "" {
+		f, err := os.Create(opts.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := writeHostAPIs(out, catalog, opts.Format); err != nil {
+		return err
+	}
+	if opts.Output != "" {
+		fmt.Printf("✅ Wrote %d host APIs to %s\n", len(catalog.HostAPIs), opts.Output)
+	}
+	return nil
+}
+
+// loadHostAPICatalog fetches the catalog from a runtime's admin API, or
+// describes the APIs built into this binary when no runtime is given
+func loadHostAPICatalog(ctx context.Context, runtimeURL string) (hostapi.Catalog, error) {
+	if runtimeURL == "" {
+		return hostapi.DescribeRegistry(hostapi.NewDefaultHostAPIRegistry()), nil
+	}
+
+	url := strings.TrimSuffix(runtimeURL, "/") + "/api/v1/hostapis"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return hostapi.Catalog{}, fmt.Errorf("invalid runtime URL: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return hostapi.Catalog{}, fmt.Errorf("failed to reach runtime: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return hostapi.Catalog{}, fmt.Errorf("runtime returned %s for %s", resp.Status, url)
+	}
+
+	var catalog hostapi.Catalog
+	if err := json.NewDecoder(resp.Body).Decode(&catalog); err != nil {
+		return hostapi.Catalog{}, fmt.Errorf("failed to decode host API catalog: %w", err)
+	}
+	return catalog, nil
+}
+
+// writeHostAPIs renders the catalog in the requested format
+func writeHostAPIs(w io.Writer, catalog hostapi.Catalog, format string) error {
+	var doc interface{}
+	switch format {
+	case "", "table":
+		return writeHostAPITable(w, catalog)
+	case "json":
+		doc = catalog
+	case "jsonschema":
+		doc = catalog.JSONSchema()
+	case "openapi":
+		doc = catalog.OpenAPI()
+	default:
+		return fmt.Errorf("unsupported format %q (supported: table, json, jsonschema, openapi)", format)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// writeHostAPITable prints each API with one line per method
+func writeHostAPITable(w io.Writer, catalog hostapi.Catalog) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, api := range catalog.HostAPIs {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		if api.Deprecated != "" {
+			fmt.Fprintf(tw, "%s\t%s (deprecated: %s)\n", api.Name, api.Version, api.Deprecated)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\n", api.Name, api.Version)
+		}
+		for _, method := range api.Methods {
+			name := method.Name
+			if method.Streaming {
+				name += " (stream)"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", name, method.Description)
+		}
+	}
+	return tw.Flush()
+}
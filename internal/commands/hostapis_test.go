@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+)
+
+// Test plan for hostapis command:
+// 1. Test the catalog is rendered in every format
+// 2. Test the catalog is fetched from a runtime's admin API
+// 3. Test runtime errors are reported
+
+func TestHostAPIs_WriteFormats(t *testing.T) {
+	catalog := hostapi.DescribeRegistry(hostapi.NewDefaultHostAPIRegistry())
+
+	// Test: The table lists each API and its methods
+	var out bytes.Buffer
+	require.NoError(t, writeHostAPIs(&out, catalog, "table"))
+	assert.Contains(t, out.String(), "okra.state")
+	assert.Contains(t, out.String(), "list (stream)")
+
+	// Test: JSON round-trips the catalog
+	out.Reset()
+	require.NoError(t, writeHostAPIs(&out, catalog, "json"))
+	var decoded hostapi.Catalog
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Len(t, decoded.HostAPIs, len(catalog.HostAPIs))
+
+	// Test: JSON Schema and OpenAPI documents are produced
+	out.Reset()
+	require.NoError(t, writeHostAPIs(&out, catalog, "jsonschema"))
+	assert.Contains(t, out.String(), `"okra.state.get.params"`)
+
+	out.Reset()
+	require.NoError(t, writeHostAPIs(&out, catalog, "openapi"))
+	assert.Contains(t, out.String(), `"swagger": "2.0"`)
+	assert.Contains(t, out.String(), `"/okra.state/get"`)
+
+	// Test: Unknown formats are rejected
+	assert.Error(t, writeHostAPIs(&out, catalog, "xml"))
+}
+
+func TestHostAPIs_LoadFromRuntime(t *testing.T) {
+	catalog := hostapi.Catalog{HostAPIs: []hostapi.HostAPIDescription{{Name: "okra.custom", Version: "v2.0.0"}}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/hostapis" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(catalog)
+	}))
+	defer server.Close()
+
+	// Test: The catalog comes from the runtime, not this binary
+	loaded, err := loadHostAPICatalog(context.Background(), server.URL+"/")
+	require.NoError(t, err)
+	assert.Equal(t, catalog, loaded)
+
+	// Test: Without a runtime the built-in APIs are described
+	loaded, err = loadHostAPICatalog(context.Background(), "")
+	require.NoError(t, err)
+	_, ok := loaded.Lookup("okra.state")
+	assert.True(t, ok)
+
+	// Test: Error statuses are reported
+	_, err = loadHostAPICatalog(context.Background(), server.URL+"/missing")
+	assert.ErrorContains(t, err, "404")
+}
@@ -2,17 +2,24 @@ package commands
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/policy"
 	"github.com/okra-platform/okra/internal/runtime"
 	"github.com/okra-platform/okra/internal/serve"
+	"github.com/okra-platform/okra/internal/wasm"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
 )
 
 // Default ports for okra serve
@@ -25,6 +32,17 @@ const (
 type ServeOptions struct {
 	ServicePort int
 	AdminPort   int
+	PolicyDir   string // Directory of capability policy files, watched for changes
+
+	WASMCacheDir  string // Directory of the compilation cache; empty compiles every module from scratch
+	WASMCacheSize int64  // Size limit of the compilation cache in bytes (0 = unlimited)
+
+	DataDir      string // Directory of durable host API data; empty keeps it in memory
+	DatabasePath string // SQLite database served by okra.sql; empty gives each service its own under DataDir
+
+	SecretsDir  string // Directory okra.secrets reads one file per key from
+	SecretsFile string // Encrypted secrets file okra.secrets reads, an alternative to SecretsDir
+	SecretsKey  string // Hex-encoded 32-byte key of SecretsFile
 }
 
 // Dependencies for the serve command
@@ -40,7 +58,7 @@ type ServeDependencies struct {
 
 // Interfaces for dependency injection
 type RuntimeFactory interface {
-	NewRuntime(logger zerolog.Logger) runtime.Runtime
+	NewRuntime(logger zerolog.Logger, opts ...runtime.OkraRuntimeOption) runtime.Runtime
 }
 
 type GatewayFactory interface {
@@ -49,7 +67,7 @@ type GatewayFactory interface {
 }
 
 type AdminServerFactory interface {
-	NewAdminServer(runtime runtime.Runtime, connectGateway runtime.ConnectGateway, graphqlGateway runtime.GraphQLGateway) AdminServer
+	NewAdminServer(runtime runtime.Runtime, connectGateway runtime.ConnectGateway, graphqlGateway runtime.GraphQLGateway, hostAPIs runtime.HostAPIEnvironment) AdminServer
 }
 
 type AdminServer interface {
@@ -77,8 +95,8 @@ type Output interface {
 // Default implementations
 type defaultRuntimeFactory struct{}
 
-func (f *defaultRuntimeFactory) NewRuntime(logger zerolog.Logger) runtime.Runtime {
-	return runtime.NewOkraRuntime(logger)
+func (f *defaultRuntimeFactory) NewRuntime(logger zerolog.Logger, opts ...runtime.OkraRuntimeOption) runtime.Runtime {
+	return runtime.NewOkraRuntime(logger, opts...)
 }
 
 type defaultGatewayFactory struct{}
@@ -93,8 +111,8 @@ func (f *defaultGatewayFactory) NewGraphQLGateway() runtime.GraphQLGateway {
 
 type defaultAdminServerFactory struct{}
 
-func (f *defaultAdminServerFactory) NewAdminServer(runtime runtime.Runtime, connectGateway runtime.ConnectGateway, graphqlGateway runtime.GraphQLGateway) AdminServer {
-	return serve.NewAdminServer(runtime, connectGateway, graphqlGateway)
+func (f *defaultAdminServerFactory) NewAdminServer(runtime runtime.Runtime, connectGateway runtime.ConnectGateway, graphqlGateway runtime.GraphQLGateway, hostAPIs runtime.HostAPIEnvironment) AdminServer {
+	return serve.NewAdminServerWithHostAPIs(runtime, connectGateway, graphqlGateway, serve.NewPackageLoader(hostAPIs), hostAPIs.Registry)
 }
 
 type defaultHTTPServerFactory struct{}
@@ -177,8 +195,36 @@ func (sc *ServeCommand) Execute(ctx context.Context, opts ServeOptions) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	// Modules compiled by earlier runs are loaded instead of compiled again
+	var runtimeOpts []runtime.OkraRuntimeOption
+	if opts.WASMCacheDir != "" {
+		cache, err := wasm.NewCompilationCache(opts.WASMCacheDir, opts.WASMCacheSize)
+		if err != nil {
+			sc.deps.Output.Printf("Warning: compilation cache disabled: %v\n", err)
+		} else {
+			defer cache.Close(context.Background())
+			runtimeOpts = append(runtimeOpts, runtime.WithCompilationCache(cache))
+		}
+	}
+
+	// Host APIs keep their data in the project, so it survives restarts. The
+	// stores close after the runtime has stopped the services using them.
+	secrets, err := openSecretsProvider(opts)
+	if err != nil {
+		return err
+	}
+	hostAPIs, err := runtime.OpenHostAPIEnvironment(runtime.HostAPIStorage{
+		DataDir:      opts.DataDir,
+		DatabasePath: opts.DatabasePath,
+		Secrets:      secrets,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open host API storage: %w", err)
+	}
+	defer hostAPIs.Close()
+
 	// Create runtime
-	okraRuntime := sc.deps.RuntimeFactory.NewRuntime(sc.deps.Logger)
+	okraRuntime := sc.deps.RuntimeFactory.NewRuntime(sc.deps.Logger, runtimeOpts...)
 	if err := okraRuntime.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start runtime: %w", err)
 	}
@@ -190,12 +236,50 @@ func (sc *ServeCommand) Execute(ctx context.Context, opts ServeOptions) error {
 		}
 	}()
 
+	// Export host and guest metrics for Prometheus on the service gateway
+	meterProvider, metricsHandler, err := serve.NewPrometheusMeterProvider()
+	if err != nil {
+		return fmt.Errorf("failed to create metrics exporter: %w", err)
+	}
+	otel.SetMeterProvider(meterProvider)
+	defer meterProvider.Shutdown(context.Background())
+
+	// Every deployed service gets its declared host APIs from one registry and
+	// is compiled into the runtime's engine
+	hostAPIs.Engine = okraRuntime.Engine()
+
+	// Deliver queued messages to the services subscribed to their topics. The
+	// dispatcher stops before the runtime shuts down.
+	dispatcherCtx, stopDispatcher := context.WithCancel(ctx)
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		hostapi.NewQueueDispatcher(hostAPIs.Queues, okraRuntime.QueueDelivery()).Run(dispatcherCtx)
+	}()
+	defer func() {
+		stopDispatcher()
+		<-dispatcherDone
+	}()
+
+	// Load capability policies and reload them as the files change
+	if opts.PolicyDir != "" {
+		policyEngine, err := policy.NewEngineFromDir(opts.PolicyDir)
+		if err != nil {
+			return fmt.Errorf("failed to load policies: %w", err)
+		}
+		if err := policyEngine.Watch(ctx); err != nil {
+			return fmt.Errorf("failed to watch policies: %w", err)
+		}
+		sc.deps.Output.Printf("Loaded %d policies from %s\n", policyEngine.PolicyCount(), opts.PolicyDir)
+		hostAPIs.Config.PolicyEngine = policyEngine
+	}
+
 	// Create gateways for service exposure
 	connectGateway := sc.deps.GatewayFactory.NewConnectGateway()
 	graphqlGateway := sc.deps.GatewayFactory.NewGraphQLGateway()
 
 	// Create admin server
-	adminServer := sc.deps.AdminServerFactory.NewAdminServer(okraRuntime, connectGateway, graphqlGateway)
+	adminServer := sc.deps.AdminServerFactory.NewAdminServer(okraRuntime, connectGateway, graphqlGateway, hostAPIs)
 
 	// Start both servers
 	var wg sync.WaitGroup
@@ -211,6 +295,7 @@ func (sc *ServeCommand) Execute(ctx context.Context, opts ServeOptions) error {
 		mux := http.NewServeMux()
 		mux.Handle("/connect/", connectGateway.Handler())
 		mux.Handle("/graphql/", graphqlGateway.Handler())
+		mux.Handle(serve.MetricsPath, metricsHandler)
 
 		gatewayServer := sc.deps.HTTPServerFactory.NewHTTPServer(
 			fmt.Sprintf(":%d", servicePort),
@@ -272,8 +357,65 @@ func (c *Controller) Serve(ctx context.Context, opts ...ServeOptions) error {
 		if opts[0].AdminPort > 0 {
 			serveOpts.AdminPort = opts[0].AdminPort
 		}
+		serveOpts.PolicyDir = opts[0].PolicyDir
+		serveOpts.WASMCacheDir = opts[0].WASMCacheDir
+		serveOpts.WASMCacheSize = opts[0].WASMCacheSize
+		serveOpts.DataDir = opts[0].DataDir
+		serveOpts.DatabasePath = opts[0].DatabasePath
+		serveOpts.SecretsDir = opts[0].SecretsDir
+		serveOpts.SecretsFile = opts[0].SecretsFile
+		serveOpts.SecretsKey = opts[0].SecretsKey
+	}
+	dataDir, databasePath := projectStorage()
+	if serveOpts.DataDir == "" {
+		serveOpts.DataDir = dataDir
+	}
+	if serveOpts.DatabasePath == "" {
+		serveOpts.DatabasePath = databasePath
 	}
 	
 	cmd := NewServeCommand()
 	return cmd.Execute(ctx, serveOpts)
-}
\ No newline at end of file
+}
+
+// openSecretsProvider opens the provider okra.secrets reads from: a directory
+// of files or an encrypted file. Without either, services can read no secrets.
+func openSecretsProvider(opts ServeOptions) (hostapi.SecretsProvider, error) {
+	switch {
+	case opts.SecretsDir != "" && opts.SecretsFile != "":
+		return nil, fmt.Errorf("secrets directory and secrets file are mutually exclusive")
+	case opts.SecretsDir != "":
+		provider, err := hostapi.NewDirectorySecretsProvider(opts.SecretsDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open secrets directory: %w", err)
+		}
+		return provider, nil
+	case opts.SecretsFile != "":
+		// Never echo the key, even in part
+		key, err := hex.DecodeString(opts.SecretsKey)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("secrets key must be 32 bytes, hex-encoded")
+		}
+		provider, err := hostapi.NewEncryptedFileSecretsProvider(opts.SecretsFile, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open secrets file: %w", err)
+		}
+		return provider, nil
+	}
+	return nil, nil
+}
+
+// projectStorage locates host API data in the project enclosing the working
+// directory: its .okra directory and the database its migrations target.
+// Outside a project, data goes to .okra in the working directory.
+func projectStorage() (dataDir, databasePath string) {
+	cfg, root, err := config.LoadConfig()
+	if err != nil {
+		return ".okra", ""
+	}
+	databasePath, err = cfg.Database.Path(root)
+	if err != nil {
+		databasePath = ""
+	}
+	return filepath.Join(root, ".okra"), databasePath
+}
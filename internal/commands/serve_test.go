@@ -2,20 +2,27 @@ package commands
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/okra-platform/okra/internal/runtime"
 	"github.com/okra-platform/okra/internal/schema"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/wasm"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/tochemey/goakt/v2/actors"
+	"go.opentelemetry.io/otel"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -78,12 +85,25 @@ func (m *mockRuntime) Shutdown(ctx context.Context) error {
 	return args.Error(0)
 }
 
+// Engine returns nil, so packages are compiled into runtimes of their own
+func (m *mockRuntime) Engine() wasm.WASMEngine {
+	return nil
+}
+
+func (m *mockRuntime) QueueDelivery() hostapi.QueueDeliverFunc {
+	return func(ctx context.Context, sub hostapi.QueueSubscription, message hostapi.QueueMessage) error {
+		return nil
+	}
+}
+
 type mockRuntimeFactory struct {
 	mock.Mock
+	opts []runtime.OkraRuntimeOption
 }
 
-func (m *mockRuntimeFactory) NewRuntime(logger zerolog.Logger) runtime.Runtime {
+func (m *mockRuntimeFactory) NewRuntime(logger zerolog.Logger, opts ...runtime.OkraRuntimeOption) runtime.Runtime {
 	args := m.Called(logger)
+	m.opts = opts
 	return args.Get(0).(runtime.Runtime)
 }
 
@@ -157,8 +177,8 @@ type mockAdminServerFactory struct {
 	mock.Mock
 }
 
-func (m *mockAdminServerFactory) NewAdminServer(runtime runtime.Runtime, connectGateway runtime.ConnectGateway, graphqlGateway runtime.GraphQLGateway) AdminServer {
-	args := m.Called(runtime, connectGateway, graphqlGateway)
+func (m *mockAdminServerFactory) NewAdminServer(runtime runtime.Runtime, connectGateway runtime.ConnectGateway, graphqlGateway runtime.GraphQLGateway, hostAPIs runtime.HostAPIEnvironment) AdminServer {
+	args := m.Called(runtime, connectGateway, graphqlGateway, hostAPIs)
 	return args.Get(0).(AdminServer)
 }
 
@@ -233,7 +253,9 @@ func TestServeCommand_Execute_Success(t *testing.T) {
 	mockConnectGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	mockGraphQLGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	
-	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW).Return(mockAdminSrv)
+	// Packages are loaded with host APIs from the shared registry
+	withRegistry := mock.MatchedBy(func(env runtime.HostAPIEnvironment) bool { return env.Registry != nil })
+	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW, withRegistry).Return(mockAdminSrv)
 	mockAdminSrv.On("Start", mock.Anything, 8081).Return(nil)
 	
 	mockHTTPFactory.On("NewHTTPServer", ":8080", mock.Anything).Return(mockHTTPSrv)
@@ -272,6 +294,125 @@ func TestServeCommand_Execute_Success(t *testing.T) {
 	mockAdminFactory.AssertExpectations(t)
 }
 
+func TestServeCommand_Execute_CompilationCache(t *testing.T) {
+	// Test: The runtime compiles packages through the cache opened in the configured directory
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// Create mocks
+	mockRT := new(mockRuntime)
+	mockRTFactory := new(mockRuntimeFactory)
+	mockConnectGW := new(mockConnectGateway)
+	mockGraphQLGW := new(mockGraphQLGateway)
+	mockGWFactory := new(mockGatewayFactory)
+	mockAdminSrv := new(mockAdminServer)
+	mockAdminFactory := new(mockAdminServerFactory)
+	mockHTTPSrv := new(mockHTTPServer)
+	mockHTTPFactory := new(mockHTTPServerFactory)
+	mockSigNotifier := new(mockSignalNotifier)
+	output := &mockOutput{}
+
+	// Set up expectations
+	mockRTFactory.On("NewRuntime", mock.Anything).Return(mockRT)
+	mockRT.On("Start", mock.Anything).Return(nil)
+	mockRT.On("Shutdown", mock.Anything).Return(nil)
+	
+	mockGWFactory.On("NewConnectGateway").Return(mockConnectGW)
+	mockGWFactory.On("NewGraphQLGateway").Return(mockGraphQLGW)
+	mockConnectGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	mockGraphQLGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	
+	cacheDir := t.TempDir()
+	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW, mock.Anything).Return(mockAdminSrv)
+	mockAdminSrv.On("Start", mock.Anything, 8081).Return(nil)
+	
+	mockHTTPFactory.On("NewHTTPServer", ":8080", mock.Anything).Return(mockHTTPSrv)
+	mockHTTPSrv.On("ListenAndServe").Return(nil)
+	
+	mockSigNotifier.On("Notify", mock.Anything, mock.Anything).Return()
+	mockSigNotifier.On("Stop", mock.Anything).Return()
+
+	// Create command with mocked dependencies
+	cmd := &ServeCommand{
+		deps: ServeDependencies{
+			RuntimeFactory:     mockRTFactory,
+			GatewayFactory:     mockGWFactory,
+			AdminServerFactory: mockAdminFactory,
+			HTTPServerFactory:  mockHTTPFactory,
+			SignalNotifier:     mockSigNotifier,
+			Logger:             zerolog.Nop(),
+			Output:             output,
+		},
+	}
+
+	// Execute
+	err := cmd.Execute(ctx, ServeOptions{WASMCacheDir: cacheDir, WASMCacheSize: 1 << 20})
+	assert.NoError(t, err)
+
+	// Verify the cache was opened and handed to the runtime
+	assert.DirExists(t, cacheDir)
+	assert.Len(t, mockRTFactory.opts, 1)
+	mockRTFactory.AssertExpectations(t)
+}
+
+func TestServeCommand_Execute_MetricsEndpoint(t *testing.T) {
+	// Test: The service gateway serves Prometheus metrics from the global meter provider
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	mockRT := new(mockRuntime)
+	mockRTFactory := new(mockRuntimeFactory)
+	mockConnectGW := new(mockConnectGateway)
+	mockGraphQLGW := new(mockGraphQLGateway)
+	mockGWFactory := new(mockGatewayFactory)
+	mockAdminSrv := new(mockAdminServer)
+	mockAdminFactory := new(mockAdminServerFactory)
+	mockHTTPSrv := new(mockHTTPServer)
+	mockHTTPFactory := new(mockHTTPServerFactory)
+	mockSigNotifier := new(mockSignalNotifier)
+
+	mockRTFactory.On("NewRuntime", mock.Anything).Return(mockRT)
+	mockRT.On("Start", mock.Anything).Return(nil)
+	mockRT.On("Shutdown", mock.Anything).Return(nil)
+	mockGWFactory.On("NewConnectGateway").Return(mockConnectGW)
+	mockGWFactory.On("NewGraphQLGateway").Return(mockGraphQLGW)
+	mockConnectGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	mockGraphQLGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW, mock.Anything).Return(mockAdminSrv)
+	mockAdminSrv.On("Start", mock.Anything, 8081).Return(nil)
+	mockSigNotifier.On("Notify", mock.Anything, mock.Anything).Return()
+	mockSigNotifier.On("Stop", mock.Anything).Return()
+
+	// Scrape the gateway while the command is still running
+	var body string
+	mockHTTPFactory.On("NewHTTPServer", ":8080", mock.Anything).Run(func(args mock.Arguments) {
+		counter, err := otel.Meter("test").Int64Counter("okra_test_requests")
+		require.NoError(t, err)
+		counter.Add(context.Background(), 1)
+
+		rec := httptest.NewRecorder()
+		args.Get(1).(http.Handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		body = rec.Body.String()
+	}).Return(mockHTTPSrv)
+	mockHTTPSrv.On("ListenAndServe").Return(nil)
+
+	cmd := &ServeCommand{
+		deps: ServeDependencies{
+			RuntimeFactory:     mockRTFactory,
+			GatewayFactory:     mockGWFactory,
+			AdminServerFactory: mockAdminFactory,
+			HTTPServerFactory:  mockHTTPFactory,
+			SignalNotifier:     mockSigNotifier,
+			Logger:             zerolog.Nop(),
+			Output:             &mockOutput{},
+		},
+	}
+
+	err := cmd.Execute(ctx, ServeOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, body, "okra_test_requests_total")
+}
+
 func TestServeCommand_Execute_RuntimeStartError(t *testing.T) {
 	// Test: Runtime fails to start
 	ctx := context.Background()
@@ -296,6 +437,32 @@ func TestServeCommand_Execute_RuntimeStartError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to start runtime")
 }
 
+func TestServeCommand_Execute_InvalidPolicies(t *testing.T) {
+	// Test: Invalid policy files stop serve before any server starts
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("capabilities:\n  state:\n    condition: \"((\"\n"), 0644))
+
+	mockRT := new(mockRuntime)
+	mockRTFactory := new(mockRuntimeFactory)
+	mockRTFactory.On("NewRuntime", mock.Anything).Return(mockRT)
+	mockRT.On("Start", mock.Anything).Return(nil)
+	mockRT.On("Shutdown", mock.Anything).Return(nil)
+
+	cmd := &ServeCommand{
+		deps: ServeDependencies{
+			RuntimeFactory: mockRTFactory,
+			Logger:         zerolog.Nop(),
+			Output:         &mockOutput{},
+		},
+	}
+
+	err := cmd.Execute(ctx, ServeOptions{PolicyDir: dir})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load policies")
+}
+
 func TestServeCommand_Execute_CustomPorts(t *testing.T) {
 	// Test: Custom ports are used
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
@@ -324,7 +491,7 @@ func TestServeCommand_Execute_CustomPorts(t *testing.T) {
 	mockConnectGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	mockGraphQLGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	
-	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW).Return(mockAdminSrv)
+	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW, mock.Anything).Return(mockAdminSrv)
 	mockAdminSrv.On("Start", mock.Anything, 9091).Return(nil) // Custom admin port
 	
 	mockHTTPFactory.On("NewHTTPServer", ":9090", mock.Anything).Return(mockHTTPSrv) // Custom service port
@@ -384,7 +551,7 @@ func TestServeCommand_Execute_ServerError(t *testing.T) {
 	mockConnectGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	mockGraphQLGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	
-	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW).Return(mockAdminSrv)
+	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW, mock.Anything).Return(mockAdminSrv)
 	mockAdminSrv.On("Start", mock.Anything, 8081).Return(nil)
 	
 	mockHTTPFactory.On("NewHTTPServer", ":8080", mock.Anything).Return(mockHTTPSrv)
@@ -459,7 +626,7 @@ func TestServeCommand_Execute_SignalHandling(t *testing.T) {
 	mockConnectGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	mockGraphQLGW.On("Handler").Return(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	
-	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW).Return(mockAdminSrv)
+	mockAdminFactory.On("NewAdminServer", mockRT, mockConnectGW, mockGraphQLGW, mock.Anything).Return(mockAdminSrv)
 	mockAdminSrv.On("Start", mock.Anything, 8081).Return(nil)
 	
 	mockHTTPFactory.On("NewHTTPServer", ":8080", mock.Anything).Return(mockHTTPSrv)
@@ -522,4 +689,44 @@ func TestServeOptions(t *testing.T) {
 	
 	assert.Equal(t, 8090, opts.ServicePort)
 	assert.Equal(t, 8091, opts.AdminPort)
-}
\ No newline at end of file
+}
+func TestOpenSecretsProvider(t *testing.T) {
+	ctx := context.Background()
+
+	// Test: Without a source no provider is configured
+	provider, err := openSecretsProvider(ServeOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+
+	// Test: A secrets directory serves one file per key
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "db"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db", "password"), []byte("hunter2\n"), 0600))
+	provider, err = openSecretsProvider(ServeOptions{SecretsDir: dir})
+	require.NoError(t, err)
+	value, ok, err := provider.Get(ctx, "db/password")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "hunter2", value)
+
+	// Test: An encrypted file is read with the hex-encoded key
+	key := []byte("0123456789abcdef0123456789abcdef")
+	sealed, err := hostapi.EncryptSecrets(map[string]string{"api/key": "k-1"}, key)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "secrets.enc")
+	require.NoError(t, os.WriteFile(file, sealed, 0600))
+	provider, err = openSecretsProvider(ServeOptions{SecretsFile: file, SecretsKey: hex.EncodeToString(key)})
+	require.NoError(t, err)
+	value, ok, err = provider.Get(ctx, "api/key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "k-1", value)
+
+	// Test: A missing or malformed key, or both sources at once, are rejected
+	_, err = openSecretsProvider(ServeOptions{SecretsFile: file})
+	assert.ErrorContains(t, err, "32 bytes")
+	_, err = openSecretsProvider(ServeOptions{SecretsFile: file, SecretsKey: "zz"})
+	assert.ErrorContains(t, err, "32 bytes")
+	_, err = openSecretsProvider(ServeOptions{SecretsDir: dir, SecretsFile: file})
+	assert.ErrorContains(t, err, "mutually exclusive")
+}
@@ -5,17 +5,32 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Config represents the okra.json configuration file
 type Config struct {
-	Name     string      `json:"name"`
-	Version  string      `json:"version"`
-	Language string      `json:"language"`
-	Schema   string      `json:"schema"`
-	Source   string      `json:"source"`
-	Build    BuildConfig `json:"build"`
-	Dev      DevConfig   `json:"dev"`
+	Name     string         `json:"name"`
+	Version  string         `json:"version"`
+	Language string         `json:"language"`
+	Schema   string         `json:"schema"`
+	Source   string         `json:"source"`
+	Tags     []string       `json:"tags,omitempty"` // Policy targeting tags, e.g. "internal"
+	Build    BuildConfig    `json:"build"`
+	Dev      DevConfig      `json:"dev"`
+	Env      EnvConfig      `json:"env"`
+	Secrets  SecretsConfig  `json:"secrets"`
+	HTTP     HTTPConfig     `json:"http"`
+	Database DatabaseConfig `json:"database"`
+
+	RateLimits []RateLimitConfig `json:"rateLimits,omitempty"`
+	Audit      AuditConfig       `json:"audit"`
+	Limits     LimitsConfig      `json:"limits"`
+	Workers    WorkersConfig     `json:"workers"`
+
+	// Host APIs the service calls, keyed by name with the version range it
+	// needs, e.g. {"okra.state": "^1.0.0"}
+	HostAPIs map[string]string `json:"hostApis,omitempty"`
 }
 
 // BuildConfig contains build-specific configuration
@@ -29,6 +44,80 @@ type DevConfig struct {
 	Exclude []string `json:"exclude"`
 }
 
+// EnvConfig controls which environment variables the service may read via okra.env
+type EnvConfig struct {
+	AllowedKeys []string `json:"allowedKeys"` // Exact names or prefixes ending in "*"
+}
+
+// SecretsConfig controls which secrets the service may read via okra.secrets
+type SecretsConfig struct {
+	AllowedKeys []string `json:"allowedKeys"` // Exact keys or prefixes ending in "*"
+}
+
+// HTTPConfig controls which outbound requests the service may make via okra.http
+type HTTPConfig struct {
+	AllowedSites   []string `json:"allowedSites"`   // e.g. "https://api.example.com", "*.trusted.net"
+	AllowedMethods []string `json:"allowedMethods"` // Empty allows all standard methods
+}
+
+// RateLimitConfig limits how often the service may call matching host API methods.
+// Counters are shared by every worker of the service.
+type RateLimitConfig struct {
+	Target            string  `json:"target"`            // "<api>.<method>", "<api>.*", "<api>" or "*" (e.g. "http.fetch")
+	RequestsPerSecond float64 `json:"requestsPerSecond"` // Sustained call rate (0 = no rate limit)
+	Burst             int     `json:"burst"`             // Calls allowed at once (0 = one second's worth)
+	DailyQuota        int64   `json:"dailyQuota"`        // Calls allowed per UTC day (0 = no quota)
+}
+
+// AuditConfig controls what the host API audit log records for the service
+type AuditConfig struct {
+	RedactFields []string `json:"redactFields"` // Parameter fields redacted in addition to the defaults, e.g. "*address*"
+}
+
+// LimitsConfig caps the resources each worker of the service may use. A
+// request that exceeds a limit fails and the worker that ran it is replaced.
+type LimitsConfig struct {
+	MaxMemoryPages    uint32 `json:"maxMemoryPages"`    // Linear memory per worker in 64 KiB pages (0 = no cap)
+	MaxExecutionMs    uint64 `json:"maxExecutionMs"`    // Wall-clock time per request (0 = no limit)
+	InstructionBudget uint64 `json:"instructionBudget"` // Guest function calls per request (0 = no budget)
+}
+
+// WorkersConfig controls how long the service's workers live and how new ones
+// start
+type WorkersConfig struct {
+	IdleTimeoutMs  int64  `json:"idleTimeoutMs"`  // Idle time before a worker beyond the minimum is closed (0 = 5 minutes, negative = never)
+	MaxInvocations uint64 `json:"maxInvocations"` // Invocations before a worker is replaced (0 = never)
+	Snapshot       bool   `json:"snapshot"`       // Start workers from a snapshot of one initialized worker
+}
+
+// DatabaseConfig controls the local database used by okra db:* commands
+type DatabaseConfig struct {
+	URL        string           `json:"url"`     // e.g. "sqlite://.okra/dev.db"
+	Schemas    []string         `json:"schemas"` // Model files; defaults to the service schema
+	Migrations MigrationsConfig `json:"migrations"`
+}
+
+// Path returns the file path of the SQLite database named by URL, resolving
+// a relative path against the project root
+func (d DatabaseConfig) Path(root string) (string, error) {
+	path := strings.TrimPrefix(d.URL, "sqlite://")
+	if strings.Contains(path, "://") {
+		return "", fmt.Errorf("unsupported database URL %q: only sqlite:// is supported", d.URL)
+	}
+	if path == "" {
+		return "", fmt.Errorf("database URL %q has no path", d.URL)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	return path, nil
+}
+
+// MigrationsConfig controls where generated migrations are stored
+type MigrationsConfig struct {
+	Dir string `json:"dir"`
+}
+
 // LoadConfig loads the okra.json configuration from the current directory or a parent directory
 func LoadConfig() (*Config, string, error) {
 	dir, err := os.Getwd()
@@ -61,6 +150,12 @@ func LoadConfigFromPath(path string) (*Config, error) {
 	if config.Build.Output == "" {
 		config.Build.Output = "./build/service.wasm"
 	}
+	if config.Database.URL == "" {
+		config.Database.URL = "sqlite://.okra/dev.db"
+	}
+	if config.Database.Migrations.Dir == "" {
+		config.Database.Migrations.Dir = "./migrations"
+	}
 	if len(config.Dev.Watch) == 0 {
 		// Set default watch patterns based on language
 		switch config.Language {
@@ -32,6 +32,21 @@ func TestLoadConfigFromPath(t *testing.T) {
 					Watch:   []string{"*.go", "*.graphql"},
 					Exclude: []string{"vendor/", "*.test.go"},
 				},
+				Env: EnvConfig{
+					AllowedKeys: []string{"MODE", "FEATURE_*"},
+				},
+				Secrets: SecretsConfig{
+					AllowedKeys: []string{"db/password"},
+				},
+				HTTP: HTTPConfig{
+					AllowedSites:   []string{"https://api.example.com", "*.trusted.net"},
+					AllowedMethods: []string{"GET", "POST"},
+				},
+				Database: DatabaseConfig{
+					URL:        "sqlite://data/app.db",
+					Schemas:    []string{"./models.okra.gql"},
+					Migrations: MigrationsConfig{Dir: "./db/migrations"},
+				},
 			},
 		},
 		{
@@ -80,6 +95,9 @@ func TestLoadConfigFromPath(t *testing.T) {
 			assert.Equal(t, tt.config.Name, got.Name)
 			assert.Equal(t, tt.config.Version, got.Version)
 			assert.Equal(t, tt.config.Language, got.Language)
+			assert.Equal(t, tt.config.Env, got.Env)
+			assert.Equal(t, tt.config.Secrets, got.Secrets)
+			assert.Equal(t, tt.config.HTTP, got.HTTP)
 
 			// Check defaults were applied
 			if tt.config.Source == "" {
@@ -91,6 +109,12 @@ func TestLoadConfigFromPath(t *testing.T) {
 			if tt.config.Build.Output == "" {
 				assert.Equal(t, "./build/service.wasm", got.Build.Output)
 			}
+			if tt.config.Database.URL == "" {
+				assert.Equal(t, "sqlite://.okra/dev.db", got.Database.URL)
+				assert.Equal(t, "./migrations", got.Database.Migrations.Dir)
+			} else {
+				assert.Equal(t, tt.config.Database, got.Database)
+			}
 
 			// Check language-specific defaults for watch patterns
 			if len(tt.config.Dev.Watch) == 0 {
@@ -235,3 +259,32 @@ func TestLoadConfig(t *testing.T) {
 		assert.Contains(t, err.Error(), "no okra.json found")
 	})
 }
+
+func TestDatabaseConfig_Path(t *testing.T) {
+	root := filepath.Join("/", "project")
+
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"relative sqlite url", "sqlite://.okra/dev.db", filepath.Join(root, ".okra", "dev.db"), false},
+		{"absolute sqlite url", "sqlite:///data/app.db", "/data/app.db", false},
+		{"plain path", "data/app.db", filepath.Join(root, "data", "app.db"), false},
+		{"other scheme", "postgres://localhost/app", "", true},
+		{"no path", "sqlite://", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DatabaseConfig{URL: tt.url}.Path(root)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/okra-platform/okra/internal/build"
 	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/policy"
 	"github.com/okra-platform/okra/internal/runtime"
 	"github.com/okra-platform/okra/internal/schema"
 	"github.com/okra-platform/okra/internal/wasm"
@@ -33,6 +35,13 @@ type Server struct {
 	connectGateway runtime.ConnectGateway
 	graphqlGateway runtime.GraphQLGateway
 	httpServer     *http.Server
+	hostAPIs       runtime.HostAPIEnvironment // Shared by every deployment of the service
+	cache          wasm.CompilationCache
+	policyDir      string // Directory of capability policy files; empty allows every call
+
+	// Queue delivery to the service's subscribed methods
+	stopDispatcher context.CancelFunc
+	dispatcherDone chan struct{}
 
 	// Current deployment state
 	currentActorID   string
@@ -46,20 +55,36 @@ type Server struct {
 	builder build.Builder
 }
 
+// ServerOption configures a development server
+type ServerOption func(*Server)
+
+// WithPolicyDir loads the capability policies in dir and reloads them as the
+// files change
+func WithPolicyDir(dir string) ServerOption {
+	return func(s *Server) {
+		s.policyDir = dir
+	}
+}
+
 // NewServer creates a new development server
-func NewServer(cfg *config.Config, projectRoot string) *Server {
+func NewServer(cfg *config.Config, projectRoot string, opts ...ServerOption) *Server {
 	// Create a logger for the dev server
 	logger := zerolog.New(os.Stderr).With().
 		Timestamp().
 		Str("component", "dev-server").
 		Logger()
 
-	return &Server{
+	s := &Server{
 		config:      cfg,
 		projectRoot: projectRoot,
 		logger:      logger,
 		builder:     build.NewServiceBuilder(cfg, projectRoot, logger),
+		hostAPIs:    runtime.NewHostAPIEnvironment(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // Start runs the development server
@@ -74,12 +99,26 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	// Rebuilds that leave the WASM unchanged skip compiling it again
+	s.openCompilationCache()
+
+	// Host APIs keep their data in the project, in the database its
+	// migrations target
+	if err := s.openHostAPIs(); err != nil {
+		return err
+	}
+	if err := s.loadPolicies(ctx); err != nil {
+		return err
+	}
+
 	// Initialize runtime with a logger
 	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
-	s.runtime = runtime.NewOkraRuntime(logger)
+	s.runtime = runtime.NewOkraRuntime(logger, runtime.WithCompilationCache(s.cache))
 	if err := s.runtime.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start runtime: %w", err)
 	}
+	s.hostAPIs.Engine = s.runtime.Engine()
+	s.startQueueDispatcher(ctx)
 	fmt.Println("🚀 Runtime started successfully")
 
 	// Initialize gateways
@@ -241,6 +280,12 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
+	// Stop delivering queued messages before the service goes away
+	if s.stopDispatcher != nil {
+		s.stopDispatcher()
+		<-s.dispatcherDone
+	}
+
 	// Shutdown runtime
 	if s.runtime != nil {
 		if err := s.runtime.Shutdown(ctx); err != nil {
@@ -248,10 +293,85 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
+	if s.cache != nil {
+		s.cache.Close(ctx)
+	}
+
+	if err := s.hostAPIs.Close(); err != nil {
+		fmt.Printf("⚠️  Warning: failed to close host API storage: %v\n", err)
+	}
+
 	fmt.Println("✅ Development server stopped")
 	return nil
 }
 
+// openCompilationCache opens the user's compilation cache for the deployments
+// of this server. Without it every deployment compiles from scratch.
+func (s *Server) openCompilationCache() {
+	dir, err := wasm.DefaultCompilationCacheDir()
+	if err == nil {
+		s.cache, err = wasm.NewCompilationCache(dir, wasm.DefaultCompilationCacheSize)
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Warning: compilation cache disabled: %v\n", err)
+	}
+}
+
+// openHostAPIs backs the host APIs with the project's .okra directory and
+// configured database
+func (s *Server) openHostAPIs() error {
+	storage := runtime.HostAPIStorage{DataDir: filepath.Join(s.projectRoot, ".okra")}
+	if s.config.Database.URL != "" {
+		databasePath, err := s.config.Database.Path(s.projectRoot)
+		if err != nil {
+			return fmt.Errorf("invalid database configuration: %w", err)
+		}
+		storage.DatabasePath = databasePath
+	}
+
+	hostAPIs, err := runtime.OpenHostAPIEnvironment(storage)
+	if err != nil {
+		return fmt.Errorf("failed to open host API storage: %w", err)
+	}
+	s.hostAPIs = hostAPIs
+	return nil
+}
+
+// loadPolicies loads the capability policies every deployment of the service
+// is checked against, and reloads them as the files change until ctx is done
+func (s *Server) loadPolicies(ctx context.Context) error {
+	if s.policyDir == "" {
+		return nil
+	}
+
+	policyEngine, err := policy.NewEngineFromDir(s.policyDir)
+	if err != nil {
+		return fmt.Errorf("failed to load policies: %w", err)
+	}
+	if err := policyEngine.Watch(ctx); err != nil {
+		return fmt.Errorf("failed to watch policies: %w", err)
+	}
+	fmt.Printf("🔒 Loaded %d policies from %s\n", policyEngine.PolicyCount(), s.policyDir)
+	s.hostAPIs.Config.PolicyEngine = policyEngine
+	return nil
+}
+
+// startQueueDispatcher delivers queued messages to the methods the service
+// subscribed to them, until Stop
+func (s *Server) startQueueDispatcher(ctx context.Context) {
+	if s.hostAPIs.Queues == nil {
+		return
+	}
+
+	ctx, s.stopDispatcher = context.WithCancel(ctx)
+	s.dispatcherDone = make(chan struct{})
+	dispatcher := hostapi.NewQueueDispatcher(s.hostAPIs.Queues, s.runtime.QueueDelivery())
+	go func() {
+		defer close(s.dispatcherDone)
+		dispatcher.Run(ctx)
+	}()
+}
+
 // handleFileChange is called when a watched file changes
 func (s *Server) handleFileChange(path string, op fsnotify.Op) {
 	// Ignore temporary files and build artifacts
@@ -291,17 +411,27 @@ func (s *Server) isSourceFile(path string) bool {
 	case "go":
 		return strings.HasSuffix(path, ".go") &&
 			!strings.HasSuffix(path, "_test.go") &&
-			!strings.HasSuffix(path, "interface.go")
+			!strings.HasSuffix(path, "interface.go") &&
+			!s.isHostAPIClient(path)
 	case "typescript":
 		return (strings.HasSuffix(path, ".ts") || strings.HasSuffix(path, ".js")) &&
 			!strings.HasSuffix(path, ".test.ts") &&
 			!strings.HasSuffix(path, ".test.js") &&
-			!strings.HasSuffix(path, ".interface.ts")
+			!strings.HasSuffix(path, ".interface.ts") &&
+			!s.isHostAPIClient(path)
 	default:
 		return false
 	}
 }
 
+// isHostAPIClient checks if a file is one of the host API clients that every
+// build regenerates, so writing them does not trigger another build
+func (s *Server) isHostAPIClient(path string) bool {
+	typesDir := filepath.Join(s.projectRoot, "types")
+	return path == filepath.Join(typesDir, "hostapis.ts") ||
+		filepath.Dir(path) == filepath.Join(typesDir, "hostapis")
+}
+
 // handleSchemaChange handles changes to .okra.gql files
 func (s *Server) handleSchemaChange(path string) {
 	fmt.Println("🔄 Schema changed, regenerating interface...")
@@ -467,7 +597,7 @@ func (s *Server) deployServicePackage() error {
 		return fmt.Errorf("WASM file is empty: %s", wasmPath)
 	}
 
-	compiledModule, err := wasm.NewWASMCompiledModule(ctx, wasmBytes)
+	compiledModule, err := runtime.CompileServiceModule(ctx, wasmBytes, s.config, s.hostAPIs)
 	if err != nil {
 		return fmt.Errorf("failed to compile WASM module: %w", err)
 	}
@@ -475,6 +605,7 @@ func (s *Server) deployServicePackage() error {
 	// Create service package
 	pkg, err := runtime.NewServicePackage(compiledModule, parsedSchema, s.config)
 	if err != nil {
+		compiledModule.Close(ctx)
 		return fmt.Errorf("failed to create service package: %w", err)
 	}
 
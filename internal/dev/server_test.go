@@ -11,6 +11,8 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/okra-platform/okra/internal/config"
 	"github.com/okra-platform/okra/internal/runtime"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/wasm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -75,6 +77,17 @@ func (m *mockRuntime) Shutdown(ctx context.Context) error {
 	return args.Error(0)
 }
 
+// Engine returns nil, so packages are compiled into runtimes of their own
+func (m *mockRuntime) Engine() wasm.WASMEngine {
+	return nil
+}
+
+func (m *mockRuntime) QueueDelivery() hostapi.QueueDeliverFunc {
+	return func(ctx context.Context, sub hostapi.QueueSubscription, message hostapi.QueueMessage) error {
+		return nil
+	}
+}
+
 
 type mockWatcher struct {
 	mock.Mock
@@ -294,6 +307,24 @@ func TestServer_isSourceFile(t *testing.T) {
 			path:     "README.md",
 			expected: false,
 		},
+		{
+			name: "generated go host API client",
+			server: &Server{
+				config:      &config.Config{Language: "go"},
+				projectRoot: "/project",
+			},
+			path:     "/project/types/hostapis/hostapis.go",
+			expected: false,
+		},
+		{
+			name: "generated typescript host API client",
+			server: &Server{
+				config:      &config.Config{Language: "typescript"},
+				projectRoot: "/project",
+			},
+			path:     "/project/types/hostapis.ts",
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -540,4 +571,44 @@ service TestService {
 	err := server.deployServicePackage()
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "WASM file not found")
-}
\ No newline at end of file
+}
+func TestServer_loadPolicies(t *testing.T) {
+	// Test: Without a policy directory every call stays allowed
+	cfg := &config.Config{Name: "test-service", Language: "go", Schema: "./service.okra.gql"}
+	server := NewServer(cfg, t.TempDir())
+	require.NoError(t, server.loadPolicies(context.Background()))
+	assert.Nil(t, server.hostAPIs.Config.PolicyEngine)
+
+	// Test: The policies in the directory guard every deployment
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "state.yaml"), []byte("capabilities:\n  state:\n    allow: false\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server = NewServer(cfg, t.TempDir(), WithPolicyDir(dir))
+	require.NoError(t, server.loadPolicies(ctx))
+	require.NotNil(t, server.hostAPIs.Config.PolicyEngine)
+
+	check := hostapi.PolicyCheck{
+		Service: "test-service",
+		Request: hostapi.HostAPIRequest{API: "okra.state", Method: "get", Parameters: []byte(`{}`)},
+		Context: map[string]interface{}{},
+	}
+	decision, err := server.hostAPIs.Config.PolicyEngine.Evaluate(ctx, check)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+
+	// Test: Editing a policy file takes effect without restarting
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "state.yaml"), []byte("capabilities:\n  state:\n    allow: true\n"), 0644))
+	require.Eventually(t, func() bool {
+		decision, err := server.hostAPIs.Config.PolicyEngine.Evaluate(ctx, check)
+		return err == nil && decision.Allowed
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// Test: Invalid policy files stop the server from starting
+	broken := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(broken, "broken.yaml"), []byte("capabilities:\n  state:\n    condition: \"((\"\n"), 0644))
+	server = NewServer(cfg, t.TempDir(), WithPolicyDir(broken))
+	err = server.loadPolicies(ctx)
+	assert.ErrorContains(t, err, "failed to load policies")
+}
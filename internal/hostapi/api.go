@@ -32,6 +32,15 @@ type StreamingHostAPI interface {
 	ExecuteStreaming(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, Iterator, error)
 }
 
+// SensitiveHostAPI is implemented by host APIs that handle confidential values.
+// The HostAPISet redacts error details for these APIs before they reach logs and spans.
+type SensitiveHostAPI interface {
+	HostAPI
+
+	// Sensitive reports whether telemetry for this API must be redacted
+	Sensitive() bool
+}
+
 // HostAPIFactory creates instances of a host API for specific services
 type HostAPIFactory interface {
 	// Name returns the namespace for this API (e.g., "okra.state")
@@ -78,6 +87,12 @@ type HostAPIConfig struct {
 	Meter  metric.Meter
 	Logger *slog.Logger
 
+	// Time source for host APIs and the guest's WASI clocks (nil = system clock)
+	Clock Clock
+
+	// Audit sink for host API calls and policy decisions (nil = no audit log)
+	AuditSink AuditSink
+
 	// Service-specific configuration from okra.json
 	Config interface{} // The full okra.json configuration
 
@@ -90,10 +105,11 @@ type HostAPIConfig struct {
 
 // HostAPIRequest represents a request to any host API
 type HostAPIRequest struct {
-	API        string          `json:"api"`        // e.g., "okra.state"
-	Method     string          `json:"method"`     // e.g., "get"
-	Parameters json.RawMessage `json:"parameters"` // Method-specific parameters
-	Metadata   RequestMetadata `json:"metadata"`   // Request context, trace info, etc.
+	API        string          `json:"api"`               // e.g., "okra.state"
+	Version    string          `json:"version,omitempty"` // Range the guest was built against; resolved version once routed
+	Method     string          `json:"method"`            // e.g., "get"
+	Parameters json.RawMessage `json:"parameters"`        // Method-specific parameters
+	Metadata   RequestMetadata `json:"metadata"`          // Request context, trace info, etc.
 }
 
 // HostAPIResponse represents the response from any host API
@@ -122,6 +138,17 @@ type NextRequest struct {
 	IteratorID string `json:"iteratorId"` // The iterator to advance
 }
 
+// CloseIteratorRequest represents a request to release an iterator early
+type CloseIteratorRequest struct {
+	IteratorID string `json:"iteratorId"` // The iterator to close
+}
+
+// CloseIteratorResponse represents the response from a close_iterator() call
+type CloseIteratorResponse struct {
+	Success bool          `json:"success"`
+	Error   *HostAPIError `json:"error,omitempty"` // Error details if failed
+}
+
 // NextResponse represents the response from a next() call
 type NextResponse struct {
 	Success bool            `json:"success"`
@@ -135,3 +162,34 @@ type StreamingResponse struct {
 	IteratorID string `json:"iteratorId"` // ID to use for subsequent next() calls
 	HasData    bool   `json:"hasData"`    // False if no results at all
 }
+
+// unmarshalParameters decodes method parameters, reporting failures as INVALID_PARAMETERS
+func unmarshalParameters(parameters json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(parameters, v); err != nil {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidParameters,
+			Message: "failed to parse parameters",
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
+
+// objectSchema builds a JSON schema for an object with the given properties
+func objectSchema(properties map[string]spec.Schema, required ...string) *spec.Schema {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"object"}}}
+	return schema.WithProperties(properties).WithRequired(required...)
+}
+
+// anySchema builds a JSON schema that accepts any JSON value
+func anySchema(description string) spec.Schema {
+	return *new(spec.Schema).WithDescription(description)
+}
+
+// streamingSchema is the return schema shared by all streaming methods
+func streamingSchema() *spec.Schema {
+	return objectSchema(map[string]spec.Schema{
+		"iteratorId": *spec.StringProperty().WithDescription("ID to pass to okra.next"),
+		"hasData":    *spec.BoolProperty(),
+	})
+}
@@ -0,0 +1,147 @@
+package hostapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"time"
+)
+
+// AuditRedacted replaces redacted parameter values in audit events
+const AuditRedacted = "[REDACTED]"
+
+// DefaultAuditRedactFields are the parameter fields never written to the audit
+// log. Patterns use path.Match syntax and match object keys at any depth,
+// ignoring case; okra.json audit.redactFields adds service-specific fields.
+var DefaultAuditRedactFields = []string{
+	"*password*", "passwd", "*secret*", "*token*", "*apikey*", "*api_key*",
+	"authorization", "cookie", "set-cookie", "*credential*", "*private_key*",
+	"email", "phone", "ssn", "*card_number*", "*cardnumber*", "cvv",
+}
+
+// AuditPayloadFields are the parameter fields of each built-in host API that
+// carry service data rather than describe the call: state and cache values,
+// queue messages, SQL values and arguments, request bodies and log lines.
+// They are never written to the audit log, whatever their content.
+var AuditPayloadFields = map[string][]string{
+	StateAPIName: {"value"},
+	CacheAPIName: {"value"},
+	QueueAPIName: {"payload", "headers"},
+	SQLAPIName:   {"value", "values", "id", "parameters"},
+	HTTPAPIName:  {"body"},
+	LogAPIName:   {"message", "context"},
+}
+
+// AuditSink records host API calls and the policy decisions made for them.
+// Sinks are shared by every worker and must be safe for concurrent use.
+type AuditSink interface {
+	// Record stores an event; errors are logged and never fail the call
+	Record(ctx context.Context, event AuditEvent) error
+}
+
+// AuditEvent describes one host API call or iterator advance
+type AuditEvent struct {
+	Time           time.Time       `json:"time"`
+	Service        string          `json:"service"`
+	ServiceVersion string          `json:"serviceVersion,omitempty"`
+	API            string          `json:"api"`
+	APIVersion     string          `json:"apiVersion,omitempty"`
+	Method         string          `json:"method"`
+	IteratorID     string          `json:"iteratorId,omitempty"` // Set when advancing an iterator
+	Parameters     json.RawMessage `json:"parameters,omitempty"` // Redacted call parameters
+	Allowed        bool            `json:"allowed"`              // Whether the policy engine allowed the call
+	Reason         string          `json:"reason,omitempty"`     // Policy denial reason
+	ErrorCode      string          `json:"errorCode,omitempty"`  // Set when the call failed
+	DurationMs     int64           `json:"durationMs"`
+	TraceID        string          `json:"traceId,omitempty"`
+}
+
+// RedactParameters returns a copy of JSON parameters with every value whose
+// key matches one of fields replaced by AuditRedacted. Parameters that are
+// not valid JSON are redacted entirely.
+func RedactParameters(parameters json.RawMessage, fields []string) json.RawMessage {
+	if len(parameters) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(parameters))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return json.RawMessage(`"` + AuditRedacted + `"`)
+	}
+
+	redacted, err := json.Marshal(redactValue(value, fields))
+	if err != nil {
+		return json.RawMessage(`"` + AuditRedacted + `"`)
+	}
+	return redacted
+}
+
+func redactValue(value interface{}, fields []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redactField(key, fields) {
+				v[key] = AuditRedacted
+			} else {
+				v[key] = redactValue(field, fields)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, fields)
+		}
+	}
+	return value
+}
+
+func redactField(key string, fields []string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range fields {
+		if matched, _ := path.Match(strings.ToLower(pattern), key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// auditRedactFields combines the default fields with the payload fields of
+// the API and the service's own fields
+func (s *defaultHostAPISet) auditRedactFields(apiName string) []string {
+	fields := append([]string{}, DefaultAuditRedactFields...)
+	fields = append(fields, AuditPayloadFields[apiName]...)
+	if cfg := serviceConfig(s.config); cfg != nil {
+		fields = append(fields, cfg.Audit.RedactFields...)
+	}
+	return fields
+}
+
+// recordAudit completes an event with its outcome and hands it to the sink
+func (s *defaultHostAPISet) recordAudit(ctx context.Context, event AuditEvent, start time.Time, err error) {
+	sink := s.config.AuditSink
+	if sink == nil {
+		return
+	}
+
+	event.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		var apiErr *HostAPIError
+		if errors.As(err, &apiErr) {
+			event.ErrorCode = apiErr.Code
+		} else {
+			event.ErrorCode = ErrorCodeInternalError
+		}
+	}
+
+	if recordErr := sink.Record(ctx, event); recordErr != nil && s.config.Logger != nil {
+		s.config.Logger.Error("failed to record audit event",
+			"api", event.API,
+			"method", event.Method,
+			"error", recordErr,
+		)
+	}
+}
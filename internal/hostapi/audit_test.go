@@ -0,0 +1,243 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test parameters are redacted by default and okra.json field rules
+// 2. Test allowed, denied, rate-limited and failed calls are audited
+// 3. Test iterator advances are audited
+// 4. Test sets without a sink audit nothing
+// 5. Test the payloads of built-in host APIs never reach the sink
+
+// recordingAuditSink collects audit events in memory
+type recordingAuditSink struct {
+	events []AuditEvent
+	mu     sync.Mutex
+}
+
+func (s *recordingAuditSink) Record(ctx context.Context, event AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestRedactParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   string
+		fields   []string
+		expected string
+	}{
+		{
+			name:     "nested keys",
+			params:   `{"url":"https://api","headers":{"Authorization":"Bearer x","Accept":"json"},"body":{"user":{"Email":"a@b.c","name":"Ann"}}}`,
+			fields:   DefaultAuditRedactFields,
+			expected: `{"url":"https://api","headers":{"Authorization":"[REDACTED]","Accept":"json"},"body":{"user":{"Email":"[REDACTED]","name":"Ann"}}}`,
+		},
+		{
+			name:     "patterns and arrays",
+			params:   `{"items":[{"accessToken":"t","id":1},{"db_password":"p","id":2}],"amount":12.50}`,
+			fields:   DefaultAuditRedactFields,
+			expected: `{"items":[{"accessToken":"[REDACTED]","id":1},{"db_password":"[REDACTED]","id":2}],"amount":12.50}`,
+		},
+		{
+			name:     "whole objects",
+			params:   `{"address":{"street":"Main St"},"city":"Oslo"}`,
+			fields:   []string{"address"},
+			expected: `{"address":"[REDACTED]","city":"Oslo"}`,
+		},
+		{
+			name:     "invalid JSON",
+			params:   `{"password":`,
+			fields:   DefaultAuditRedactFields,
+			expected: `"[REDACTED]"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.JSONEq(t, tt.expected, string(RedactParameters(json.RawMessage(tt.params), tt.fields)))
+		})
+	}
+
+	assert.Nil(t, RedactParameters(nil, DefaultAuditRedactFields))
+}
+
+func TestHostAPISet_Audit(t *testing.T) {
+	sink := &recordingAuditSink{}
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	api := &mockHostAPI{
+		name:    "test.api",
+		version: "v1.0.0",
+		methods: map[string]func(ctx context.Context, params json.RawMessage) (json.RawMessage, error){
+			"send": func(ctx context.Context, params json.RawMessage) (json.RawMessage, error) {
+				return json.RawMessage(`{}`), nil
+			},
+			"fail": func(ctx context.Context, params json.RawMessage) (json.RawMessage, error) {
+				return nil, &HostAPIError{Code: "UPSTREAM_FAILED", Message: "token abc rejected"}
+			},
+		},
+	}
+
+	apiSet := &defaultHostAPISet{
+		apis:      map[string]HostAPI{"test.api": api},
+		iterators: make(map[string]*iteratorInfo),
+		limiter:   newRateLimiter(),
+		config: HostAPIConfig{
+			ServiceName:    "acme/orders",
+			ServiceVersion: "v1.2.0",
+			Tracer:         tracenoop.NewTracerProvider().Tracer("test"),
+			Meter:          metricnoop.NewMeterProvider().Meter("test"),
+			Clock:          clock,
+			AuditSink:      sink,
+			PolicyEngine: &mockPolicyEngine{
+				decisions: map[string]PolicyDecision{
+					"test.api.blocked": {Allowed: false, Reason: "blocked by policy"},
+				},
+			},
+			Config: &config.Config{
+				Audit:      config.AuditConfig{RedactFields: []string{"customer*"}},
+				RateLimits: []config.RateLimitConfig{{Target: "test.api.send", RequestsPerSecond: 1, Burst: 1}},
+			},
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), requestMetadataKey{}, RequestMetadata{TraceID: "trace-1"})
+	_, err := apiSet.Execute(ctx, "test.api", "send", json.RawMessage(`{"customerId":"c-1","apiKey":"k","quantity":2}`))
+	require.NoError(t, err)
+	_, err = apiSet.Execute(ctx, "test.api", "send", json.RawMessage(`{"quantity":3}`))
+	requireHostAPIError(t, err, ErrorCodeRateLimited)
+	_, err = apiSet.Execute(ctx, "test.api", "blocked", nil)
+	requireHostAPIError(t, err, ErrorCodePolicyDenied)
+	_, err = apiSet.Execute(ctx, "test.api", "fail", nil)
+	requireHostAPIError(t, err, "UPSTREAM_FAILED")
+
+	require.Len(t, sink.events, 4)
+
+	sent := sink.events[0]
+	assert.Equal(t, clock.Now(), sent.Time)
+	assert.Equal(t, "acme/orders", sent.Service)
+	assert.Equal(t, "v1.2.0", sent.ServiceVersion)
+	assert.Equal(t, "test.api", sent.API)
+	assert.Equal(t, "send", sent.Method)
+	assert.Equal(t, "trace-1", sent.TraceID)
+	assert.True(t, sent.Allowed)
+	assert.Empty(t, sent.ErrorCode)
+	assert.JSONEq(t, `{"customerId":"[REDACTED]","apiKey":"[REDACTED]","quantity":2}`, string(sent.Parameters))
+
+	assert.True(t, sink.events[1].Allowed)
+	assert.Equal(t, ErrorCodeRateLimited, sink.events[1].ErrorCode)
+
+	assert.False(t, sink.events[2].Allowed)
+	assert.Equal(t, "blocked by policy", sink.events[2].Reason)
+	assert.Equal(t, ErrorCodePolicyDenied, sink.events[2].ErrorCode)
+
+	// Only the code is recorded, never the error message
+	assert.Equal(t, "UPSTREAM_FAILED", sink.events[3].ErrorCode)
+	assert.Empty(t, sink.events[3].Reason)
+}
+
+func TestHostAPISet_AuditIterators(t *testing.T) {
+	sink := &recordingAuditSink{}
+	api := &mockStreamingAPI{
+		mockHostAPI: mockHostAPI{name: "test.streaming", version: "v1.0.0"},
+		streamingMethods: map[string]func(ctx context.Context, params json.RawMessage) (json.RawMessage, Iterator, error){
+			"list": func(ctx context.Context, params json.RawMessage) (json.RawMessage, Iterator, error) {
+				iterator := &mockIterator{data: []json.RawMessage{json.RawMessage(`1`), json.RawMessage(`2`)}}
+				return json.RawMessage(`{"iteratorId":"iter-1","hasData":true}`), iterator, nil
+			},
+		},
+	}
+
+	apiSet := &defaultHostAPISet{
+		apis:      map[string]HostAPI{"test.streaming": api},
+		iterators: make(map[string]*iteratorInfo),
+		config: HostAPIConfig{
+			ServiceName:  "acme/orders",
+			Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+			Meter:        metricnoop.NewMeterProvider().Meter("test"),
+			PolicyEngine: &mockPolicyEngine{},
+			AuditSink:    sink,
+		},
+	}
+
+	ctx := context.Background()
+	_, err := apiSet.Execute(ctx, "test.streaming", "list", json.RawMessage(`{"prefix":"orders/"}`))
+	require.NoError(t, err)
+	_, _, err = apiSet.NextIterator(ctx, "iter-1")
+	require.NoError(t, err)
+	_, _, err = apiSet.NextIterator(ctx, "iter-1")
+	require.NoError(t, err)
+
+	require.Len(t, sink.events, 3)
+	assert.Empty(t, sink.events[0].IteratorID)
+	for _, event := range sink.events[1:] {
+		assert.Equal(t, "iter-1", event.IteratorID)
+		assert.Equal(t, "test.streaming", event.API)
+		assert.Equal(t, "list", event.Method)
+		assert.Equal(t, "acme/orders", event.Service)
+		assert.Nil(t, event.Parameters)
+	}
+}
+
+func TestHostAPISet_NoAuditSink(t *testing.T) {
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(NewTimeAPIFactory()))
+	set, err := registry.CreateHostAPISet(context.Background(), []string{TimeAPIName}, HostAPIConfig{
+		ServiceName:  "acme/orders",
+		PolicyEngine: &mockPolicyEngine{},
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+	})
+	require.NoError(t, err)
+	defer set.Close()
+
+	_, err = set.Execute(context.Background(), TimeAPIName, "now", json.RawMessage(`{}`))
+	assert.NoError(t, err)
+}
+
+func TestHostAPISet_AuditPayloads(t *testing.T) {
+	sink := &recordingAuditSink{}
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(NewStateAPIFactory()))
+	require.NoError(t, registry.Register(NewQueueAPIFactory()))
+	set, err := registry.CreateHostAPISet(context.Background(), []string{StateAPIName, QueueAPIName}, HostAPIConfig{
+		ServiceName:  "acme/orders",
+		PolicyEngine: &mockPolicyEngine{},
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		AuditSink:    sink,
+	})
+	require.NoError(t, err)
+	defer set.Close()
+
+	ctx := context.Background()
+	_, err = set.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"note","value":{"text":"meet at noon"}}`))
+	require.NoError(t, err)
+	_, err = set.Execute(ctx, QueueAPIName, "publish", json.RawMessage(`{"topic":"orders","payload":"meet at noon","headers":{"from":"ann"}}`))
+	require.NoError(t, err)
+
+	// Test: Values and messages are redacted whatever their keys; the rest of
+	// the call is kept
+	require.Len(t, sink.events, 2)
+	assert.JSONEq(t, `{"key":"note","value":"[REDACTED]"}`, string(sink.events[0].Parameters))
+	assert.JSONEq(t, `{"topic":"orders","payload":"[REDACTED]","headers":"[REDACTED]"}`, string(sink.events[1].Parameters))
+	for _, event := range sink.events {
+		encoded, err := json.Marshal(event)
+		require.NoError(t, err)
+		assert.NotContains(t, string(encoded), "meet at noon")
+	}
+}
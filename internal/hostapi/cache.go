@@ -0,0 +1,425 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/spec"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+)
+
+const (
+	// CacheAPIName is the namespace of the cache host API
+	CacheAPIName = "okra.cache"
+
+	// CacheAPIVersion is the current version of the cache host API
+	CacheAPIVersion = "v1.0.0"
+)
+
+// CacheGetRequest is the payload for cache.get
+type CacheGetRequest struct {
+	Key string `json:"key"`
+}
+
+// CacheGetResponse is the result of cache.get
+type CacheGetResponse struct {
+	Value   json.RawMessage `json:"value,omitempty"`
+	Exists  bool            `json:"exists"`
+	TTL     int64           `json:"ttl,omitempty"` // Remaining seconds
+	Created *time.Time      `json:"created,omitempty"`
+	Hits    int64           `json:"hits,omitempty"`
+}
+
+// CacheSetRequest is the payload for cache.set
+type CacheSetRequest struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+	TTL   *int64          `json:"ttl,omitempty"` // Seconds (default: CacheConfig.DefaultTTL)
+}
+
+// CacheSetResponse is the result of cache.set
+type CacheSetResponse struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CacheDeleteRequest is the payload for cache.delete
+type CacheDeleteRequest struct {
+	Key string `json:"key"`
+}
+
+// CacheDeleteResponse is the result of cache.delete
+type CacheDeleteResponse struct {
+	Deleted bool `json:"deleted"`
+}
+
+// CacheInvalidateRequest is the payload for cache.invalidate
+type CacheInvalidateRequest struct {
+	Prefix string `json:"prefix"`
+}
+
+// CacheInvalidateResponse is the result of cache.invalidate
+type CacheInvalidateResponse struct {
+	Invalidated int `json:"invalidated"`
+}
+
+// CacheConfig holds the code-level limits enforced by the cache API.
+// MaxEntries and MaxBytes bound each service's cache independently.
+type CacheConfig struct {
+	MaxKeyLength int
+	MaxValueSize int
+	MaxEntries   int
+	MaxBytes     int
+	DefaultTTL   time.Duration
+	MinTTL       time.Duration
+	MaxTTL       time.Duration
+}
+
+// defaultCacheConfig returns the limits described in docs/host-apis/cache.md
+func defaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		MaxKeyLength: 256,
+		MaxValueSize: 10 * 1024 * 1024,
+		MaxEntries:   10000,
+		MaxBytes:     64 * 1024 * 1024,
+		DefaultTTL:   time.Hour,
+		MinTTL:       time.Second,
+		MaxTTL:       30 * 24 * time.Hour,
+	}
+}
+
+// CacheAPIOption configures the cache API factory
+type CacheAPIOption func(*cacheAPIFactory)
+
+// WithCacheConfig overrides the default cache limits
+func WithCacheConfig(config CacheConfig) CacheAPIOption {
+	return func(f *cacheAPIFactory) {
+		f.config = config
+	}
+}
+
+// cacheAPIFactory owns one LRU per service so every worker of a service
+// shares the same cache, unlike the per-instance HostAPISet
+type cacheAPIFactory struct {
+	config CacheConfig
+
+	mu     sync.Mutex
+	caches map[string]*lruCache
+}
+
+// NewCacheAPIFactory creates the okra.cache host API factory.
+// Entries are kept in memory for the lifetime of the process.
+func NewCacheAPIFactory(opts ...CacheAPIOption) HostAPIFactory {
+	factory := &cacheAPIFactory{
+		config: defaultCacheConfig(),
+		caches: make(map[string]*lruCache),
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	return factory
+}
+
+func (f *cacheAPIFactory) Name() string    { return CacheAPIName }
+func (f *cacheAPIFactory) Version() string { return CacheAPIVersion }
+
+func (f *cacheAPIFactory) Create(ctx context.Context, hostConfig HostAPIConfig) (HostAPI, error) {
+	if hostConfig.ServiceName == "" {
+		return nil, fmt.Errorf("service name is required for %s", CacheAPIName)
+	}
+
+	meter := hostConfig.Meter
+	if meter == nil {
+		meter = metricnoop.NewMeterProvider().Meter(CacheAPIName)
+	}
+	hits, err := meter.Int64Counter("host_api_cache_hits")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache hit counter: %w", err)
+	}
+	misses, err := meter.Int64Counter("host_api_cache_misses")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache miss counter: %w", err)
+	}
+	evictions, err := meter.Int64Counter("host_api_cache_evictions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache eviction counter: %w", err)
+	}
+
+	maxResponseSize := hostConfig.MaxResponseSize
+	if maxResponseSize == 0 {
+		maxResponseSize = DefaultMaxResponseSize
+	}
+
+	return &cacheAPI{
+		cache:           f.cacheFor(hostConfig.ServiceName),
+		config:          f.config,
+		maxResponseSize: maxResponseSize,
+		now:             clockOrSystem(hostConfig.Clock).Now,
+		hits:            hits,
+		misses:          misses,
+		evictions:       evictions,
+		attrs:           metric.WithAttributes(attribute.String("service", hostConfig.ServiceName)),
+	}, nil
+}
+
+// cacheFor returns the service's cache, creating it on first use
+func (f *cacheAPIFactory) cacheFor(service string) *lruCache {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cache, ok := f.caches[service]
+	if !ok {
+		cache = newLRUCache(f.config.MaxEntries, f.config.MaxBytes)
+		f.caches[service] = cache
+	}
+	return cache
+}
+
+func (f *cacheAPIFactory) Methods() []MethodMetadata {
+	keyErrors := []ErrorMetadata{
+		{Code: ErrorCodeInvalidKey, Description: "Key contains invalid characters"},
+		{Code: ErrorCodeKeyTooLong, Description: "Key exceeds maximum length"},
+	}
+
+	return []MethodMetadata{
+		{
+			Name:        "get",
+			Description: "Retrieve a cached value",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"key": *spec.StringProperty().WithDescription("The key to retrieve"),
+			}, "key"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"value":   anySchema("The cached JSON value"),
+				"exists":  *spec.BoolProperty(),
+				"ttl":     *spec.Int64Property().WithDescription("Remaining time to live in seconds"),
+				"created": *spec.DateTimeProperty(),
+				"hits":    *spec.Int64Property().WithDescription("Number of times the entry was read"),
+			}),
+			Errors: append(keyErrors,
+				ErrorMetadata{Code: ErrorCodeResponseTooLarge, Description: "Cached value exceeds the response size limit"},
+			),
+		},
+		{
+			Name:        "set",
+			Description: "Store a value in the service cache",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"key":   *spec.StringProperty(),
+				"value": anySchema("Any JSON value"),
+				"ttl":   *spec.Int64Property().WithDescription("Time to live in seconds"),
+			}, "key", "value"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"expiresAt": *spec.DateTimeProperty(),
+			}),
+			Errors: append(keyErrors,
+				ErrorMetadata{Code: ErrorCodeValueTooLarge, Description: "Value exceeds maximum size"},
+				ErrorMetadata{Code: ErrorCodeInvalidTTL, Description: "TTL is outside the allowed range"},
+			),
+		},
+		{
+			Name:        "delete",
+			Description: "Remove a cached value",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"key": *spec.StringProperty(),
+			}, "key"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"deleted": *spec.BoolProperty(),
+			}),
+			Errors: keyErrors,
+		},
+		{
+			Name:        "invalidate",
+			Description: "Remove all cached values whose key starts with a prefix",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"prefix": *spec.StringProperty().WithDescription("Key prefix; wildcards are not supported"),
+			}, "prefix"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"invalidated": *spec.Int32Property().WithDescription("Number of entries removed"),
+			}),
+			Errors: keyErrors,
+		},
+	}
+}
+
+// cacheAPI is a service-scoped handle on the service's shared cache
+type cacheAPI struct {
+	cache           *lruCache
+	config          CacheConfig
+	maxResponseSize int
+	now             func() time.Time
+
+	hits      metric.Int64Counter
+	misses    metric.Int64Counter
+	evictions metric.Int64Counter
+	attrs     metric.MeasurementOption
+}
+
+// Compile-time interface compliance checks
+var (
+	_ HostAPI        = (*cacheAPI)(nil)
+	_ HostAPIFactory = (*cacheAPIFactory)(nil)
+)
+
+func (c *cacheAPI) Name() string    { return CacheAPIName }
+func (c *cacheAPI) Version() string { return CacheAPIVersion }
+
+func (c *cacheAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	switch method {
+	case "get":
+		return c.executeGet(ctx, parameters)
+	case "set":
+		return c.executeSet(ctx, parameters)
+	case "delete":
+		return c.executeDelete(parameters)
+	case "invalidate":
+		return c.executeInvalidate(parameters)
+	default:
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+}
+
+func (c *cacheAPI) executeGet(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req CacheGetRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	if err := c.validateKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	entry, ok := c.cache.get(req.Key, now)
+	if !ok {
+		c.misses.Add(ctx, 1, c.attrs)
+		return json.Marshal(CacheGetResponse{Exists: false})
+	}
+	c.hits.Add(ctx, 1, c.attrs)
+
+	// Round up so an entry that is still live never reports a zero TTL
+	remaining := entry.expiresAt.Sub(now)
+	ttl := int64((remaining + time.Second - 1) / time.Second)
+
+	result, err := json.Marshal(CacheGetResponse{
+		Value:   entry.value,
+		Exists:  true,
+		TTL:     ttl,
+		Created: &entry.created,
+		Hits:    entry.hits,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Another worker may have cached the value under a larger response limit
+	if len(result) > c.maxResponseSize {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeResponseTooLarge,
+			Message: fmt.Sprintf("cached value size %d exceeds response limit %d", len(entry.value), c.maxResponseSize),
+		}
+	}
+	return result, nil
+}
+
+func (c *cacheAPI) executeSet(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req CacheSetRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	if err := c.validateKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	if len(req.Value) == 0 || !json.Valid(req.Value) {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidParameters,
+			Message: "value must be valid JSON",
+		}
+	}
+
+	// A value that could never be returned by get is rejected up front
+	limit := min(c.config.MaxValueSize, c.config.MaxBytes, c.maxResponseSize)
+	if len(req.Value) > limit {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeValueTooLarge,
+			Message: fmt.Sprintf("value size %d exceeds limit %d", len(req.Value), limit),
+		}
+	}
+
+	ttl := c.config.DefaultTTL
+	if req.TTL != nil {
+		ttl = time.Duration(*req.TTL) * time.Second
+		if ttl < c.config.MinTTL || ttl > c.config.MaxTTL {
+			return nil, &HostAPIError{
+				Code:    ErrorCodeInvalidTTL,
+				Message: fmt.Sprintf("ttl must be between %s and %s", c.config.MinTTL, c.config.MaxTTL),
+			}
+		}
+	}
+
+	// Copy so the cache never aliases a caller's buffer
+	value := append([]byte(nil), req.Value...)
+	now := c.now()
+	if evicted := c.cache.set(req.Key, value, ttl, now); evicted > 0 {
+		c.evictions.Add(ctx, int64(evicted), c.attrs)
+	}
+
+	return json.Marshal(CacheSetResponse{ExpiresAt: now.Add(ttl)})
+}
+
+func (c *cacheAPI) executeDelete(parameters json.RawMessage) (json.RawMessage, error) {
+	var req CacheDeleteRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	if err := c.validateKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(CacheDeleteResponse{Deleted: c.cache.delete(req.Key, c.now())})
+}
+
+func (c *cacheAPI) executeInvalidate(parameters json.RawMessage) (json.RawMessage, error) {
+	var req CacheInvalidateRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+
+	// An empty prefix would clear the whole cache; require it to be explicit key material
+	if err := c.validateKey(req.Prefix); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(CacheInvalidateResponse{Invalidated: c.cache.deletePrefix(req.Prefix, c.now())})
+}
+
+// validateKey enforces the code-level key policies shared with okra.state
+func (c *cacheAPI) validateKey(key string) error {
+	if key == "" {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidKey,
+			Message: "key cannot be empty",
+		}
+	}
+	if len(key) > c.config.MaxKeyLength {
+		return &HostAPIError{
+			Code:    ErrorCodeKeyTooLong,
+			Message: fmt.Sprintf("key length %d exceeds limit %d", len(key), c.config.MaxKeyLength),
+		}
+	}
+	if !stateKeyPattern.MatchString(key) || strings.HasPrefix(key, "/") {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidKey,
+			Message: "key may only contain letters, digits, '_', '-', ':' and '/' and must not start with '/'",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package hostapi
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheEntry is a single cached value
+type cacheEntry struct {
+	key       string
+	value     []byte
+	created   time.Time
+	expiresAt time.Time
+	hits      int64
+}
+
+// size approximates the memory held by an entry
+func (e *cacheEntry) size() int {
+	return len(e.key) + len(e.value)
+}
+
+// lruCache is a size-bounded cache with per-entry expiry. The least recently
+// used entry is evicted first once either bound is exceeded. Every operation
+// takes the caller's current time, so workers sharing the cache each expire
+// entries by their own clock.
+type lruCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int
+	bytes      int
+	order      *list.List // Front is most recently used
+	entries    map[string]*list.Element
+}
+
+func newLRUCache(maxEntries, maxBytes int) *lruCache {
+	return &lruCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of a live entry and marks it as recently used
+func (c *lruCache) get(key string, now time.Time) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !now.Before(entry.expiresAt) {
+		c.removeElement(element)
+		return cacheEntry{}, false
+	}
+
+	entry.hits++
+	c.order.MoveToFront(element)
+	return *entry, true
+}
+
+// set stores a value and returns the number of entries evicted to make room
+func (c *lruCache) set(key string, value []byte, ttl time.Duration, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{
+		key:       key,
+		value:     value,
+		created:   now,
+		expiresAt: now.Add(ttl),
+	}
+
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += entry.size()
+
+	return c.evict(now)
+}
+
+// delete removes a key and reports whether it was present
+func (c *lruCache) delete(key string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	expired := !now.Before(element.Value.(*cacheEntry).expiresAt)
+	c.removeElement(element)
+	return !expired
+}
+
+// deletePrefix removes every key with the given prefix and returns how many live entries were removed
+func (c *lruCache) deletePrefix(prefix string, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, element := range c.entries {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if now.Before(element.Value.(*cacheEntry).expiresAt) {
+			removed++
+		}
+		c.removeElement(element)
+	}
+	return removed
+}
+
+// evict drops expired entries, then least recently used ones, until the cache is within bounds
+func (c *lruCache) evict(now time.Time) int {
+	evicted := 0
+	if c.order.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		for element := c.order.Back(); element != nil; {
+			prev := element.Prev()
+			if !now.Before(element.Value.(*cacheEntry).expiresAt) {
+				c.removeElement(element)
+			}
+			element = prev
+		}
+	}
+	for c.order.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		c.removeElement(c.order.Back())
+		evicted++
+	}
+	return evicted
+}
+
+func (c *lruCache) removeElement(element *list.Element) {
+	entry := c.order.Remove(element).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size()
+}
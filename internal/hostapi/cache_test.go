@@ -0,0 +1,279 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test get/set/delete/invalidate round trip through a HostAPISet
+// 2. Test TTL expiry and reported remaining TTL, by each set's clock
+// 3. Test LRU eviction by entry count and by bytes
+// 4. Test the cache is shared by sets of one service and isolated between services
+// 5. Test code-level validation and MaxResponseSize
+// 6. Test hit/miss/eviction counters are recorded on the configured Meter
+
+// countingMeter records Int64Counter totals by instrument name
+type countingMeter struct {
+	metricnoop.Meter
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+type countingCounter struct {
+	metricnoop.Int64Counter
+	meter *countingMeter
+	name  string
+}
+
+func newCountingMeter() *countingMeter {
+	return &countingMeter{counts: map[string]int64{}}
+}
+
+func (m *countingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return &countingCounter{meter: m, name: name}, nil
+}
+
+func (m *countingMeter) count(name string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[name]
+}
+
+func (c *countingCounter) Add(_ context.Context, incr int64, _ ...metric.AddOption) {
+	c.meter.mu.Lock()
+	defer c.meter.mu.Unlock()
+	c.meter.counts[c.name] += incr
+}
+
+// newCacheTestSet creates a HostAPISet with the cache API for the given service
+func newCacheTestSet(t *testing.T, factory HostAPIFactory, service string, meter metric.Meter, maxResponseSize int) HostAPISet {
+	if meter == nil {
+		meter = metricnoop.NewMeterProvider().Meter("test")
+	}
+
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(factory))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{CacheAPIName}, HostAPIConfig{
+		ServiceName:     service,
+		PolicyEngine:    &mockPolicyEngine{},
+		Tracer:          tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:           meter,
+		MaxResponseSize: maxResponseSize,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+// cacheGet fetches a key and decodes the response
+func cacheGet(t *testing.T, set HostAPISet, key string) CacheGetResponse {
+	t.Helper()
+	result, err := set.Execute(context.Background(), CacheAPIName, "get", json.RawMessage(fmt.Sprintf(`{"key":%q}`, key)))
+	require.NoError(t, err)
+
+	var resp CacheGetResponse
+	require.NoError(t, json.Unmarshal(result, &resp))
+	return resp
+}
+
+// cacheSet stores a JSON value
+func cacheSet(t *testing.T, set HostAPISet, key, value string) {
+	t.Helper()
+	_, err := set.Execute(context.Background(), CacheAPIName, "set", json.RawMessage(fmt.Sprintf(`{"key":%q,"value":%s}`, key, value)))
+	require.NoError(t, err)
+}
+
+func TestCacheAPI_GetSetDeleteInvalidate(t *testing.T) {
+	ctx := context.Background()
+	meter := newCountingMeter()
+	set := newCacheTestSet(t, NewCacheAPIFactory(), "acme/users", meter, 0)
+
+	assert.False(t, cacheGet(t, set, "user:1").Exists)
+
+	cacheSet(t, set, "user:1", `{"name":"Ada"}`)
+	cacheSet(t, set, "user:2", `{"name":"Grace"}`)
+	cacheSet(t, set, "session:1", `"abc"`)
+
+	resp := cacheGet(t, set, "user:1")
+	assert.True(t, resp.Exists)
+	assert.JSONEq(t, `{"name":"Ada"}`, string(resp.Value))
+	assert.Equal(t, int64(3600), resp.TTL)
+	assert.Equal(t, int64(1), resp.Hits)
+	assert.NotNil(t, resp.Created)
+	assert.Equal(t, int64(2), cacheGet(t, set, "user:1").Hits)
+
+	result, err := set.Execute(ctx, CacheAPIName, "delete", json.RawMessage(`{"key":"user:1"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"deleted":true}`, string(result))
+
+	result, err = set.Execute(ctx, CacheAPIName, "delete", json.RawMessage(`{"key":"user:1"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"deleted":false}`, string(result))
+
+	cacheSet(t, set, "user:3", `3`)
+	result, err = set.Execute(ctx, CacheAPIName, "invalidate", json.RawMessage(`{"prefix":"user:"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"invalidated":2}`, string(result))
+	assert.False(t, cacheGet(t, set, "user:2").Exists)
+	assert.True(t, cacheGet(t, set, "session:1").Exists)
+
+	// user:1 twice, session:1 once; misses for the first user:1 and user:2
+	assert.Equal(t, int64(3), meter.count("host_api_cache_hits"))
+	assert.Equal(t, int64(2), meter.count("host_api_cache_misses"))
+}
+
+func TestCacheAPI_TTL(t *testing.T) {
+	// Entries expire by the set's HostAPIConfig.Clock
+	factory := NewCacheAPIFactory()
+	newSet := func(clock Clock) HostAPISet {
+		registry := NewHostAPIRegistry()
+		require.NoError(t, registry.Register(factory))
+		set, err := registry.CreateHostAPISet(context.Background(), []string{CacheAPIName}, HostAPIConfig{
+			ServiceName:  "acme/users",
+			PolicyEngine: &mockPolicyEngine{},
+			Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+			Meter:        metricnoop.NewMeterProvider().Meter("test"),
+			Clock:        clock,
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { set.Close() })
+		return set
+	}
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	set := newSet(clock)
+
+	_, err := set.Execute(context.Background(), CacheAPIName, "set", json.RawMessage(`{"key":"k","value":1,"ttl":10}`))
+	require.NoError(t, err)
+
+	clock.Advance(9500 * time.Millisecond)
+	resp := cacheGet(t, set, "k")
+	assert.True(t, resp.Exists)
+	assert.Equal(t, int64(1), resp.TTL, "partial seconds round up")
+
+	clock.Advance(500 * time.Millisecond)
+	assert.False(t, cacheGet(t, set, "k").Exists)
+
+	// Workers sharing the service's cache each read it by their own clock
+	later := NewFakeClock(clock.Now())
+	laterSet := newSet(later)
+	_, err = set.Execute(context.Background(), CacheAPIName, "set", json.RawMessage(`{"key":"k","value":1,"ttl":10}`))
+	require.NoError(t, err)
+	later.Advance(time.Minute)
+	assert.False(t, cacheGet(t, laterSet, "k").Exists)
+
+	_, err = laterSet.Execute(context.Background(), CacheAPIName, "set", json.RawMessage(`{"key":"k","value":1,"ttl":10}`))
+	require.NoError(t, err)
+	assert.True(t, cacheGet(t, laterSet, "k").Exists)
+	assert.Equal(t, int64(70), cacheGet(t, set, "k").TTL)
+}
+
+func TestCacheAPI_LRUEviction(t *testing.T) {
+	meter := newCountingMeter()
+	config := defaultCacheConfig()
+	config.MaxEntries = 2
+	set := newCacheTestSet(t, NewCacheAPIFactory(WithCacheConfig(config)), "acme/users", meter, 0)
+
+	cacheSet(t, set, "a", `1`)
+	cacheSet(t, set, "b", `2`)
+	cacheGet(t, set, "a") // a is now more recently used than b
+	cacheSet(t, set, "c", `3`)
+
+	assert.True(t, cacheGet(t, set, "a").Exists)
+	assert.False(t, cacheGet(t, set, "b").Exists)
+	assert.True(t, cacheGet(t, set, "c").Exists)
+	assert.Equal(t, int64(1), meter.count("host_api_cache_evictions"))
+
+	// Byte bound: each entry is 1 byte of key plus 10 bytes of value
+	config = defaultCacheConfig()
+	config.MaxBytes = 25
+	set = newCacheTestSet(t, NewCacheAPIFactory(WithCacheConfig(config)), "acme/users", nil, 0)
+	cacheSet(t, set, "a", `"12345678"`)
+	cacheSet(t, set, "b", `"12345678"`)
+	cacheSet(t, set, "c", `"12345678"`)
+	assert.False(t, cacheGet(t, set, "a").Exists)
+	assert.True(t, cacheGet(t, set, "b").Exists)
+	assert.True(t, cacheGet(t, set, "c").Exists)
+}
+
+func TestCacheAPI_SharedPerService(t *testing.T) {
+	factory := NewCacheAPIFactory()
+	worker1 := newCacheTestSet(t, factory, "acme/users", nil, 0)
+	worker2 := newCacheTestSet(t, factory, "acme/users", nil, 0)
+	other := newCacheTestSet(t, factory, "acme/billing", nil, 0)
+
+	cacheSet(t, worker1, "k", `"shared"`)
+	assert.JSONEq(t, `"shared"`, string(cacheGet(t, worker2, "k").Value))
+	assert.False(t, cacheGet(t, other, "k").Exists)
+
+	// Closing a worker's set leaves the service cache intact
+	require.NoError(t, worker1.Close())
+	assert.True(t, cacheGet(t, worker2, "k").Exists)
+}
+
+func TestCacheAPI_Validation(t *testing.T) {
+	ctx := context.Background()
+	set := newCacheTestSet(t, NewCacheAPIFactory(), "acme/users", nil, 64)
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		code   string
+	}{
+		{"empty key", "get", `{"key":""}`, ErrorCodeInvalidKey},
+		{"invalid key", "set", `{"key":"a b","value":1}`, ErrorCodeInvalidKey},
+		{"long key", "get", `{"key":"` + strings.Repeat("k", 257) + `"}`, ErrorCodeKeyTooLong},
+		{"invalid value", "set", `{"key":"k","value":nope}`, ErrorCodeInvalidParameters},
+		{"ttl too short", "set", `{"key":"k","value":1,"ttl":0}`, ErrorCodeInvalidTTL},
+		{"ttl too long", "set", `{"key":"k","value":1,"ttl":99999999}`, ErrorCodeInvalidTTL},
+		{"value over response limit", "set", `{"key":"k","value":"` + strings.Repeat("v", 64) + `"}`, ErrorCodeValueTooLarge},
+		{"empty prefix", "invalidate", `{"prefix":""}`, ErrorCodeInvalidKey},
+		{"unknown method", "getMany", `{}`, ErrorCodeMethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := set.Execute(ctx, CacheAPIName, tt.method, json.RawMessage(tt.params))
+			requireHostAPIError(t, err, tt.code)
+		})
+	}
+}
+
+func TestCacheAPI_MaxResponseSize(t *testing.T) {
+	factory := NewCacheAPIFactory()
+	large := newCacheTestSet(t, factory, "acme/users", nil, 0)
+	small := newCacheTestSet(t, factory, "acme/users", nil, 64)
+
+	cacheSet(t, large, "k", `"`+strings.Repeat("v", 100)+`"`)
+
+	_, err := small.Execute(context.Background(), CacheAPIName, "get", json.RawMessage(`{"key":"k"}`))
+	requireHostAPIError(t, err, ErrorCodeResponseTooLarge)
+}
+
+func TestCacheAPI_Registered(t *testing.T) {
+	registry := NewHostAPIRegistry()
+	require.NoError(t, InitializeHostAPIs(registry))
+
+	factory, ok := registry.Get(CacheAPIName)
+	require.True(t, ok)
+	assert.Equal(t, CacheAPIVersion, factory.Version())
+
+	methods := []string{}
+	for _, method := range factory.Methods() {
+		methods = append(methods, method.Name)
+	}
+	assert.Equal(t, []string{"get", "set", "delete", "invalidate"}, methods)
+}
@@ -0,0 +1,181 @@
+package hostapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the time source shared by host APIs and the guest's WASI clocks.
+// Injecting a FakeClock through HostAPIConfig.Clock makes both deterministic.
+type Clock interface {
+	// Now returns the current wall-clock time
+	Now() time.Time
+
+	// Nanotime returns monotonic nanoseconds since an arbitrary start point
+	Nanotime() int64
+
+	// Sleep blocks for d or until ctx is done, returning ctx.Err() if interrupted
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// systemClock reads the host's real clock
+type systemClock struct {
+	start time.Time
+}
+
+// NewSystemClock returns a Clock backed by the host's real clock
+func NewSystemClock() Clock {
+	return &systemClock{start: time.Now()}
+}
+
+func (c *systemClock) Now() time.Time { return time.Now() }
+
+func (c *systemClock) Nanotime() int64 { return int64(time.Since(c.start)) }
+
+func (c *systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// clockOrSystem returns clock, or the system clock when none is configured
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return NewSystemClock()
+	}
+	return clock
+}
+
+type clockContextKey struct{}
+
+// ContextWithClock returns ctx carrying clock. Host APIs pass it to backends
+// shared by every service, such as a StateStore, so that time is read from
+// the calling set's HostAPIConfig.Clock.
+func ContextWithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockContextKey{}, clock)
+}
+
+// ClockFromContext returns the clock ctx carries, or nil if it carries none
+func ClockFromContext(ctx context.Context) Clock {
+	clock, _ := ctx.Value(clockContextKey{}).(Clock)
+	return clock
+}
+
+// nowFrom returns the time by the clock ctx carries, or by now if it carries none
+func nowFrom(ctx context.Context, now func() time.Time) time.Time {
+	if clock := ClockFromContext(ctx); clock != nil {
+		return clock.Now()
+	}
+	return now()
+}
+
+// FakeClock is a manually advanced Clock for tests. Time only moves when
+// Advance or Set is called; sleepers wake once the clock passes their deadline.
+type FakeClock struct {
+	mu       sync.Mutex
+	now      time.Time
+	start    time.Time
+	sleepers []*fakeSleeper
+}
+
+type fakeSleeper struct {
+	until time.Time
+	done  chan struct{}
+}
+
+// Compile-time interface compliance checks
+var (
+	_ Clock = (*systemClock)(nil)
+	_ Clock = (*FakeClock)(nil)
+)
+
+// NewFakeClock creates a FakeClock reading start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, start: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Nanotime() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int64(c.now.Sub(c.start))
+}
+
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	if d <= 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	sleeper := &fakeSleeper{until: c.now.Add(d), done: make(chan struct{})}
+	c.sleepers = append(c.sleepers, sleeper)
+	c.mu.Unlock()
+
+	select {
+	case <-sleeper.done:
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		c.removeSleeper(sleeper)
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Advance moves the clock forward by d and wakes sleepers that are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(c.now.Add(d))
+}
+
+// Set moves the clock to t and wakes sleepers that are due. Moving backwards
+// changes Now but never wakes anyone.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(t)
+}
+
+// Sleepers returns the number of goroutines blocked in Sleep, so tests can
+// wait for a sleep to start before advancing the clock
+func (c *FakeClock) Sleepers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sleepers)
+}
+
+func (c *FakeClock) setLocked(t time.Time) {
+	c.now = t
+
+	remaining := c.sleepers[:0]
+	for _, sleeper := range c.sleepers {
+		if c.now.Before(sleeper.until) {
+			remaining = append(remaining, sleeper)
+			continue
+		}
+		close(sleeper.done)
+	}
+	c.sleepers = remaining
+}
+
+func (c *FakeClock) removeSleeper(target *fakeSleeper) {
+	for i, sleeper := range c.sleepers {
+		if sleeper == target {
+			c.sleepers = append(c.sleepers[:i], c.sleepers[i+1:]...)
+			return
+		}
+	}
+}
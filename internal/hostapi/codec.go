@@ -0,0 +1,187 @@
+package hostapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/okra-platform/okra/internal/hostapi/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Encoding identifies the wire format of host call envelopes
+type Encoding int32
+
+const (
+	// EncodingJSON encodes envelopes as JSON; every guest starts with it
+	EncodingJSON Encoding = 0
+	// EncodingProtobuf encodes envelopes as the protobuf messages in pb/abi.proto
+	EncodingProtobuf Encoding = 1
+)
+
+// String returns the name of the encoding
+func (e Encoding) String() string {
+	switch e {
+	case EncodingJSON:
+		return "json"
+	case EncodingProtobuf:
+		return "protobuf"
+	default:
+		return fmt.Sprintf("unknown(%d)", int32(e))
+	}
+}
+
+// codec marshals the request and response envelopes of host calls
+type codec interface {
+	marshal(v interface{}) ([]byte, error)
+	unmarshal(data []byte, v interface{}) error
+}
+
+// codecFor returns the codec for an encoding, or false if it is not supported
+func codecFor(encoding Encoding) (codec, bool) {
+	switch encoding {
+	case EncodingJSON:
+		return jsonCodec{}, true
+	case EncodingProtobuf:
+		return protobufCodec{}, true
+	default:
+		return nil, false
+	}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// protobufCodec encodes envelopes as the messages generated from
+// pb/abi.proto. Parameters and data are carried as opaque bytes and never
+// re-parsed by the codec.
+type protobufCodec struct{}
+
+func (protobufCodec) marshal(v interface{}) ([]byte, error) {
+	var msg proto.Message
+	switch v := v.(type) {
+	case *HostAPIRequest:
+		msg = toPBRequest(v)
+	case *HostAPIResponse:
+		msg = &pb.HostAPIResponse{Success: v.Success, Error: toPBError(v.Error), Data: v.Data}
+	case *NextRequest:
+		msg = &pb.NextRequest{IteratorId: v.IteratorID}
+	case *NextResponse:
+		msg = &pb.NextResponse{Success: v.Success, Error: toPBError(v.Error), Data: v.Data, HasMore: v.HasMore}
+	case *CloseIteratorRequest:
+		msg = &pb.CloseIteratorRequest{IteratorId: v.IteratorID}
+	case *CloseIteratorResponse:
+		msg = &pb.CloseIteratorResponse{Success: v.Success, Error: toPBError(v.Error)}
+	default:
+		return nil, fmt.Errorf("protobuf codec: unsupported type %T", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protobufCodec) unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *HostAPIRequest:
+		var msg pb.HostAPIRequest
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		*v = fromPBRequest(&msg)
+	case *HostAPIResponse:
+		var msg pb.HostAPIResponse
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		*v = HostAPIResponse{Success: msg.Success, Error: fromPBError(msg.Error), Data: msg.Data}
+	case *NextRequest:
+		var msg pb.NextRequest
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		*v = NextRequest{IteratorID: msg.IteratorId}
+	case *NextResponse:
+		var msg pb.NextResponse
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		*v = NextResponse{Success: msg.Success, Error: fromPBError(msg.Error), Data: msg.Data, HasMore: msg.HasMore}
+	case *CloseIteratorRequest:
+		var msg pb.CloseIteratorRequest
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		*v = CloseIteratorRequest{IteratorID: msg.IteratorId}
+	case *CloseIteratorResponse:
+		var msg pb.CloseIteratorResponse
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		*v = CloseIteratorResponse{Success: msg.Success, Error: fromPBError(msg.Error)}
+	default:
+		return fmt.Errorf("protobuf codec: unsupported type %T", v)
+	}
+	return nil
+}
+
+func toPBRequest(req *HostAPIRequest) *pb.HostAPIRequest {
+	msg := &pb.HostAPIRequest{
+		Api:        req.API,
+		Method:     req.Method,
+		Parameters: req.Parameters,
+		Version:    req.Version,
+	}
+
+	// Leave out empty metadata, as JSON leaves out its empty fields
+	metadata := req.Metadata
+	if metadata.TraceID != "" || metadata.SpanID != "" || len(metadata.Baggage) > 0 || metadata.ServiceInfo != (ServiceInfo{}) {
+		msg.Metadata = &pb.RequestMetadata{
+			TraceId: metadata.TraceID,
+			SpanId:  metadata.SpanID,
+			Baggage: metadata.Baggage,
+		}
+		if metadata.ServiceInfo != (ServiceInfo{}) {
+			msg.Metadata.ServiceInfo = &pb.ServiceInfo{Name: metadata.ServiceInfo.Name, Version: metadata.ServiceInfo.Version}
+		}
+	}
+	return msg
+}
+
+func fromPBRequest(msg *pb.HostAPIRequest) HostAPIRequest {
+	req := HostAPIRequest{
+		API:        msg.Api,
+		Method:     msg.Method,
+		Parameters: msg.Parameters,
+		Version:    msg.Version,
+	}
+	if metadata := msg.Metadata; metadata != nil {
+		req.Metadata = RequestMetadata{
+			TraceID: metadata.TraceId,
+			SpanID:  metadata.SpanId,
+			Baggage: metadata.Baggage,
+			ServiceInfo: ServiceInfo{
+				Name:    metadata.GetServiceInfo().GetName(),
+				Version: metadata.GetServiceInfo().GetVersion(),
+			},
+		}
+	}
+	return req
+}
+
+func toPBError(err *HostAPIError) *pb.HostAPIError {
+	if err == nil {
+		return nil
+	}
+	return &pb.HostAPIError{Code: err.Code, Message: err.Message, Details: err.Details}
+}
+
+func fromPBError(msg *pb.HostAPIError) *HostAPIError {
+	if msg == nil {
+		return nil
+	}
+	return &HostAPIError{Code: msg.Code, Message: msg.Message, Details: msg.Details}
+}
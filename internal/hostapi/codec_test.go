@@ -0,0 +1,219 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Test Plan:
+// 1. Test every envelope round-trips through the protobuf codec
+// 2. Test unknown fields are skipped and truncated input is rejected
+// 3. Test host call handlers return protobuf responses
+// 4. Test guests negotiate their encoding with the host module
+// 5. Benchmark JSON and protobuf envelopes for small and large payloads
+
+func TestProtobufCodec_RoundTrip(t *testing.T) {
+	// Test: Every envelope survives marshal and unmarshal
+	c := protobufCodec{}
+	apiErr := &HostAPIError{Code: ErrorCodeAPINotFound, Message: "missing", Details: "key k"}
+
+	messages := []struct {
+		in  interface{}
+		out interface{}
+	}{
+		{&HostAPIRequest{
+			API:        "okra.state",
+			Method:     "set",
+			Parameters: json.RawMessage(`{"key":"k","value":"v"}`),
+			Metadata: RequestMetadata{
+				TraceID:     "trace",
+				SpanID:      "span",
+				Baggage:     map[string]string{"tenant": "acme", "region": "eu"},
+				ServiceInfo: ServiceInfo{Name: "acme/orders", Version: "v1.2.3"},
+			},
+		}, &HostAPIRequest{}},
+		{&HostAPIRequest{API: "okra.time", Version: "^1.0.0", Method: "now"}, &HostAPIRequest{}},
+		{&HostAPIResponse{Success: true, Data: json.RawMessage(`{"value":1}`)}, &HostAPIResponse{}},
+		{&HostAPIResponse{Success: false, Error: apiErr}, &HostAPIResponse{}},
+		{&NextRequest{IteratorID: "it-1"}, &NextRequest{}},
+		{&NextResponse{Success: true, Data: json.RawMessage(`[1,2]`), HasMore: true}, &NextResponse{}},
+		{&NextResponse{Success: false, Error: apiErr}, &NextResponse{}},
+		{&CloseIteratorRequest{IteratorID: "it-1"}, &CloseIteratorRequest{}},
+		{&CloseIteratorResponse{Success: true}, &CloseIteratorResponse{}},
+		{&CloseIteratorResponse{Error: apiErr}, &CloseIteratorResponse{}},
+	}
+
+	for _, m := range messages {
+		data, err := c.marshal(m.in)
+		require.NoError(t, err)
+		require.NoError(t, c.unmarshal(data, m.out))
+		assert.Equal(t, m.in, m.out)
+	}
+
+	_, err := c.marshal(&StreamingResponse{})
+	assert.Error(t, err)
+	assert.Error(t, c.unmarshal(nil, &StreamingResponse{}))
+}
+
+func TestProtobufCodec_UnknownAndMalformed(t *testing.T) {
+	c := protobufCodec{}
+
+	// Test: Fields added by newer guests are skipped
+	data, err := c.marshal(&NextRequest{IteratorID: "it-1"})
+	require.NoError(t, err)
+	data = protowire.AppendTag(data, 9, protowire.VarintType)
+	data = protowire.AppendVarint(data, 42)
+	var req NextRequest
+	require.NoError(t, c.unmarshal(data, &req))
+	assert.Equal(t, "it-1", req.IteratorID)
+
+	// Test: Truncated input is rejected
+	assert.Error(t, c.unmarshal(data[:3], &NextRequest{}))
+
+	// Test: A field with an unexpected wire type is skipped like an unknown one
+	wrongType := protowire.AppendTag(nil, 1, protowire.VarintType)
+	wrongType = protowire.AppendVarint(wrongType, 1)
+	var wrongReq HostAPIRequest
+	require.NoError(t, c.unmarshal(wrongType, &wrongReq))
+	assert.Empty(t, wrongReq.API)
+
+	// Test: JSON is not mistaken for a protobuf request
+	assert.Error(t, c.unmarshal([]byte(`{"api":"okra.state"}`), &HostAPIRequest{}))
+}
+
+func TestHostCallHandlers_Protobuf(t *testing.T) {
+	mockSet := &mockHostAPISet{
+		executeFunc: func(ctx context.Context, apiName, method string, parameters json.RawMessage) (json.RawMessage, error) {
+			if method == "fail" {
+				return nil, &HostAPIError{Code: ErrorCodeAPINotFound, Message: "missing"}
+			}
+			metadata, _ := RequestMetadataFromContext(ctx)
+			assert.Equal(t, "trace", metadata.TraceID)
+			return parameters, nil
+		},
+	}
+	ctx := context.WithValue(context.Background(), hostAPISetKey{}, mockSet)
+	c := protobufCodec{}
+
+	// Test: run_host_api decodes the request and encodes the result
+	request, err := c.marshal(&HostAPIRequest{
+		API:        "test.api",
+		Method:     "echo",
+		Parameters: json.RawMessage(`{"message":"hello"}`),
+		Metadata:   RequestMetadata{TraceID: "trace"},
+	})
+	require.NoError(t, err)
+	response, err := runHostAPI(ctx, c, request)
+	require.NoError(t, err)
+	var resp HostAPIResponse
+	require.NoError(t, c.unmarshal(response, &resp))
+	assert.True(t, resp.Success)
+	assert.JSONEq(t, `{"message":"hello"}`, string(resp.Data))
+
+	// Test: errors are returned in the response
+	request, err = c.marshal(&HostAPIRequest{API: "test.api", Method: "fail", Metadata: RequestMetadata{TraceID: "trace"}})
+	require.NoError(t, err)
+	response, err = runHostAPI(ctx, c, request)
+	require.NoError(t, err)
+	resp = HostAPIResponse{}
+	require.NoError(t, c.unmarshal(response, &resp))
+	assert.False(t, resp.Success)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, ErrorCodeAPINotFound, resp.Error.Code)
+
+	// Test: a JSON request is rejected once the guest selected protobuf
+	_, err = runHostAPI(ctx, c, []byte(`{"api":"test.api","method":"echo"}`))
+	assert.ErrorContains(t, err, "invalid request format")
+}
+
+func TestHostModule_SetEncoding(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	hostModule, err := RegisterHostModule(ctx, runtime)
+	require.NoError(t, err)
+
+	// An empty guest module is enough to bind against
+	compiled, err := runtime.CompileModule(ctx, []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00})
+	require.NoError(t, err)
+	guest, err := runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName(""))
+	require.NoError(t, err)
+
+	// Test: unbound guests get nothing and stay on JSON
+	_, _, ok := hostModule.lookup(guest)
+	assert.False(t, ok)
+	assert.Equal(t, EncodingJSON, hostModule.SetEncoding(guest, EncodingProtobuf))
+
+	// Test: bound guests start on JSON and can switch to protobuf
+	set := &mockHostAPISet{}
+	hostModule.Bind(guest, set)
+	boundSet, c, ok := hostModule.lookup(guest)
+	require.True(t, ok)
+	assert.Equal(t, set, boundSet)
+	assert.Equal(t, jsonCodec{}, c)
+
+	assert.Equal(t, EncodingProtobuf, hostModule.SetEncoding(guest, EncodingProtobuf))
+	_, c, _ = hostModule.lookup(guest)
+	assert.Equal(t, protobufCodec{}, c)
+
+	// Test: unsupported encodings leave the current one in place
+	assert.Equal(t, EncodingProtobuf, hostModule.SetEncoding(guest, Encoding(7)))
+	assert.Equal(t, EncodingJSON, hostModule.SetEncoding(guest, EncodingJSON))
+
+	// Test: the host module exports set_encoding, and only the listed functions
+	exported := runtime.Module(HostModuleName).ExportedFunctionDefinitions()
+	assert.Contains(t, exported, "set_encoding")
+	assert.Len(t, exported, len(hostFunctionNames))
+	for name := range exported {
+		assert.True(t, IsHostFunction(name), name)
+	}
+
+	hostModule.Unbind(guest)
+	_, _, ok = hostModule.lookup(guest)
+	assert.False(t, ok)
+}
+
+func benchmarkHostCall(b *testing.B, c codec, valueSize int) {
+	mockSet := &mockHostAPISet{
+		executeFunc: func(ctx context.Context, apiName, method string, parameters json.RawMessage) (json.RawMessage, error) {
+			return parameters, nil
+		},
+	}
+	ctx := context.WithValue(context.Background(), hostAPISetKey{}, mockSet)
+
+	value, err := json.Marshal(strings.Repeat("x", valueSize))
+	require.NoError(b, err)
+	request, err := c.marshal(&HostAPIRequest{
+		API:        "okra.state",
+		Method:     "set",
+		Parameters: json.RawMessage(`{"key":"orders/123","value":` + string(value) + `}`),
+		Metadata: RequestMetadata{
+			TraceID:     "4bf92f3577b34da6a3ce929d0e0e4736",
+			SpanID:      "00f067aa0ba902b7",
+			ServiceInfo: ServiceInfo{Name: "acme/orders", Version: "v1.0.0"},
+		},
+	})
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(request)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := runHostAPI(ctx, c, request); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHostCall_JSON_Small(b *testing.B)     { benchmarkHostCall(b, jsonCodec{}, 64) }
+func BenchmarkHostCall_Protobuf_Small(b *testing.B) { benchmarkHostCall(b, protobufCodec{}, 64) }
+func BenchmarkHostCall_JSON_Large(b *testing.B)     { benchmarkHostCall(b, jsonCodec{}, 64*1024) }
+func BenchmarkHostCall_Protobuf_Large(b *testing.B) { benchmarkHostCall(b, protobufCodec{}, 64*1024) }
@@ -0,0 +1,190 @@
+package hostapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// HostAPIDescription describes a host API offered by a runtime
+type HostAPIDescription struct {
+	Name       string           `json:"name"`
+	Version    string           `json:"version"`
+	Deprecated string           `json:"deprecated,omitempty"` // Why the version is deprecated, if it is
+	Methods    []MethodMetadata `json:"methods"`
+}
+
+// Catalog lists the host APIs a runtime offers. It is what the admin API
+// serves at /api/v1/hostapis and what `okra hostapis` prints.
+type Catalog struct {
+	HostAPIs []HostAPIDescription `json:"hostApis"`
+}
+
+// DescribeRegistry returns the catalog of every API version in the registry,
+// sorted by name and then version
+func DescribeRegistry(registry HostAPIRegistry) Catalog {
+	factories := registry.List()
+	catalog := Catalog{HostAPIs: make([]HostAPIDescription, 0, len(factories))}
+	for _, factory := range factories {
+		methods := factory.Methods()
+		if methods == nil {
+			methods = []MethodMetadata{}
+		}
+		message, _ := deprecation(factory)
+		catalog.HostAPIs = append(catalog.HostAPIs, HostAPIDescription{
+			Name:       factory.Name(),
+			Version:    factory.Version(),
+			Deprecated: message,
+			Methods:    methods,
+		})
+	}
+	sort.Slice(catalog.HostAPIs, func(i, j int) bool {
+		a, b := catalog.HostAPIs[i], catalog.HostAPIs[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		va, _ := ParseVersion(a.Version)
+		vb, _ := ParseVersion(b.Version)
+		return va.Compare(vb) < 0
+	})
+	return catalog
+}
+
+// Lookup returns the description of the latest version of a host API by name
+func (c Catalog) Lookup(name string) (HostAPIDescription, bool) {
+	api, err := c.Resolve(name, "")
+	return api, err == nil
+}
+
+// Versions returns every version of a host API the catalog lists
+func (c Catalog) Versions(name string) []HostAPIDescription {
+	var versions []HostAPIDescription
+	for _, api := range c.HostAPIs {
+		if api.Name == name {
+			versions = append(versions, api)
+		}
+	}
+	return versions
+}
+
+// Resolve returns the version of a host API a service declaring versionRange
+// would be bound to, picked the same way as HostAPIRegistry.Resolve
+func (c Catalog) Resolve(name, versionRange string) (HostAPIDescription, error) {
+	apis := c.Versions(name)
+	if len(apis) == 0 {
+		return HostAPIDescription{}, fmt.Errorf("%s: not offered by the runtime", name)
+	}
+
+	rng, err := ParseVersionRange(versionRange)
+	if err != nil {
+		return HostAPIDescription{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	versions := make([]Version, len(apis))
+	offered := make([]string, len(apis))
+	for i, api := range apis {
+		if versions[i], err = ParseVersion(api.Version); err != nil {
+			return HostAPIDescription{}, fmt.Errorf("%s: %w", name, err)
+		}
+		offered[i] = versions[i].String()
+	}
+	if i := bestMatch(versions, rng); i >= 0 {
+		return apis[i], nil
+	}
+	return HostAPIDescription{}, fmt.Errorf("%s: runtime offers %s, service needs %s", name, strings.Join(offered, " and "), rng)
+}
+
+// latest returns the latest version of each API, sorted by name
+func (c Catalog) latest() []HostAPIDescription {
+	var apis []HostAPIDescription
+	for _, api := range c.HostAPIs {
+		if len(apis) > 0 && apis[len(apis)-1].Name == api.Name {
+			continue
+		}
+		if latest, ok := c.Lookup(api.Name); ok {
+			apis = append(apis, latest)
+		}
+	}
+	return apis
+}
+
+// JSONSchema returns a JSON Schema document with a definition for the
+// parameters and result of every method, named "<api>.<method>.params" and
+// "<api>.<method>.result". Only the latest version of each API is described.
+func (c Catalog) JSONSchema() *spec.Schema {
+	schema := &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Schema:      "http://json-schema.org/draft-04/schema#",
+			Title:       "OKRA host APIs",
+			Definitions: spec.Definitions{},
+		},
+	}
+	for _, api := range c.latest() {
+		for _, method := range api.Methods {
+			prefix := api.Name + "." + method.Name
+			schema.Definitions[prefix+".params"] = schemaOrEmpty(method.Parameters)
+			schema.Definitions[prefix+".result"] = schemaOrEmpty(method.Returns)
+		}
+	}
+	return schema
+}
+
+// OpenAPI returns a Swagger 2.0 document describing each method as
+// POST /<api>/<method>, with the parameters as the body and the result as the
+// 200 response. Error codes, streaming and deprecation are carried as x-okra
+// extensions. Only the latest version of each API is described.
+func (c Catalog) OpenAPI() *spec.Swagger {
+	doc := &spec.Swagger{
+		SwaggerProps: spec.SwaggerProps{
+			Swagger:     "2.0",
+			Info:        &spec.Info{InfoProps: spec.InfoProps{Title: "OKRA host APIs", Version: "1.0"}},
+			Consumes:    []string{"application/json"},
+			Produces:    []string{"application/json"},
+			Paths:       &spec.Paths{Paths: map[string]spec.PathItem{}},
+			Definitions: spec.Definitions{},
+		},
+	}
+
+	for _, api := range c.latest() {
+		doc.Tags = append(doc.Tags, spec.NewTag(api.Name, "Version "+api.Version, nil))
+		for _, method := range api.Methods {
+			prefix := api.Name + "." + method.Name
+			doc.Definitions[prefix+".params"] = schemaOrEmpty(method.Parameters)
+			doc.Definitions[prefix+".result"] = schemaOrEmpty(method.Returns)
+
+			op := spec.NewOperation(prefix).
+				WithSummary(method.Description).
+				WithTags(api.Name).
+				AddParam(spec.BodyParam("parameters", spec.RefSchema("#/definitions/"+prefix+".params")).AsRequired()).
+				RespondsWith(http.StatusOK, spec.NewResponse().
+					WithDescription("Result of "+prefix).
+					WithSchema(spec.RefSchema("#/definitions/"+prefix+".result")))
+			op.AddExtension("x-okra-version", api.Version)
+			if len(method.Errors) > 0 {
+				op.AddExtension("x-okra-errors", method.Errors)
+			}
+			if method.Streaming {
+				op.AddExtension("x-okra-streaming", true)
+			}
+			if api.Deprecated != "" {
+				op.Deprecated = true
+				op.AddExtension("x-okra-deprecated", api.Deprecated)
+			}
+
+			doc.Paths.Paths["/"+api.Name+"/"+method.Name] = spec.PathItem{
+				PathItemProps: spec.PathItemProps{Post: op},
+			}
+		}
+	}
+	return doc
+}
+
+func schemaOrEmpty(schema *spec.Schema) spec.Schema {
+	if schema == nil {
+		return spec.Schema{}
+	}
+	return *schema
+}
@@ -0,0 +1,73 @@
+package hostapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test the catalog describes every registered API, sorted by name
+// 2. Test JSON Schema and OpenAPI documents cover every method
+
+func TestDescribeRegistry(t *testing.T) {
+	catalog := DescribeRegistry(NewDefaultHostAPIRegistry())
+
+	// Test: Every built-in API is described, sorted by name
+	require.NotEmpty(t, catalog.HostAPIs)
+	for i := 1; i < len(catalog.HostAPIs); i++ {
+		assert.Less(t, catalog.HostAPIs[i-1].Name, catalog.HostAPIs[i].Name)
+	}
+
+	state, ok := catalog.Lookup("okra.state")
+	require.True(t, ok)
+	assert.Equal(t, "v1.0.0", state.Version)
+	assert.NotEmpty(t, state.Methods)
+
+	// Test: Methods serialize with their schemas
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"streaming":true`)
+}
+
+func TestCatalog_Documents(t *testing.T) {
+	catalog := Catalog{HostAPIs: []HostAPIDescription{{
+		Name:    "okra.state",
+		Version: "v1.0.0",
+		Methods: []MethodMetadata{
+			{
+				Name:        "get",
+				Description: "Get a value",
+				Parameters:  &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"object"}}},
+				Errors:      []ErrorMetadata{{Code: "KEY_TOO_LONG", Description: "Key too long"}},
+			},
+			{Name: "list", Streaming: true},
+		},
+	}}}
+
+	// Test: JSON Schema has a params and result definition per method
+	schema := catalog.JSONSchema()
+	assert.Len(t, schema.Definitions, 4)
+	assert.Equal(t, spec.StringOrArray{"object"}, schema.Definitions["okra.state.get.params"].Type)
+	assert.Contains(t, schema.Definitions, "okra.state.list.result")
+
+	// Test: OpenAPI has an operation per method carrying errors and streaming
+	doc := catalog.OpenAPI()
+	require.Contains(t, doc.Paths.Paths, "/okra.state/get")
+	get := doc.Paths.Paths["/okra.state/get"].Post
+	require.NotNil(t, get)
+	assert.Equal(t, "okra.state.get", get.ID)
+	assert.Equal(t, "#/definitions/okra.state.get.params", get.Parameters[0].Schema.Ref.String())
+	assert.Contains(t, get.Extensions, "x-okra-errors")
+
+	list := doc.Paths.Paths["/okra.state/list"].Post
+	require.NotNil(t, list)
+	assert.Equal(t, true, list.Extensions["x-okra-streaming"])
+
+	// Test: The document serializes
+	_, err := json.Marshal(doc)
+	require.NoError(t, err)
+}
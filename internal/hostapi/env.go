@@ -0,0 +1,222 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/okra-platform/okra/internal/config"
+)
+
+const (
+	// EnvAPIName is the namespace of the env host API
+	EnvAPIName = "okra.env"
+
+	// EnvAPIVersion is the current version of the env host API
+	EnvAPIVersion = "v1.0.0"
+)
+
+// ErrorCodeKeyNotAllowed indicates the key is not in the service's allowlist
+const ErrorCodeKeyNotAllowed = "KEY_NOT_ALLOWED"
+
+// envKeyPattern restricts keys to alphanumerics, underscore and dash
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+
+// reservedEnvPrefixes are host-internal keys no service may read
+var reservedEnvPrefixes = []string{"OKRA_", "HOST_"}
+
+// EnvGetRequest is the payload for env.get
+type EnvGetRequest struct {
+	Key string `json:"key"`
+}
+
+// EnvGetResponse is the result of env.get. Value is null when the key is unset.
+type EnvGetResponse struct {
+	Value *string `json:"value"`
+}
+
+// EnvConfig holds the code-level limits enforced by the env API
+type EnvConfig struct {
+	MaxKeyLength int // Maximum key length in characters
+}
+
+// EnvAPIOption configures the env API factory
+type EnvAPIOption func(*envAPIFactory)
+
+// WithEnvLookup replaces the process environment as the source of values
+func WithEnvLookup(lookup func(key string) (string, bool)) EnvAPIOption {
+	return func(f *envAPIFactory) {
+		f.lookup = lookup
+	}
+}
+
+// WithEnvConfig overrides the default env limits
+func WithEnvConfig(config EnvConfig) EnvAPIOption {
+	return func(f *envAPIFactory) {
+		f.config = config
+	}
+}
+
+// envAPIFactory creates okra.env instances
+type envAPIFactory struct {
+	config EnvConfig
+	lookup func(key string) (string, bool)
+}
+
+// NewEnvAPIFactory creates the okra.env host API factory backed by the process environment
+func NewEnvAPIFactory(opts ...EnvAPIOption) HostAPIFactory {
+	factory := &envAPIFactory{
+		config: EnvConfig{MaxKeyLength: 256},
+		lookup: os.LookupEnv,
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	return factory
+}
+
+func (f *envAPIFactory) Name() string    { return EnvAPIName }
+func (f *envAPIFactory) Version() string { return EnvAPIVersion }
+
+// Create builds an env API restricted to the keys allowed by the service's okra.json.
+// Services without an env section can read nothing.
+func (f *envAPIFactory) Create(ctx context.Context, hostConfig HostAPIConfig) (HostAPI, error) {
+	var allowed []string
+	if cfg := serviceConfig(hostConfig); cfg != nil {
+		allowed = cfg.Env.AllowedKeys
+	}
+
+	return &envAPI{
+		config:  f.config,
+		lookup:  f.lookup,
+		allowed: allowed,
+	}, nil
+}
+
+func (f *envAPIFactory) Methods() []MethodMetadata {
+	return []MethodMetadata{
+		{
+			Name:        "get",
+			Description: "Read a runtime environment variable",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"key": *spec.StringProperty().WithMaxLength(int64(f.config.MaxKeyLength)),
+			}, "key"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"value": *spec.StringProperty().WithDescription("Variable value, or null if unset"),
+			}, "value"),
+			Errors: []ErrorMetadata{
+				{Code: ErrorCodeInvalidKey, Description: "Invalid or reserved key name"},
+				{Code: ErrorCodeKeyTooLong, Description: "Key exceeds maximum length"},
+				{Code: ErrorCodeKeyNotAllowed, Description: "Key is not in the service allowlist"},
+			},
+		},
+	}
+}
+
+// envAPI reads allowlisted environment variables
+type envAPI struct {
+	config  EnvConfig
+	lookup  func(key string) (string, bool)
+	allowed []string
+}
+
+// Compile-time interface compliance checks
+var (
+	_ HostAPI        = (*envAPI)(nil)
+	_ HostAPIFactory = (*envAPIFactory)(nil)
+)
+
+func (e *envAPI) Name() string    { return EnvAPIName }
+func (e *envAPI) Version() string { return EnvAPIVersion }
+
+func (e *envAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	if method != "get" {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+
+	var req EnvGetRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+
+	if err := e.validateKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	if !matchesAllowlist(e.allowed, req.Key) {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeKeyNotAllowed,
+			Message: fmt.Sprintf("environment variable %s is not allowed", req.Key),
+		}
+	}
+
+	var resp EnvGetResponse
+	if value, ok := e.lookup(req.Key); ok {
+		resp.Value = &value
+	}
+	return json.Marshal(resp)
+}
+
+// validateKey enforces the code-level key policies
+func (e *envAPI) validateKey(key string) error {
+	if len(key) > e.config.MaxKeyLength {
+		return &HostAPIError{
+			Code:    ErrorCodeKeyTooLong,
+			Message: fmt.Sprintf("key length %d exceeds limit %d", len(key), e.config.MaxKeyLength),
+		}
+	}
+
+	if !envKeyPattern.MatchString(key) {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidKey,
+			Message: "key may only contain letters, digits, '_' and '-'",
+		}
+	}
+
+	for _, prefix := range reservedEnvPrefixes {
+		if strings.HasPrefix(strings.ToUpper(key), prefix) {
+			return &HostAPIError{
+				Code:    ErrorCodeInvalidKey,
+				Message: fmt.Sprintf("keys starting with %s are reserved", prefix),
+			}
+		}
+	}
+
+	return nil
+}
+
+// serviceConfig extracts the service's okra.json from the host API config, if present
+func serviceConfig(hostConfig HostAPIConfig) *config.Config {
+	switch cfg := hostConfig.Config.(type) {
+	case *config.Config:
+		return cfg
+	case config.Config:
+		return &cfg
+	default:
+		return nil
+	}
+}
+
+// matchesAllowlist reports whether key matches an entry in allowed.
+// Entries ending in "*" match any key with that prefix.
+func matchesAllowlist(allowed []string, key string) bool {
+	for _, pattern := range allowed {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if pattern == key {
+			return true
+		}
+	}
+	return false
+}
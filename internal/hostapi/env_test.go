@@ -0,0 +1,131 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test env.get returns allowlisted values and null for unset keys
+// 2. Test keys outside the okra.json allowlist are rejected
+// 3. Test code-level key validation (format, length, reserved prefixes)
+// 4. Test services without an env section can read nothing
+// 5. Test allowlist prefix matching
+
+// newEnvTestSet creates a HostAPISet with the given APIs and okra.json
+func newEnvTestSet(t *testing.T, factory HostAPIFactory, cfg *config.Config, logger *slog.Logger) HostAPISet {
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(factory))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{factory.Name()}, HostAPIConfig{
+		ServiceName:  "acme/users",
+		PolicyEngine: &mockPolicyEngine{},
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		Logger:       logger,
+		Config:       cfg,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+func TestEnvAPI_Get(t *testing.T) {
+	env := map[string]string{"MODE": "prod", "FEATURE_SEARCH": "on", "DATABASE_URL": "postgres://"}
+	factory := NewEnvAPIFactory(WithEnvLookup(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}))
+	set := newEnvTestSet(t, factory, &config.Config{
+		Env: config.EnvConfig{AllowedKeys: []string{"MODE", "REGION", "FEATURE_*"}},
+	}, slog.Default())
+
+	ctx := context.Background()
+
+	result, err := set.Execute(ctx, EnvAPIName, "get", json.RawMessage(`{"key":"MODE"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":"prod"}`, string(result))
+
+	result, err = set.Execute(ctx, EnvAPIName, "get", json.RawMessage(`{"key":"FEATURE_SEARCH"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":"on"}`, string(result))
+
+	// Allowed but unset
+	result, err = set.Execute(ctx, EnvAPIName, "get", json.RawMessage(`{"key":"REGION"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":null}`, string(result))
+
+	// Set but not allowed
+	_, err = set.Execute(ctx, EnvAPIName, "get", json.RawMessage(`{"key":"DATABASE_URL"}`))
+	requireHostAPIError(t, err, ErrorCodeKeyNotAllowed)
+}
+
+func TestEnvAPI_ProcessEnvironment(t *testing.T) {
+	t.Setenv("OKRA_TEST_ENV_MODE", "unused")
+	t.Setenv("ENV_TEST_MODE", "staging")
+
+	set := newEnvTestSet(t, NewEnvAPIFactory(), &config.Config{
+		Env: config.EnvConfig{AllowedKeys: []string{"ENV_TEST_MODE"}},
+	}, slog.Default())
+
+	result, err := set.Execute(context.Background(), EnvAPIName, "get", json.RawMessage(`{"key":"ENV_TEST_MODE"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":"staging"}`, string(result))
+}
+
+func TestEnvAPI_Validation(t *testing.T) {
+	set := newEnvTestSet(t, NewEnvAPIFactory(), &config.Config{
+		Env: config.EnvConfig{AllowedKeys: []string{"*"}},
+	}, slog.Default())
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		code   string
+	}{
+		{"malformed parameters", "get", `{"key":`, ErrorCodeInvalidParameters},
+		{"empty key", "get", `{"key":""}`, ErrorCodeInvalidKey},
+		{"invalid characters", "get", `{"key":"MODE=1"}`, ErrorCodeInvalidKey},
+		{"null byte", "get", `{"key":"MODE\u0000"}`, ErrorCodeInvalidKey},
+		{"reserved okra prefix", "get", `{"key":"OKRA_ADMIN_TOKEN"}`, ErrorCodeInvalidKey},
+		{"reserved host prefix", "get", `{"key":"host_name"}`, ErrorCodeInvalidKey},
+		{"key too long", "get", `{"key":"` + strings.Repeat("K", 257) + `"}`, ErrorCodeKeyTooLong},
+		{"unknown method", "list", `{}`, ErrorCodeMethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := set.Execute(context.Background(), EnvAPIName, tt.method, json.RawMessage(tt.params))
+			requireHostAPIError(t, err, tt.code)
+		})
+	}
+}
+
+func TestEnvAPI_NoConfigDeniesAll(t *testing.T) {
+	t.Setenv("ENV_TEST_MODE", "staging")
+	set := newEnvTestSet(t, NewEnvAPIFactory(), nil, slog.Default())
+
+	_, err := set.Execute(context.Background(), EnvAPIName, "get", json.RawMessage(`{"key":"ENV_TEST_MODE"}`))
+	requireHostAPIError(t, err, ErrorCodeKeyNotAllowed)
+}
+
+func TestMatchesAllowlist(t *testing.T) {
+	allowed := []string{"MODE", "FEATURE_*", "db/*"}
+
+	assert.True(t, matchesAllowlist(allowed, "MODE"))
+	assert.True(t, matchesAllowlist(allowed, "FEATURE_X"))
+	assert.True(t, matchesAllowlist(allowed, "db/password"))
+	assert.False(t, matchesAllowlist(allowed, "MODES"))
+	assert.False(t, matchesAllowlist(allowed, "FEATURE"))
+	assert.False(t, matchesAllowlist(nil, "MODE"))
+}
@@ -37,8 +37,24 @@ const (
 	// ErrorCodeIteratorNotFound indicates the iterator ID is invalid
 	ErrorCodeIteratorNotFound = "ITERATOR_NOT_FOUND"
 
+	// ErrorCodeIteratorAccessDenied indicates the iterator belongs to another service
+	ErrorCodeIteratorAccessDenied = "ITERATOR_ACCESS_DENIED"
+
 	// ErrorCodeIteratorLimitExceeded indicates too many concurrent iterators
 	ErrorCodeIteratorLimitExceeded = "ITERATOR_LIMIT_EXCEEDED"
+
+	// ErrorCodeInvalidParameters indicates the method parameters could not be parsed or validated
+	ErrorCodeInvalidParameters = "INVALID_PARAMETERS"
+
+	// ErrorCodeMethodNotFound indicates the API does not implement the requested method
+	ErrorCodeMethodNotFound = "METHOD_NOT_FOUND"
+
+	// ErrorCodeRateLimited indicates the caller exceeded a rate limit
+	ErrorCodeRateLimited = "RATE_LIMITED"
+
+	// ErrorCodeVersionMismatch indicates the service is bound to an API version
+	// outside the range the call asked for
+	ErrorCodeVersionMismatch = "API_VERSION_MISMATCH"
 )
 
 // WASM memory error indicators
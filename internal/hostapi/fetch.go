@@ -0,0 +1,647 @@
+package hostapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/spec"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// HTTPAPIName is the namespace of the HTTP host API
+	HTTPAPIName = "okra.http"
+
+	// HTTPAPIVersion is the current version of the HTTP host API
+	HTTPAPIVersion = "v1.0.0"
+)
+
+// HTTP API error codes
+const (
+	ErrorCodeInvalidURL       = "INVALID_URL"
+	ErrorCodeSiteNotAllowed   = "SITE_NOT_ALLOWED"
+	ErrorCodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+	ErrorCodeInvalidHeader    = "INVALID_HEADER"
+	ErrorCodeInvalidTimeout   = "INVALID_TIMEOUT"
+	ErrorCodeBodyTooLarge     = "BODY_TOO_LARGE"
+	ErrorCodeRequestFailed    = "REQUEST_FAILED"
+	ErrorCodeRequestTimeout   = "REQUEST_TIMEOUT"
+)
+
+// standardHTTPMethods are the methods guests may use when okra.json does not narrow them
+var standardHTTPMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// reservedHTTPHeaders are managed by the host and cannot be set by guests
+var reservedHTTPHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Upgrade":           true,
+	"Traceparent":       true,
+	"Tracestate":        true,
+}
+
+// headerNamePattern matches RFC 7230 header field names
+var headerNamePattern = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// FetchRequest is the payload for http.fetch and http.fetchStream
+type FetchRequest struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method,omitempty"` // Default: GET
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      []byte            `json:"body,omitempty"`      // Base64 encoded
+	TimeoutMs int64             `json:"timeoutMs,omitempty"` // Default: FetchConfig.DefaultTimeout
+}
+
+// FetchResponse is the result of http.fetch
+type FetchResponse struct {
+	Status     int               `json:"status"`
+	StatusText string            `json:"statusText"`
+	Headers    map[string]string `json:"headers"`
+	Body       []byte            `json:"body"` // Base64 encoded
+}
+
+// FetchStreamResponse is the result of http.fetchStream. The body is read
+// in chunks through the iterator.
+type FetchStreamResponse struct {
+	Status     int               `json:"status"`
+	StatusText string            `json:"statusText"`
+	Headers    map[string]string `json:"headers"`
+	IteratorID string            `json:"iteratorId"`
+	HasData    bool              `json:"hasData"`
+}
+
+// FetchBodyChunk is a single chunk returned by the http.fetchStream iterator
+type FetchBodyChunk struct {
+	Body []byte `json:"body"` // Base64 encoded
+}
+
+// FetchConfig holds the code-level limits enforced by the HTTP API
+type FetchConfig struct {
+	MaxURLLength           int
+	MaxRequestBodySize     int
+	MaxResponseBodySize    int64 // Limit for buffered fetch responses
+	MaxStreamBodySize      int64 // Limit for streamed fetch responses
+	StreamChunkSize        int
+	MaxRedirects           int
+	DefaultTimeout         time.Duration
+	MinTimeout, MaxTimeout time.Duration
+}
+
+// defaultFetchConfig returns the limits described in docs/host-apis/fetch.md
+func defaultFetchConfig() FetchConfig {
+	return FetchConfig{
+		MaxURLLength:        8 * 1024,
+		MaxRequestBodySize:  10 * 1024 * 1024,
+		MaxResponseBodySize: 10 * 1024 * 1024,
+		MaxStreamBodySize:   100 * 1024 * 1024,
+		StreamChunkSize:     64 * 1024,
+		MaxRedirects:        10,
+		DefaultTimeout:      30 * time.Second,
+		MinTimeout:          100 * time.Millisecond,
+		MaxTimeout:          5 * time.Minute,
+	}
+}
+
+// HTTPAPIOption configures the HTTP API factory
+type HTTPAPIOption func(*httpAPIFactory)
+
+// WithFetchConfig overrides the default HTTP limits
+func WithFetchConfig(config FetchConfig) HTTPAPIOption {
+	return func(f *httpAPIFactory) {
+		f.config = config
+	}
+}
+
+// WithHTTPTransport sets the transport used for outbound requests
+func WithHTTPTransport(transport http.RoundTripper) HTTPAPIOption {
+	return func(f *httpAPIFactory) {
+		f.transport = transport
+	}
+}
+
+// httpAPIFactory creates okra.http instances sharing one transport
+type httpAPIFactory struct {
+	config    FetchConfig
+	transport http.RoundTripper
+}
+
+// NewHTTPAPIFactory creates the okra.http host API factory
+func NewHTTPAPIFactory(opts ...HTTPAPIOption) HostAPIFactory {
+	factory := &httpAPIFactory{
+		config:    defaultFetchConfig(),
+		transport: http.DefaultTransport,
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	return factory
+}
+
+func (f *httpAPIFactory) Name() string    { return HTTPAPIName }
+func (f *httpAPIFactory) Version() string { return HTTPAPIVersion }
+
+// Create builds an HTTP API restricted to the sites and methods allowed by the
+// service's okra.json. Services without an http section can reach no sites.
+func (f *httpAPIFactory) Create(ctx context.Context, hostConfig HostAPIConfig) (HostAPI, error) {
+	api := &httpAPI{
+		config:         f.config,
+		allowedMethods: standardHTTPMethods,
+	}
+
+	if cfg := serviceConfig(hostConfig); cfg != nil {
+		api.allowedSites = cfg.HTTP.AllowedSites
+		if len(cfg.HTTP.AllowedMethods) > 0 {
+			api.allowedMethods = cfg.HTTP.AllowedMethods
+		}
+	}
+
+	api.client = &http.Client{
+		Transport:     f.transport,
+		CheckRedirect: api.checkRedirect,
+	}
+
+	return api, nil
+}
+
+func (f *httpAPIFactory) Methods() []MethodMetadata {
+	headersSchema := *spec.MapProperty(spec.StringProperty())
+	requestSchema := objectSchema(map[string]spec.Schema{
+		"url":       *spec.StrFmtProperty("uri"),
+		"method":    *spec.StringProperty().WithEnum("GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"),
+		"headers":   headersSchema,
+		"body":      *spec.StrFmtProperty("byte"),
+		"timeoutMs": *spec.Int64Property().WithDescription("Timeout in milliseconds"),
+	}, "url")
+
+	errorCodes := []ErrorMetadata{
+		{Code: ErrorCodeInvalidURL, Description: "URL is malformed, too long or not http/https"},
+		{Code: ErrorCodeSiteNotAllowed, Description: "Site is not in the service allowlist"},
+		{Code: ErrorCodeMethodNotAllowed, Description: "HTTP method is not allowed"},
+		{Code: ErrorCodeInvalidHeader, Description: "Header is malformed or reserved"},
+		{Code: ErrorCodeInvalidTimeout, Description: "Timeout is out of bounds"},
+		{Code: ErrorCodeBodyTooLarge, Description: "Request or response body exceeds size limit"},
+		{Code: ErrorCodeRequestFailed, Description: "HTTP request failed"},
+		{Code: ErrorCodeRequestTimeout, Description: "HTTP request timed out"},
+	}
+
+	return []MethodMetadata{
+		{
+			Name:        "fetch",
+			Description: "Make an HTTP request and buffer the response body",
+			Parameters:  requestSchema,
+			Returns: objectSchema(map[string]spec.Schema{
+				"status":     *spec.Int32Property(),
+				"statusText": *spec.StringProperty(),
+				"headers":    headersSchema,
+				"body":       *spec.StrFmtProperty("byte"),
+			}),
+			Errors: errorCodes,
+		},
+		{
+			Name:        "fetchStream",
+			Description: "Make an HTTP request and stream the response body in chunks",
+			Parameters:  requestSchema,
+			Returns: objectSchema(map[string]spec.Schema{
+				"status":     *spec.Int32Property(),
+				"statusText": *spec.StringProperty(),
+				"headers":    headersSchema,
+				"iteratorId": *spec.StringProperty().WithDescription("ID to pass to okra.next"),
+				"hasData":    *spec.BoolProperty(),
+			}),
+			Errors:    errorCodes,
+			Streaming: true,
+		},
+	}
+}
+
+// httpAPI makes outbound HTTP requests on behalf of a service
+type httpAPI struct {
+	config         FetchConfig
+	client         *http.Client
+	allowedSites   []string
+	allowedMethods []string
+}
+
+// Compile-time interface compliance checks
+var (
+	_ StreamingHostAPI = (*httpAPI)(nil)
+	_ HostAPIFactory   = (*httpAPIFactory)(nil)
+)
+
+func (h *httpAPI) Name() string    { return HTTPAPIName }
+func (h *httpAPI) Version() string { return HTTPAPIVersion }
+
+func (h *httpAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	switch method {
+	case "fetch":
+		return h.executeFetch(ctx, parameters)
+	case "fetchStream":
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidParameters,
+			Message: "fetchStream must be called as a streaming method",
+		}
+	default:
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+}
+
+func (h *httpAPI) ExecuteStreaming(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, Iterator, error) {
+	if method == "fetchStream" {
+		return h.executeFetchStream(ctx, parameters)
+	}
+
+	result, err := h.Execute(ctx, method, parameters)
+	return result, nil, err
+}
+
+func (h *httpAPI) executeFetch(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	httpReq, timeout, err := h.buildRequest(ctx, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := h.client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, fetchError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, h.config.MaxResponseBodySize+1))
+	if err != nil {
+		return nil, fetchError(err)
+	}
+	if int64(len(body)) > h.config.MaxResponseBodySize {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeBodyTooLarge,
+			Message: fmt.Sprintf("response body exceeds limit %d; use fetchStream for large payloads", h.config.MaxResponseBodySize),
+		}
+	}
+
+	return json.Marshal(FetchResponse{
+		Status:     resp.StatusCode,
+		StatusText: http.StatusText(resp.StatusCode),
+		Headers:    flattenHeaders(resp.Header),
+		Body:       body,
+	})
+}
+
+func (h *httpAPI) executeFetchStream(ctx context.Context, parameters json.RawMessage) (json.RawMessage, Iterator, error) {
+	httpReq, timeout, err := h.buildRequest(ctx, parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The body outlives this call, so the timeout covers the whole stream
+	// and is only cancelled when the iterator is closed
+	streamCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+
+	resp, err := h.client.Do(httpReq.WithContext(streamCtx))
+	if err != nil {
+		cancel()
+		return nil, nil, fetchError(err)
+	}
+
+	iterator := &fetchBodyIterator{
+		body:      resp.Body,
+		reader:    bufio.NewReaderSize(resp.Body, h.config.StreamChunkSize),
+		cancel:    cancel,
+		chunkSize: h.config.StreamChunkSize,
+		maxSize:   h.config.MaxStreamBodySize,
+	}
+
+	hasData := resp.ContentLength != 0 && httpReq.Method != http.MethodHead
+	result, err := json.Marshal(FetchStreamResponse{
+		Status:     resp.StatusCode,
+		StatusText: http.StatusText(resp.StatusCode),
+		Headers:    flattenHeaders(resp.Header),
+		IteratorID: generateIteratorID(),
+		HasData:    hasData,
+	})
+	if err != nil {
+		iterator.Close()
+		return nil, nil, err
+	}
+
+	return result, iterator, nil
+}
+
+// buildRequest validates a fetch request and converts it into an outbound HTTP request
+func (h *httpAPI) buildRequest(ctx context.Context, parameters json.RawMessage) (*http.Request, time.Duration, error) {
+	var req FetchRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, 0, err
+	}
+
+	target, err := h.validateURL(req.URL)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !containsFold(standardHTTPMethods, method) || !containsFold(h.allowedMethods, method) {
+		return nil, 0, &HostAPIError{
+			Code:    ErrorCodeMethodNotAllowed,
+			Message: fmt.Sprintf("HTTP method %s is not allowed", method),
+		}
+	}
+
+	if len(req.Body) > h.config.MaxRequestBodySize {
+		return nil, 0, &HostAPIError{
+			Code:    ErrorCodeBodyTooLarge,
+			Message: fmt.Sprintf("request body size %d exceeds limit %d", len(req.Body), h.config.MaxRequestBodySize),
+		}
+	}
+
+	timeout := h.config.DefaultTimeout
+	if req.TimeoutMs != 0 {
+		timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+		if timeout < h.config.MinTimeout || timeout > h.config.MaxTimeout {
+			return nil, 0, &HostAPIError{
+				Code:    ErrorCodeInvalidTimeout,
+				Message: fmt.Sprintf("timeout must be between %s and %s", h.config.MinTimeout, h.config.MaxTimeout),
+			}
+		}
+	}
+
+	var body io.Reader
+	if len(req.Body) > 0 {
+		body = bytes.NewReader(req.Body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, 0, &HostAPIError{
+			Code:    ErrorCodeInvalidURL,
+			Message: "failed to create HTTP request",
+			Details: err.Error(),
+		}
+	}
+
+	for name, value := range req.Headers {
+		if err := validateHeader(name, value); err != nil {
+			return nil, 0, err
+		}
+		httpReq.Header.Set(name, value)
+	}
+
+	injectTraceHeaders(ctx, httpReq.Header)
+
+	return httpReq, timeout, nil
+}
+
+// validateURL enforces URL syntax, scheme and the site allowlist
+func (h *httpAPI) validateURL(rawURL string) (*url.URL, error) {
+	if len(rawURL) > h.config.MaxURLLength {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidURL,
+			Message: fmt.Sprintf("URL length %d exceeds limit %d", len(rawURL), h.config.MaxURLLength),
+		}
+	}
+
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidURL,
+			Message: "invalid URL format",
+			Details: err.Error(),
+		}
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidURL,
+			Message: "only http and https URLs are allowed",
+		}
+	}
+	if target.Hostname() == "" || target.User != nil {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidURL,
+			Message: "URL must have a host and no credentials",
+		}
+	}
+
+	if !siteAllowed(h.allowedSites, target) {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeSiteNotAllowed,
+			Message: fmt.Sprintf("site %s is not allowed", target.Host),
+		}
+	}
+
+	return target, nil
+}
+
+// checkRedirect applies the redirect limit and re-checks the allowlist for every hop
+func (h *httpAPI) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= h.config.MaxRedirects {
+		return &HostAPIError{
+			Code:    ErrorCodeRequestFailed,
+			Message: fmt.Sprintf("stopped after %d redirects", h.config.MaxRedirects),
+		}
+	}
+	if !siteAllowed(h.allowedSites, req.URL) {
+		return &HostAPIError{
+			Code:    ErrorCodeSiteNotAllowed,
+			Message: fmt.Sprintf("redirect to site %s is not allowed", req.URL.Host),
+		}
+	}
+	return nil
+}
+
+// fetchBodyIterator streams a response body in fixed-size chunks
+type fetchBodyIterator struct {
+	body      io.Closer
+	reader    *bufio.Reader
+	cancel    context.CancelFunc
+	chunkSize int
+	maxSize   int64
+	read      int64
+	done      bool
+}
+
+// Next returns the next chunk of the response body
+func (it *fetchBodyIterator) Next(ctx context.Context) (json.RawMessage, bool, error) {
+	if it.done {
+		return nil, false, nil
+	}
+
+	buf := make([]byte, it.chunkSize)
+	n, err := io.ReadFull(it.reader, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		it.Close()
+		return nil, false, fetchError(err)
+	}
+
+	it.read += int64(n)
+	if it.read > it.maxSize {
+		it.Close()
+		return nil, false, &HostAPIError{
+			Code:    ErrorCodeBodyTooLarge,
+			Message: fmt.Sprintf("response body exceeds limit %d", it.maxSize),
+		}
+	}
+
+	// Peek ahead so hasMore is accurate rather than optimistic
+	_, peekErr := it.reader.Peek(1)
+	hasMore := peekErr == nil
+	if !hasMore {
+		it.Close()
+	}
+
+	data, err := json.Marshal(FetchBodyChunk{Body: buf[:n]})
+	if err != nil {
+		return nil, false, err
+	}
+	return data, hasMore, nil
+}
+
+// Close releases the response body and its request context
+func (it *fetchBodyIterator) Close() error {
+	if it.done {
+		return nil
+	}
+	it.done = true
+	err := it.body.Close()
+	it.cancel()
+	return err
+}
+
+// fetchError converts transport errors into host API errors
+func fetchError(err error) error {
+	var apiErr *HostAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &HostAPIError{
+			Code:    ErrorCodeRequestTimeout,
+			Message: "HTTP request timed out",
+		}
+	}
+	return &HostAPIError{
+		Code:    ErrorCodeRequestFailed,
+		Message: "HTTP request failed",
+		Details: err.Error(),
+	}
+}
+
+// validateHeader rejects malformed, CRLF-injected and host-managed headers
+func validateHeader(name, value string) error {
+	if !headerNamePattern.MatchString(name) || strings.ContainsAny(value, "\r\n\x00") {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidHeader,
+			Message: fmt.Sprintf("invalid header %q", name),
+		}
+	}
+	if reservedHTTPHeaders[http.CanonicalHeaderKey(name)] {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidHeader,
+			Message: fmt.Sprintf("header %s is managed by the host", name),
+		}
+	}
+	return nil
+}
+
+// injectTraceHeaders adds a W3C traceparent for the calling request.
+// Guest-supplied metadata wins; otherwise we fall back to the active span.
+func injectTraceHeaders(ctx context.Context, header http.Header) {
+	if metadata, ok := RequestMetadataFromContext(ctx); ok {
+		traceID, traceErr := trace.TraceIDFromHex(metadata.TraceID)
+		spanID, spanErr := trace.SpanIDFromHex(metadata.SpanID)
+		if traceErr == nil && spanErr == nil {
+			header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", traceID, spanID))
+			return
+		}
+	}
+
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		header.Set("traceparent", fmt.Sprintf("00-%s-%s-%s", spanCtx.TraceID(), spanCtx.SpanID(), spanCtx.TraceFlags()))
+	}
+}
+
+// flattenHeaders joins multi-value headers into a single comma-separated value
+func flattenHeaders(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for name, values := range header {
+		flat[name] = strings.Join(values, ", ")
+	}
+	return flat
+}
+
+// siteAllowed reports whether target matches an allowlist entry. Entries may be
+// origins ("https://api.example.com"), hosts ("api.example.com") or wildcard
+// subdomains ("*.trusted.net"). Host-only entries match any scheme and port.
+func siteAllowed(allowed []string, target *url.URL) bool {
+	host := strings.ToLower(target.Hostname())
+	for _, site := range allowed {
+		site = strings.ToLower(site)
+		if !strings.Contains(site, "://") {
+			if hostMatches(site, host) {
+				return true
+			}
+			continue
+		}
+
+		origin, err := url.Parse(site)
+		if err != nil {
+			continue
+		}
+		if origin.Scheme == target.Scheme && hostMatches(origin.Hostname(), host) && effectivePort(origin) == effectivePort(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostMatches compares a host against a pattern that may start with "*."
+func hostMatches(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return pattern == host
+}
+
+// effectivePort returns the explicit port or the scheme's default
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,11 @@
 package hostapi
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
@@ -12,18 +13,29 @@ import (
 
 // RunHostAPI is the single entry point for all host API calls
 // It's exposed to WASM as "okra.run_host_api"
-// The hostAPISet is retrieved from the context, set during WASM module instantiation
+// The hostAPISet is retrieved from the context, set by the host module for the calling guest
 func RunHostAPI(ctx context.Context, requestJSON string) (string, error) {
+	response, err := runHostAPI(ctx, jsonCodec{}, []byte(requestJSON))
+	return string(response), err
+}
+
+func runHostAPI(ctx context.Context, codec codec, request []byte) ([]byte, error) {
 	// Get the host API set for this service instance
 	hostAPISet, ok := ctx.Value(hostAPISetKey{}).(HostAPISet)
 	if !ok {
-		return "", fmt.Errorf("host API set not found in context")
+		return nil, fmt.Errorf("host API set not found in context")
 	}
 
 	// Parse request
 	var req HostAPIRequest
-	if err := json.Unmarshal([]byte(requestJSON), &req); err != nil {
-		return "", fmt.Errorf("invalid request format: %w", err)
+	if err := codec.unmarshal(request, &req); err != nil {
+		return nil, fmt.Errorf("invalid request format: %w", err)
+	}
+
+	// Make the guest's trace metadata available to host API implementations
+	ctx = context.WithValue(ctx, requestMetadataKey{}, req.Metadata)
+	if req.Version != "" {
+		ctx = context.WithValue(ctx, requestedVersionKey{}, req.Version)
 	}
 
 	// Execute the method via the host API set
@@ -34,116 +46,224 @@ func RunHostAPI(ctx context.Context, requestJSON string) (string, error) {
 	// - Error handling
 	result, err := hostAPISet.Execute(ctx, req.API, req.Method, req.Parameters)
 	if err != nil {
-		// Convert error to HostAPIError format
-		var apiErr *HostAPIError
-		if errors.As(err, &apiErr) {
-			resp := HostAPIResponse{
-				Success: false,
-				Error:   apiErr,
-			}
-			respJSON, _ := json.Marshal(resp)
-			return string(respJSON), nil
-		}
-
-		// Generic error
-		resp := HostAPIResponse{
+		return codec.marshal(&HostAPIResponse{
 			Success: false,
-			Error: &HostAPIError{
-				Code:    ErrorCodeInternalError,
-				Message: err.Error(),
-			},
-		}
-		respJSON, _ := json.Marshal(resp)
-		return string(respJSON), nil
+			Error:   toHostAPIError(err),
+		})
 	}
 
 	// Return success response
-	resp := HostAPIResponse{
+	return codec.marshal(&HostAPIResponse{
 		Success: true,
 		Data:    result,
-	}
-	respJSON, _ := json.Marshal(resp)
-	return string(respJSON), nil
+	})
 }
 
 // NextIterator is the entry point for iterator advancement
 // It's exposed to WASM as "okra.next"
 func NextIterator(ctx context.Context, requestJSON string) (string, error) {
+	response, err := nextIterator(ctx, jsonCodec{}, []byte(requestJSON))
+	return string(response), err
+}
+
+func nextIterator(ctx context.Context, codec codec, request []byte) ([]byte, error) {
 	// Get the host API set for this service instance
 	hostAPISet, ok := ctx.Value(hostAPISetKey{}).(HostAPISet)
 	if !ok {
-		return "", fmt.Errorf("host API set not found in context")
+		return nil, fmt.Errorf("host API set not found in context")
 	}
 
 	// Parse request
 	var req NextRequest
-	if err := json.Unmarshal([]byte(requestJSON), &req); err != nil {
-		return "", fmt.Errorf("invalid next request format: %w", err)
+	if err := codec.unmarshal(request, &req); err != nil {
+		return nil, fmt.Errorf("invalid next request format: %w", err)
 	}
 
 	// Get next chunk from iterator
 	data, hasMore, err := hostAPISet.NextIterator(ctx, req.IteratorID)
 	if err != nil {
-		// Convert error to NextResponse format
-		var apiErr *HostAPIError
-		if errors.As(err, &apiErr) {
-			resp := NextResponse{
-				Success: false,
-				Error:   apiErr,
-			}
-			respJSON, _ := json.Marshal(resp)
-			return string(respJSON), nil
-		}
-
-		// Generic error
-		resp := NextResponse{
+		return codec.marshal(&NextResponse{
 			Success: false,
-			Error: &HostAPIError{
-				Code:    ErrorCodeInternalError,
-				Message: err.Error(),
-			},
-		}
-		respJSON, _ := json.Marshal(resp)
-		return string(respJSON), nil
+			Error:   toHostAPIError(err),
+		})
 	}
 
 	// Return success response
-	resp := NextResponse{
+	return codec.marshal(&NextResponse{
 		Success: true,
 		Data:    data,
 		HasMore: hasMore,
+	})
+}
+
+// CloseIterator is the entry point for releasing an iterator before it is exhausted
+// It's exposed to WASM as "okra.close_iterator"
+func CloseIterator(ctx context.Context, requestJSON string) (string, error) {
+	response, err := closeIterator(ctx, jsonCodec{}, []byte(requestJSON))
+	return string(response), err
+}
+
+func closeIterator(ctx context.Context, codec codec, request []byte) ([]byte, error) {
+	// Get the host API set for this service instance
+	hostAPISet, ok := ctx.Value(hostAPISetKey{}).(HostAPISet)
+	if !ok {
+		return nil, fmt.Errorf("host API set not found in context")
+	}
+
+	// Parse request
+	var req CloseIteratorRequest
+	if err := codec.unmarshal(request, &req); err != nil {
+		return nil, fmt.Errorf("invalid close iterator request format: %w", err)
+	}
+
+	resp := CloseIteratorResponse{Success: true}
+	if err := hostAPISet.CloseIterator(ctx, req.IteratorID); err != nil {
+		resp = CloseIteratorResponse{Success: false, Error: toHostAPIError(err)}
 	}
-	respJSON, _ := json.Marshal(resp)
-	return string(respJSON), nil
+	return codec.marshal(&resp)
 }
 
-// RegisterHostAPI registers the unified host API function with Wazero
-func RegisterHostAPI(ctx context.Context, runtime wazero.Runtime, hostAPISet HostAPISet) error {
-	// Store the hostAPISet in a closure so it's accessible to the host function
-	// This is safe because each WASM instance has its own hostAPISet
+// toHostAPIError converts an error into the HostAPIError returned to the guest
+func toHostAPIError(err error) *HostAPIError {
+	var apiErr *HostAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
 
-	// Get configuration for limits
-	config := hostAPISet.Config()
-	maxRequestSize := config.MaxRequestSize
-	if maxRequestSize == 0 {
-		maxRequestSize = DefaultMaxRequestSize
+	// Generic error
+	return &HostAPIError{
+		Code:    ErrorCodeInternalError,
+		Message: err.Error(),
 	}
-	maxResponseSize := config.MaxResponseSize
-	if maxResponseSize == 0 {
-		maxResponseSize = DefaultMaxResponseSize
+}
+
+// HostModuleName is the module guests import host functions from
+const HostModuleName = "okra"
+
+// hostFunctionNames are the functions the okra host module exports
+var hostFunctionNames = []string{
+	"run_host_api", "next", "close_iterator",
+	"run_host_api_packed", "next_packed", "close_iterator_packed",
+	"set_encoding",
+}
+
+// IsHostFunction reports whether the okra host module exports a function named name
+func IsHostFunction(name string) bool {
+	for _, fn := range hostFunctionNames {
+		if fn == name {
+			return true
+		}
 	}
+	return false
+}
+
+// HostModule is the "okra" host module shared by every guest instantiated in
+// a wazero runtime. A runtime can only hold one module named "okra", so the
+// host functions are registered once and each call is routed to the
+// HostAPISet bound to the calling guest module.
+type HostModule struct {
+	mu     sync.RWMutex
+	guests map[api.Module]*boundGuest
+}
+
+// boundGuest is the per-instance state of a guest using the host module
+type boundGuest struct {
+	hostAPISet HostAPISet
+	encoding   Encoding
+}
+
+// Bind routes host API calls made by module to hostAPISet. Calls use JSON
+// envelopes until the guest selects another encoding with okra.set_encoding.
+func (h *HostModule) Bind(module api.Module, hostAPISet HostAPISet) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.guests[module] = &boundGuest{hostAPISet: hostAPISet, encoding: EncodingJSON}
+}
+
+// Unbind removes the HostAPISet bound to module; call it when the module is closed
+func (h *HostModule) Unbind(module api.Module) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.guests, module)
+}
+
+// lookup returns the HostAPISet bound to the calling module and the codec for
+// its envelopes. The context is deliberately not consulted: a host API that
+// calls another guest passes its context along, and that guest must not
+// inherit the caller's set.
+func (h *HostModule) lookup(module api.Module) (HostAPISet, codec, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	guest, ok := h.guests[module]
+	if !ok {
+		return nil, nil, false
+	}
+	codec, _ := codecFor(guest.encoding)
+	return guest.hostAPISet, codec, true
+}
+
+// Encoding returns the envelope encoding module has selected, or JSON if it is
+// not bound
+func (h *HostModule) Encoding(module api.Module) Encoding {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if guest, ok := h.guests[module]; ok {
+		return guest.encoding
+	}
+	return EncodingJSON
+}
+
+// SetEncoding switches the envelope encoding for module if it is supported and
+// returns the encoding now in effect. Guests call it through okra.set_encoding;
+// the host calls it to carry a guest's choice over to a copy of the guest.
+func (h *HostModule) SetEncoding(module api.Module, encoding Encoding) Encoding {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	guest, ok := h.guests[module]
+	if !ok {
+		return EncodingJSON
+	}
+	if _, supported := codecFor(encoding); supported {
+		guest.encoding = encoding
+	}
+	return guest.encoding
+}
+
+// RegisterHostModule registers the okra host functions with a Wazero runtime.
+// Call it once per runtime, then Bind each guest module to its HostAPISet.
+func RegisterHostModule(ctx context.Context, runtime wazero.Runtime) (*HostModule, error) {
+	h := &HostModule{guests: make(map[api.Module]*boundGuest)}
 
 	// Create the host module
 	// Using "okra" namespace to clearly identify these as OKRA host functions
 	// and avoid confusion with "env" which suggests environment variables
-	builder := runtime.NewHostModuleBuilder("okra")
+	builder := runtime.NewHostModuleBuilder(HostModuleName)
 
 	// Helper function to handle WASM memory operations
-	handleHostCall := func(ctx context.Context, module api.Module, stack []uint64, handler func(context.Context, string) (string, error)) {
+	handleHostCall := func(ctx context.Context, module api.Module, stack []uint64, handler func(context.Context, codec, []byte) ([]byte, error)) {
 		// Extract parameters from stack
 		requestPtr := uint32(stack[0])
 		requestLen := uint32(stack[1])
 
+		// Find the host API set for the calling guest
+		hostAPISet, codec, ok := h.lookup(module)
+		if !ok {
+			stack[0] = uint64(NullPointer)
+			stack[1] = uint64(ZeroLength)
+			return
+		}
+
+		// Get configuration for limits
+		config := hostAPISet.Config()
+		maxRequestSize := config.MaxRequestSize
+		if maxRequestSize == 0 {
+			maxRequestSize = DefaultMaxRequestSize
+		}
+		maxResponseSize := config.MaxResponseSize
+		if maxResponseSize == 0 {
+			maxResponseSize = DefaultMaxResponseSize
+		}
+
 		// Validate request size
 		if requestLen > uint32(maxRequestSize) {
 			stack[0] = uint64(NullPointer)
@@ -159,11 +279,17 @@ func RegisterHostAPI(ctx context.Context, runtime wazero.Runtime, hostAPISet Hos
 			return
 		}
 
-		// Add hostAPISet to context
+		// Add hostAPISet to context, and the service it was created for as the
+		// caller: iterators may only be used by the service that opened them
 		ctx = context.WithValue(ctx, hostAPISetKey{}, hostAPISet)
+		ctx = context.WithValue(ctx, serviceInfoKey{}, ServiceInfo{
+			Name:    config.ServiceName,
+			Version: config.ServiceVersion,
+		})
 
-		// Execute the handler
-		response, err := handler(ctx, string(requestBytes))
+		// Execute the handler on a copy: the view into guest memory is
+		// invalidated if the guest's memory grows during the call
+		respBytes, err := handler(ctx, codec, bytes.Clone(requestBytes))
 		if err != nil {
 			// This should not happen as handlers return error in the response
 			stack[0] = uint64(NullPointer)
@@ -172,19 +298,17 @@ func RegisterHostAPI(ctx context.Context, runtime wazero.Runtime, hostAPISet Hos
 		}
 
 		// Write response to WASM memory
-		respBytes := []byte(response)
-
 		// Validate response size
 		if len(respBytes) > maxResponseSize {
 			// Return error response for oversized response
-			errResp := map[string]interface{}{
-				"success": false,
-				"error": map[string]string{
-					"code":    ErrorCodeResponseTooLarge,
-					"message": fmt.Sprintf("response size %d exceeds limit %d", len(respBytes), maxResponseSize),
+			// Every response type shares the success and error fields
+			respBytes, _ = codec.marshal(&HostAPIResponse{
+				Success: false,
+				Error: &HostAPIError{
+					Code:    ErrorCodeResponseTooLarge,
+					Message: fmt.Sprintf("response size %d exceeds limit %d", len(respBytes), maxResponseSize),
 				},
-			}
-			respBytes, _ = json.Marshal(errResp)
+			})
 		}
 
 		// Get the guest's allocate function
@@ -228,7 +352,7 @@ func RegisterHostAPI(ctx context.Context, runtime wazero.Runtime, hostAPISet Hos
 	// Register run_host_api function
 	builder.NewFunctionBuilder().
 		WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, module api.Module, stack []uint64) {
-			handleHostCall(ctx, module, stack, RunHostAPI)
+			handleHostCall(ctx, module, stack, runHostAPI)
 		}), []api.ValueType{
 			api.ValueTypeI32, // requestPtr
 			api.ValueTypeI32, // requestLen
@@ -241,7 +365,7 @@ func RegisterHostAPI(ctx context.Context, runtime wazero.Runtime, hostAPISet Hos
 	// Register the next function for iterator support
 	builder.NewFunctionBuilder().
 		WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, module api.Module, stack []uint64) {
-			handleHostCall(ctx, module, stack, NextIterator)
+			handleHostCall(ctx, module, stack, nextIterator)
 		}), []api.ValueType{
 			api.ValueTypeI32, // requestPtr
 			api.ValueTypeI32, // requestLen
@@ -251,7 +375,57 @@ func RegisterHostAPI(ctx context.Context, runtime wazero.Runtime, hostAPISet Hos
 		}).
 		Export("next")
 
-	// Instantiate the module with both functions
-	_, err := builder.Instantiate(ctx)
-	return err
+	// Register close_iterator so guests can release iterators early
+	builder.NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, module api.Module, stack []uint64) {
+			handleHostCall(ctx, module, stack, closeIterator)
+		}), []api.ValueType{
+			api.ValueTypeI32, // requestPtr
+			api.ValueTypeI32, // requestLen
+		}, []api.ValueType{
+			api.ValueTypeI32, // responsePtr
+			api.ValueTypeI32, // responseLen
+		}).
+		Export("close_iterator")
+
+	// Register packed variants of the three calls above. They return the
+	// response as a single i64 (ptr << 32 | len), the same convention as the
+	// guest's handle_request, for toolchains such as TinyGo whose imports
+	// cannot return multiple values.
+	for name, handler := range map[string]func(context.Context, codec, []byte) ([]byte, error){
+		"run_host_api_packed":   runHostAPI,
+		"next_packed":           nextIterator,
+		"close_iterator_packed": closeIterator,
+	} {
+		builder.NewFunctionBuilder().
+			WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, module api.Module, stack []uint64) {
+				handleHostCall(ctx, module, stack, handler)
+				stack[0] = stack[0]<<32 | stack[1]
+			}), []api.ValueType{
+				api.ValueTypeI32, // requestPtr
+				api.ValueTypeI32, // requestLen
+			}, []api.ValueType{
+				api.ValueTypeI64, // responsePtr << 32 | responseLen
+			}).
+			Export(name)
+	}
+
+	// Register set_encoding so guests can negotiate binary envelopes; it
+	// returns the encoding in effect, which stays JSON if the request is
+	// not supported
+	builder.NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(func(ctx context.Context, module api.Module, stack []uint64) {
+			stack[0] = uint64(uint32(h.SetEncoding(module, Encoding(int32(uint32(stack[0]))))))
+		}), []api.ValueType{
+			api.ValueTypeI32, // encoding
+		}, []api.ValueType{
+			api.ValueTypeI32, // encoding in effect
+		}).
+		Export("set_encoding")
+
+	// Instantiate the module with all functions
+	if _, err := builder.Instantiate(ctx); err != nil {
+		return nil, err
+	}
+	return h, nil
 }
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
 )
 
 // Test Plan:
@@ -18,6 +20,8 @@ import (
 // 5. Test NextIterator with valid request
 // 6. Test NextIterator with invalid request format
 // 7. Test error response formatting
+// 8. Test CloseIterator with valid request, errors and invalid format
+// 9. Test the host module identifies callers by the service their set belongs to
 
 // Test: RunHostAPI with valid request
 func TestRunHostAPI_ValidRequest(t *testing.T) {
@@ -273,10 +277,148 @@ func TestNextIterator_Error(t *testing.T) {
 	})
 }
 
+// Test: CloseIterator releases iterators and reports errors
+func TestCloseIterator(t *testing.T) {
+	var closed []string
+	mockSet := &mockHostAPISet{
+		closeIteratorFunc: func(ctx context.Context, iteratorID string) error {
+			switch iteratorID {
+			case "foreign":
+				return &HostAPIError{Code: ErrorCodeIteratorAccessDenied, Message: "iterator foreign belongs to another service"}
+			case "broken":
+				return errors.New("close failed")
+			}
+			closed = append(closed, iteratorID)
+			return nil
+		},
+	}
+	ctx := context.WithValue(context.Background(), hostAPISetKey{}, mockSet)
+
+	tests := []struct {
+		iteratorID string
+		success    bool
+		code       string
+	}{
+		{"iter-1", true, ""},
+		{"foreign", false, ErrorCodeIteratorAccessDenied},
+		{"broken", false, ErrorCodeInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.iteratorID, func(t *testing.T) {
+			reqJSON, err := json.Marshal(CloseIteratorRequest{IteratorID: tt.iteratorID})
+			require.NoError(t, err)
+
+			response, err := CloseIterator(ctx, string(reqJSON))
+			require.NoError(t, err)
+
+			var resp CloseIteratorResponse
+			require.NoError(t, json.Unmarshal([]byte(response), &resp))
+			assert.Equal(t, tt.success, resp.Success)
+			if tt.code == "" {
+				assert.Nil(t, resp.Error)
+			} else {
+				require.NotNil(t, resp.Error)
+				assert.Equal(t, tt.code, resp.Error.Code)
+			}
+		})
+	}
+	assert.Equal(t, []string{"iter-1"}, closed)
+
+	_, err := CloseIterator(ctx, "invalid json")
+	assert.ErrorContains(t, err, "invalid close iterator request format")
+
+	_, err = CloseIterator(context.Background(), `{"iteratorId":"iter-1"}`)
+	assert.ErrorContains(t, err, "host API set not found")
+}
+
+// nextGuestModule exports next, which forwards its arguments to
+// okra.next_packed, with memory and an allocate that always returns 1024
+var nextGuestModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32, i32) -> i64 and (i32) -> i32
+	0x01, 0x0c, 0x02,
+	0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	// Import okra.next_packed
+	0x02, 0x14, 0x01,
+	0x04, 'o', 'k', 'r', 'a',
+	0x0b, 'n', 'e', 'x', 't', '_', 'p', 'a', 'c', 'k', 'e', 'd',
+	0x00, 0x00,
+	// Functions and one page of memory
+	0x03, 0x03, 0x02, 0x01, 0x00,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Export memory, allocate and next
+	0x07, 0x1c, 0x03,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x08, 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x01,
+	0x04, 'n', 'e', 'x', 't', 0x00, 0x02,
+	0x0a, 0x10, 0x02,
+	// allocate: return 1024
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+	// next: return next_packed(ptr, len)
+	0x08, 0x00, 0x20, 0x00, 0x20, 0x01, 0x10, 0x00, 0x0b,
+}
+
+// serviceHostAPISet presents a set as belonging to another service, as if the
+// set were shared by guests of two services
+type serviceHostAPISet struct {
+	HostAPISet
+	service string
+}
+
+func (s serviceHostAPISet) Config() HostAPIConfig {
+	config := s.HostAPISet.Config()
+	config.ServiceName = s.service
+	return config
+}
+
+// Test: A guest cannot advance an iterator opened by another service
+func TestHostModule_IteratorCallerIdentity(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+	hostModule, err := RegisterHostModule(ctx, runtime)
+	require.NoError(t, err)
+
+	set := streamingTestSet(t, nil, time.Minute, 5)
+	iteratorID := openTestIterator(t, set)
+
+	compiled, err := runtime.CompileModule(ctx, nextGuestModule)
+	require.NoError(t, err)
+	next := func(name string, boundSet HostAPISet) NextResponse {
+		guest, err := runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName(name))
+		require.NoError(t, err)
+		defer guest.Close(ctx)
+		hostModule.Bind(guest, boundSet)
+		defer hostModule.Unbind(guest)
+
+		request := []byte(`{"iteratorId":"` + iteratorID + `"}`)
+		require.True(t, guest.Memory().Write(0, request))
+		results, err := guest.ExportedFunction("next").Call(ctx, 0, uint64(len(request)))
+		require.NoError(t, err)
+		response, ok := guest.Memory().Read(uint32(results[0]>>32), uint32(results[0]))
+		require.True(t, ok)
+
+		var resp NextResponse
+		require.NoError(t, json.Unmarshal(response, &resp))
+		return resp
+	}
+
+	denied := next("billing", serviceHostAPISet{HostAPISet: set, service: "acme/billing"})
+	assert.False(t, denied.Success)
+	require.NotNil(t, denied.Error)
+	assert.Equal(t, ErrorCodeIteratorAccessDenied, denied.Error.Code)
+
+	allowed := next("orders", set)
+	assert.True(t, allowed.Success)
+}
+
 // mockHostAPISet implements HostAPISet for testing
 type mockHostAPISet struct {
-	executeFunc      func(ctx context.Context, apiName, method string, parameters json.RawMessage) (json.RawMessage, error)
-	nextIteratorFunc func(ctx context.Context, iteratorID string) (json.RawMessage, bool, error)
+	executeFunc       func(ctx context.Context, apiName, method string, parameters json.RawMessage) (json.RawMessage, error)
+	nextIteratorFunc  func(ctx context.Context, iteratorID string) (json.RawMessage, bool, error)
+	closeIteratorFunc func(ctx context.Context, iteratorID string) error
 }
 
 func (m *mockHostAPISet) Get(name string) (HostAPI, bool) {
@@ -298,6 +440,9 @@ func (m *mockHostAPISet) NextIterator(ctx context.Context, iteratorID string) (j
 }
 
 func (m *mockHostAPISet) CloseIterator(ctx context.Context, iteratorID string) error {
+	if m.closeIteratorFunc != nil {
+		return m.closeIteratorFunc(ctx, iteratorID)
+	}
 	return nil
 }
 
@@ -2,17 +2,63 @@ package hostapi
 
 import "fmt"
 
+// DefaultHostAPIOption configures the built-in host API factories registered
+// by InitializeHostAPIs, such as the stores that back them
+type DefaultHostAPIOption func(*defaultHostAPIOptions)
+
+type defaultHostAPIOptions struct {
+	state   []StateAPIOption
+	secrets []SecretsAPIOption
+	sql     []SQLAPIOption
+	queue   []QueueAPIOption
+}
+
+// WithStateAPIOptions configures the okra.state factory
+func WithStateAPIOptions(opts ...StateAPIOption) DefaultHostAPIOption {
+	return func(o *defaultHostAPIOptions) {
+		o.state = append(o.state, opts...)
+	}
+}
+
+// WithSecretsAPIOptions configures the okra.secrets factory
+func WithSecretsAPIOptions(opts ...SecretsAPIOption) DefaultHostAPIOption {
+	return func(o *defaultHostAPIOptions) {
+		o.secrets = append(o.secrets, opts...)
+	}
+}
+
+// WithSQLAPIOptions configures the okra.sql factory
+func WithSQLAPIOptions(opts ...SQLAPIOption) DefaultHostAPIOption {
+	return func(o *defaultHostAPIOptions) {
+		o.sql = append(o.sql, opts...)
+	}
+}
+
+// WithQueueAPIOptions configures the okra.queue factory
+func WithQueueAPIOptions(opts ...QueueAPIOption) DefaultHostAPIOption {
+	return func(o *defaultHostAPIOptions) {
+		o.queue = append(o.queue, opts...)
+	}
+}
+
 // InitializeHostAPIs registers all available host API factories
-func InitializeHostAPIs(registry HostAPIRegistry) error {
-	// TODO: Register core host APIs when they are implemented
-	// This is a placeholder for now - actual implementations will come
-	// in the core-host-apis feature
+func InitializeHostAPIs(registry HostAPIRegistry, opts ...DefaultHostAPIOption) error {
+	var options defaultHostAPIOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	factories := []HostAPIFactory{
-		// NewStateAPIFactory(),
-		// NewLogAPIFactory(),
-		// NewEnvAPIFactory(),
-		// NewSecretsAPIFactory(),
-		// NewFetchAPIFactory(),
+		NewStateAPIFactory(options.state...),
+		NewLogAPIFactory(),
+		NewEnvAPIFactory(),
+		NewSecretsAPIFactory(options.secrets...),
+		NewHTTPAPIFactory(),
+		NewSQLAPIFactory(options.sql...),
+		NewCacheAPIFactory(),
+		NewQueueAPIFactory(options.queue...),
+		NewTimeAPIFactory(),
+		NewMetricsAPIFactory(),
 	}
 
 	for _, factory := range factories {
@@ -23,3 +69,15 @@ func InitializeHostAPIs(registry HostAPIRegistry) error {
 
 	return nil
 }
+
+// NewDefaultHostAPIRegistry returns a registry with every built-in host API
+// registered. It is the set of APIs this build of the runtime offers.
+func NewDefaultHostAPIRegistry(opts ...DefaultHostAPIOption) HostAPIRegistry {
+	registry := NewHostAPIRegistry()
+	if err := InitializeHostAPIs(registry, opts...); err != nil {
+		// The built-in factories have distinct names, so this only fails if
+		// one of them is listed twice
+		panic(err)
+	}
+	return registry
+}
@@ -481,12 +481,12 @@ func TestHostAPI_IteratorCleanupTimeout(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, hasMore)
 	
-	// Wait for timeout
+	// Wait for timeout; the set's background reaper closes the idle iterator
 	time.Sleep(200 * time.Millisecond)
 	
-	// Trigger cleanup
+	// Nothing is left for a manual cleanup
 	cleaned := hostAPISet.CleanupStaleIterators()
-	assert.Equal(t, 1, cleaned)
+	assert.Equal(t, 0, cleaned)
 	
 	// Iterator should be gone
 	_, _, err = hostAPISet.NextIterator(ctx, listResp.IteratorID)
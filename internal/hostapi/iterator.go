@@ -21,8 +21,11 @@ type Iterator interface {
 
 // iteratorInfo tracks iterator metadata
 type iteratorInfo struct {
-	iterator  Iterator
-	apiName   string
-	method    string
-	createdAt time.Time
+	iterator   Iterator
+	apiName    string
+	method     string
+	owner      ServiceInfo // Service that created the iterator; only it may advance or close it
+	createdAt  time.Time
+	lastAccess time.Time // Updated on every advance; idle iterators are reaped
+	inUse      int       // Advances in progress; the reaper never closes an iterator in use
 }
@@ -0,0 +1,356 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/go-openapi/spec"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// LogAPIName is the namespace of the log host API
+	LogAPIName = "okra.log"
+
+	// LogAPIVersion is the current version of the log host API
+	LogAPIVersion = "v1.0.0"
+)
+
+// Log API error codes
+const (
+	ErrorCodeInvalidLevel     = "INVALID_LEVEL"
+	ErrorCodeMessageTooLarge  = "MESSAGE_TOO_LARGE"
+	ErrorCodeContextTooLarge  = "CONTEXT_TOO_LARGE"
+	ErrorCodeContextTooDeep   = "CONTEXT_TOO_DEEP"
+	ErrorCodeInvalidTimestamp = "INVALID_TIMESTAMP"
+)
+
+// LogWriteRequest is the payload for log.write
+type LogWriteRequest struct {
+	Level     string                 `json:"level"` // debug, info, warn, error
+	Message   string                 `json:"message"`
+	Context   map[string]interface{} `json:"context,omitempty"`
+	Timestamp string                 `json:"timestamp,omitempty"` // ISO 8601, host adds if missing
+}
+
+// LogWriteResponse is the (empty) result of log.write
+type LogWriteResponse struct{}
+
+// LogConfig holds the code-level limits enforced by the log API
+type LogConfig struct {
+	MaxMessageSize  int      // Maximum message size in bytes
+	MaxContextKeys  int      // Maximum number of top-level context fields
+	MaxContextDepth int      // Maximum nesting depth of context values
+	AllowedLevels   []string // Levels guests may write
+	RateLimit       float64  // Entries per second per service (0 = unlimited)
+	RateBurst       int      // Entries that may be written in a burst (0 = ceil(RateLimit))
+}
+
+// defaultLogConfig returns the limits described in docs/host-apis/log.md
+func defaultLogConfig() LogConfig {
+	return LogConfig{
+		MaxMessageSize:  1024 * 1024,
+		MaxContextKeys:  100,
+		MaxContextDepth: 5,
+		AllowedLevels:   []string{"debug", "info", "warn", "error"},
+		RateLimit:       100,
+		RateBurst:       1000,
+	}
+}
+
+// logLevels maps guest level names onto slog levels
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// LogAPIOption configures the log API factory
+type LogAPIOption func(*logAPIFactory)
+
+// WithLogConfig overrides the default log limits
+func WithLogConfig(config LogConfig) LogAPIOption {
+	return func(f *logAPIFactory) {
+		f.config = config
+	}
+}
+
+// logAPIFactory creates okra.log instances. Rate limits are tracked per
+// service here so they hold across all workers of that service.
+type logAPIFactory struct {
+	config   LogConfig
+	limiters map[string]*tokenBucket
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+// NewLogAPIFactory creates the okra.log host API factory
+func NewLogAPIFactory(opts ...LogAPIOption) HostAPIFactory {
+	factory := &logAPIFactory{
+		config:   defaultLogConfig(),
+		limiters: make(map[string]*tokenBucket),
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	return factory
+}
+
+func (f *logAPIFactory) Name() string    { return LogAPIName }
+func (f *logAPIFactory) Version() string { return LogAPIVersion }
+
+func (f *logAPIFactory) Create(ctx context.Context, config HostAPIConfig) (HostAPI, error) {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	allowed := make(map[string]bool, len(f.config.AllowedLevels))
+	for _, level := range f.config.AllowedLevels {
+		allowed[level] = true
+	}
+
+	return &logAPI{
+		logger: logger.With(
+			"api", LogAPIName,
+			"service", config.ServiceName,
+			"service_version", config.ServiceVersion,
+		),
+		config:        f.config,
+		allowedLevels: allowed,
+		limiter:       f.limiterFor(config.ServiceName),
+		now:           f.now,
+	}, nil
+}
+
+// limiterFor returns the shared rate limiter for a service
+func (f *logAPIFactory) limiterFor(service string) *tokenBucket {
+	if f.config.RateLimit <= 0 {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	limiter, ok := f.limiters[service]
+	if !ok {
+		burst := f.config.RateBurst
+		if burst <= 0 {
+			burst = int(math.Max(1, math.Ceil(f.config.RateLimit)))
+		}
+		limiter = newTokenBucket(f.config.RateLimit, burst, f.now)
+		f.limiters[service] = limiter
+	}
+	return limiter
+}
+
+func (f *logAPIFactory) Methods() []MethodMetadata {
+	return []MethodMetadata{
+		{
+			Name:        "write",
+			Description: "Write a structured log message",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"level":     *spec.StringProperty().WithEnum("debug", "info", "warn", "error"),
+				"message":   *spec.StringProperty().WithDescription("Log message"),
+				"context":   *objectSchema(nil).WithDescription("Additional structured fields"),
+				"timestamp": *spec.DateTimeProperty(),
+			}, "level", "message"),
+			Returns: objectSchema(nil),
+			Errors: []ErrorMetadata{
+				{Code: ErrorCodeInvalidLevel, Description: "Invalid or disallowed log level"},
+				{Code: ErrorCodeMessageTooLarge, Description: "Message exceeds size limit"},
+				{Code: ErrorCodeContextTooLarge, Description: "Context has too many fields"},
+				{Code: ErrorCodeContextTooDeep, Description: "Context is nested too deeply"},
+				{Code: ErrorCodeInvalidTimestamp, Description: "Timestamp is not ISO 8601"},
+				{Code: ErrorCodeRateLimited, Description: "Too many log entries"},
+			},
+		},
+	}
+}
+
+// logAPI forwards guest log entries to the host logger
+type logAPI struct {
+	logger        *slog.Logger
+	config        LogConfig
+	allowedLevels map[string]bool
+	limiter       *tokenBucket
+	now           func() time.Time
+}
+
+// Compile-time interface compliance checks
+var (
+	_ HostAPI        = (*logAPI)(nil)
+	_ HostAPIFactory = (*logAPIFactory)(nil)
+)
+
+func (l *logAPI) Name() string    { return LogAPIName }
+func (l *logAPI) Version() string { return LogAPIVersion }
+
+func (l *logAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	switch method {
+	case "write":
+		return l.executeWrite(ctx, parameters)
+	default:
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+}
+
+func (l *logAPI) executeWrite(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req LogWriteRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := l.validateRequest(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.limiter != nil {
+		if ok, retryAfter := l.limiter.take(1); !ok {
+			return nil, &HostAPIError{
+				Code:    ErrorCodeRateLimited,
+				Message: "log rate limit exceeded",
+				Details: fmt.Sprintf("retry after %s", retryAfter.Round(time.Millisecond)),
+			}
+		}
+	}
+
+	level := logLevels[req.Level]
+	handler := l.logger.Handler()
+	if !handler.Enabled(ctx, level) {
+		return json.Marshal(LogWriteResponse{})
+	}
+
+	record := slog.NewRecord(timestamp, level, sanitizeLogMessage(req.Message), 0)
+	record.AddAttrs(l.traceAttrs(ctx)...)
+	if len(req.Context) > 0 {
+		record.AddAttrs(slog.Any("context", req.Context))
+	}
+
+	if err := handler.Handle(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to write log entry: %w", err)
+	}
+
+	return json.Marshal(LogWriteResponse{})
+}
+
+// validateRequest enforces the code-level log policies and resolves the entry timestamp
+func (l *logAPI) validateRequest(req *LogWriteRequest) (time.Time, error) {
+	if _, ok := logLevels[req.Level]; !ok || !l.allowedLevels[req.Level] {
+		return time.Time{}, &HostAPIError{
+			Code:    ErrorCodeInvalidLevel,
+			Message: fmt.Sprintf("invalid log level: %q", req.Level),
+		}
+	}
+
+	if len(req.Message) > l.config.MaxMessageSize {
+		return time.Time{}, &HostAPIError{
+			Code:    ErrorCodeMessageTooLarge,
+			Message: fmt.Sprintf("message size %d exceeds limit %d", len(req.Message), l.config.MaxMessageSize),
+		}
+	}
+
+	if len(req.Context) > l.config.MaxContextKeys {
+		return time.Time{}, &HostAPIError{
+			Code:    ErrorCodeContextTooLarge,
+			Message: fmt.Sprintf("context has %d keys, exceeds limit %d", len(req.Context), l.config.MaxContextKeys),
+		}
+	}
+
+	if depth := valueDepth(req.Context); depth > l.config.MaxContextDepth {
+		return time.Time{}, &HostAPIError{
+			Code:    ErrorCodeContextTooDeep,
+			Message: fmt.Sprintf("context depth %d exceeds limit %d", depth, l.config.MaxContextDepth),
+		}
+	}
+
+	if req.Timestamp == "" {
+		return l.now(), nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, req.Timestamp)
+	if err != nil {
+		return time.Time{}, &HostAPIError{
+			Code:    ErrorCodeInvalidTimestamp,
+			Message: "timestamp must be ISO 8601",
+			Details: err.Error(),
+		}
+	}
+	return timestamp, nil
+}
+
+// traceAttrs returns trace correlation attributes for the current call.
+// Guest-supplied metadata wins; otherwise we fall back to the active span.
+func (l *logAPI) traceAttrs(ctx context.Context) []slog.Attr {
+	var traceID, spanID string
+	if metadata, ok := RequestMetadataFromContext(ctx); ok {
+		traceID, spanID = metadata.TraceID, metadata.SpanID
+	}
+
+	if traceID == "" {
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+			traceID, spanID = spanCtx.TraceID().String(), spanCtx.SpanID().String()
+		}
+	}
+
+	var attrs []slog.Attr
+	if traceID != "" {
+		attrs = append(attrs, slog.String("trace_id", traceID))
+	}
+	if spanID != "" {
+		attrs = append(attrs, slog.String("span_id", spanID))
+	}
+	return attrs
+}
+
+// sanitizeLogMessage escapes line breaks and drops other control characters
+// so guests cannot forge additional log lines
+func sanitizeLogMessage(message string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return r
+		case r == '\n' || r == '\r':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		default:
+			return r
+		}
+	}, message)
+}
+
+// valueDepth returns the nesting depth of a decoded JSON value
+func valueDepth(value interface{}) int {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		depth := 0
+		for _, child := range v {
+			depth = max(depth, valueDepth(child))
+		}
+		return depth + 1
+	case []interface{}:
+		depth := 0
+		for _, child := range v {
+			depth = max(depth, valueDepth(child))
+		}
+		return depth + 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,250 @@
+package hostapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test log.write forwards entries to the configured logger with service enrichment
+// 2. Test trace IDs from request metadata are attached
+// 3. Test code-level validation (level, size, context keys/depth, timestamp)
+// 4. Test control characters are sanitized
+// 5. Test rate limiting is shared across sets of the same service, and an unset burst defaults to the rate
+// 6. Test factory metadata and registration
+
+// newLogTestSet creates a HostAPISet with the log API writing JSON to buf
+func newLogTestSet(t *testing.T, factory HostAPIFactory, service string, buf *bytes.Buffer) HostAPISet {
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(factory))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{LogAPIName}, HostAPIConfig{
+		ServiceName:    service,
+		ServiceVersion: "v1.2.3",
+		PolicyEngine:   &mockPolicyEngine{},
+		Tracer:         tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:          metricnoop.NewMeterProvider().Meter("test"),
+		Logger:         slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+// decodeLogLines parses guest entries from JSON log output. The set's own
+// error logging shares the buffer, so only lines tagged with a service are kept.
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if _, ok := entry["service"]; ok {
+			lines = append(lines, entry)
+		}
+	}
+	return lines
+}
+
+func TestLogAPI_Write(t *testing.T) {
+	var buf bytes.Buffer
+	set := newLogTestSet(t, NewLogAPIFactory(), "acme/users", &buf)
+
+	result, err := set.Execute(context.Background(), LogAPIName, "write", json.RawMessage(
+		`{"level":"warn","message":"user created","context":{"userId":"123","attempt":2},"timestamp":"2025-01-15T10:30:00Z"}`,
+	))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(result))
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 1)
+	entry := lines[0]
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "user created", entry["msg"])
+	assert.Equal(t, "2025-01-15T10:30:00Z", entry["time"])
+	assert.Equal(t, LogAPIName, entry["api"])
+	assert.Equal(t, "acme/users", entry["service"])
+	assert.Equal(t, "v1.2.3", entry["service_version"])
+	assert.Equal(t, map[string]interface{}{"userId": "123", "attempt": float64(2)}, entry["context"])
+}
+
+func TestLogAPI_TraceMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	set := newLogTestSet(t, NewLogAPIFactory(), "acme/users", &buf)
+
+	ctx := context.WithValue(context.Background(), requestMetadataKey{}, RequestMetadata{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	})
+	_, err := set.Execute(ctx, LogAPIName, "write", json.RawMessage(`{"level":"info","message":"traced"}`))
+	require.NoError(t, err)
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", lines[0]["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", lines[0]["span_id"])
+	assert.NotContains(t, lines[0], "context")
+}
+
+func TestLogAPI_Validation(t *testing.T) {
+	var buf bytes.Buffer
+	factory := NewLogAPIFactory(WithLogConfig(LogConfig{
+		MaxMessageSize:  16,
+		MaxContextKeys:  2,
+		MaxContextDepth: 2,
+		AllowedLevels:   []string{"info", "warn", "error"},
+	}))
+	set := newLogTestSet(t, factory, "acme/users", &buf)
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		code   string
+	}{
+		{"malformed parameters", "write", `{"level":`, ErrorCodeInvalidParameters},
+		{"unknown level", "write", `{"level":"trace","message":"x"}`, ErrorCodeInvalidLevel},
+		{"disallowed level", "write", `{"level":"debug","message":"x"}`, ErrorCodeInvalidLevel},
+		{"message too large", "write", `{"level":"info","message":"` + strings.Repeat("m", 17) + `"}`, ErrorCodeMessageTooLarge},
+		{"too many context keys", "write", `{"level":"info","message":"x","context":{"a":1,"b":2,"c":3}}`, ErrorCodeContextTooLarge},
+		{"context too deep", "write", `{"level":"info","message":"x","context":{"a":{"b":{"c":1}}}}`, ErrorCodeContextTooDeep},
+		{"invalid timestamp", "write", `{"level":"info","message":"x","timestamp":"yesterday"}`, ErrorCodeInvalidTimestamp},
+		{"unknown method", "flush", `{}`, ErrorCodeMethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := set.Execute(context.Background(), LogAPIName, tt.method, json.RawMessage(tt.params))
+			requireHostAPIError(t, err, tt.code)
+		})
+	}
+
+	assert.Empty(t, decodeLogLines(t, &buf), "rejected entries must not be logged")
+}
+
+func TestLogAPI_SanitizesMessage(t *testing.T) {
+	var buf bytes.Buffer
+	set := newLogTestSet(t, NewLogAPIFactory(), "acme/users", &buf)
+
+	_, err := set.Execute(context.Background(), LogAPIName, "write", json.RawMessage(
+		`{"level":"info","message":"line one\nlevel=error forged\u0007\tend"}`,
+	))
+	require.NoError(t, err)
+
+	lines := decodeLogLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "line one level=error forged\tend", lines[0]["msg"])
+}
+
+func TestLogAPI_RateLimit(t *testing.T) {
+	var buf bytes.Buffer
+	config := defaultLogConfig()
+	config.RateLimit = 1
+	config.RateBurst = 2
+
+	now := time.Unix(1700000000, 0)
+	factory := NewLogAPIFactory(WithLogConfig(config)).(*logAPIFactory)
+	factory.now = func() time.Time { return now }
+
+	worker1 := newLogTestSet(t, factory, "acme/users", &buf)
+	worker2 := newLogTestSet(t, factory, "acme/users", &buf)
+	other := newLogTestSet(t, factory, "acme/orders", &buf)
+
+	write := json.RawMessage(`{"level":"info","message":"hello"}`)
+	ctx := context.Background()
+
+	// Both workers draw from the same per-service bucket
+	_, err := worker1.Execute(ctx, LogAPIName, "write", write)
+	require.NoError(t, err)
+	_, err = worker2.Execute(ctx, LogAPIName, "write", write)
+	require.NoError(t, err)
+	_, err = worker1.Execute(ctx, LogAPIName, "write", write)
+	requireHostAPIError(t, err, ErrorCodeRateLimited)
+
+	// Other services are unaffected
+	_, err = other.Execute(ctx, LogAPIName, "write", write)
+	require.NoError(t, err)
+
+	// Tokens refill over time
+	now = now.Add(time.Second)
+	_, err = worker2.Execute(ctx, LogAPIName, "write", write)
+	require.NoError(t, err)
+
+	assert.Len(t, decodeLogLines(t, &buf), 4)
+}
+
+func TestLogAPI_RateLimitDefaultBurst(t *testing.T) {
+	var buf bytes.Buffer
+	config := defaultLogConfig()
+	config.RateLimit = 2.5
+	config.RateBurst = 0
+
+	now := time.Unix(1700000000, 0)
+	factory := NewLogAPIFactory(WithLogConfig(config)).(*logAPIFactory)
+	factory.now = func() time.Time { return now }
+	set := newLogTestSet(t, factory, "acme/users", &buf)
+
+	write := json.RawMessage(`{"level":"info","message":"hello"}`)
+	ctx := context.Background()
+
+	// An unset burst allows ceil(rate) entries rather than none
+	for i := 0; i < 3; i++ {
+		_, err := set.Execute(ctx, LogAPIName, "write", write)
+		require.NoError(t, err)
+	}
+	_, err := set.Execute(ctx, LogAPIName, "write", write)
+	requireHostAPIError(t, err, ErrorCodeRateLimited)
+}
+
+func TestLogAPI_RespectsLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(NewLogAPIFactory()))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{LogAPIName}, HostAPIConfig{
+		ServiceName:  "acme/users",
+		PolicyEngine: &mockPolicyEngine{},
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		Logger:       slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})),
+	})
+	require.NoError(t, err)
+	defer set.Close()
+
+	_, err = set.Execute(context.Background(), LogAPIName, "write", json.RawMessage(`{"level":"debug","message":"noise"}`))
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
+
+func TestLogAPIFactory(t *testing.T) {
+	factory := NewLogAPIFactory()
+	assert.Equal(t, LogAPIName, factory.Name())
+	assert.Equal(t, LogAPIVersion, factory.Version())
+
+	methods := factory.Methods()
+	require.Len(t, methods, 1)
+	assert.Equal(t, "write", methods[0].Name)
+	assert.Equal(t, []string{"level", "message"}, methods[0].Parameters.Required)
+
+	// A nil logger falls back to the default logger
+	api, err := factory.Create(context.Background(), HostAPIConfig{ServiceName: "acme/users"})
+	require.NoError(t, err)
+	assert.Equal(t, LogAPIName, api.Name())
+
+	registry := NewHostAPIRegistry()
+	require.NoError(t, InitializeHostAPIs(registry))
+	_, ok := registry.Get(LogAPIName)
+	assert.True(t, ok)
+}
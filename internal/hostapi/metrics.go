@@ -0,0 +1,509 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-openapi/spec"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+)
+
+const (
+	// MetricsAPIName is the namespace of the metrics host API
+	MetricsAPIName = "okra.metrics"
+
+	// MetricsAPIVersion is the current version of the metrics host API
+	MetricsAPIVersion = "v1.0.0"
+)
+
+// Metrics API error codes
+const (
+	// ErrorCodeInvalidMetricName indicates the metric name is malformed or too long
+	ErrorCodeInvalidMetricName = "INVALID_METRIC_NAME"
+
+	// ErrorCodeInvalidTags indicates a tag key or value is malformed, or there are too many tags
+	ErrorCodeInvalidTags = "INVALID_TAGS"
+
+	// ErrorCodeInvalidValue indicates a missing, non-finite or negative counter value
+	ErrorCodeInvalidValue = "INVALID_VALUE"
+
+	// ErrorCodeMetricTypeMismatch indicates the name is already used by a different metric type
+	ErrorCodeMetricTypeMismatch = "METRIC_TYPE_MISMATCH"
+
+	// ErrorCodeCardinalityExceeded indicates the metric or tag combination limit was reached
+	ErrorCodeCardinalityExceeded = "CARDINALITY_EXCEEDED"
+)
+
+// Metric types
+const (
+	metricTypeCounter   = "counter"
+	metricTypeGauge     = "gauge"
+	metricTypeHistogram = "histogram"
+)
+
+var (
+	// metricNamePattern is Prometheus compatible, plus dots for namespacing
+	metricNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+	// metricTagKeyPattern matches Prometheus label names
+	metricTagKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// maxInstrumentNameLength is the OpenTelemetry limit on instrument names
+const maxInstrumentNameLength = 255
+
+// MetricsRecordRequest is the payload for metrics.counter, metrics.gauge and metrics.histogram
+type MetricsRecordRequest struct {
+	Name    string                 `json:"name"`
+	Value   *float64               `json:"value"`
+	Tags    map[string]interface{} `json:"tags,omitempty"`    // string, number or boolean values
+	Buckets []float64              `json:"buckets,omitempty"` // Histogram only; applied when the histogram is first used
+}
+
+// MetricsRecordResponse is the (empty) result of recording a metric
+type MetricsRecordResponse struct{}
+
+// MetricsConfig holds the code-level limits enforced by the metrics API.
+// The cardinality limits apply per service across all of its workers.
+type MetricsConfig struct {
+	MaxNameLength        int
+	MaxTags              int
+	MaxTagKeyLength      int
+	MaxTagValueLength    int
+	MaxMetricsPerService int // Distinct metric names
+	MaxSeriesPerMetric   int // Distinct tag combinations per metric
+	MaxHistogramBuckets  int
+	MaxAbsoluteValue     float64
+}
+
+// defaultMetricsConfig returns the limits described in docs/host-apis/metrics.md
+func defaultMetricsConfig() MetricsConfig {
+	return MetricsConfig{
+		MaxNameLength:        256,
+		MaxTags:              20,
+		MaxTagKeyLength:      64,
+		MaxTagValueLength:    256,
+		MaxMetricsPerService: 1000,
+		MaxSeriesPerMetric:   10000,
+		MaxHistogramBuckets:  50,
+		MaxAbsoluteValue:     1e15,
+	}
+}
+
+// MetricsAPIOption configures the metrics API factory
+type MetricsAPIOption func(*metricsAPIFactory)
+
+// WithMetricsConfig overrides the default metrics limits
+func WithMetricsConfig(config MetricsConfig) MetricsAPIOption {
+	return func(f *metricsAPIFactory) {
+		f.config = config
+	}
+}
+
+// metricsAPIFactory tracks metric names and tag combinations per service so
+// cardinality limits hold across every worker of the service
+type metricsAPIFactory struct {
+	config MetricsConfig
+
+	mu       sync.Mutex
+	services map[string]map[string]*metricSeries
+}
+
+// metricSeries records a metric's type and the tag combinations seen so far
+type metricSeries struct {
+	metricType string
+	tagSets    map[string]struct{}
+}
+
+// NewMetricsAPIFactory creates the okra.metrics host API factory.
+// Metrics are recorded on HostAPIConfig.Meter under the service's namespace.
+func NewMetricsAPIFactory(opts ...MetricsAPIOption) HostAPIFactory {
+	factory := &metricsAPIFactory{
+		config:   defaultMetricsConfig(),
+		services: make(map[string]map[string]*metricSeries),
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	return factory
+}
+
+func (f *metricsAPIFactory) Name() string    { return MetricsAPIName }
+func (f *metricsAPIFactory) Version() string { return MetricsAPIVersion }
+
+func (f *metricsAPIFactory) Create(ctx context.Context, hostConfig HostAPIConfig) (HostAPI, error) {
+	if hostConfig.ServiceName == "" {
+		return nil, fmt.Errorf("service name is required for %s", MetricsAPIName)
+	}
+
+	meter := hostConfig.Meter
+	if meter == nil {
+		meter = metricnoop.NewMeterProvider().Meter(MetricsAPIName)
+	}
+
+	return &metricsAPI{
+		factory:     f,
+		config:      f.config,
+		service:     hostConfig.ServiceName,
+		prefix:      metricPrefix(hostConfig.ServiceName),
+		meter:       meter,
+		instruments: make(map[string]interface{}),
+	}, nil
+}
+
+func (f *metricsAPIFactory) Methods() []MethodMetadata {
+	params := func(histogram bool) *spec.Schema {
+		properties := map[string]spec.Schema{
+			"name":  *spec.StringProperty().WithMaxLength(int64(f.config.MaxNameLength)).WithPattern(metricNamePattern.String()),
+			"value": *spec.Float64Property(),
+			"tags":  *new(spec.Schema).Typed("object", "").WithDescription("Tag values may be strings, numbers or booleans"),
+		}
+		if histogram {
+			properties["buckets"] = *spec.ArrayProperty(spec.Float64Property()).WithDescription("Strictly increasing bucket boundaries")
+		}
+		return objectSchema(properties, "name", "value")
+	}
+	returns := objectSchema(map[string]spec.Schema{})
+	errors := []ErrorMetadata{
+		{Code: ErrorCodeInvalidMetricName, Description: "Invalid metric name"},
+		{Code: ErrorCodeInvalidTags, Description: "Invalid tag key or value, or too many tags"},
+		{Code: ErrorCodeInvalidValue, Description: "Value is missing, not finite or out of range"},
+		{Code: ErrorCodeMetricTypeMismatch, Description: "Name already used by another metric type"},
+		{Code: ErrorCodeCardinalityExceeded, Description: "Too many metrics or tag combinations"},
+	}
+
+	return []MethodMetadata{
+		{
+			Name:        metricTypeCounter,
+			Description: "Add a non-negative value to a counter",
+			Parameters:  params(false),
+			Returns:     returns,
+			Errors:      errors,
+		},
+		{
+			Name:        metricTypeGauge,
+			Description: "Set the current value of a gauge",
+			Parameters:  params(false),
+			Returns:     returns,
+			Errors:      errors,
+		},
+		{
+			Name:        metricTypeHistogram,
+			Description: "Record a value in a histogram",
+			Parameters:  params(true),
+			Returns:     returns,
+			Errors:      errors,
+		},
+	}
+}
+
+// track registers a metric type and tag combination for service, enforcing the cardinality limits
+func (f *metricsAPIFactory) track(service, name, metricType, tagSet string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	metrics, ok := f.services[service]
+	if !ok {
+		metrics = make(map[string]*metricSeries)
+		f.services[service] = metrics
+	}
+
+	series, ok := metrics[name]
+	if !ok {
+		if len(metrics) >= f.config.MaxMetricsPerService {
+			return &HostAPIError{
+				Code:    ErrorCodeCardinalityExceeded,
+				Message: fmt.Sprintf("service already has %d metrics", f.config.MaxMetricsPerService),
+			}
+		}
+		series = &metricSeries{metricType: metricType, tagSets: make(map[string]struct{})}
+		metrics[name] = series
+	}
+
+	if series.metricType != metricType {
+		return &HostAPIError{
+			Code:    ErrorCodeMetricTypeMismatch,
+			Message: fmt.Sprintf("metric %s is a %s, not a %s", name, series.metricType, metricType),
+		}
+	}
+
+	if _, ok := series.tagSets[tagSet]; !ok {
+		if len(series.tagSets) >= f.config.MaxSeriesPerMetric {
+			return &HostAPIError{
+				Code:    ErrorCodeCardinalityExceeded,
+				Message: fmt.Sprintf("metric %s already has %d tag combinations", name, f.config.MaxSeriesPerMetric),
+			}
+		}
+		series.tagSets[tagSet] = struct{}{}
+	}
+
+	return nil
+}
+
+// metricsAPI records a service's metrics on the host meter
+type metricsAPI struct {
+	factory *metricsAPIFactory
+	config  MetricsConfig
+	service string
+	prefix  string
+	meter   metric.Meter
+
+	mu          sync.Mutex
+	instruments map[string]interface{} // Full name -> Float64Counter, Float64Gauge or Float64Histogram
+}
+
+// Compile-time interface compliance checks
+var (
+	_ HostAPI        = (*metricsAPI)(nil)
+	_ HostAPIFactory = (*metricsAPIFactory)(nil)
+)
+
+func (m *metricsAPI) Name() string    { return MetricsAPIName }
+func (m *metricsAPI) Version() string { return MetricsAPIVersion }
+
+func (m *metricsAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	switch method {
+	case metricTypeCounter, metricTypeGauge, metricTypeHistogram:
+	default:
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+
+	var req MetricsRecordRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+
+	name, err := m.metricName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.validateValue(method, req.Value); err != nil {
+		return nil, err
+	}
+	attrs, tagSet, err := m.attributes(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Buckets) > 0 {
+		if err := m.validateBuckets(method, req.Buckets); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.factory.track(m.service, name, method, tagSet); err != nil {
+		return nil, err
+	}
+
+	instrument, err := m.instrument(name, method, req.Buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	opt := metric.WithAttributes(attrs...)
+	switch instrument := instrument.(type) {
+	case metric.Float64Counter:
+		instrument.Add(ctx, *req.Value, opt)
+	case metric.Float64Gauge:
+		instrument.Record(ctx, *req.Value, opt)
+	case metric.Float64Histogram:
+		instrument.Record(ctx, *req.Value, opt)
+	}
+
+	return json.Marshal(MetricsRecordResponse{})
+}
+
+// metricName validates a guest metric name and qualifies it with the service prefix
+func (m *metricsAPI) metricName(name string) (string, error) {
+	if name == "" || len(name) > m.config.MaxNameLength {
+		return "", &HostAPIError{
+			Code:    ErrorCodeInvalidMetricName,
+			Message: fmt.Sprintf("metric name must be 1-%d characters", m.config.MaxNameLength),
+		}
+	}
+	if !metricNamePattern.MatchString(name) {
+		return "", &HostAPIError{
+			Code:    ErrorCodeInvalidMetricName,
+			Message: "metric name may only contain letters, digits, '_' and '.', and must not start with a digit",
+		}
+	}
+
+	qualified := m.prefix + name
+	if len(qualified) > maxInstrumentNameLength {
+		return "", &HostAPIError{
+			Code:    ErrorCodeInvalidMetricName,
+			Message: fmt.Sprintf("metric name %s exceeds %d characters", qualified, maxInstrumentNameLength),
+		}
+	}
+	return qualified, nil
+}
+
+// validateValue rejects missing, non-finite and out-of-range values
+func (m *metricsAPI) validateValue(metricType string, value *float64) error {
+	if value == nil {
+		return &HostAPIError{Code: ErrorCodeInvalidValue, Message: "value is required"}
+	}
+	if math.IsNaN(*value) || math.IsInf(*value, 0) || math.Abs(*value) > m.config.MaxAbsoluteValue {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidValue,
+			Message: fmt.Sprintf("value must be finite and within ±%g", m.config.MaxAbsoluteValue),
+		}
+	}
+	if metricType == metricTypeCounter && *value < 0 {
+		return &HostAPIError{Code: ErrorCodeInvalidValue, Message: "counter values must not be negative"}
+	}
+	return nil
+}
+
+// attributes converts tags to attributes and returns a canonical key for the combination
+func (m *metricsAPI) attributes(tags map[string]interface{}) ([]attribute.KeyValue, string, error) {
+	if len(tags) > m.config.MaxTags {
+		return nil, "", &HostAPIError{
+			Code:    ErrorCodeInvalidTags,
+			Message: fmt.Sprintf("at most %d tags are allowed", m.config.MaxTags),
+		}
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]attribute.KeyValue, 0, len(keys))
+	var tagSet strings.Builder
+	for _, key := range keys {
+		if len(key) > m.config.MaxTagKeyLength || !metricTagKeyPattern.MatchString(key) || strings.HasPrefix(key, "__") {
+			return nil, "", &HostAPIError{
+				Code:    ErrorCodeInvalidTags,
+				Message: fmt.Sprintf("invalid tag key: %q", key),
+			}
+		}
+
+		var value string
+		switch v := tags[key].(type) {
+		case string:
+			value = v
+			attrs = append(attrs, attribute.String(key, v))
+		case float64:
+			value = strconv.FormatFloat(v, 'g', -1, 64)
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				attrs = append(attrs, attribute.Int64(key, int64(v)))
+			} else {
+				attrs = append(attrs, attribute.Float64(key, v))
+			}
+		case bool:
+			value = strconv.FormatBool(v)
+			attrs = append(attrs, attribute.Bool(key, v))
+		default:
+			return nil, "", &HostAPIError{
+				Code:    ErrorCodeInvalidTags,
+				Message: fmt.Sprintf("tag %s must be a string, number or boolean", key),
+			}
+		}
+		if len(value) > m.config.MaxTagValueLength {
+			return nil, "", &HostAPIError{
+				Code:    ErrorCodeInvalidTags,
+				Message: fmt.Sprintf("tag %s exceeds %d characters", key, m.config.MaxTagValueLength),
+			}
+		}
+
+		tagSet.WriteString(strconv.Quote(key))
+		tagSet.WriteByte('=')
+		tagSet.WriteString(strconv.Quote(value))
+		tagSet.WriteByte(',')
+	}
+
+	return attrs, tagSet.String(), nil
+}
+
+// validateBuckets ensures histogram bucket boundaries are usable
+func (m *metricsAPI) validateBuckets(metricType string, buckets []float64) error {
+	if metricType != metricTypeHistogram {
+		return &HostAPIError{Code: ErrorCodeInvalidParameters, Message: "buckets are only supported for histograms"}
+	}
+	if len(buckets) > m.config.MaxHistogramBuckets {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidParameters,
+			Message: fmt.Sprintf("at most %d buckets are allowed", m.config.MaxHistogramBuckets),
+		}
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return &HostAPIError{Code: ErrorCodeInvalidParameters, Message: "buckets must be strictly increasing"}
+		}
+	}
+	return nil
+}
+
+// instrument returns the instrument for name, creating it on first use
+func (m *metricsAPI) instrument(name, metricType string, buckets []float64) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if instrument, ok := m.instruments[name]; ok {
+		return instrument, nil
+	}
+
+	var instrument interface{}
+	var err error
+	switch metricType {
+	case metricTypeCounter:
+		instrument, err = m.meter.Float64Counter(name)
+	case metricTypeGauge:
+		instrument, err = m.meter.Float64Gauge(name)
+	case metricTypeHistogram:
+		var opts []metric.Float64HistogramOption
+		if len(buckets) > 0 {
+			opts = append(opts, metric.WithExplicitBucketBoundaries(slices.Clone(buckets)...))
+		}
+		instrument, err = m.meter.Float64Histogram(name, opts...)
+	}
+	if err != nil {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidMetricName,
+			Message: fmt.Sprintf("failed to create %s %s", metricType, name),
+			Details: err.Error(),
+		}
+	}
+
+	m.instruments[name] = instrument
+	return instrument, nil
+}
+
+// metricPrefix derives the metric namespace from a service name,
+// e.g. "acme-corp/user-service" becomes "acme_corp.user_service."
+func metricPrefix(service string) string {
+	var prefix strings.Builder
+	for _, r := range service {
+		switch {
+		case r == '/':
+			prefix.WriteByte('.')
+		case r == '_' || r == '.' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			prefix.WriteRune(r)
+		default:
+			prefix.WriteByte('_')
+		}
+	}
+	prefix.WriteByte('.')
+
+	// Instrument names must start with a letter
+	if c := prefix.String()[0]; !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') {
+		return "svc_" + prefix.String()
+	}
+	return prefix.String()
+}
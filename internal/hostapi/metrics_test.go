@@ -0,0 +1,187 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test counters, gauges and histograms are recorded on the host meter
+// 2. Test metric names are prefixed with the service namespace
+// 3. Test tags become attributes and are validated
+// 4. Test cardinality limits are shared by all workers of a service
+// 5. Test type mismatches, invalid values and custom buckets
+
+// newMetricsTestSet creates a HostAPISet with the metrics API recording on meter
+func newMetricsTestSet(t *testing.T, factory HostAPIFactory, service string, meter metric.Meter) HostAPISet {
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(factory))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{MetricsAPIName}, HostAPIConfig{
+		ServiceName:  service,
+		PolicyEngine: &mockPolicyEngine{},
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        meter,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+// newTestMeter returns a meter whose recordings can be collected from the reader
+func newTestMeter(t *testing.T) (metric.Meter, *sdkmetric.ManualReader) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return provider.Meter("test"), reader
+}
+
+// collectMetrics gathers the recorded metrics by name
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func metricsCall(t *testing.T, set HostAPISet, method, params string) {
+	t.Helper()
+	result, err := set.Execute(context.Background(), MetricsAPIName, method, json.RawMessage(params))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(result))
+}
+
+func TestMetricsAPI_Record(t *testing.T) {
+	meter, reader := newTestMeter(t)
+	set := newMetricsTestSet(t, NewMetricsAPIFactory(), "acme-corp/order-service", meter)
+
+	metricsCall(t, set, "counter", `{"name":"orders.created","value":2,"tags":{"region":"eu","priority":1,"express":true}}`)
+	metricsCall(t, set, "counter", `{"name":"orders.created","value":3,"tags":{"region":"eu","priority":1,"express":true}}`)
+	metricsCall(t, set, "gauge", `{"name":"queue_depth","value":7}`)
+	metricsCall(t, set, "gauge", `{"name":"queue_depth","value":4}`)
+	metricsCall(t, set, "histogram", `{"name":"order_value","value":12.5,"buckets":[10,100]}`)
+	metricsCall(t, set, "histogram", `{"name":"order_value","value":250}`)
+
+	metrics := collectMetrics(t, reader)
+
+	counter, ok := metrics["acme_corp.order_service.orders.created"].(metricdata.Sum[float64])
+	require.True(t, ok, "counter is recorded under the service prefix")
+	require.Len(t, counter.DataPoints, 1)
+	assert.Equal(t, 5.0, counter.DataPoints[0].Value)
+	assert.True(t, counter.IsMonotonic)
+	assert.Equal(t, attribute.NewSet(
+		attribute.String("region", "eu"),
+		attribute.Int64("priority", 1),
+		attribute.Bool("express", true),
+	), counter.DataPoints[0].Attributes)
+
+	gauge, ok := metrics["acme_corp.order_service.queue_depth"].(metricdata.Gauge[float64])
+	require.True(t, ok)
+	require.Len(t, gauge.DataPoints, 1)
+	assert.Equal(t, 4.0, gauge.DataPoints[0].Value)
+
+	histogram, ok := metrics["acme_corp.order_service.order_value"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, histogram.DataPoints, 1)
+	assert.Equal(t, []float64{10, 100}, histogram.DataPoints[0].Bounds)
+	assert.Equal(t, []uint64{0, 1, 1}, histogram.DataPoints[0].BucketCounts)
+	assert.Equal(t, uint64(2), histogram.DataPoints[0].Count)
+}
+
+func TestMetricsAPI_Validation(t *testing.T) {
+	meter, _ := newTestMeter(t)
+	set := newMetricsTestSet(t, NewMetricsAPIFactory(), "acme/orders", meter)
+	metricsCall(t, set, "counter", `{"name":"requests","value":1}`)
+
+	tooManyTags := make([]string, 21)
+	for i := range tooManyTags {
+		tooManyTags[i] = fmt.Sprintf(`"t%d":"v"`, i)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		code   string
+	}{
+		{"empty name", "counter", `{"name":"","value":1}`, ErrorCodeInvalidMetricName},
+		{"invalid name", "counter", `{"name":"requests-total","value":1}`, ErrorCodeInvalidMetricName},
+		{"leading digit", "counter", `{"name":"1requests","value":1}`, ErrorCodeInvalidMetricName},
+		{"long name", "counter", `{"name":"` + strings.Repeat("n", 257) + `","value":1}`, ErrorCodeInvalidMetricName},
+		{"missing value", "gauge", `{"name":"g"}`, ErrorCodeInvalidValue},
+		{"negative counter", "counter", `{"name":"c","value":-1}`, ErrorCodeInvalidValue},
+		{"extreme value", "gauge", `{"name":"g","value":1e300}`, ErrorCodeInvalidValue},
+		{"invalid tag key", "counter", `{"name":"c","value":1,"tags":{"bad-key":"v"}}`, ErrorCodeInvalidTags},
+		{"reserved tag key", "counter", `{"name":"c","value":1,"tags":{"__name__":"v"}}`, ErrorCodeInvalidTags},
+		{"object tag value", "counter", `{"name":"c","value":1,"tags":{"k":{"nested":true}}}`, ErrorCodeInvalidTags},
+		{"long tag value", "counter", `{"name":"c","value":1,"tags":{"k":"` + strings.Repeat("v", 257) + `"}}`, ErrorCodeInvalidTags},
+		{"too many tags", "counter", `{"name":"c","value":1,"tags":{` + strings.Join(tooManyTags, ",") + `}}`, ErrorCodeInvalidTags},
+		{"type mismatch", "gauge", `{"name":"requests","value":1}`, ErrorCodeMetricTypeMismatch},
+		{"unsorted buckets", "histogram", `{"name":"h","value":1,"buckets":[5,1]}`, ErrorCodeInvalidParameters},
+		{"buckets on counter", "counter", `{"name":"c","value":1,"buckets":[1]}`, ErrorCodeInvalidParameters},
+		{"unknown method", "summary", `{"name":"s","value":1}`, ErrorCodeMethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := set.Execute(context.Background(), MetricsAPIName, tt.method, json.RawMessage(tt.params))
+			requireHostAPIError(t, err, tt.code)
+		})
+	}
+}
+
+func TestMetricsAPI_CardinalityLimits(t *testing.T) {
+	config := defaultMetricsConfig()
+	config.MaxMetricsPerService = 2
+	config.MaxSeriesPerMetric = 2
+	factory := NewMetricsAPIFactory(WithMetricsConfig(config))
+	meter, _ := newTestMeter(t)
+
+	// Two workers of the same service share the limits
+	worker1 := newMetricsTestSet(t, factory, "acme/orders", meter)
+	worker2 := newMetricsTestSet(t, factory, "acme/orders", meter)
+
+	metricsCall(t, worker1, "counter", `{"name":"requests","value":1,"tags":{"status":"200"}}`)
+	metricsCall(t, worker2, "counter", `{"name":"requests","value":1,"tags":{"status":"500"}}`)
+	// Repeating a known combination is always allowed
+	metricsCall(t, worker2, "counter", `{"name":"requests","value":1,"tags":{"status":"200"}}`)
+
+	_, err := worker1.Execute(context.Background(), MetricsAPIName, "counter", json.RawMessage(`{"name":"requests","value":1,"tags":{"status":"404"}}`))
+	requireHostAPIError(t, err, ErrorCodeCardinalityExceeded)
+
+	metricsCall(t, worker1, "gauge", `{"name":"inflight","value":1}`)
+	_, err = worker2.Execute(context.Background(), MetricsAPIName, "gauge", json.RawMessage(`{"name":"connections","value":1}`))
+	requireHostAPIError(t, err, ErrorCodeCardinalityExceeded)
+
+	// Another service has its own budget
+	other := newMetricsTestSet(t, factory, "acme/billing", meter)
+	metricsCall(t, other, "gauge", `{"name":"connections","value":1}`)
+}
+
+func TestMetricPrefix(t *testing.T) {
+	tests := map[string]string{
+		"acme/orders":            "acme.orders.",
+		"acme-corp/user-service": "acme_corp.user_service.",
+		"1password/vault":        "svc_1password.vault.",
+	}
+	for service, prefix := range tests {
+		assert.Equal(t, prefix, metricPrefix(service), service)
+	}
+}
@@ -0,0 +1,665 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: internal/hostapi/pb/abi.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// HostAPIRequest is the argument to okra.run_host_api
+type HostAPIRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Api    string                 `protobuf:"bytes,1,opt,name=api,proto3" json:"api,omitempty"`
+	Method string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	// JSON-encoded method parameters
+	Parameters []byte           `protobuf:"bytes,3,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	Metadata   *RequestMetadata `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// Version range the guest was built against (empty = any)
+	Version       string `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostAPIRequest) Reset() {
+	*x = HostAPIRequest{}
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostAPIRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostAPIRequest) ProtoMessage() {}
+
+func (x *HostAPIRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostAPIRequest.ProtoReflect.Descriptor instead.
+func (*HostAPIRequest) Descriptor() ([]byte, []int) {
+	return file_internal_hostapi_pb_abi_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *HostAPIRequest) GetApi() string {
+	if x != nil {
+		return x.Api
+	}
+	return ""
+}
+
+func (x *HostAPIRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *HostAPIRequest) GetParameters() []byte {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *HostAPIRequest) GetMetadata() *RequestMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *HostAPIRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type RequestMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraceId       string                 `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	SpanId        string                 `protobuf:"bytes,2,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	Baggage       map[string]string      `protobuf:"bytes,3,rep,name=baggage,proto3" json:"baggage,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ServiceInfo   *ServiceInfo           `protobuf:"bytes,4,opt,name=service_info,json=serviceInfo,proto3" json:"service_info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestMetadata) Reset() {
+	*x = RequestMetadata{}
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestMetadata) ProtoMessage() {}
+
+func (x *RequestMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestMetadata.ProtoReflect.Descriptor instead.
+func (*RequestMetadata) Descriptor() ([]byte, []int) {
+	return file_internal_hostapi_pb_abi_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RequestMetadata) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *RequestMetadata) GetSpanId() string {
+	if x != nil {
+		return x.SpanId
+	}
+	return ""
+}
+
+func (x *RequestMetadata) GetBaggage() map[string]string {
+	if x != nil {
+		return x.Baggage
+	}
+	return nil
+}
+
+func (x *RequestMetadata) GetServiceInfo() *ServiceInfo {
+	if x != nil {
+		return x.ServiceInfo
+	}
+	return nil
+}
+
+type ServiceInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceInfo) Reset() {
+	*x = ServiceInfo{}
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceInfo) ProtoMessage() {}
+
+func (x *ServiceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceInfo.ProtoReflect.Descriptor instead.
+func (*ServiceInfo) Descriptor() ([]byte, []int) {
+	return file_internal_hostapi_pb_abi_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ServiceInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ServiceInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type HostAPIError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Details       string                 `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostAPIError) Reset() {
+	*x = HostAPIError{}
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostAPIError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostAPIError) ProtoMessage() {}
+
+func (x *HostAPIError) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostAPIError.ProtoReflect.Descriptor instead.
+func (*HostAPIError) Descriptor() ([]byte, []int) {
+	return file_internal_hostapi_pb_abi_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HostAPIError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *HostAPIError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *HostAPIError) GetDetails() string {
+	if x != nil {
+		return x.Details
+	}
+	return ""
+}
+
+// HostAPIResponse is returned by okra.run_host_api. The other responses share
+// its first two fields, so a guest can always decode success and error.
+type HostAPIResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *HostAPIError          `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// JSON-encoded result
+	Data          []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostAPIResponse) Reset() {
+	*x = HostAPIResponse{}
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostAPIResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostAPIResponse) ProtoMessage() {}
+
+func (x *HostAPIResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostAPIResponse.ProtoReflect.Descriptor instead.
+func (*HostAPIResponse) Descriptor() ([]byte, []int) {
+	return file_internal_hostapi_pb_abi_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HostAPIResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *HostAPIResponse) GetError() *HostAPIError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *HostAPIResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// NextRequest is the argument to okra.next
+type NextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IteratorId    string                 `protobuf:"bytes,1,opt,name=iterator_id,json=iteratorId,proto3" json:"iterator_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NextRequest) Reset() {
+	*x = NextRequest{}
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NextRequest) ProtoMessage() {}
+
+func (x *NextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NextRequest.ProtoReflect.Descriptor instead.
+func (*NextRequest) Descriptor() ([]byte, []int) {
+	return file_internal_hostapi_pb_abi_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NextRequest) GetIteratorId() string {
+	if x != nil {
+		return x.IteratorId
+	}
+	return ""
+}
+
+type NextResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   *HostAPIError          `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// JSON-encoded chunk
+	Data          []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	HasMore       bool   `protobuf:"varint,4,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NextResponse) Reset() {
+	*x = NextResponse{}
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NextResponse) ProtoMessage() {}
+
+func (x *NextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NextResponse.ProtoReflect.Descriptor instead.
+func (*NextResponse) Descriptor() ([]byte, []int) {
+	return file_internal_hostapi_pb_abi_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NextResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *NextResponse) GetError() *HostAPIError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *NextResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *NextResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// CloseIteratorRequest is the argument to okra.close_iterator
+type CloseIteratorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IteratorId    string                 `protobuf:"bytes,1,opt,name=iterator_id,json=iteratorId,proto3" json:"iterator_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseIteratorRequest) Reset() {
+	*x = CloseIteratorRequest{}
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseIteratorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseIteratorRequest) ProtoMessage() {}
+
+func (x *CloseIteratorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseIteratorRequest.ProtoReflect.Descriptor instead.
+func (*CloseIteratorRequest) Descriptor() ([]byte, []int) {
+	return file_internal_hostapi_pb_abi_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CloseIteratorRequest) GetIteratorId() string {
+	if x != nil {
+		return x.IteratorId
+	}
+	return ""
+}
+
+type CloseIteratorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         *HostAPIError          `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseIteratorResponse) Reset() {
+	*x = CloseIteratorResponse{}
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseIteratorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseIteratorResponse) ProtoMessage() {}
+
+func (x *CloseIteratorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_hostapi_pb_abi_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseIteratorResponse.ProtoReflect.Descriptor instead.
+func (*CloseIteratorResponse) Descriptor() ([]byte, []int) {
+	return file_internal_hostapi_pb_abi_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CloseIteratorResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CloseIteratorResponse) GetError() *HostAPIError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+var File_internal_hostapi_pb_abi_proto protoreflect.FileDescriptor
+
+const file_internal_hostapi_pb_abi_proto_rawDesc = "" +
+	"\n" +
+	"\x1dinternal/hostapi/pb/abi.proto\x12\ahostapi\"\xaa\x01\n" +
+	"\x0eHostAPIRequest\x12\x10\n" +
+	"\x03api\x18\x01 \x01(\tR\x03api\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x1e\n" +
+	"\n" +
+	"parameters\x18\x03 \x01(\fR\n" +
+	"parameters\x124\n" +
+	"\bmetadata\x18\x04 \x01(\v2\x18.hostapi.RequestMetadataR\bmetadata\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\tR\aversion\"\xfb\x01\n" +
+	"\x0fRequestMetadata\x12\x19\n" +
+	"\btrace_id\x18\x01 \x01(\tR\atraceId\x12\x17\n" +
+	"\aspan_id\x18\x02 \x01(\tR\x06spanId\x12?\n" +
+	"\abaggage\x18\x03 \x03(\v2%.hostapi.RequestMetadata.BaggageEntryR\abaggage\x127\n" +
+	"\fservice_info\x18\x04 \x01(\v2\x14.hostapi.ServiceInfoR\vserviceInfo\x1a:\n" +
+	"\fBaggageEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\";\n" +
+	"\vServiceInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\"V\n" +
+	"\fHostAPIError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\adetails\x18\x03 \x01(\tR\adetails\"l\n" +
+	"\x0fHostAPIResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12+\n" +
+	"\x05error\x18\x02 \x01(\v2\x15.hostapi.HostAPIErrorR\x05error\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\".\n" +
+	"\vNextRequest\x12\x1f\n" +
+	"\viterator_id\x18\x01 \x01(\tR\n" +
+	"iteratorId\"\x84\x01\n" +
+	"\fNextResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12+\n" +
+	"\x05error\x18\x02 \x01(\v2\x15.hostapi.HostAPIErrorR\x05error\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12\x19\n" +
+	"\bhas_more\x18\x04 \x01(\bR\ahasMore\"7\n" +
+	"\x14CloseIteratorRequest\x12\x1f\n" +
+	"\viterator_id\x18\x01 \x01(\tR\n" +
+	"iteratorId\"^\n" +
+	"\x15CloseIteratorResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12+\n" +
+	"\x05error\x18\x02 \x01(\v2\x15.hostapi.HostAPIErrorR\x05errorB3Z1github.com/okra-platform/okra/internal/hostapi/pbb\x06proto3"
+
+var (
+	file_internal_hostapi_pb_abi_proto_rawDescOnce sync.Once
+	file_internal_hostapi_pb_abi_proto_rawDescData []byte
+)
+
+func file_internal_hostapi_pb_abi_proto_rawDescGZIP() []byte {
+	file_internal_hostapi_pb_abi_proto_rawDescOnce.Do(func() {
+		file_internal_hostapi_pb_abi_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_hostapi_pb_abi_proto_rawDesc), len(file_internal_hostapi_pb_abi_proto_rawDesc)))
+	})
+	return file_internal_hostapi_pb_abi_proto_rawDescData
+}
+
+var file_internal_hostapi_pb_abi_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_internal_hostapi_pb_abi_proto_goTypes = []any{
+	(*HostAPIRequest)(nil),        // 0: hostapi.HostAPIRequest
+	(*RequestMetadata)(nil),       // 1: hostapi.RequestMetadata
+	(*ServiceInfo)(nil),           // 2: hostapi.ServiceInfo
+	(*HostAPIError)(nil),          // 3: hostapi.HostAPIError
+	(*HostAPIResponse)(nil),       // 4: hostapi.HostAPIResponse
+	(*NextRequest)(nil),           // 5: hostapi.NextRequest
+	(*NextResponse)(nil),          // 6: hostapi.NextResponse
+	(*CloseIteratorRequest)(nil),  // 7: hostapi.CloseIteratorRequest
+	(*CloseIteratorResponse)(nil), // 8: hostapi.CloseIteratorResponse
+	nil,                           // 9: hostapi.RequestMetadata.BaggageEntry
+}
+var file_internal_hostapi_pb_abi_proto_depIdxs = []int32{
+	1, // 0: hostapi.HostAPIRequest.metadata:type_name -> hostapi.RequestMetadata
+	9, // 1: hostapi.RequestMetadata.baggage:type_name -> hostapi.RequestMetadata.BaggageEntry
+	2, // 2: hostapi.RequestMetadata.service_info:type_name -> hostapi.ServiceInfo
+	3, // 3: hostapi.HostAPIResponse.error:type_name -> hostapi.HostAPIError
+	3, // 4: hostapi.NextResponse.error:type_name -> hostapi.HostAPIError
+	3, // 5: hostapi.CloseIteratorResponse.error:type_name -> hostapi.HostAPIError
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_internal_hostapi_pb_abi_proto_init() }
+func file_internal_hostapi_pb_abi_proto_init() {
+	if File_internal_hostapi_pb_abi_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_hostapi_pb_abi_proto_rawDesc), len(file_internal_hostapi_pb_abi_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_internal_hostapi_pb_abi_proto_goTypes,
+		DependencyIndexes: file_internal_hostapi_pb_abi_proto_depIdxs,
+		MessageInfos:      file_internal_hostapi_pb_abi_proto_msgTypes,
+	}.Build()
+	File_internal_hostapi_pb_abi_proto = out.File
+	file_internal_hostapi_pb_abi_proto_goTypes = nil
+	file_internal_hostapi_pb_abi_proto_depIdxs = nil
+}
@@ -1,6 +1,11 @@
 package hostapi
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
 
 // PolicyEngine evaluates CEL-based policies
 type PolicyEngine interface {
@@ -21,3 +26,81 @@ type PolicyDecision struct {
 	Reason   string
 	Metadata map[string]interface{} // e.g., rate limit remaining
 }
+
+// Policy capabilities that unlock elevated host API operations
+const (
+	// CapabilitySQLRaw unlocks sql.raw
+	CapabilitySQLRaw = "sql.raw"
+
+	// CapabilityQueueTopic unlocks queue topics outside the caller's namespace
+	CapabilityQueueTopic = "queue.topic"
+)
+
+// requireCapability asks the policy engine whether a service holds an elevated
+// capability. The engine sees the capability name (plus any details) in the check
+// context and must both allow the call and set Metadata[capability] to true;
+// anything else denies.
+func requireCapability(ctx context.Context, engine PolicyEngine, service, api, method string, parameters json.RawMessage, capability string, details map[string]interface{}) error {
+	if engine == nil {
+		return &HostAPIError{
+			Code:    ErrorCodePolicyDenied,
+			Message: fmt.Sprintf("capability %s is not granted", capability),
+		}
+	}
+
+	// Start from the call's own check context so policies see the same environment and tags
+	checkContext := map[string]interface{}{}
+	if base, ok := ctx.Value(policyContextKey{}).(map[string]interface{}); ok {
+		for key, value := range base {
+			checkContext[key] = value
+		}
+	}
+	checkContext["capability"] = capability
+	for key, value := range details {
+		checkContext[key] = value
+	}
+
+	metadata, _ := RequestMetadataFromContext(ctx)
+	decision, err := engine.Evaluate(ctx, PolicyCheck{
+		Service: service,
+		Request: HostAPIRequest{
+			API:        api,
+			Method:     method,
+			Parameters: parameters,
+			Metadata:   metadata,
+		},
+		Context: checkContext,
+	})
+	if err != nil {
+		return &HostAPIError{
+			Code:    ErrorCodePolicyError,
+			Message: fmt.Sprintf("policy evaluation failed: %v", err),
+		}
+	}
+
+	if granted, _ := decision.Metadata[capability].(bool); !decision.Allowed || !granted {
+		return &HostAPIError{
+			Code:    ErrorCodePolicyDenied,
+			Message: fmt.Sprintf("capability %s is not granted", capability),
+		}
+	}
+
+	return nil
+}
+
+// MatchCapability reports whether a capability key covers api.method. Keys are
+// "*", "<api>", "<api>.*" or "<api>.<method>"; the "okra." prefix of API names
+// is optional.
+func MatchCapability(key, api, method string) bool {
+	if key == "*" {
+		return true
+	}
+
+	short := strings.TrimPrefix(api, "okra.")
+	for _, name := range []string{api, short} {
+		if key == name || key == name+".*" || key == name+"."+method {
+			return true
+		}
+	}
+	return false
+}
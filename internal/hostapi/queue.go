@@ -0,0 +1,716 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/spec"
+)
+
+const (
+	// QueueAPIName is the namespace of the queue host API
+	QueueAPIName = "okra.queue"
+
+	// QueueAPIVersion is the current version of the queue host API
+	QueueAPIVersion = "v1.0.0"
+)
+
+// Queue API error codes (oversized messages reuse ErrorCodeMessageTooLarge)
+const (
+	ErrorCodeInvalidTopic    = "INVALID_TOPIC"
+	ErrorCodeMessageNotFound = "MESSAGE_NOT_FOUND"
+)
+
+var (
+	// queueTopicPattern restricts topic names to alphanumerics, underscore, dash and dot
+	queueTopicPattern = regexp.MustCompile(`^[A-Za-z0-9_\-.]+$`)
+
+	// queueNamespacePattern matches service names used as topic namespaces
+	queueNamespacePattern = regexp.MustCompile(`^[A-Za-z0-9_\-.]+(/[A-Za-z0-9_\-.]+)*$`)
+)
+
+// QueuePublishRequest is the payload for queue.publish
+type QueuePublishRequest struct {
+	Topic           string            `json:"topic"`
+	Payload         json.RawMessage   `json:"payload"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Delay           int64             `json:"delay,omitempty"` // Seconds before the message becomes visible
+	TTL             int64             `json:"ttl,omitempty"`   // Seconds; 0 = never expires
+	DeduplicationID string            `json:"deduplicationId,omitempty"`
+}
+
+// QueuePublishResponse is the result of queue.publish
+type QueuePublishResponse struct {
+	ID    string `json:"id"`
+	Topic string `json:"topic"` // Fully qualified topic
+}
+
+// QueueConsumeRequest is the payload for queue.consume
+type QueueConsumeRequest struct {
+	Topic             string `json:"topic"`
+	Max               int    `json:"max,omitempty"`
+	VisibilityTimeout int64  `json:"visibilityTimeout,omitempty"` // Seconds before an unacked message reappears
+	MaxRetries        *int   `json:"maxRetries,omitempty"`
+	DeadLetterTopic   string `json:"deadLetterTopic,omitempty"`
+}
+
+// QueueConsumeResponse is the result of queue.consume
+type QueueConsumeResponse struct {
+	Messages []QueueMessage `json:"messages"`
+}
+
+// QueueAckRequest is the payload for queue.ack
+type QueueAckRequest struct {
+	Topic   string `json:"topic"`
+	ID      string `json:"id"`
+	Receipt string `json:"receipt"`
+}
+
+// QueueAckResponse is the result of queue.ack
+type QueueAckResponse struct {
+	Acked bool `json:"acked"`
+}
+
+// QueueNackRequest is the payload for queue.nack
+type QueueNackRequest struct {
+	Topic   string `json:"topic"`
+	ID      string `json:"id"`
+	Receipt string `json:"receipt"`
+	Requeue *bool  `json:"requeue,omitempty"` // Default true; false dead-letters immediately
+	Delay   int64  `json:"delay,omitempty"`   // Seconds before the message is redelivered
+}
+
+// QueueNackResponse is the result of queue.nack
+type QueueNackResponse struct {
+	DeadLettered bool `json:"deadLettered"`
+}
+
+// QueueSubscribeRequest is the payload for queue.subscribe and queue.unsubscribe
+type QueueSubscribeRequest struct {
+	Topic             string `json:"topic"`
+	Method            string `json:"method"` // Service method that receives each message
+	VisibilityTimeout int64  `json:"visibilityTimeout,omitempty"`
+	MaxRetries        *int   `json:"maxRetries,omitempty"`
+	DeadLetterTopic   string `json:"deadLetterTopic,omitempty"`
+}
+
+// QueueSubscribeResponse is the result of queue.subscribe and queue.unsubscribe
+type QueueSubscribeResponse struct {
+	Subscribed bool `json:"subscribed"`
+}
+
+// QueueTopicRequest is the payload for queue.getQueueDepth and queue.listTopics
+type QueueTopicRequest struct {
+	Topic  string `json:"topic,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// QueueListTopicsResponse is the result of queue.listTopics
+type QueueListTopicsResponse struct {
+	Topics []string `json:"topics"`
+}
+
+// QueueConfig holds the code-level limits enforced by the queue API
+type QueueConfig struct {
+	MaxTopicLength           int
+	MaxMessageSize           int
+	MaxHeaders               int
+	MaxHeaderSize            int
+	MaxDelay                 time.Duration
+	MinTTL                   time.Duration
+	MaxTTL                   time.Duration
+	DefaultMaxRetries        int
+	MaxRetries               int
+	DefaultVisibilityTimeout time.Duration
+	MaxVisibilityTimeout     time.Duration
+	DefaultBatchSize         int
+	MaxBatchSize             int
+}
+
+// defaultQueueConfig returns the limits described in docs/host-apis/queue.md
+func defaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		MaxTopicLength:           256,
+		MaxMessageSize:           256 * 1024,
+		MaxHeaders:               50,
+		MaxHeaderSize:            1024,
+		MaxDelay:                 15 * time.Minute,
+		MinTTL:                   time.Second,
+		MaxTTL:                   14 * 24 * time.Hour,
+		DefaultMaxRetries:        3,
+		MaxRetries:               10,
+		DefaultVisibilityTimeout: 30 * time.Second,
+		MaxVisibilityTimeout:     12 * time.Hour,
+		DefaultBatchSize:         10,
+		MaxBatchSize:             100,
+	}
+}
+
+// QueueAPIOption configures the queue API factory
+type QueueAPIOption func(*queueAPIFactory)
+
+// WithQueueStore sets the backend used by the queue API
+func WithQueueStore(store QueueStore) QueueAPIOption {
+	return func(f *queueAPIFactory) {
+		f.store = store
+	}
+}
+
+// WithQueueConfig overrides the default queue limits
+func WithQueueConfig(config QueueConfig) QueueAPIOption {
+	return func(f *queueAPIFactory) {
+		f.config = config
+	}
+}
+
+// queueAPIFactory creates okra.queue instances that share a single store
+type queueAPIFactory struct {
+	store  QueueStore
+	config QueueConfig
+}
+
+// NewQueueAPIFactory creates the okra.queue host API factory.
+// Without options, messages are kept in memory for the lifetime of the process;
+// use WithQueueStore(NewBoltQueueStore(path)) to persist them.
+func NewQueueAPIFactory(opts ...QueueAPIOption) HostAPIFactory {
+	factory := &queueAPIFactory{
+		config: defaultQueueConfig(),
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	if factory.store == nil {
+		factory.store = NewMemoryQueueStore()
+	}
+
+	return factory
+}
+
+func (f *queueAPIFactory) Name() string    { return QueueAPIName }
+func (f *queueAPIFactory) Version() string { return QueueAPIVersion }
+
+func (f *queueAPIFactory) Create(ctx context.Context, hostConfig HostAPIConfig) (HostAPI, error) {
+	if hostConfig.ServiceName == "" {
+		return nil, fmt.Errorf("service name is required for %s", QueueAPIName)
+	}
+
+	return &queueAPI{
+		store:        f.store,
+		config:       f.config,
+		service:      hostConfig.ServiceName,
+		policyEngine: hostConfig.PolicyEngine,
+	}, nil
+}
+
+func (f *queueAPIFactory) Methods() []MethodMetadata {
+	topicErrors := []ErrorMetadata{
+		{Code: ErrorCodeInvalidTopic, Description: "Topic name is invalid"},
+		{Code: ErrorCodePolicyDenied, Description: "Topic is outside the service namespace and not granted by policy"},
+	}
+	leaseErrors := append(topicErrors,
+		ErrorMetadata{Code: ErrorCodeMessageNotFound, Description: "Message does not exist or its lease expired"},
+	)
+	message := objectSchema(map[string]spec.Schema{
+		"id":         *spec.StringProperty(),
+		"topic":      *spec.StringProperty(),
+		"payload":    anySchema("The published JSON value"),
+		"headers":    *spec.MapProperty(spec.StringProperty()),
+		"timestamp":  *spec.DateTimeProperty(),
+		"attempts":   *spec.Int32Property(),
+		"maxRetries": *spec.Int32Property(),
+		"receipt":    *spec.StringProperty().WithDescription("Lease handle to pass to ack or nack"),
+	})
+
+	return []MethodMetadata{
+		{
+			Name:        "publish",
+			Description: "Publish a message to a topic",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"topic":           *spec.StringProperty().WithDescription("Topic name; qualify with another service's name to publish outside your namespace"),
+				"payload":         anySchema("Any JSON value"),
+				"headers":         *spec.MapProperty(spec.StringProperty()),
+				"delay":           *spec.Int64Property().WithDescription("Seconds before the message becomes visible"),
+				"ttl":             *spec.Int64Property().WithDescription("Seconds before an undelivered message expires"),
+				"deduplicationId": *spec.StringProperty().WithDescription("Publishing the same ID again returns the original message"),
+			}, "topic", "payload"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"id":    *spec.StringProperty(),
+				"topic": *spec.StringProperty(),
+			}),
+			Errors: append(topicErrors,
+				ErrorMetadata{Code: ErrorCodeMessageTooLarge, Description: "Payload or headers exceed size limits"},
+				ErrorMetadata{Code: ErrorCodeInvalidTTL, Description: "Delay or TTL is outside the allowed range"},
+			),
+		},
+		{
+			Name:        "consume",
+			Description: "Lease up to max visible messages from a topic",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"topic":             *spec.StringProperty(),
+				"max":               *spec.Int32Property().WithMaximum(100, false),
+				"visibilityTimeout": *spec.Int64Property().WithDescription("Seconds before an unacknowledged message is redelivered"),
+				"maxRetries":        *spec.Int32Property().WithDescription("Redeliveries before the message is dead-lettered").WithMaximum(10, false),
+				"deadLetterTopic":   *spec.StringProperty().WithDescription("Defaults to <topic>.dlq"),
+			}, "topic"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"messages": *spec.ArrayProperty(message),
+			}),
+			Errors: topicErrors,
+		},
+		{
+			Name:        "ack",
+			Description: "Acknowledge and remove a leased message",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"topic":   *spec.StringProperty(),
+				"id":      *spec.StringProperty(),
+				"receipt": *spec.StringProperty(),
+			}, "topic", "id", "receipt"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"acked": *spec.BoolProperty(),
+			}),
+			Errors: leaseErrors,
+		},
+		{
+			Name:        "nack",
+			Description: "Release a leased message for redelivery or dead-lettering",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"topic":   *spec.StringProperty(),
+				"id":      *spec.StringProperty(),
+				"receipt": *spec.StringProperty(),
+				"requeue": *spec.BoolProperty().WithDescription("Defaults to true; false dead-letters the message"),
+				"delay":   *spec.Int64Property().WithDescription("Seconds before redelivery"),
+			}, "topic", "id", "receipt"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"deadLettered": *spec.BoolProperty(),
+			}),
+			Errors: leaseErrors,
+		},
+		{
+			Name:        "subscribe",
+			Description: "Have the runtime deliver a topic's messages to a service method",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"topic":             *spec.StringProperty(),
+				"method":            *spec.StringProperty().WithDescription("Method of this service that receives each message"),
+				"visibilityTimeout": *spec.Int64Property(),
+				"maxRetries":        *spec.Int32Property().WithMaximum(10, false),
+				"deadLetterTopic":   *spec.StringProperty(),
+			}, "topic", "method"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"subscribed": *spec.BoolProperty(),
+			}),
+			Errors: topicErrors,
+		},
+		{
+			Name:        "unsubscribe",
+			Description: "Stop runtime-driven delivery of a topic to a service method",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"topic":  *spec.StringProperty(),
+				"method": *spec.StringProperty(),
+			}, "topic", "method"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"subscribed": *spec.BoolProperty(),
+			}),
+			Errors: topicErrors,
+		},
+		{
+			Name:        "listTopics",
+			Description: "List topics with pending messages in the service namespace",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"prefix": *spec.StringProperty(),
+			}),
+			Returns: objectSchema(map[string]spec.Schema{
+				"topics": *spec.ArrayProperty(spec.StringProperty()),
+			}),
+			Errors: topicErrors,
+		},
+		{
+			Name:        "getQueueDepth",
+			Description: "Count available, in-flight and delayed messages in a topic",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"topic": *spec.StringProperty(),
+			}, "topic"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"available": *spec.Int32Property(),
+				"inFlight":  *spec.Int32Property(),
+				"delayed":   *spec.Int32Property(),
+			}),
+			Errors: topicErrors,
+		},
+	}
+}
+
+// queueAPI is a service-scoped handle on the shared queue store
+type queueAPI struct {
+	store        QueueStore
+	config       QueueConfig
+	service      string
+	policyEngine PolicyEngine
+}
+
+// Compile-time interface compliance checks
+var (
+	_ HostAPI        = (*queueAPI)(nil)
+	_ HostAPIFactory = (*queueAPIFactory)(nil)
+)
+
+func (q *queueAPI) Name() string    { return QueueAPIName }
+func (q *queueAPI) Version() string { return QueueAPIVersion }
+
+func (q *queueAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	switch method {
+	case "publish":
+		return q.executePublish(ctx, parameters)
+	case "consume":
+		return q.executeConsume(ctx, parameters)
+	case "ack":
+		return q.executeAck(ctx, parameters)
+	case "nack":
+		return q.executeNack(ctx, parameters)
+	case "subscribe":
+		return q.executeSubscribe(ctx, parameters, true)
+	case "unsubscribe":
+		return q.executeSubscribe(ctx, parameters, false)
+	case "listTopics":
+		return q.executeListTopics(ctx, parameters)
+	case "getQueueDepth":
+		return q.executeDepth(ctx, parameters)
+	default:
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+}
+
+func (q *queueAPI) executePublish(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req QueuePublishRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	topic, err := q.resolveTopic(ctx, "publish", parameters, req.Topic)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Payload) == 0 || !json.Valid(req.Payload) {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidParameters,
+			Message: "payload must be valid JSON",
+		}
+	}
+	if len(req.Payload) > q.config.MaxMessageSize {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMessageTooLarge,
+			Message: fmt.Sprintf("payload size %d exceeds limit %d", len(req.Payload), q.config.MaxMessageSize),
+		}
+	}
+	if err := q.validateHeaders(req.Headers); err != nil {
+		return nil, err
+	}
+
+	delay := time.Duration(req.Delay) * time.Second
+	if delay < 0 || delay > q.config.MaxDelay {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidTTL,
+			Message: fmt.Sprintf("delay must be between 0s and %s", q.config.MaxDelay),
+		}
+	}
+	ttl := time.Duration(req.TTL) * time.Second
+	if req.TTL != 0 && (ttl < q.config.MinTTL || ttl > q.config.MaxTTL) {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidTTL,
+			Message: fmt.Sprintf("ttl must be between %s and %s", q.config.MinTTL, q.config.MaxTTL),
+		}
+	}
+
+	message, err := q.store.Publish(ctx, topic, req.Payload, req.Headers, QueuePublishOptions{
+		Delay:           delay,
+		TTL:             ttl,
+		DeduplicationID: req.DeduplicationID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return json.Marshal(QueuePublishResponse{ID: message.ID, Topic: topic})
+}
+
+func (q *queueAPI) executeConsume(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req QueueConsumeRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	topic, err := q.resolveTopic(ctx, "consume", parameters, req.Topic)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := q.receiveOptions(ctx, "consume", parameters, req.VisibilityTimeout, req.MaxRetries, req.DeadLetterTopic)
+	if err != nil {
+		return nil, err
+	}
+	opts.Max = req.Max
+	if opts.Max <= 0 {
+		opts.Max = q.config.DefaultBatchSize
+	}
+	if opts.Max > q.config.MaxBatchSize {
+		opts.Max = q.config.MaxBatchSize
+	}
+
+	messages, err := q.store.Receive(ctx, topic, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume messages: %w", err)
+	}
+
+	return json.Marshal(QueueConsumeResponse{Messages: messages})
+}
+
+func (q *queueAPI) executeAck(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req QueueAckRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	topic, err := q.resolveTopic(ctx, "ack", parameters, req.Topic)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.store.Ack(ctx, topic, req.ID, req.Receipt); err != nil {
+		return nil, q.storeError("ack", req.ID, err)
+	}
+	return json.Marshal(QueueAckResponse{Acked: true})
+}
+
+func (q *queueAPI) executeNack(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req QueueNackRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	topic, err := q.resolveTopic(ctx, "nack", parameters, req.Topic)
+	if err != nil {
+		return nil, err
+	}
+
+	delay := time.Duration(req.Delay) * time.Second
+	if delay < 0 || delay > q.config.MaxDelay {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidTTL,
+			Message: fmt.Sprintf("delay must be between 0s and %s", q.config.MaxDelay),
+		}
+	}
+	requeue := req.Requeue == nil || *req.Requeue
+
+	deadLettered, err := q.store.Nack(ctx, topic, req.ID, req.Receipt, requeue, delay)
+	if err != nil {
+		return nil, q.storeError("nack", req.ID, err)
+	}
+	return json.Marshal(QueueNackResponse{DeadLettered: deadLettered})
+}
+
+func (q *queueAPI) executeSubscribe(ctx context.Context, parameters json.RawMessage, subscribe bool) (json.RawMessage, error) {
+	var req QueueSubscribeRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	method := "unsubscribe"
+	if subscribe {
+		method = "subscribe"
+	}
+	topic, err := q.resolveTopic(ctx, method, parameters, req.Topic)
+	if err != nil {
+		return nil, err
+	}
+	if req.Method == "" {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidParameters,
+			Message: "method is required",
+		}
+	}
+
+	if !subscribe {
+		if _, err := q.store.Unsubscribe(ctx, q.service, topic, req.Method); err != nil {
+			return nil, fmt.Errorf("failed to unsubscribe: %w", err)
+		}
+		return json.Marshal(QueueSubscribeResponse{Subscribed: false})
+	}
+
+	opts, err := q.receiveOptions(ctx, method, parameters, req.VisibilityTimeout, req.MaxRetries, req.DeadLetterTopic)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribing again with the same topic and method replaces the options,
+	// so every worker may subscribe on startup
+	err = q.store.Subscribe(ctx, QueueSubscription{
+		Service:           q.service,
+		Topic:             topic,
+		Method:            req.Method,
+		VisibilityTimeout: opts.VisibilityTimeout,
+		MaxRetries:        opts.MaxRetries,
+		DeadLetterTopic:   opts.DeadLetterTopic,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+	return json.Marshal(QueueSubscribeResponse{Subscribed: true})
+}
+
+func (q *queueAPI) executeListTopics(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req QueueTopicRequest
+	if len(parameters) > 0 {
+		if err := unmarshalParameters(parameters, &req); err != nil {
+			return nil, err
+		}
+	}
+
+	// Listing is limited to the caller's own namespace
+	if req.Prefix != "" && !queueTopicPattern.MatchString(req.Prefix) {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidTopic,
+			Message: "prefix may only contain letters, digits, '_', '-' and '.'",
+		}
+	}
+
+	topics, err := q.store.Topics(ctx, q.service+"/"+req.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+	return json.Marshal(QueueListTopicsResponse{Topics: topics})
+}
+
+func (q *queueAPI) executeDepth(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req QueueTopicRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	topic, err := q.resolveTopic(ctx, "getQueueDepth", parameters, req.Topic)
+	if err != nil {
+		return nil, err
+	}
+
+	depth, err := q.store.Depth(ctx, topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue depth: %w", err)
+	}
+	return json.Marshal(depth)
+}
+
+// receiveOptions validates lease options shared by consume and subscribe
+func (q *queueAPI) receiveOptions(ctx context.Context, method string, parameters json.RawMessage, visibilityTimeout int64, maxRetries *int, deadLetterTopic string) (QueueReceiveOptions, error) {
+	opts := QueueReceiveOptions{
+		VisibilityTimeout: q.config.DefaultVisibilityTimeout,
+		MaxRetries:        q.config.DefaultMaxRetries,
+	}
+
+	if visibilityTimeout != 0 {
+		opts.VisibilityTimeout = time.Duration(visibilityTimeout) * time.Second
+		if opts.VisibilityTimeout < time.Second || opts.VisibilityTimeout > q.config.MaxVisibilityTimeout {
+			return opts, &HostAPIError{
+				Code:    ErrorCodeInvalidParameters,
+				Message: fmt.Sprintf("visibilityTimeout must be between 1s and %s", q.config.MaxVisibilityTimeout),
+			}
+		}
+	}
+	if maxRetries != nil {
+		if *maxRetries < 0 || *maxRetries > q.config.MaxRetries {
+			return opts, &HostAPIError{
+				Code:    ErrorCodeInvalidParameters,
+				Message: fmt.Sprintf("maxRetries must be between 0 and %d", q.config.MaxRetries),
+			}
+		}
+		opts.MaxRetries = *maxRetries
+	}
+	if deadLetterTopic != "" {
+		topic, err := q.resolveTopic(ctx, method, parameters, deadLetterTopic)
+		if err != nil {
+			return opts, err
+		}
+		opts.DeadLetterTopic = topic
+	}
+	return opts, nil
+}
+
+// resolveTopic qualifies a topic with the caller's namespace. Bare names such as
+// "orders.created" belong to the calling service; a name qualified with another
+// service ("acme/billing/invoices") requires the queue.topic policy capability.
+func (q *queueAPI) resolveTopic(ctx context.Context, method string, parameters json.RawMessage, topic string) (string, error) {
+	if topic == "" {
+		return "", &HostAPIError{
+			Code:    ErrorCodeInvalidTopic,
+			Message: "topic cannot be empty",
+		}
+	}
+
+	namespace, name := q.service, topic
+	if i := strings.LastIndex(topic, "/"); i >= 0 {
+		namespace, name = topic[:i], topic[i+1:]
+	}
+
+	qualified := namespace + "/" + name
+	if len(qualified) > q.config.MaxTopicLength {
+		return "", &HostAPIError{
+			Code:    ErrorCodeInvalidTopic,
+			Message: fmt.Sprintf("topic length %d exceeds limit %d", len(qualified), q.config.MaxTopicLength),
+		}
+	}
+	if !queueTopicPattern.MatchString(name) || !queueNamespacePattern.MatchString(namespace) {
+		return "", &HostAPIError{
+			Code:    ErrorCodeInvalidTopic,
+			Message: "topic may only contain letters, digits, '_', '-' and '.', optionally prefixed by a service name",
+		}
+	}
+
+	if namespace != q.service {
+		err := requireCapability(ctx, q.policyEngine, q.service, QueueAPIName, method, parameters, CapabilityQueueTopic, map[string]interface{}{
+			"topic":     qualified,
+			"namespace": namespace,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return qualified, nil
+}
+
+// validateHeaders enforces header count and size limits
+func (q *queueAPI) validateHeaders(headers map[string]string) error {
+	if len(headers) > q.config.MaxHeaders {
+		return &HostAPIError{
+			Code:    ErrorCodeMessageTooLarge,
+			Message: fmt.Sprintf("header count %d exceeds limit %d", len(headers), q.config.MaxHeaders),
+		}
+	}
+	for key, value := range headers {
+		if strings.HasPrefix(key, "okra-") {
+			return &HostAPIError{
+				Code:    ErrorCodeInvalidParameters,
+				Message: fmt.Sprintf("header %s uses the reserved okra- prefix", key),
+			}
+		}
+		if len(value) > q.config.MaxHeaderSize {
+			return &HostAPIError{
+				Code:    ErrorCodeMessageTooLarge,
+				Message: fmt.Sprintf("header %s exceeds %d bytes", key, q.config.MaxHeaderSize),
+			}
+		}
+	}
+	return nil
+}
+
+// storeError converts store errors into host API errors
+func (q *queueAPI) storeError(op, id string, err error) error {
+	if errors.Is(err, ErrQueueMessageNotFound) {
+		return &HostAPIError{
+			Code:    ErrorCodeMessageNotFound,
+			Message: fmt.Sprintf("message %s not found or its lease expired", id),
+		}
+	}
+	return fmt.Errorf("failed to %s message: %w", op, err)
+}
@@ -0,0 +1,150 @@
+package hostapi
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// QueueDeliverFunc delivers a message to the subscribed service method.
+// Returning nil acknowledges the message; an error requeues it for retry.
+type QueueDeliverFunc func(ctx context.Context, sub QueueSubscription, message QueueMessage) error
+
+// QueueDispatcherOption configures a QueueDispatcher
+type QueueDispatcherOption func(*QueueDispatcher)
+
+// WithQueuePollInterval sets how often subscriptions are polled when idle
+func WithQueuePollInterval(interval time.Duration) QueueDispatcherOption {
+	return func(d *QueueDispatcher) {
+		d.pollInterval = interval
+	}
+}
+
+// WithQueueBatchSize sets how many messages are leased per subscription per poll
+func WithQueueBatchSize(size int) QueueDispatcherOption {
+	return func(d *QueueDispatcher) {
+		d.batchSize = size
+	}
+}
+
+// WithQueueDispatcherLogger sets the logger used for delivery failures
+func WithQueueDispatcherLogger(logger *slog.Logger) QueueDispatcherOption {
+	return func(d *QueueDispatcher) {
+		d.logger = logger
+	}
+}
+
+// QueueDispatcher drives runtime delivery: it leases messages for every
+// subscription registered through queue.subscribe and hands them to deliver.
+type QueueDispatcher struct {
+	store        QueueStore
+	deliver      QueueDeliverFunc
+	pollInterval time.Duration
+	batchSize    int
+	logger       *slog.Logger
+}
+
+// NewQueueDispatcher creates a dispatcher for the subscriptions in store
+func NewQueueDispatcher(store QueueStore, deliver QueueDeliverFunc, opts ...QueueDispatcherOption) *QueueDispatcher {
+	d := &QueueDispatcher{
+		store:        store,
+		deliver:      deliver,
+		pollInterval: time.Second,
+		batchSize:    10,
+		logger:       slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Run delivers messages until ctx is cancelled
+func (d *QueueDispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while there is work; wait for the ticker once idle
+		for d.Poll(ctx) > 0 {
+			if ctx.Err() != nil {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll leases one batch per subscription, delivers it and returns the number
+// of messages delivered. Subscriptions are processed concurrently.
+func (d *QueueDispatcher) Poll(ctx context.Context) int {
+	subs, err := d.store.Subscriptions(ctx)
+	if err != nil {
+		d.logger.Error("failed to list queue subscriptions", "error", err)
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	delivered := 0
+	for _, sub := range subs {
+		wg.Add(1)
+		go func(sub QueueSubscription) {
+			defer wg.Done()
+			n := d.pollSubscription(ctx, sub)
+			mu.Lock()
+			delivered += n
+			mu.Unlock()
+		}(sub)
+	}
+	wg.Wait()
+	return delivered
+}
+
+func (d *QueueDispatcher) pollSubscription(ctx context.Context, sub QueueSubscription) int {
+	messages, err := d.store.Receive(ctx, sub.Topic, QueueReceiveOptions{
+		Max:               d.batchSize,
+		VisibilityTimeout: sub.VisibilityTimeout,
+		MaxRetries:        sub.MaxRetries,
+		DeadLetterTopic:   sub.DeadLetterTopic,
+	})
+	if err != nil {
+		d.logger.Error("failed to receive queue messages", "topic", sub.Topic, "error", err)
+		return 0
+	}
+
+	for _, message := range messages {
+		// The lease bounds delivery; an unfinished delivery is redelivered after it expires
+		deliverCtx, cancel := context.WithTimeout(ctx, sub.VisibilityTimeout)
+		deliverErr := d.deliver(deliverCtx, sub, message)
+		cancel()
+
+		if deliverErr == nil {
+			if err := d.store.Ack(ctx, sub.Topic, message.ID, message.Receipt); err != nil {
+				d.logger.Warn("failed to ack delivered message", "topic", sub.Topic, "id", message.ID, "error", err)
+			}
+			continue
+		}
+
+		d.logger.Warn("queue delivery failed",
+			"service", sub.Service,
+			"method", sub.Method,
+			"topic", sub.Topic,
+			"id", message.ID,
+			"attempt", message.Attempts,
+			"error", deliverErr,
+		)
+		if _, err := d.store.Nack(ctx, sub.Topic, message.ID, message.Receipt, true, 0); err != nil {
+			d.logger.Warn("failed to nack message", "topic", sub.Topic, "id", message.ID, "error", err)
+		}
+	}
+	return len(messages)
+}
@@ -0,0 +1,565 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Queue store errors, mapped to API error codes by the queue API
+var (
+	ErrQueueMessageNotFound = errors.New("message not found or lease expired")
+	ErrQueueStoreClosed     = errors.New("queue store is closed")
+)
+
+// Headers added to messages routed to a dead letter topic
+const (
+	QueueHeaderOriginalTopic    = "okra-original-topic"
+	QueueHeaderDeadLetterReason = "okra-dead-letter-reason"
+)
+
+// QueueMessage is a message as seen by consumers
+type QueueMessage struct {
+	ID         string            `json:"id"`
+	Topic      string            `json:"topic"`
+	Payload    json.RawMessage   `json:"payload"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Attempts   int               `json:"attempts"`
+	MaxRetries int               `json:"maxRetries"`
+	Receipt    string            `json:"receipt,omitempty"` // Identifies the current lease; required to ack or nack
+}
+
+// QueuePublishOptions controls how a message is enqueued
+type QueuePublishOptions struct {
+	Delay           time.Duration
+	TTL             time.Duration // 0 = never expires
+	DeduplicationID string
+}
+
+// QueueReceiveOptions controls how messages are leased
+type QueueReceiveOptions struct {
+	Max               int
+	VisibilityTimeout time.Duration
+	MaxRetries        int    // Redeliveries allowed before dead-lettering
+	DeadLetterTopic   string // Empty = <topic>.dlq
+}
+
+// QueueDepth counts the messages of a topic by state
+type QueueDepth struct {
+	Available int `json:"available"`
+	InFlight  int `json:"inFlight"`
+	Delayed   int `json:"delayed"`
+}
+
+// QueueSubscription routes a topic to a service method for runtime-driven delivery
+type QueueSubscription struct {
+	Service           string        `json:"service"`
+	Topic             string        `json:"topic"`
+	Method            string        `json:"method"`
+	VisibilityTimeout time.Duration `json:"visibilityTimeout"`
+	MaxRetries        int           `json:"maxRetries"`
+	DeadLetterTopic   string        `json:"deadLetterTopic,omitempty"`
+}
+
+// QueueStore is the message store behind okra.queue. Topics are global;
+// namespace enforcement happens in the API layer.
+type QueueStore interface {
+	Publish(ctx context.Context, topic string, payload json.RawMessage, headers map[string]string, opts QueuePublishOptions) (QueueMessage, error)
+	Receive(ctx context.Context, topic string, opts QueueReceiveOptions) ([]QueueMessage, error)
+	Ack(ctx context.Context, topic, id, receipt string) error
+	// Nack releases a lease. Messages that are not requeued, or have no retries
+	// left, move to the dead letter topic; deadLettered reports which happened.
+	Nack(ctx context.Context, topic, id, receipt string, requeue bool, delay time.Duration) (deadLettered bool, err error)
+	Depth(ctx context.Context, topic string) (QueueDepth, error)
+	Topics(ctx context.Context, prefix string) ([]string, error)
+
+	Subscribe(ctx context.Context, sub QueueSubscription) error
+	Unsubscribe(ctx context.Context, service, topic, method string) (bool, error)
+	Subscriptions(ctx context.Context) ([]QueueSubscription, error)
+
+	Close() error
+}
+
+// queueRecord is the stored form of a message
+type queueRecord struct {
+	Message         QueueMessage `json:"message"`
+	Seq             uint64       `json:"seq"`
+	VisibleAt       time.Time    `json:"visibleAt"`
+	ExpiresAt       *time.Time   `json:"expiresAt,omitempty"`
+	DeduplicationID string       `json:"deduplicationId,omitempty"`
+	DeadLetterTopic string       `json:"deadLetterTopic,omitempty"`
+	Leased          bool         `json:"leased"`
+}
+
+func (r *queueRecord) expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
+}
+
+// Bolt bucket names
+var (
+	queueMessagesBucket      = []byte("messages")
+	queueSubscriptionsBucket = []byte("subscriptions")
+)
+
+// queueStore keeps all messages in memory, ordered per topic by sequence.
+// When backed by bbolt every mutation is written through before it is visible,
+// so the queue survives restarts.
+type queueStore struct {
+	mu            sync.Mutex
+	topics        map[string][]*queueRecord // Sorted by Seq
+	subscriptions map[string]QueueSubscription
+	seq           uint64
+	db            *bolt.DB
+	closed        bool
+	now           func() time.Time
+}
+
+// NewMemoryQueueStore creates a queue store that lives for the lifetime of the process
+func NewMemoryQueueStore() QueueStore {
+	return newQueueStore(nil)
+}
+
+// NewBoltQueueStore opens (or creates) an on-disk queue store at path
+func NewBoltQueueStore(path string) (QueueStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open queue database: %w", err)
+	}
+
+	store := newQueueStore(db)
+	if err := store.load(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to load queue database: %w", err)
+	}
+	return store, nil
+}
+
+func newQueueStore(db *bolt.DB) *queueStore {
+	return &queueStore{
+		topics:        make(map[string][]*queueRecord),
+		subscriptions: make(map[string]QueueSubscription),
+		db:            db,
+		now:           time.Now,
+	}
+}
+
+// load restores messages and subscriptions from disk. Leases do not survive a
+// restart: in-flight messages become visible again immediately.
+func (s *queueStore) load() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		messages, err := tx.CreateBucketIfNotExists(queueMessagesBucket)
+		if err != nil {
+			return err
+		}
+		subscriptions, err := tx.CreateBucketIfNotExists(queueSubscriptionsBucket)
+		if err != nil {
+			return err
+		}
+
+		err = messages.ForEach(func(_, value []byte) error {
+			var record queueRecord
+			if err := json.Unmarshal(value, &record); err != nil {
+				return err
+			}
+			if record.Leased {
+				record.Leased = false
+				record.Message.Receipt = ""
+				record.VisibleAt = time.Time{}
+			}
+			s.topics[record.Message.Topic] = append(s.topics[record.Message.Topic], &record)
+			s.seq = max(s.seq, record.Seq)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, records := range s.topics {
+			sort.Slice(records, func(i, j int) bool { return records[i].Seq < records[j].Seq })
+		}
+
+		return subscriptions.ForEach(func(key, value []byte) error {
+			var sub QueueSubscription
+			if err := json.Unmarshal(value, &sub); err != nil {
+				return err
+			}
+			s.subscriptions[string(key)] = sub
+			return nil
+		})
+	})
+}
+
+func (s *queueStore) Publish(ctx context.Context, topic string, payload json.RawMessage, headers map[string]string, opts QueuePublishOptions) (QueueMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return QueueMessage{}, ErrQueueStoreClosed
+	}
+
+	now := s.now()
+	if opts.DeduplicationID != "" {
+		for _, record := range s.topics[topic] {
+			if record.DeduplicationID == opts.DeduplicationID && !record.expired(now) {
+				return record.Message, nil
+			}
+		}
+	}
+
+	record := s.newRecord(topic, payload, headers, now)
+	record.VisibleAt = now.Add(opts.Delay)
+	record.DeduplicationID = opts.DeduplicationID
+	if opts.TTL > 0 {
+		expiresAt := now.Add(opts.TTL)
+		record.ExpiresAt = &expiresAt
+	}
+
+	if err := s.persist(nil, []*queueRecord{record}); err != nil {
+		return QueueMessage{}, err
+	}
+	s.topics[topic] = append(s.topics[topic], record)
+	return record.Message, nil
+}
+
+func (s *queueStore) newRecord(topic string, payload json.RawMessage, headers map[string]string, now time.Time) *queueRecord {
+	s.seq++
+	return &queueRecord{
+		Message: QueueMessage{
+			ID:        uuid.New().String(),
+			Topic:     topic,
+			Payload:   payload,
+			Headers:   headers,
+			Timestamp: now,
+		},
+		Seq: s.seq,
+	}
+}
+
+func (s *queueStore) Receive(ctx context.Context, topic string, opts QueueReceiveOptions) ([]QueueMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrQueueStoreClosed
+	}
+
+	now := s.now()
+	var removed []*queueRecord
+	var originals []*queueRecord
+	var leases []*queueRecord
+	var deadLetters []*queueRecord
+
+	for _, record := range s.topics[topic] {
+		if len(leases) >= opts.Max {
+			break
+		}
+		if now.Before(record.VisibleAt) {
+			continue
+		}
+		if record.expired(now) {
+			removed = append(removed, record)
+			continue
+		}
+
+		// A visible message that is still leased had its lease expire
+		if record.Leased && record.Message.Attempts > record.Message.MaxRetries {
+			removed = append(removed, record)
+			deadLetters = append(deadLetters, s.deadLetter(record, "visibility timeout exceeded after max retries", now))
+			continue
+		}
+
+		lease := *record
+		lease.Message.Attempts++
+		lease.Message.MaxRetries = opts.MaxRetries
+		lease.Message.Receipt = uuid.New().String()
+		lease.DeadLetterTopic = opts.DeadLetterTopic
+		lease.VisibleAt = now.Add(opts.VisibilityTimeout)
+		lease.Leased = true
+		originals = append(originals, record)
+		leases = append(leases, &lease)
+	}
+
+	if err := s.persist(removed, append(append([]*queueRecord{}, leases...), deadLetters...)); err != nil {
+		return nil, err
+	}
+
+	messages := make([]QueueMessage, 0, len(leases))
+	for i, lease := range leases {
+		*originals[i] = *lease
+		messages = append(messages, lease.Message)
+	}
+	s.remove(removed)
+	s.append(deadLetters)
+	return messages, nil
+}
+
+func (s *queueStore) Ack(ctx context.Context, topic, id, receipt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrQueueStoreClosed
+	}
+
+	record := s.leased(topic, id, receipt)
+	if record == nil {
+		return ErrQueueMessageNotFound
+	}
+	if err := s.persist([]*queueRecord{record}, nil); err != nil {
+		return err
+	}
+	s.remove([]*queueRecord{record})
+	return nil
+}
+
+func (s *queueStore) Nack(ctx context.Context, topic, id, receipt string, requeue bool, delay time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false, ErrQueueStoreClosed
+	}
+
+	record := s.leased(topic, id, receipt)
+	if record == nil {
+		return false, ErrQueueMessageNotFound
+	}
+
+	now := s.now()
+	if !requeue || record.Message.Attempts > record.Message.MaxRetries {
+		reason := "rejected by consumer"
+		if requeue {
+			reason = "max retries exceeded"
+		}
+		deadLetter := s.deadLetter(record, reason, now)
+		if err := s.persist([]*queueRecord{record}, []*queueRecord{deadLetter}); err != nil {
+			return false, err
+		}
+		s.remove([]*queueRecord{record})
+		s.append([]*queueRecord{deadLetter})
+		return true, nil
+	}
+
+	updated := *record
+	updated.Leased = false
+	updated.Message.Receipt = ""
+	updated.VisibleAt = now.Add(delay)
+	if err := s.persist(nil, []*queueRecord{&updated}); err != nil {
+		return false, err
+	}
+	*record = updated
+	return false, nil
+}
+
+// leased finds a message by ID whose current lease matches receipt
+func (s *queueStore) leased(topic, id, receipt string) *queueRecord {
+	now := s.now()
+	for _, record := range s.topics[topic] {
+		if record.Message.ID != id {
+			continue
+		}
+		if !record.Leased || record.Message.Receipt != receipt || !now.Before(record.VisibleAt) {
+			return nil
+		}
+		return record
+	}
+	return nil
+}
+
+// deadLetter builds the dead letter copy of a record. The caller removes the original.
+func (s *queueStore) deadLetter(record *queueRecord, reason string, now time.Time) *queueRecord {
+	topic := record.DeadLetterTopic
+	if topic == "" {
+		topic = record.Message.Topic + ".dlq"
+	}
+
+	headers := make(map[string]string, len(record.Message.Headers)+2)
+	for key, value := range record.Message.Headers {
+		headers[key] = value
+	}
+	headers[QueueHeaderOriginalTopic] = record.Message.Topic
+	headers[QueueHeaderDeadLetterReason] = reason
+
+	return s.newRecord(topic, record.Message.Payload, headers, now)
+}
+
+func (s *queueStore) Depth(ctx context.Context, topic string) (QueueDepth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	depth := QueueDepth{}
+	for _, record := range s.topics[topic] {
+		switch {
+		case record.expired(now):
+		case !now.Before(record.VisibleAt):
+			depth.Available++
+		case record.Leased:
+			depth.InFlight++
+		default:
+			depth.Delayed++
+		}
+	}
+	return depth, nil
+}
+
+func (s *queueStore) Topics(ctx context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	topics := []string{}
+	for topic, records := range s.topics {
+		if len(records) > 0 && strings.HasPrefix(topic, prefix) {
+			topics = append(topics, topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics, nil
+}
+
+// subscriptionKey identifies a subscription; subscribing again replaces its options
+func subscriptionKey(service, topic, method string) string {
+	return service + "\x00" + topic + "\x00" + method
+}
+
+func (s *queueStore) Subscribe(ctx context.Context, sub QueueSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrQueueStoreClosed
+	}
+
+	key := subscriptionKey(sub.Service, sub.Topic, sub.Method)
+	if s.db != nil {
+		data, err := json.Marshal(sub)
+		if err != nil {
+			return err
+		}
+		err = s.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(queueSubscriptionsBucket).Put([]byte(key), data)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to persist subscription: %w", err)
+		}
+	}
+	s.subscriptions[key] = sub
+	return nil
+}
+
+func (s *queueStore) Unsubscribe(ctx context.Context, service, topic, method string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false, ErrQueueStoreClosed
+	}
+
+	key := subscriptionKey(service, topic, method)
+	if _, ok := s.subscriptions[key]; !ok {
+		return false, nil
+	}
+	if s.db != nil {
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(queueSubscriptionsBucket).Delete([]byte(key))
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to persist subscription: %w", err)
+		}
+	}
+	delete(s.subscriptions, key)
+	return true, nil
+}
+
+func (s *queueStore) Subscriptions(ctx context.Context) ([]QueueSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.subscriptions))
+	for key := range s.subscriptions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	subs := make([]QueueSubscription, 0, len(keys))
+	for _, key := range keys {
+		subs = append(subs, s.subscriptions[key])
+	}
+	return subs, nil
+}
+
+func (s *queueStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// persist writes deletions and upserts in one transaction. In-memory state is
+// only changed by the caller after this succeeds.
+func (s *queueStore) persist(deleted, upserted []*queueRecord) error {
+	if s.db == nil || (len(deleted) == 0 && len(upserted) == 0) {
+		return nil
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(queueMessagesBucket)
+		for _, record := range deleted {
+			if err := bucket.Delete([]byte(record.Message.ID)); err != nil {
+				return err
+			}
+		}
+		for _, record := range upserted {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(record.Message.ID), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to persist queue: %w", err)
+	}
+	return nil
+}
+
+// remove drops records from their topics
+func (s *queueStore) remove(records []*queueRecord) {
+	for _, record := range records {
+		topic := record.Message.Topic
+		list := s.topics[topic]
+		for i, candidate := range list {
+			if candidate == record {
+				s.topics[topic] = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(s.topics[topic]) == 0 {
+			delete(s.topics, topic)
+		}
+	}
+}
+
+// append adds new records, which always carry the highest sequence numbers
+func (s *queueStore) append(records []*queueRecord) {
+	for _, record := range records {
+		s.topics[record.Message.Topic] = append(s.topics[record.Message.Topic], record)
+	}
+}
@@ -0,0 +1,358 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test publish/consume/ack round trip through a HostAPISet
+// 2. Test visibility timeouts, nack retries and dead-letter routing
+// 3. Test delay, TTL and deduplication
+// 4. Test topic namespaces: own topics are free, others need the queue.topic capability
+// 5. Test the bolt store survives a reopen, releasing in-flight leases
+// 6. Test runtime-driven delivery through QueueDispatcher
+// 7. Test code-level validation
+
+// newQueueTestSet creates a HostAPISet with the queue API for the given service
+func newQueueTestSet(t *testing.T, factory HostAPIFactory, service string, engine PolicyEngine) HostAPISet {
+	if engine == nil {
+		engine = &mockPolicyEngine{}
+	}
+
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(factory))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{QueueAPIName}, HostAPIConfig{
+		ServiceName:  service,
+		PolicyEngine: engine,
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+// newTestQueueStore creates a memory queue store with a controllable clock
+func newTestQueueStore(now *time.Time) *queueStore {
+	store := newQueueStore(nil)
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+// queueCall executes a queue method and decodes the result into out
+func queueCall(t *testing.T, set HostAPISet, method, params string, out interface{}) {
+	t.Helper()
+	result, err := set.Execute(context.Background(), QueueAPIName, method, json.RawMessage(params))
+	require.NoError(t, err)
+	if out != nil {
+		require.NoError(t, json.Unmarshal(result, out))
+	}
+}
+
+// queueConsume leases messages from a topic
+func queueConsume(t *testing.T, set HostAPISet, params string) []QueueMessage {
+	t.Helper()
+	var resp QueueConsumeResponse
+	queueCall(t, set, "consume", params, &resp)
+	return resp.Messages
+}
+
+func TestQueueAPI_PublishConsumeAck(t *testing.T) {
+	set := newQueueTestSet(t, NewQueueAPIFactory(), "acme/orders", nil)
+
+	var published QueuePublishResponse
+	queueCall(t, set, "publish", `{"topic":"orders.created","payload":{"id":1},"headers":{"source":"web"}}`, &published)
+	assert.NotEmpty(t, published.ID)
+	assert.Equal(t, "acme/orders/orders.created", published.Topic)
+	queueCall(t, set, "publish", `{"topic":"orders.created","payload":{"id":2}}`, nil)
+
+	messages := queueConsume(t, set, `{"topic":"orders.created","max":1}`)
+	require.Len(t, messages, 1)
+	assert.Equal(t, published.ID, messages[0].ID)
+	assert.JSONEq(t, `{"id":1}`, string(messages[0].Payload))
+	assert.Equal(t, map[string]string{"source": "web"}, messages[0].Headers)
+	assert.Equal(t, 1, messages[0].Attempts)
+	assert.NotEmpty(t, messages[0].Receipt)
+
+	var depth QueueDepth
+	queueCall(t, set, "getQueueDepth", `{"topic":"orders.created"}`, &depth)
+	assert.Equal(t, QueueDepth{Available: 1, InFlight: 1}, depth)
+
+	// A stale or wrong receipt cannot ack
+	_, err := set.Execute(context.Background(), QueueAPIName, "ack", json.RawMessage(
+		fmt.Sprintf(`{"topic":"orders.created","id":%q,"receipt":"wrong"}`, messages[0].ID)))
+	requireHostAPIError(t, err, ErrorCodeMessageNotFound)
+
+	queueCall(t, set, "ack", fmt.Sprintf(`{"topic":"orders.created","id":%q,"receipt":%q}`, messages[0].ID, messages[0].Receipt), nil)
+
+	second := queueConsume(t, set, `{"topic":"orders.created"}`)
+	require.Len(t, second, 1)
+	assert.JSONEq(t, `{"id":2}`, string(second[0].Payload))
+
+	var topics QueueListTopicsResponse
+	queueCall(t, set, "listTopics", `{"prefix":"orders"}`, &topics)
+	assert.Equal(t, []string{"acme/orders/orders.created"}, topics.Topics)
+}
+
+func TestQueueAPI_RetriesAndDeadLetters(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestQueueStore(&now)
+	set := newQueueTestSet(t, NewQueueAPIFactory(WithQueueStore(store)), "acme/orders", nil)
+
+	queueCall(t, set, "publish", `{"topic":"jobs","payload":"work"}`, nil)
+	consume := `{"topic":"jobs","visibilityTimeout":10,"maxRetries":1,"deadLetterTopic":"jobs.failed"}`
+
+	// First delivery is nacked and requeued
+	first := queueConsume(t, set, consume)
+	require.Len(t, first, 1)
+	var nack QueueNackResponse
+	queueCall(t, set, "nack", fmt.Sprintf(`{"topic":"jobs","id":%q,"receipt":%q}`, first[0].ID, first[0].Receipt), &nack)
+	assert.False(t, nack.DeadLettered)
+
+	// Second delivery times out instead of being acked
+	second := queueConsume(t, set, consume)
+	require.Len(t, second, 1)
+	assert.Equal(t, 2, second[0].Attempts)
+	assert.Empty(t, queueConsume(t, set, consume), "leased message is invisible")
+
+	now = now.Add(11 * time.Second)
+	assert.Empty(t, queueConsume(t, set, consume), "retries exhausted, so the message is dead-lettered")
+
+	dead := queueConsume(t, set, `{"topic":"jobs.failed"}`)
+	require.Len(t, dead, 1)
+	assert.JSONEq(t, `"work"`, string(dead[0].Payload))
+	assert.Equal(t, "acme/orders/jobs", dead[0].Headers[QueueHeaderOriginalTopic])
+	assert.Equal(t, "visibility timeout exceeded after max retries", dead[0].Headers[QueueHeaderDeadLetterReason])
+
+	// Rejecting without requeue dead-letters immediately, to <topic>.dlq by default
+	queueCall(t, set, "publish", `{"topic":"jobs","payload":"bad"}`, nil)
+	msg := queueConsume(t, set, `{"topic":"jobs"}`)
+	require.Len(t, msg, 1)
+	queueCall(t, set, "nack", fmt.Sprintf(`{"topic":"jobs","id":%q,"receipt":%q,"requeue":false}`, msg[0].ID, msg[0].Receipt), &nack)
+	assert.True(t, nack.DeadLettered)
+	assert.Len(t, queueConsume(t, set, `{"topic":"jobs.dlq"}`), 1)
+}
+
+func TestQueueAPI_DelayTTLAndDeduplication(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestQueueStore(&now)
+	set := newQueueTestSet(t, NewQueueAPIFactory(WithQueueStore(store)), "acme/orders", nil)
+
+	queueCall(t, set, "publish", `{"topic":"later","payload":1,"delay":60}`, nil)
+	var depth QueueDepth
+	queueCall(t, set, "getQueueDepth", `{"topic":"later"}`, &depth)
+	assert.Equal(t, QueueDepth{Delayed: 1}, depth)
+	assert.Empty(t, queueConsume(t, set, `{"topic":"later"}`))
+	now = now.Add(time.Minute)
+	assert.Len(t, queueConsume(t, set, `{"topic":"later"}`), 1)
+
+	queueCall(t, set, "publish", `{"topic":"expiring","payload":1,"ttl":5}`, nil)
+	now = now.Add(5 * time.Second)
+	assert.Empty(t, queueConsume(t, set, `{"topic":"expiring"}`))
+
+	var first, second QueuePublishResponse
+	queueCall(t, set, "publish", `{"topic":"dedup","payload":1,"deduplicationId":"order-1"}`, &first)
+	queueCall(t, set, "publish", `{"topic":"dedup","payload":2,"deduplicationId":"order-1"}`, &second)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Len(t, queueConsume(t, set, `{"topic":"dedup"}`), 1)
+}
+
+// topicEngine grants the queue.topic capability for the listed topics
+type topicEngine struct {
+	mu     sync.Mutex
+	topics map[string]bool
+	checks []map[string]interface{}
+}
+
+func (e *topicEngine) Evaluate(ctx context.Context, check PolicyCheck) (PolicyDecision, error) {
+	if check.Context["capability"] != CapabilityQueueTopic {
+		return PolicyDecision{Allowed: true}, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.checks = append(e.checks, check.Context)
+	topic, _ := check.Context["topic"].(string)
+	return PolicyDecision{Allowed: true, Metadata: map[string]interface{}{CapabilityQueueTopic: e.topics[topic]}}, nil
+}
+
+func TestQueueAPI_TopicNamespaces(t *testing.T) {
+	factory := NewQueueAPIFactory()
+	engine := &topicEngine{topics: map[string]bool{"acme/billing/invoices": true}}
+	orders := newQueueTestSet(t, factory, "acme/orders", engine)
+	billing := newQueueTestSet(t, factory, "acme/billing", nil)
+
+	// Publishing into another service's namespace requires a grant
+	queueCall(t, orders, "publish", `{"topic":"acme/billing/invoices","payload":1}`, nil)
+	_, err := orders.Execute(context.Background(), QueueAPIName, "publish", json.RawMessage(`{"topic":"acme/billing/refunds","payload":1}`))
+	requireHostAPIError(t, err, ErrorCodePolicyDenied)
+
+	require.Len(t, engine.checks, 2)
+	assert.Equal(t, "acme/billing", engine.checks[0]["namespace"])
+	assert.Equal(t, "acme/billing/invoices", engine.checks[0]["topic"])
+
+	// The owner consumes with a bare topic name; its own qualified name needs no grant
+	assert.Len(t, queueConsume(t, billing, `{"topic":"invoices"}`), 1)
+	assert.Empty(t, queueConsume(t, billing, `{"topic":"acme/billing/invoices"}`))
+
+	// Bare names are isolated per service
+	queueCall(t, orders, "publish", `{"topic":"events","payload":1}`, nil)
+	assert.Empty(t, queueConsume(t, billing, `{"topic":"events"}`))
+
+	// Dead letter topics are resolved and checked the same way
+	_, err = orders.Execute(context.Background(), QueueAPIName, "consume", json.RawMessage(`{"topic":"events","deadLetterTopic":"acme/billing/dlq"}`))
+	requireHostAPIError(t, err, ErrorCodePolicyDenied)
+}
+
+func TestBoltQueueStore_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "queue.db")
+
+	store, err := NewBoltQueueStore(path)
+	require.NoError(t, err)
+
+	first, err := store.Publish(ctx, "svc/jobs", json.RawMessage(`1`), nil, QueuePublishOptions{})
+	require.NoError(t, err)
+	_, err = store.Publish(ctx, "svc/jobs", json.RawMessage(`2`), nil, QueuePublishOptions{})
+	require.NoError(t, err)
+	require.NoError(t, store.Subscribe(ctx, QueueSubscription{Service: "svc", Topic: "svc/jobs", Method: "handle", VisibilityTimeout: time.Minute}))
+
+	leased, err := store.Receive(ctx, "svc/jobs", QueueReceiveOptions{Max: 1, VisibilityTimeout: time.Hour, MaxRetries: 3})
+	require.NoError(t, err)
+	require.Len(t, leased, 1)
+	assert.Equal(t, first.ID, leased[0].ID)
+	require.NoError(t, store.Close())
+
+	// Reopen: both messages remain, the in-flight lease is released but its attempt is kept
+	store, err = NewBoltQueueStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	depth, err := store.Depth(ctx, "svc/jobs")
+	require.NoError(t, err)
+	assert.Equal(t, QueueDepth{Available: 2}, depth)
+
+	subs, err := store.Subscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "handle", subs[0].Method)
+
+	messages, err := store.Receive(ctx, "svc/jobs", QueueReceiveOptions{Max: 10, VisibilityTimeout: time.Minute, MaxRetries: 3})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, first.ID, messages[0].ID, "order is preserved")
+	assert.Equal(t, 2, messages[0].Attempts)
+
+	for _, message := range messages {
+		require.NoError(t, store.Ack(ctx, "svc/jobs", message.ID, message.Receipt))
+	}
+	topics, err := store.Topics(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, topics)
+}
+
+func TestQueueDispatcher(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryQueueStore()
+	set := newQueueTestSet(t, NewQueueAPIFactory(WithQueueStore(store)), "acme/orders", nil)
+
+	queueCall(t, set, "subscribe", `{"topic":"jobs","method":"handleJob","maxRetries":1}`, nil)
+	// Subscribing again from another worker is idempotent
+	queueCall(t, set, "subscribe", `{"topic":"jobs","method":"handleJob","maxRetries":1}`, nil)
+	queueCall(t, set, "publish", `{"topic":"jobs","payload":"ok"}`, nil)
+	queueCall(t, set, "publish", `{"topic":"jobs","payload":"fail"}`, nil)
+
+	var mu sync.Mutex
+	delivered := map[string]int{}
+	dispatcher := NewQueueDispatcher(store, func(ctx context.Context, sub QueueSubscription, message QueueMessage) error {
+		assert.Equal(t, "acme/orders", sub.Service)
+		assert.Equal(t, "handleJob", sub.Method)
+
+		mu.Lock()
+		defer mu.Unlock()
+		delivered[string(message.Payload)]++
+		if string(message.Payload) == `"fail"` {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	// Drain: "ok" is acked, "fail" is retried once and then dead-lettered
+	for dispatcher.Poll(ctx) > 0 {
+	}
+	assert.Equal(t, map[string]int{`"ok"`: 1, `"fail"`: 2}, delivered)
+
+	depth, err := store.Depth(ctx, "acme/orders/jobs")
+	require.NoError(t, err)
+	assert.Equal(t, QueueDepth{}, depth)
+	depth, err = store.Depth(ctx, "acme/orders/jobs.dlq")
+	require.NoError(t, err)
+	assert.Equal(t, 1, depth.Available)
+
+	var resp QueueSubscribeResponse
+	queueCall(t, set, "unsubscribe", `{"topic":"jobs","method":"handleJob"}`, &resp)
+	assert.False(t, resp.Subscribed)
+	subs, err := store.Subscriptions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+
+	// Run stops when the context is cancelled
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- NewQueueDispatcher(store, nil, WithQueuePollInterval(time.Millisecond)).Run(runCtx) }()
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher did not stop")
+	}
+}
+
+func TestQueueAPI_Validation(t *testing.T) {
+	ctx := context.Background()
+	set := newQueueTestSet(t, NewQueueAPIFactory(), "acme/orders", nil)
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		code   string
+	}{
+		{"empty topic", "publish", `{"topic":"","payload":1}`, ErrorCodeInvalidTopic},
+		{"invalid topic", "publish", `{"topic":"a b","payload":1}`, ErrorCodeInvalidTopic},
+		{"invalid namespace", "publish", `{"topic":"//x","payload":1}`, ErrorCodeInvalidTopic},
+		{"long topic", "publish", `{"topic":"` + strings.Repeat("t", 256) + `","payload":1}`, ErrorCodeInvalidTopic},
+		{"missing payload", "publish", `{"topic":"t"}`, ErrorCodeInvalidParameters},
+		{"large payload", "publish", `{"topic":"t","payload":"` + strings.Repeat("p", 256*1024) + `"}`, ErrorCodeMessageTooLarge},
+		{"reserved header", "publish", `{"topic":"t","payload":1,"headers":{"okra-original-topic":"x"}}`, ErrorCodeInvalidParameters},
+		{"delay too long", "publish", `{"topic":"t","payload":1,"delay":901}`, ErrorCodeInvalidTTL},
+		{"ttl too long", "publish", `{"topic":"t","payload":1,"ttl":9999999}`, ErrorCodeInvalidTTL},
+		{"too many retries", "consume", `{"topic":"t","maxRetries":11}`, ErrorCodeInvalidParameters},
+		{"visibility too long", "consume", `{"topic":"t","visibilityTimeout":999999}`, ErrorCodeInvalidParameters},
+		{"subscribe without method", "subscribe", `{"topic":"t"}`, ErrorCodeInvalidParameters},
+		{"unknown message", "ack", `{"topic":"t","id":"nope","receipt":"nope"}`, ErrorCodeMessageNotFound},
+		{"unknown method", "purge", `{}`, ErrorCodeMethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := set.Execute(ctx, QueueAPIName, tt.method, json.RawMessage(tt.params))
+			requireHostAPIError(t, err, tt.code)
+		})
+	}
+}
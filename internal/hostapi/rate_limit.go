@@ -0,0 +1,168 @@
+package hostapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/okra-platform/okra/internal/config"
+)
+
+// PolicyRateLimitKey is the policy constraint holding a rate limit for the
+// matched methods, e.g. rateLimit: {requestsPerSecond: 50, dailyQuota: 1000000}.
+// It is read from PolicyDecision.Metadata and uses the okra.json field names.
+const PolicyRateLimitKey = "rateLimit"
+
+// rateLimiter enforces rate limits and daily quotas on host API calls. It is
+// owned by the registry so counters are shared by every HostAPISet, and so
+// every worker, of a service.
+type rateLimiter struct {
+	buckets map[string]*tokenBucket
+	quotas  map[string]*dailyQuota
+	mu      sync.Mutex
+}
+
+// dailyQuota counts calls made during one UTC day
+type dailyQuota struct {
+	day  time.Time
+	used int64
+}
+
+// rateLimitRule is a limit resolved for one call, keyed by the counters it uses
+type rateLimitRule struct {
+	key   string
+	limit config.RateLimitConfig
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		quotas:  make(map[string]*dailyQuota),
+	}
+}
+
+// allow consumes one call from every limit covering api.method. Limits come
+// from the service's okra.json and from the policy decision that allowed the
+// call. A rejected call consumes nothing from the daily quotas or the rate
+// limits.
+func (l *rateLimiter) allow(hostConfig HostAPIConfig, service, api, method string, decision PolicyDecision) error {
+	rules := rateLimitRules(hostConfig, service, api, method, decision)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	clock := clockOrSystem(hostConfig.Clock)
+	now := clock.Now()
+	day := now.UTC().Truncate(24 * time.Hour)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Check quotas first so calls are never counted against a limit they fail
+	var quotas []*dailyQuota
+	for _, rule := range rules {
+		if rule.limit.DailyQuota <= 0 {
+			continue
+		}
+
+		quota, ok := l.quotas[rule.key]
+		if !ok || !quota.day.Equal(day) {
+			quota = &dailyQuota{day: day}
+			l.quotas[rule.key] = quota
+		}
+		if quota.used >= rule.limit.DailyQuota {
+			return rateLimitedError(api, method,
+				fmt.Sprintf("daily quota of %d calls exhausted", rule.limit.DailyQuota),
+				day.Add(24*time.Hour).Sub(now))
+		}
+		quotas = append(quotas, quota)
+	}
+
+	var taken []*tokenBucket
+	for _, rule := range rules {
+		if rule.limit.RequestsPerSecond <= 0 {
+			continue
+		}
+
+		bucket, ok := l.buckets[rule.key]
+		if !ok {
+			burst := rule.limit.Burst
+			if burst <= 0 {
+				burst = int(math.Max(1, math.Ceil(rule.limit.RequestsPerSecond)))
+			}
+			bucket = newTokenBucket(rule.limit.RequestsPerSecond, burst, clock.Now)
+			l.buckets[rule.key] = bucket
+		}
+		if ok, retryAfter := bucket.take(1); !ok {
+			// Give back what the limits checked so far took for this call
+			for _, bucket := range taken {
+				bucket.refund(1)
+			}
+			return rateLimitedError(api, method,
+				fmt.Sprintf("rate limit of %g calls per second exceeded", rule.limit.RequestsPerSecond),
+				retryAfter)
+		}
+		taken = append(taken, bucket)
+	}
+
+	for _, quota := range quotas {
+		quota.used++
+	}
+	return nil
+}
+
+// rateLimitRules collects the limits covering a call. Counters are keyed by the
+// service, target and limit values, so changing a limit starts fresh counters.
+// Policy limits count each method separately.
+func rateLimitRules(hostConfig HostAPIConfig, service, api, method string, decision PolicyDecision) []rateLimitRule {
+	var limits []config.RateLimitConfig
+	if cfg := serviceConfig(hostConfig); cfg != nil {
+		for _, limit := range cfg.RateLimits {
+			if MatchCapability(limit.Target, api, method) {
+				limits = append(limits, limit)
+			}
+		}
+	}
+
+	if raw, ok := decision.Metadata[PolicyRateLimitKey]; ok {
+		if limit, ok := policyRateLimit(raw); ok {
+			limit.Target = api + "." + method
+			limits = append(limits, limit)
+		}
+	}
+
+	rules := make([]rateLimitRule, 0, len(limits))
+	for _, limit := range limits {
+		if limit.RequestsPerSecond <= 0 && limit.DailyQuota <= 0 {
+			continue
+		}
+		rules = append(rules, rateLimitRule{
+			key:   fmt.Sprintf("%s|%s|%g|%d|%d", service, limit.Target, limit.RequestsPerSecond, limit.Burst, limit.DailyQuota),
+			limit: limit,
+		})
+	}
+	return rules
+}
+
+// policyRateLimit decodes a rateLimit constraint from policy metadata
+func policyRateLimit(raw interface{}) (config.RateLimitConfig, bool) {
+	var limit config.RateLimitConfig
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return limit, false
+	}
+	if err := json.Unmarshal(data, &limit); err != nil {
+		return limit, false
+	}
+	return limit, true
+}
+
+func rateLimitedError(api, method, reason string, retryAfter time.Duration) error {
+	return &HostAPIError{
+		Code:    ErrorCodeRateLimited,
+		Message: fmt.Sprintf("%s.%s: %s", api, method, reason),
+		Details: fmt.Sprintf("retry after %s", retryAfter.Round(time.Millisecond)),
+	}
+}
@@ -0,0 +1,161 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test okra.json rate limits are shared by every set the registry creates
+// 2. Test limits only cover matching methods and their own service
+// 3. Test daily quotas reset at UTC midnight and ignore rejected calls
+// 4. Test rate limits from policy decisions
+// 5. Test capability key matching
+// 6. Test a call rejected by one rate limit takes nothing from the others
+
+// newRateLimitTestSet creates an okra.time set for service from registry
+func newRateLimitTestSet(t *testing.T, registry HostAPIRegistry, service string, clock Clock, engine PolicyEngine, limits ...config.RateLimitConfig) HostAPISet {
+	set, err := registry.CreateHostAPISet(context.Background(), []string{TimeAPIName}, HostAPIConfig{
+		ServiceName:  service,
+		PolicyEngine: engine,
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		Clock:        clock,
+		Config:       &config.Config{RateLimits: limits},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+func newRateLimitRegistry(t *testing.T) HostAPIRegistry {
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(NewTimeAPIFactory()))
+	return registry
+}
+
+func timeNow(set HostAPISet) error {
+	_, err := set.Execute(context.Background(), TimeAPIName, "now", json.RawMessage(`{}`))
+	return err
+}
+
+func requireRateLimited(t *testing.T, err error, details string) {
+	t.Helper()
+	requireHostAPIError(t, err, ErrorCodeRateLimited)
+
+	var apiErr *HostAPIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, details, apiErr.Details)
+}
+
+func TestRateLimiter_SharedAcrossWorkers(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	registry := newRateLimitRegistry(t)
+	limit := config.RateLimitConfig{Target: "time.now", RequestsPerSecond: 1, Burst: 2}
+
+	worker1 := newRateLimitTestSet(t, registry, "acme/orders", clock, &mockPolicyEngine{}, limit)
+	worker2 := newRateLimitTestSet(t, registry, "acme/orders", clock, &mockPolicyEngine{}, limit)
+
+	require.NoError(t, timeNow(worker1))
+	require.NoError(t, timeNow(worker2))
+	requireRateLimited(t, timeNow(worker1), "retry after 1s")
+
+	// Unmatched methods and other services are not limited
+	_, err := worker1.Execute(context.Background(), TimeAPIName, "convert", json.RawMessage(`{"timestamp":0,"timezone":"UTC"}`))
+	require.NoError(t, err)
+	other := newRateLimitTestSet(t, registry, "acme/billing", clock, &mockPolicyEngine{}, limit)
+	require.NoError(t, timeNow(other))
+
+	clock.Advance(time.Second)
+	require.NoError(t, timeNow(worker2))
+	requireRateLimited(t, timeNow(worker1), "retry after 1s")
+}
+
+func TestRateLimiter_DailyQuota(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 21, 0, 0, 0, time.UTC))
+	registry := newRateLimitRegistry(t)
+	set := newRateLimitTestSet(t, registry, "acme/orders", clock, &mockPolicyEngine{},
+		config.RateLimitConfig{Target: "okra.time", DailyQuota: 2},
+		config.RateLimitConfig{Target: "*", RequestsPerSecond: 1},
+	)
+
+	require.NoError(t, timeNow(set))
+	// Rejected by the rate limit, so the quota is not consumed
+	requireRateLimited(t, timeNow(set), "retry after 1s")
+
+	clock.Advance(time.Second)
+	require.NoError(t, timeNow(set))
+	clock.Advance(time.Second)
+	requireRateLimited(t, timeNow(set), "retry after 2h59m58s")
+
+	clock.Set(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, timeNow(set))
+}
+
+func TestRateLimiter_RejectedCallRefunded(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	registry := newRateLimitRegistry(t)
+	set := newRateLimitTestSet(t, registry, "acme/orders", clock, &mockPolicyEngine{},
+		config.RateLimitConfig{Target: "time.now", RequestsPerSecond: 0.1, Burst: 3},
+		config.RateLimitConfig{Target: "okra.time", RequestsPerSecond: 10, Burst: 1},
+	)
+
+	// Only the second bucket is empty, so it rejects these calls
+	require.NoError(t, timeNow(set))
+	requireRateLimited(t, timeNow(set), "retry after 100ms")
+	requireRateLimited(t, timeNow(set), "retry after 100ms")
+
+	// The first bucket kept the tokens the rejected calls took from it
+	clock.Advance(100 * time.Millisecond)
+	require.NoError(t, timeNow(set))
+}
+
+func TestRateLimiter_PolicyLimits(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	registry := newRateLimitRegistry(t)
+	engine := &mockPolicyEngine{
+		decisions: map[string]PolicyDecision{
+			"okra.time.now": {
+				Allowed:  true,
+				Metadata: map[string]interface{}{PolicyRateLimitKey: map[string]interface{}{"requestsPerSecond": 2, "burst": 1}},
+			},
+		},
+	}
+	set := newRateLimitTestSet(t, registry, "acme/orders", clock, engine)
+
+	require.NoError(t, timeNow(set))
+	requireRateLimited(t, timeNow(set), "retry after 500ms")
+
+	clock.Advance(500 * time.Millisecond)
+	require.NoError(t, timeNow(set))
+}
+
+func TestMatchCapability(t *testing.T) {
+	tests := []struct {
+		key     string
+		matches bool
+	}{
+		{"*", true},
+		{"http", true},
+		{"okra.http", true},
+		{"http.*", true},
+		{"okra.http.fetch", true},
+		{"http.fetch", true},
+		{"http.stream", false},
+		{"sql", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.matches, MatchCapability(tt.key, "okra.http", "fetch"), tt.key)
+	}
+}
@@ -4,58 +4,138 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
+	"strings"
 	"sync"
 )
 
-// HostAPIRegistry manages all available host API factories
+// HostAPIRegistry manages all available host API factories. It may hold
+// several versions of the same API, so services can move to a new version
+// while others keep using the old one.
 type HostAPIRegistry interface {
-	// Register adds a new host API factory
+	// Register adds a new host API factory. Factories for the same API must
+	// have distinct versions.
 	Register(factory HostAPIFactory) error
 
-	// Get retrieves a host API factory by name
+	// Get retrieves the latest version of a host API factory by name
 	Get(name string) (HostAPIFactory, bool)
 
-	// List returns all registered API factories
+	// Resolve returns the best version of a host API for a semver range: the
+	// highest matching release, or the highest matching prerelease if no
+	// release matches
+	Resolve(name, versionRange string) (HostAPIFactory, error)
+
+	// List returns all registered API factories, every version included
 	List() []HostAPIFactory
 
-	// CreateHostAPISet creates a set of host API instances for a specific service
+	// CreateHostAPISet creates a set of host API instances for a specific
+	// service, bound to the versions resolved for the ranges the service
+	// declares in okra.json (the latest version when it declares none)
 	CreateHostAPISet(ctx context.Context, apis []string, config HostAPIConfig) (HostAPISet, error)
 }
 
+// DeprecatedHostAPIFactory is implemented by factories for API versions that
+// are still served but should no longer be used
+type DeprecatedHostAPIFactory interface {
+	HostAPIFactory
+
+	// Deprecation explains why the version is deprecated and what to use instead
+	Deprecation() string
+}
+
+// Deprecate marks a factory's version as deprecated. Services that bind to it
+// keep working but log a warning with message.
+func Deprecate(factory HostAPIFactory, message string) HostAPIFactory {
+	return &deprecatedFactory{HostAPIFactory: factory, message: message}
+}
+
+type deprecatedFactory struct {
+	HostAPIFactory
+	message string
+}
+
+func (f *deprecatedFactory) Deprecation() string { return f.message }
+
+// deprecation returns the deprecation message of a factory, if it has one
+func deprecation(factory HostAPIFactory) (string, bool) {
+	deprecated, ok := factory.(DeprecatedHostAPIFactory)
+	if !ok {
+		return "", false
+	}
+	return deprecated.Deprecation(), true
+}
+
 // defaultHostAPIRegistry is the concrete implementation
 type defaultHostAPIRegistry struct {
-	factories map[string]HostAPIFactory
+	factories map[string][]HostAPIFactory // Every registered version of each API
+	limiter   *rateLimiter                // Rate limit counters shared by all sets
+	warned    sync.Map                    // Service and API versions already warned about as deprecated
 	mu        sync.RWMutex
 }
 
 // NewHostAPIRegistry creates a new host API registry
 func NewHostAPIRegistry() HostAPIRegistry {
 	return &defaultHostAPIRegistry{
-		factories: make(map[string]HostAPIFactory),
+		factories: make(map[string][]HostAPIFactory),
+		limiter:   newRateLimiter(),
 	}
 }
 
 // Register adds a new host API factory
 func (r *defaultHostAPIRegistry) Register(factory HostAPIFactory) error {
+	name := factory.Name()
+	version, err := ParseVersion(factory.Version())
+	if err != nil {
+		return fmt.Errorf("host API factory %s: %w", name, err)
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	name := factory.Name()
-	if _, exists := r.factories[name]; exists {
-		return fmt.Errorf("host API factory %s already registered", name)
+	for _, registered := range r.factories[name] {
+		if factoryVersion(registered).Compare(version) == 0 {
+			return fmt.Errorf("host API factory %s %s already registered", name, version)
+		}
 	}
 
-	r.factories[name] = factory
+	r.factories[name] = append(r.factories[name], factory)
 	return nil
 }
 
-// Get retrieves a host API factory by name
+// Get retrieves the latest version of a host API factory by name
 func (r *defaultHostAPIRegistry) Get(name string) (HostAPIFactory, bool) {
+	factory, err := r.Resolve(name, "")
+	return factory, err == nil
+}
+
+// Resolve returns the best version of a host API for a semver range
+func (r *defaultHostAPIRegistry) Resolve(name, versionRange string) (HostAPIFactory, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	return r.resolve(name, versionRange)
+}
+
+func (r *defaultHostAPIRegistry) resolve(name, versionRange string) (HostAPIFactory, error) {
+	versions, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("host API %s not found", name)
+	}
 
-	factory, ok := r.factories[name]
-	return factory, ok
+	rng, err := ParseVersionRange(versionRange)
+	if err != nil {
+		return nil, fmt.Errorf("host API %s: %w", name, err)
+	}
+
+	parsed := make([]Version, len(versions))
+	offered := make([]string, len(versions))
+	for i, factory := range versions {
+		parsed[i] = factoryVersion(factory)
+		offered[i] = parsed[i].String()
+	}
+	if i := bestMatch(parsed, rng); i >= 0 {
+		return versions[i], nil
+	}
+	return nil, fmt.Errorf("host API %s has no version matching %s (offers %s)", name, rng, strings.Join(offered, ", "))
 }
 
 // List returns all registered API factories
@@ -64,8 +144,8 @@ func (r *defaultHostAPIRegistry) List() []HostAPIFactory {
 	defer r.mu.RUnlock()
 
 	factories := make([]HostAPIFactory, 0, len(r.factories))
-	for _, factory := range r.factories {
-		factories = append(factories, factory)
+	for _, versions := range r.factories {
+		factories = append(factories, versions...)
 	}
 	return factories
 }
@@ -75,33 +155,84 @@ func (r *defaultHostAPIRegistry) CreateHostAPISet(ctx context.Context, apis []st
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	var declared map[string]string
+	if cfg := serviceConfig(config); cfg != nil {
+		declared = cfg.HostAPIs
+	}
+
 	hostAPIs := make(map[string]HostAPI)
+	versions := make(map[string]string)
 
 	// Create instances for each requested API
 	for _, apiName := range apis {
-		factory, ok := r.factories[apiName]
-		if !ok {
-			return nil, fmt.Errorf("host API %s not found", apiName)
+		factory, err := r.resolve(apiName, declared[apiName])
+		if err != nil {
+			closeHostAPIs(hostAPIs)
+			return nil, err
 		}
 
 		api, err := factory.Create(ctx, config)
 		if err != nil {
-			// Cleanup already created APIs
-			for _, created := range hostAPIs {
-				if closer, ok := created.(io.Closer); ok {
-					closer.Close()
-				}
-			}
+			closeHostAPIs(hostAPIs)
 			return nil, fmt.Errorf("failed to create %s: %w", apiName, err)
 		}
 
 		hostAPIs[apiName] = api
+		versions[apiName] = factoryVersion(factory).String()
+		r.warnIfDeprecated(config, factory)
 	}
 
-	return &defaultHostAPISet{
+	set := &defaultHostAPISet{
 		apis:      hostAPIs,
+		versions:  versions,
 		iterators: make(map[string]*iteratorInfo),
 		config:    config,
+		limiter:   r.limiter,
 		closed:    false,
-	}, nil
+	}
+	set.startReaper()
+
+	return set, nil
+}
+
+// closeHostAPIs cleans up the APIs created for a set that failed to build
+func closeHostAPIs(hostAPIs map[string]HostAPI) {
+	for _, created := range hostAPIs {
+		if closer, ok := created.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// warnIfDeprecated logs a warning the first time a service binds to a
+// deprecated API version
+func (r *defaultHostAPIRegistry) warnIfDeprecated(config HostAPIConfig, factory HostAPIFactory) {
+	message, ok := deprecation(factory)
+	if !ok {
+		return
+	}
+
+	version := factoryVersion(factory).String()
+	key := config.ServiceName + "\x00" + factory.Name() + "\x00" + version
+	if _, warned := r.warned.LoadOrStore(key, struct{}{}); warned {
+		return
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Warn("service uses a deprecated host API version",
+		"service", config.ServiceName,
+		"api", factory.Name(),
+		"version", version,
+		"deprecation", message,
+	)
+}
+
+// factoryVersion returns the parsed version of a registered factory, which
+// Register has already validated
+func factoryVersion(factory HostAPIFactory) Version {
+	version, _ := ParseVersion(factory.Version())
+	return version
 }
@@ -1,12 +1,17 @@
 package hostapi
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"testing"
 
+	"github.com/okra-platform/okra/internal/config"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metricnoop "go.opentelemetry.io/otel/metric/noop"
@@ -20,6 +25,10 @@ import (
 // 4. Test host API set creation with valid APIs
 // 5. Test host API set creation with missing APIs
 // 6. Test cleanup on failed host API set creation
+// 7. Test several versions of one API resolve to the best match for a range
+// 8. Test sets bind the declared version and carry it to policy checks and audit
+// 9. Test calls asking for a version the set is not bound to are rejected
+// 10. Test binding a deprecated version warns once per service
 
 // Test: Registry creation and basic operations
 func TestHostAPIRegistry_BasicOperations(t *testing.T) {
@@ -74,19 +83,23 @@ func TestHostAPIRegistry_DuplicateRegistration(t *testing.T) {
 	err := registry.Register(factory1)
 	require.NoError(t, err)
 
-	// Duplicate registration should fail
-	factory2 := &mockHostAPIFactory{
-		name:    "test.api", // Same name
-		version: "v2.0.0",
+	// Registering the same version again should fail
+	duplicate := &mockHostAPIFactory{
+		name:    "test.api",
+		version: "1.0.0", // Same version without the "v"
 	}
-	err = registry.Register(factory2)
+	err = registry.Register(duplicate)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "already registered")
+	assert.Contains(t, err.Error(), "test.api v1.0.0 already registered")
 
 	// Verify only the first one is registered
 	retrieved, ok := registry.Get("test.api")
 	assert.True(t, ok)
-	assert.Equal(t, "v1.0.0", retrieved.Version())
+	assert.Same(t, factory1, retrieved)
+
+	// Factories without a valid semantic version are rejected
+	err = registry.Register(&mockHostAPIFactory{name: "test.unversioned", version: "latest"})
+	assert.Error(t, err)
 }
 
 // Test: Concurrent access to registry
@@ -282,12 +295,8 @@ func TestHostAPIRegistry_CreateHostAPISetCleanupOnFailure(t *testing.T) {
 
 	// Track created APIs
 	var createdAPIs []*mockClosableAPI
-	registry.factories["test.api1"] = &mockHostAPIFactory{
-		name:    "test.api1",
-		version: "v1.0.0",
-	}
 	// Override factory1 to track creation
-	registry.factories["test.api1"] = HostAPIFactoryFunc(func(ctx context.Context, config HostAPIConfig) (HostAPI, error) {
+	registry.factories["test.api1"] = []HostAPIFactory{HostAPIFactoryFunc(func(ctx context.Context, config HostAPIConfig) (HostAPI, error) {
 		api := &mockClosableAPI{
 			mockHostAPI: mockHostAPI{
 				name:    "test.api1",
@@ -296,7 +305,7 @@ func TestHostAPIRegistry_CreateHostAPISetCleanupOnFailure(t *testing.T) {
 		}
 		createdAPIs = append(createdAPIs, api)
 		return api, nil
-	})
+	})}
 
 	config := HostAPIConfig{
 		ServiceName:  "test-service",
@@ -329,3 +338,144 @@ func (f HostAPIFactoryFunc) Methods() []MethodMetadata { return nil }
 func (f HostAPIFactoryFunc) Create(ctx context.Context, config HostAPIConfig) (HostAPI, error) {
 	return f(ctx, config)
 }
+
+// versionedRegistry returns a registry holding several versions of test.api
+func versionedRegistry(t *testing.T) HostAPIRegistry {
+	registry := NewHostAPIRegistry()
+	for _, version := range []string{"v1.0.0", "v1.4.0", "v2.0.0", "v3.0.0-beta.1"} {
+		require.NoError(t, registry.Register(&mockHostAPIFactory{name: "test.api", version: version}))
+	}
+	require.NoError(t, registry.Register(Deprecate(&mockHostAPIFactory{name: "test.api", version: "v0.9.0"}, "use v1")))
+	return registry
+}
+
+// Test: Several versions of one API resolve to the best match for a range
+func TestHostAPIRegistry_ResolveVersions(t *testing.T) {
+	registry := versionedRegistry(t)
+	assert.Len(t, registry.List(), 5)
+
+	// Get and an empty range pick the latest release, not the prerelease
+	latest, ok := registry.Get("test.api")
+	require.True(t, ok)
+	assert.Equal(t, "v2.0.0", latest.Version())
+
+	tests := []struct {
+		rng  string
+		want string
+	}{
+		{rng: "", want: "v2.0.0"},
+		{rng: "^1.0.0", want: "v1.4.0"},
+		{rng: "~1.0.0", want: "v1.0.0"},
+		{rng: "<1.0.0", want: "v0.9.0"},
+		{rng: ">=3.0.0-0", want: "v3.0.0-beta.1"},
+	}
+	for _, tt := range tests {
+		factory, err := registry.Resolve("test.api", tt.rng)
+		require.NoError(t, err, tt.rng)
+		assert.Equal(t, tt.want, factory.Version(), tt.rng)
+	}
+
+	_, err := registry.Resolve("test.api", "^4.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no version matching ^4.0.0 (offers")
+
+	_, err = registry.Resolve("test.missing", "")
+	assert.Error(t, err)
+}
+
+// policyRecorder allows every call and keeps the checks it was asked to make
+type policyRecorder struct {
+	checks []PolicyCheck
+}
+
+func (p *policyRecorder) Evaluate(ctx context.Context, check PolicyCheck) (PolicyDecision, error) {
+	p.checks = append(p.checks, check)
+	return PolicyDecision{Allowed: true}, nil
+}
+
+// Test: Sets bind the declared version and carry it to policy checks and audit
+func TestHostAPIRegistry_CreateHostAPISetBindsDeclaredVersion(t *testing.T) {
+	registry := versionedRegistry(t)
+	policy := &policyRecorder{}
+	sink := &recordingAuditSink{}
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{"test.api"}, HostAPIConfig{
+		ServiceName:  "test-service",
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		PolicyEngine: policy,
+		AuditSink:    sink,
+		Config:       &config.Config{HostAPIs: map[string]string{"test.api": "^1.0.0"}},
+	})
+	require.NoError(t, err)
+	defer set.Close()
+
+	api, ok := set.Get("test.api")
+	require.True(t, ok)
+	assert.Equal(t, "v1.4.0", api.Version())
+
+	_, _ = set.Execute(context.Background(), "test.api", "get", json.RawMessage(`{}`))
+	require.Len(t, policy.checks, 1)
+	assert.Equal(t, "v1.4.0", policy.checks[0].Request.Version)
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, "v1.4.0", sink.events[0].APIVersion)
+
+	// A declared range no registered version satisfies fails the set
+	_, err = registry.CreateHostAPISet(context.Background(), []string{"test.api"}, HostAPIConfig{
+		Config: config.Config{HostAPIs: map[string]string{"test.api": "^5.0.0"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no version matching ^5.0.0")
+}
+
+// Test: Calls asking for a version the set is not bound to are rejected
+func TestHostAPISet_RequestedVersion(t *testing.T) {
+	registry := versionedRegistry(t)
+	set, err := registry.CreateHostAPISet(context.Background(), []string{"test.api"}, HostAPIConfig{
+		ServiceName:  "test-service",
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		PolicyEngine: &mockPolicyEngine{},
+	})
+	require.NoError(t, err)
+	defer set.Close()
+
+	ctx := context.WithValue(context.Background(), requestedVersionKey{}, "^1.0.0")
+	_, err = set.Execute(ctx, "test.api", "get", json.RawMessage(`{}`))
+	var apiErr *HostAPIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, ErrorCodeVersionMismatch, apiErr.Code)
+	assert.Contains(t, apiErr.Message, "bound to test.api v2.0.0")
+
+	// A matching range reaches the API, which has no "get" method
+	ctx = context.WithValue(context.Background(), requestedVersionKey{}, "^2.0.0")
+	_, err = set.Execute(ctx, "test.api", "get", json.RawMessage(`{}`))
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "METHOD_NOT_FOUND", apiErr.Code)
+}
+
+// Test: Binding a deprecated version warns once per service
+func TestHostAPIRegistry_DeprecationWarning(t *testing.T) {
+	registry := versionedRegistry(t)
+	var logs bytes.Buffer
+	hostConfig := HostAPIConfig{
+		ServiceName: "legacy-service",
+		Logger:      slog.New(slog.NewTextHandler(&logs, nil)),
+		Config:      &config.Config{HostAPIs: map[string]string{"test.api": "<1.0.0"}},
+	}
+
+	for i := 0; i < 3; i++ {
+		set, err := registry.CreateHostAPISet(context.Background(), []string{"test.api"}, hostConfig)
+		require.NoError(t, err)
+		require.NoError(t, set.Close())
+	}
+
+	assert.Equal(t, 1, strings.Count(logs.String(), "deprecated host API version"))
+	assert.Contains(t, logs.String(), "service=legacy-service api=test.api version=v0.9.0 deprecation=\"use v1\"")
+
+	// The catalog reports the deprecation
+	versions := DescribeRegistry(registry).Versions("test.api")
+	require.Len(t, versions, 5)
+	assert.Equal(t, "v0.9.0", versions[0].Version)
+	assert.Equal(t, "use v1", versions[0].Deprecated)
+}
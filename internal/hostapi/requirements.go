@@ -0,0 +1,31 @@
+package hostapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// CheckRequirements verifies the catalog satisfies every host API a service
+// declares, keyed by name with the version range it needs: some version of
+// each API must be in its range. It returns the declared names, sorted, or an
+// error listing every unmet requirement.
+func (c Catalog) CheckRequirements(declared map[string]string) ([]string, error) {
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if _, err := c.Resolve(name, declared[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("unmet host API requirements: %w", errors.Join(errs...))
+	}
+	return names, nil
+}
@@ -0,0 +1,195 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+const (
+	// SecretsAPIName is the namespace of the secrets host API
+	SecretsAPIName = "okra.secrets"
+
+	// SecretsAPIVersion is the current version of the secrets host API
+	SecretsAPIVersion = "v1.0.0"
+)
+
+// ErrorCodeSecretsUnavailable indicates the secrets provider failed
+const ErrorCodeSecretsUnavailable = "SECRETS_UNAVAILABLE"
+
+// secretKeyPattern restricts keys to alphanumerics, slash, underscore and dash
+var secretKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_\-/]+$`)
+
+// SecretsGetRequest is the payload for secrets.get
+type SecretsGetRequest struct {
+	Key string `json:"key"`
+}
+
+// SecretsGetResponse is the result of secrets.get. Value is null when the secret does not exist.
+type SecretsGetResponse struct {
+	Value *string `json:"value"`
+}
+
+// SecretsConfig holds the code-level limits enforced by the secrets API
+type SecretsConfig struct {
+	MaxKeyLength int // Maximum key length in characters
+}
+
+// SecretsAPIOption configures the secrets API factory
+type SecretsAPIOption func(*secretsAPIFactory)
+
+// WithSecretsProvider sets the provider that secret values are read from
+func WithSecretsProvider(provider SecretsProvider) SecretsAPIOption {
+	return func(f *secretsAPIFactory) {
+		f.provider = provider
+	}
+}
+
+// WithSecretsConfig overrides the default secrets limits
+func WithSecretsConfig(config SecretsConfig) SecretsAPIOption {
+	return func(f *secretsAPIFactory) {
+		f.config = config
+	}
+}
+
+// secretsAPIFactory creates okra.secrets instances sharing one provider
+type secretsAPIFactory struct {
+	config   SecretsConfig
+	provider SecretsProvider
+}
+
+// NewSecretsAPIFactory creates the okra.secrets host API factory.
+// Without WithSecretsProvider no secrets are available.
+func NewSecretsAPIFactory(opts ...SecretsAPIOption) HostAPIFactory {
+	factory := &secretsAPIFactory{
+		config:   SecretsConfig{MaxKeyLength: 256},
+		provider: emptySecretsProvider{},
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	return factory
+}
+
+func (f *secretsAPIFactory) Name() string    { return SecretsAPIName }
+func (f *secretsAPIFactory) Version() string { return SecretsAPIVersion }
+
+// Create builds a secrets API restricted to the keys allowed by the service's okra.json.
+// Services without a secrets section can read nothing.
+func (f *secretsAPIFactory) Create(ctx context.Context, hostConfig HostAPIConfig) (HostAPI, error) {
+	var allowed []string
+	if cfg := serviceConfig(hostConfig); cfg != nil {
+		allowed = cfg.Secrets.AllowedKeys
+	}
+
+	return &secretsAPI{
+		config:   f.config,
+		provider: f.provider,
+		allowed:  allowed,
+	}, nil
+}
+
+func (f *secretsAPIFactory) Methods() []MethodMetadata {
+	return []MethodMetadata{
+		{
+			Name:        "get",
+			Description: "Read a secret value",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"key": *spec.StringProperty().WithMaxLength(int64(f.config.MaxKeyLength)),
+			}, "key"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"value": *spec.StringProperty().WithDescription("Secret value, or null if not found"),
+			}, "value"),
+			Errors: []ErrorMetadata{
+				{Code: ErrorCodeInvalidKey, Description: "Invalid key format"},
+				{Code: ErrorCodeKeyTooLong, Description: "Key exceeds maximum length"},
+				{Code: ErrorCodeKeyNotAllowed, Description: "Key is not in the service allowlist"},
+				{Code: ErrorCodeSecretsUnavailable, Description: "Secrets provider failed"},
+			},
+		},
+	}
+}
+
+// secretsAPI reads allowlisted secrets from the provider
+type secretsAPI struct {
+	config   SecretsConfig
+	provider SecretsProvider
+	allowed  []string
+}
+
+// Compile-time interface compliance checks
+var (
+	_ SensitiveHostAPI = (*secretsAPI)(nil)
+	_ HostAPIFactory   = (*secretsAPIFactory)(nil)
+)
+
+func (s *secretsAPI) Name() string    { return SecretsAPIName }
+func (s *secretsAPI) Version() string { return SecretsAPIVersion }
+
+// Sensitive marks secrets telemetry for redaction by the HostAPISet
+func (s *secretsAPI) Sensitive() bool { return true }
+
+func (s *secretsAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	if method != "get" {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+
+	var req SecretsGetRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	if !matchesAllowlist(s.allowed, req.Key) {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeKeyNotAllowed,
+			Message: fmt.Sprintf("secret %s is not allowed", req.Key),
+		}
+	}
+
+	value, ok, err := s.provider.Get(ctx, req.Key)
+	if err != nil {
+		// Provider errors may echo secret material, so only the key is reported
+		return nil, &HostAPIError{
+			Code:    ErrorCodeSecretsUnavailable,
+			Message: fmt.Sprintf("secret %s could not be read", req.Key),
+		}
+	}
+
+	var resp SecretsGetResponse
+	if ok {
+		resp.Value = &value
+	}
+	return json.Marshal(resp)
+}
+
+// validateKey enforces the code-level key policies
+func (s *secretsAPI) validateKey(key string) error {
+	if len(key) > s.config.MaxKeyLength {
+		return &HostAPIError{
+			Code:    ErrorCodeKeyTooLong,
+			Message: fmt.Sprintf("key length %d exceeds limit %d", len(key), s.config.MaxKeyLength),
+		}
+	}
+
+	if !secretKeyPattern.MatchString(key) || strings.HasPrefix(key, "/") || strings.Contains(key, "//") {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidKey,
+			Message: "key may only contain letters, digits, '_', '-' and '/' and must be a relative path",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package hostapi
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretsProvider resolves secret values for the secrets host API
+type SecretsProvider interface {
+	// Get returns the secret value and whether it exists
+	Get(ctx context.Context, key string) (string, bool, error)
+}
+
+// emptySecretsProvider holds no secrets; every lookup reports not found
+type emptySecretsProvider struct{}
+
+func (emptySecretsProvider) Get(ctx context.Context, key string) (string, bool, error) {
+	return "", false, nil
+}
+
+// directorySecretsProvider reads each secret from a file named after its key,
+// e.g. "db/password" is read from <dir>/db/password. This matches how
+// Kubernetes and Docker mount secrets.
+type directorySecretsProvider struct {
+	dir string
+}
+
+// NewDirectorySecretsProvider creates a provider backed by a directory of files.
+// A single trailing newline is stripped from each value.
+func NewDirectorySecretsProvider(dir string) (SecretsProvider, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secrets directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("secrets path %s is not a directory", dir)
+	}
+	return &directorySecretsProvider{dir: dir}, nil
+}
+
+func (p *directorySecretsProvider) Get(ctx context.Context, key string) (string, bool, error) {
+	if !filepath.IsLocal(key) {
+		return "", false, fmt.Errorf("secret key escapes secrets directory")
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	return strings.TrimSuffix(string(data), "\n"), true, nil
+}
+
+// encryptedFileSecretsProvider serves secrets decrypted once from an
+// AES-256-GCM encrypted JSON object of key/value pairs
+type encryptedFileSecretsProvider struct {
+	secrets map[string]string
+}
+
+// NewEncryptedFileSecretsProvider loads secrets from a file produced by
+// EncryptSecrets using the given 32-byte key
+func NewEncryptedFileSecretsProvider(path string, key []byte) (SecretsProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	gcm, err := newSecretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("secrets file is truncated")
+	}
+
+	plaintext, err := gcm.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets file: %w", err)
+	}
+
+	var secrets map[string]string
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		// Do not wrap: JSON syntax errors can echo fragments of secret values
+		return nil, fmt.Errorf("secrets file does not contain a JSON object of strings")
+	}
+
+	return &encryptedFileSecretsProvider{secrets: secrets}, nil
+}
+
+func (p *encryptedFileSecretsProvider) Get(ctx context.Context, key string) (string, bool, error) {
+	value, ok := p.secrets[key]
+	return value, ok, nil
+}
+
+// EncryptSecrets encodes secrets in the format read by NewEncryptedFileSecretsProvider:
+// a random GCM nonce followed by the sealed JSON object
+func EncryptSecrets(secrets map[string]string, key []byte) ([]byte, error) {
+	gcm, err := newSecretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode secrets: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// newSecretsCipher creates the AES-256-GCM cipher used for secrets files
+func newSecretsCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secrets key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
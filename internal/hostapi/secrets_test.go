@@ -0,0 +1,189 @@
+package hostapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test secrets.get against directory and encrypted file providers
+// 2. Test allowlist enforcement and not-found vs not-allowed responses
+// 3. Test key validation (format, path traversal, length)
+// 4. Test secret values never reach HostAPISet log lines, including on provider failure
+// 5. Test encrypted file error handling (wrong key, truncation, bad size)
+
+// failingSecretsProvider returns an error that embeds a secret value
+type failingSecretsProvider struct {
+	leak string
+}
+
+func (p failingSecretsProvider) Get(ctx context.Context, key string) (string, bool, error) {
+	return "", false, errors.New("backend exploded near " + p.leak)
+}
+
+// writeSecretFiles creates a secrets directory from a key/value map
+func writeSecretFiles(t *testing.T, secrets map[string]string) string {
+	dir := t.TempDir()
+	for key, value := range secrets {
+		path := filepath.Join(dir, filepath.FromSlash(key))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(value), 0600))
+	}
+	return dir
+}
+
+func secretsConfig(allowed ...string) *config.Config {
+	return &config.Config{Secrets: config.SecretsConfig{AllowedKeys: allowed}}
+}
+
+func TestSecretsAPI_DirectoryProvider(t *testing.T) {
+	dir := writeSecretFiles(t, map[string]string{
+		"db/password": "hunter2\n",
+		"api/key":     "sk-123",
+	})
+	provider, err := NewDirectorySecretsProvider(dir)
+	require.NoError(t, err)
+
+	set := newEnvTestSet(t, NewSecretsAPIFactory(WithSecretsProvider(provider)),
+		secretsConfig("db/*", "smtp/password"), slog.Default())
+	ctx := context.Background()
+
+	result, err := set.Execute(ctx, SecretsAPIName, "get", json.RawMessage(`{"key":"db/password"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":"hunter2"}`, string(result))
+
+	// Allowed but missing is distinguishable from not allowed
+	result, err = set.Execute(ctx, SecretsAPIName, "get", json.RawMessage(`{"key":"smtp/password"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":null}`, string(result))
+
+	_, err = set.Execute(ctx, SecretsAPIName, "get", json.RawMessage(`{"key":"api/key"}`))
+	requireHostAPIError(t, err, ErrorCodeKeyNotAllowed)
+}
+
+func TestSecretsAPI_EncryptedFileProvider(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	data, err := EncryptSecrets(map[string]string{"api/key": "sk-live-123"}, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	assert.NotContains(t, string(data), "sk-live-123")
+
+	provider, err := NewEncryptedFileSecretsProvider(path, key)
+	require.NoError(t, err)
+
+	set := newEnvTestSet(t, NewSecretsAPIFactory(WithSecretsProvider(provider)), secretsConfig("api/key"), slog.Default())
+	result, err := set.Execute(context.Background(), SecretsAPIName, "get", json.RawMessage(`{"key":"api/key"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":"sk-live-123"}`, string(result))
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := NewEncryptedFileSecretsProvider(path, bytes.Repeat([]byte{8}, 32))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		_, err := NewEncryptedFileSecretsProvider(path, []byte("short"))
+		assert.ErrorContains(t, err, "32 bytes")
+	})
+
+	t.Run("truncated file", func(t *testing.T) {
+		truncated := filepath.Join(t.TempDir(), "secrets.enc")
+		require.NoError(t, os.WriteFile(truncated, data[:4], 0600))
+		_, err := NewEncryptedFileSecretsProvider(truncated, key)
+		assert.ErrorContains(t, err, "truncated")
+	})
+}
+
+func TestSecretsAPI_Validation(t *testing.T) {
+	set := newEnvTestSet(t, NewSecretsAPIFactory(), secretsConfig("*"), slog.Default())
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		code   string
+	}{
+		{"malformed parameters", "get", `{"key":`, ErrorCodeInvalidParameters},
+		{"empty key", "get", `{"key":""}`, ErrorCodeInvalidKey},
+		{"path traversal", "get", `{"key":"../etc/passwd"}`, ErrorCodeInvalidKey},
+		{"absolute path", "get", `{"key":"/etc/passwd"}`, ErrorCodeInvalidKey},
+		{"empty segment", "get", `{"key":"db//password"}`, ErrorCodeInvalidKey},
+		{"key too long", "get", `{"key":"` + string(bytes.Repeat([]byte("k"), 257)) + `"}`, ErrorCodeKeyTooLong},
+		{"unknown method", "list", `{}`, ErrorCodeMethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := set.Execute(context.Background(), SecretsAPIName, tt.method, json.RawMessage(tt.params))
+			requireHostAPIError(t, err, tt.code)
+		})
+	}
+}
+
+func TestSecretsAPI_NeverLogsValues(t *testing.T) {
+	const secret = "s3cr3t-value"
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	dir := writeSecretFiles(t, map[string]string{"db/password": secret})
+	provider, err := NewDirectorySecretsProvider(dir)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	set := newEnvTestSet(t, NewSecretsAPIFactory(WithSecretsProvider(provider)), secretsConfig("db/password"), logger)
+	_, err = set.Execute(ctx, SecretsAPIName, "get", json.RawMessage(`{"key":"db/password"}`))
+	require.NoError(t, err)
+
+	// A provider error that embeds the value is neither logged nor returned
+	failing := newEnvTestSet(t, NewSecretsAPIFactory(WithSecretsProvider(failingSecretsProvider{leak: secret})),
+		secretsConfig("db/password"), logger)
+	_, err = failing.Execute(ctx, SecretsAPIName, "get", json.RawMessage(`{"key":"db/password"}`))
+	requireHostAPIError(t, err, ErrorCodeSecretsUnavailable)
+	assert.NotContains(t, err.Error(), secret)
+
+	assert.Contains(t, buf.String(), "host API call failed")
+	assert.Contains(t, buf.String(), ErrorCodeSecretsUnavailable)
+	assert.NotContains(t, buf.String(), secret)
+}
+
+func TestSecretsAPI_RedactsErrorMessages(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	set := newEnvTestSet(t, NewSecretsAPIFactory(), secretsConfig(), logger)
+	_, err := set.Execute(context.Background(), SecretsAPIName, "get", json.RawMessage(`{"key":"payment/api_key"}`))
+	requireHostAPIError(t, err, ErrorCodeKeyNotAllowed)
+
+	// The guest sees the full error; telemetry only sees the code
+	assert.Contains(t, err.Error(), "payment/api_key")
+	assert.Contains(t, buf.String(), ErrorCodeKeyNotAllowed)
+	assert.NotContains(t, buf.String(), "payment/api_key")
+}
+
+func TestDirectorySecretsProvider(t *testing.T) {
+	_, err := NewDirectorySecretsProvider(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+	_, err = NewDirectorySecretsProvider(file)
+	assert.Error(t, err)
+
+	provider, err := NewDirectorySecretsProvider(t.TempDir())
+	require.NoError(t, err)
+	_, _, err = provider.Get(context.Background(), "../outside")
+	assert.Error(t, err)
+}
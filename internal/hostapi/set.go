@@ -3,6 +3,7 @@ package hostapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"sync"
@@ -11,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HostAPISet contains all host API instances for a specific service
@@ -27,7 +29,8 @@ type HostAPISet interface {
 	// CloseIterator cleans up iterator resources
 	CloseIterator(ctx context.Context, iteratorID string) error
 
-	// CleanupStaleIterators removes iterators older than the timeout
+	// CleanupStaleIterators removes iterators idle for longer than the timeout.
+	// Sets created by the registry also run it periodically in the background.
 	CleanupStaleIterators() int
 
 	// Config returns the configuration for this host API set
@@ -43,10 +46,16 @@ type HostAPISet interface {
 // because host-side Go code may have concurrent access patterns
 type defaultHostAPISet struct {
 	apis      map[string]HostAPI
+	versions  map[string]string        // Version each API was resolved to
 	iterators map[string]*iteratorInfo // Active iterators
 	config    HostAPIConfig
-	closed    bool // Defensive: tracks if Close() has been called
+	limiter   *rateLimiter // Shared with every set the registry creates (nil = no limits)
+	closed    bool         // Defensive: tracks if Close() has been called
 	mu        sync.RWMutex
+
+	// Background reaper for idle iterators (nil when not started)
+	stopReaper chan struct{}
+	reaperDone chan struct{}
 }
 
 // Compile-time interface compliance checks
@@ -83,37 +92,79 @@ func (s *defaultHostAPISet) Execute(ctx context.Context, apiName, method string,
 			Message: fmt.Sprintf("host API %s not found", apiName),
 		}
 	}
+	version := s.versions[apiName]
+	if err := checkRequestedVersion(ctx, apiName, version); err != nil {
+		return nil, err
+	}
 
 	// Start telemetry span
-	ctx, span := s.config.Tracer.Start(ctx, fmt.Sprintf("host.%s.%s", apiName, method))
+	ctx, span := s.config.Tracer.Start(ctx, fmt.Sprintf("host.%s.%s", apiName, method),
+		trace.WithAttributes(attribute.String("api.version", version)))
 	defer span.End()
 
 	// Policy check
-	serviceInfo, _ := ctx.Value(serviceInfoKey{}).(ServiceInfo)
+	serviceInfo := s.callerInfo(ctx)
+	metadata, _ := RequestMetadataFromContext(ctx)
+	metadata.ServiceInfo = serviceInfo
+
+	// Audit every call that reaches the policy check, whatever its outcome
+	auditStart := time.Now()
+	auditEvent := AuditEvent{
+		Time:           clockOrSystem(s.config.Clock).Now(),
+		Service:        serviceInfo.Name,
+		ServiceVersion: serviceInfo.Version,
+		API:            apiName,
+		APIVersion:     version,
+		Method:         method,
+		TraceID:        metadata.TraceID,
+	}
+	if s.config.AuditSink != nil {
+		auditEvent.Parameters = RedactParameters(parameters, s.auditRedactFields(apiName))
+	}
+
+	checkContext := s.policyContext()
 	decision, err := s.config.PolicyEngine.Evaluate(ctx, PolicyCheck{
 		Service: serviceInfo.Name,
 		Request: HostAPIRequest{
 			API:        apiName,
+			Version:    version,
 			Method:     method,
 			Parameters: parameters,
-			Metadata:   RequestMetadata{ServiceInfo: serviceInfo},
+			Metadata:   metadata,
 		},
-		Context: make(map[string]interface{}),
+		Context: checkContext,
 	})
 
 	if err != nil {
 		span.RecordError(err)
-		return nil, &HostAPIError{
+		policyErr := &HostAPIError{
 			Code:    ErrorCodePolicyError,
 			Message: fmt.Sprintf("policy evaluation failed: %v", err),
 		}
+		s.recordAudit(ctx, auditEvent, auditStart, policyErr)
+		return nil, policyErr
 	}
 
+	auditEvent.Allowed = decision.Allowed
 	if !decision.Allowed {
-		return nil, &HostAPIError{
+		deniedErr := &HostAPIError{
 			Code:    ErrorCodePolicyDenied,
 			Message: decision.Reason,
 		}
+		auditEvent.Reason = decision.Reason
+		s.recordAudit(ctx, auditEvent, auditStart, deniedErr)
+		return nil, deniedErr
+	}
+	ctx = context.WithValue(ctx, policyDecisionKey{}, decision)
+	ctx = context.WithValue(ctx, policyContextKey{}, checkContext)
+
+	// Rate limits and quotas from okra.json and policy
+	if s.limiter != nil {
+		if err := s.limiter.allow(s.config, serviceInfo.Name, apiName, method, decision); err != nil {
+			span.RecordError(err)
+			s.recordAudit(ctx, auditEvent, auditStart, err)
+			return nil, err
+		}
 	}
 
 	// Execute the API method
@@ -142,17 +193,22 @@ func (s *defaultHostAPISet) Execute(ctx context.Context, apiName, method string,
 				if len(s.iterators) >= maxIterators {
 					s.mu.Unlock()
 					iterator.Close() // Clean up the iterator
-					return nil, &HostAPIError{
+					limitErr := &HostAPIError{
 						Code:    ErrorCodeIteratorLimitExceeded,
 						Message: fmt.Sprintf("maximum concurrent iterators (%d) exceeded", maxIterators),
 					}
+					s.recordAudit(ctx, auditEvent, auditStart, limitErr)
+					return nil, limitErr
 				}
 
+				now := clockOrSystem(s.config.Clock).Now()
 				s.iterators[streamResp.IteratorID] = &iteratorInfo{
-					iterator:  iterator,
-					apiName:   apiName,
-					method:    method,
-					createdAt: time.Now(),
+					iterator:   iterator,
+					apiName:    apiName,
+					method:     method,
+					owner:      serviceInfo,
+					createdAt:  now,
+					lastAccess: now,
 				}
 				s.mu.Unlock()
 			}
@@ -162,11 +218,13 @@ func (s *defaultHostAPISet) Execute(ctx context.Context, apiName, method string,
 		result, executeErr = api.Execute(ctx, method, parameters)
 	}
 	duration := time.Since(start)
+	s.recordAudit(ctx, auditEvent, auditStart, executeErr)
 
 	// Record metrics
 	attrs := []attribute.KeyValue{
 		attribute.String("api", apiName),
 		attribute.String("method", method),
+		attribute.String("version", version),
 		attribute.Bool("success", executeErr == nil),
 	}
 
@@ -174,15 +232,21 @@ func (s *defaultHostAPISet) Execute(ctx context.Context, apiName, method string,
 	callCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
 
 	durationHistogram, _ := s.config.Meter.Float64Histogram("host_api_duration_ms")
-	durationHistogram.Record(ctx, float64(duration.Milliseconds()), metric.WithAttributes(attrs[:2]...))
+	durationHistogram.Record(ctx, float64(duration.Milliseconds()), metric.WithAttributes(attrs[:3]...))
 
 	if executeErr != nil {
-		span.RecordError(executeErr)
+		telemetryErr := executeErr
+		if sensitive, ok := api.(SensitiveHostAPI); ok && sensitive.Sensitive() {
+			telemetryErr = redactError(executeErr)
+		}
+
+		span.RecordError(telemetryErr)
 		if s.config.Logger != nil {
 			s.config.Logger.Error("host API call failed",
 				"api", apiName,
+				"version", version,
 				"method", method,
-				"error", executeErr,
+				"error", telemetryErr,
 				"duration_ms", duration.Milliseconds(),
 			)
 		}
@@ -195,36 +259,39 @@ func (s *defaultHostAPISet) Execute(ctx context.Context, apiName, method string,
 // NextIterator advances an iterator and returns the next chunk
 func (s *defaultHostAPISet) NextIterator(ctx context.Context, iteratorID string) (json.RawMessage, bool, error) {
 	// Defensive check with proper locking
-	s.mu.RLock()
+	s.mu.Lock()
 	if s.closed {
-		s.mu.RUnlock()
+		s.mu.Unlock()
 		return nil, false, &HostAPIError{
 			Code:    ErrorCodeHostAPISetClosed,
 			Message: "HostAPISet has been closed",
 		}
 	}
 
-	// Get iterator while holding read lock
 	info, ok := s.iterators[iteratorID]
-	s.mu.RUnlock()
-
 	if !ok {
+		s.mu.Unlock()
 		return nil, false, &HostAPIError{
 			Code:    ErrorCodeIteratorNotFound,
 			Message: fmt.Sprintf("iterator %s not found", iteratorID),
 		}
 	}
 
-	// Validate iterator belongs to calling service (from context)
-	if serviceInfo, ok := ctx.Value(serviceInfoKey{}).(ServiceInfo); ok {
-		// In practice, we'd store service info with the iterator and validate here
-		// For now, we'll add a TODO
-		// TODO: Add service validation to prevent cross-service iterator access
-		_ = serviceInfo
+	// Only the service that created the iterator may advance it
+	if err := checkIteratorOwner(info, s.callerInfo(ctx), iteratorID); err != nil {
+		s.mu.Unlock()
+		return nil, false, err
 	}
 
+	// Mark the iterator in use so the reaper leaves it alone while Next runs
+	info.inUse++
+	info.lastAccess = clockOrSystem(s.config.Clock).Now()
+	s.mu.Unlock()
+
 	// Start telemetry span
-	ctx, span := s.config.Tracer.Start(ctx, fmt.Sprintf("host.%s.%s.next", info.apiName, info.method))
+	version := s.versions[info.apiName]
+	ctx, span := s.config.Tracer.Start(ctx, fmt.Sprintf("host.%s.%s.next", info.apiName, info.method),
+		trace.WithAttributes(attribute.String("api.version", version)))
 	defer span.End()
 
 	// Get next chunk
@@ -232,10 +299,30 @@ func (s *defaultHostAPISet) NextIterator(ctx context.Context, iteratorID string)
 	data, hasMore, err := info.iterator.Next(ctx)
 	duration := time.Since(start)
 
+	// A slow Next counts as activity too
+	s.mu.Lock()
+	info.inUse--
+	info.lastAccess = clockOrSystem(s.config.Clock).Now()
+	s.mu.Unlock()
+
+	metadata, _ := RequestMetadataFromContext(ctx)
+	s.recordAudit(ctx, AuditEvent{
+		Time:           clockOrSystem(s.config.Clock).Now(),
+		Service:        s.config.ServiceName,
+		ServiceVersion: s.config.ServiceVersion,
+		API:            info.apiName,
+		APIVersion:     version,
+		Method:         info.method,
+		IteratorID:     iteratorID,
+		Allowed:        true,
+		TraceID:        metadata.TraceID,
+	}, start, err)
+
 	// Record metrics
 	attrs := []attribute.KeyValue{
 		attribute.String("api", info.apiName),
 		attribute.String("method", info.method),
+		attribute.String("version", version),
 		attribute.Bool("success", err == nil),
 		attribute.Bool("has_more", hasMore),
 	}
@@ -244,7 +331,7 @@ func (s *defaultHostAPISet) NextIterator(ctx context.Context, iteratorID string)
 	iteratorCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
 
 	iteratorDurationHistogram, _ := s.config.Meter.Float64Histogram("host_api_iterator_duration_ms")
-	iteratorDurationHistogram.Record(ctx, float64(duration.Milliseconds()), metric.WithAttributes(attrs[:2]...))
+	iteratorDurationHistogram.Record(ctx, float64(duration.Milliseconds()), metric.WithAttributes(attrs[:3]...))
 
 	if err != nil {
 		span.RecordError(err)
@@ -271,6 +358,11 @@ func (s *defaultHostAPISet) CloseIterator(ctx context.Context, iteratorID string
 		return nil // Already closed or never existed
 	}
 
+	if err := checkIteratorOwner(info, s.callerInfo(ctx), iteratorID); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
 	delete(s.iterators, iteratorID)
 	s.mu.Unlock()
 
@@ -289,19 +381,19 @@ func (s *defaultHostAPISet) CloseIterator(ctx context.Context, iteratorID string
 	return err
 }
 
-// CleanupStaleIterators removes iterators older than the timeout
+// CleanupStaleIterators removes iterators idle for longer than the timeout
 func (s *defaultHostAPISet) CleanupStaleIterators() int {
 	timeout := s.config.IteratorTimeout
 	if timeout == 0 {
 		timeout = DefaultIteratorTimeout
 	}
 
-	now := time.Now()
+	now := clockOrSystem(s.config.Clock).Now()
 
 	s.mu.Lock()
 	staleIterators := make(map[string]*iteratorInfo)
 	for id, info := range s.iterators {
-		if now.Sub(info.createdAt) > timeout {
+		if info.inUse == 0 && now.Sub(info.lastAccess) > timeout {
 			staleIterators[id] = info
 			delete(s.iterators, id)
 		}
@@ -316,7 +408,7 @@ func (s *defaultHostAPISet) CleanupStaleIterators() int {
 					"iterator_id", id,
 					"api", info.apiName,
 					"method", info.method,
-					"age", now.Sub(info.createdAt),
+					"idle", now.Sub(info.lastAccess),
 					"error", err,
 				)
 			}
@@ -337,6 +429,8 @@ func (s *defaultHostAPISet) Close() error {
 
 	// Mark as closed first to prevent new operations
 	s.closed = true
+	stopReaper, reaperDone := s.stopReaper, s.reaperDone
+	s.stopReaper = nil
 
 	// Copy iterators to close outside of lock
 	iteratorsToClose := make(map[string]*iteratorInfo)
@@ -346,6 +440,12 @@ func (s *defaultHostAPISet) Close() error {
 	s.iterators = nil
 	s.mu.Unlock()
 
+	// Stop the reaper before closing iterators it might also be closing
+	if stopReaper != nil {
+		close(stopReaper)
+		<-reaperDone
+	}
+
 	var errs []error
 
 	// Close all active iterators
@@ -371,6 +471,66 @@ func (s *defaultHostAPISet) Close() error {
 	return nil
 }
 
+// startReaper closes idle iterators in the background until the set is closed
+func (s *defaultHostAPISet) startReaper() {
+	timeout := s.config.IteratorTimeout
+	if timeout == 0 {
+		timeout = DefaultIteratorTimeout
+	}
+
+	s.stopReaper = make(chan struct{})
+	s.reaperDone = make(chan struct{})
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+
+		// Checking twice per timeout bounds how long an idle iterator outlives it
+		ticker := time.NewTicker(timeout / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if reaped := s.CleanupStaleIterators(); reaped > 0 && s.config.Logger != nil {
+					s.config.Logger.Debug("reaped idle iterators", "count", reaped)
+				}
+			}
+		}
+	}(s.stopReaper, s.reaperDone)
+}
+
+// callerInfo identifies the service making a call, defaulting to the set's own service
+func (s *defaultHostAPISet) callerInfo(ctx context.Context) ServiceInfo {
+	if serviceInfo, ok := ctx.Value(serviceInfoKey{}).(ServiceInfo); ok {
+		return serviceInfo
+	}
+	return ServiceInfo{Name: s.config.ServiceName, Version: s.config.ServiceVersion}
+}
+
+// checkIteratorOwner rejects callers other than the service that created the iterator
+func checkIteratorOwner(info *iteratorInfo, caller ServiceInfo, iteratorID string) error {
+	if info.owner.Name == caller.Name {
+		return nil
+	}
+	return &HostAPIError{
+		Code:    ErrorCodeIteratorAccessDenied,
+		Message: fmt.Sprintf("iterator %s belongs to another service", iteratorID),
+	}
+}
+
+// policyContext is the context every policy check of this set starts from
+func (s *defaultHostAPISet) policyContext() map[string]interface{} {
+	checkContext := map[string]interface{}{
+		"environment": s.config.Environment,
+		"time":        clockOrSystem(s.config.Clock).Now(),
+	}
+	if cfg := serviceConfig(s.config); cfg != nil {
+		checkContext["tags"] = cfg.Tags
+	}
+	return checkContext
+}
+
 // Config returns the configuration for this host API set
 func (s *defaultHostAPISet) Config() HostAPIConfig {
 	return s.config
@@ -378,13 +538,63 @@ func (s *defaultHostAPISet) Config() HostAPIConfig {
 
 // Context keys for passing data through the call stack
 type (
-	hostAPISetKey  struct{}
-	serviceInfoKey struct{}
-	memoryKey      struct{}
-	moduleKey      struct{}
-	iteratorKey    struct{ id string }
+	hostAPISetKey       struct{}
+	serviceInfoKey      struct{}
+	requestMetadataKey  struct{}
+	requestedVersionKey struct{}
+	policyDecisionKey   struct{}
+	policyContextKey    struct{}
+	memoryKey           struct{}
+	moduleKey           struct{}
+	iteratorKey         struct{ id string }
 )
 
+// RequestMetadataFromContext returns the guest-supplied metadata for the
+// host API call in progress, if any
+func RequestMetadataFromContext(ctx context.Context) (RequestMetadata, bool) {
+	metadata, ok := ctx.Value(requestMetadataKey{}).(RequestMetadata)
+	return metadata, ok
+}
+
+// checkRequestedVersion rejects a call whose guest asked for a version range
+// the service's binding of the API does not satisfy
+func checkRequestedVersion(ctx context.Context, apiName, version string) error {
+	requested, ok := ctx.Value(requestedVersionKey{}).(string)
+	if !ok || version == "" {
+		return nil
+	}
+
+	rng, err := ParseVersionRange(requested)
+	if err != nil {
+		return &HostAPIError{Code: ErrorCodeInvalidParameters, Message: err.Error()}
+	}
+	bound, err := ParseVersion(version)
+	if err != nil || !rng.Contains(bound) {
+		return &HostAPIError{
+			Code:    ErrorCodeVersionMismatch,
+			Message: fmt.Sprintf("service is bound to %s %s, call requires %s", apiName, version, rng),
+		}
+	}
+	return nil
+}
+
+// PolicyDecisionFromContext returns the policy decision that allowed the host
+// API call in progress, so APIs can apply constraints from its Metadata
+func PolicyDecisionFromContext(ctx context.Context) (PolicyDecision, bool) {
+	decision, ok := ctx.Value(policyDecisionKey{}).(PolicyDecision)
+	return decision, ok
+}
+
+// redactError strips everything but the error code so confidential values
+// embedded in messages never reach telemetry
+func redactError(err error) error {
+	var apiErr *HostAPIError
+	if errors.As(err, &apiErr) {
+		return &HostAPIError{Code: apiErr.Code, Message: "[redacted]"}
+	}
+	return &HostAPIError{Code: ErrorCodeInternalError, Message: "[redacted]"}
+}
+
 // Helper function to generate iterator IDs
 func generateIteratorID() string {
 	return uuid.New().String()
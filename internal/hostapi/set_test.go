@@ -25,6 +25,9 @@ import (
 // 6. Test resource limits (max iterators)
 // 7. Test concurrent access
 // 8. Test closing behavior and idempotency
+// 9. Test iterators are bound to the owning service
+// 10. Test the background reaper closes idle iterators only
+// 11. Test the reaper never closes an iterator while Next is running
 
 // Test: Basic execute functionality
 func TestHostAPISet_Execute(t *testing.T) {
@@ -409,21 +412,24 @@ func TestHostAPISet_CloseIterator(t *testing.T) {
 func TestHostAPISet_CleanupStaleIterators(t *testing.T) {
 	now := time.Now()
 
-	// Create iterators with different ages
+	// Create iterators with different idle times; an old but active iterator is kept
 	apiSet := &defaultHostAPISet{
 		apis: make(map[string]HostAPI),
 		iterators: map[string]*iteratorInfo{
 			"fresh": {
-				iterator:  &mockIterator{},
-				createdAt: now.Add(-1 * time.Minute),
+				iterator:   &mockIterator{},
+				createdAt:  now.Add(-30 * time.Minute),
+				lastAccess: now.Add(-1 * time.Minute),
 			},
 			"stale1": {
-				iterator:  &mockIterator{},
-				createdAt: now.Add(-10 * time.Minute),
+				iterator:   &mockIterator{},
+				createdAt:  now.Add(-10 * time.Minute),
+				lastAccess: now.Add(-10 * time.Minute),
 			},
 			"stale2": {
-				iterator:  &mockIterator{},
-				createdAt: now.Add(-15 * time.Minute),
+				iterator:   &mockIterator{},
+				createdAt:  now.Add(-15 * time.Minute),
+				lastAccess: now.Add(-6 * time.Minute),
 			},
 		},
 		config: HostAPIConfig{
@@ -664,3 +670,164 @@ var (
 	_ io.Closer = (*mockClosableAPI)(nil)
 	_ io.Closer = (*failingClosableAPI)(nil)
 )
+
+// streamingTestSet creates a registry-backed set whose "list" method opens iterators
+func streamingTestSet(t *testing.T, clock Clock, timeout time.Duration, items int) HostAPISet {
+	return streamingTestSetWith(t, clock, timeout, func() Iterator {
+		data := make([]json.RawMessage, items)
+		for i := range data {
+			data[i] = json.RawMessage(`{"item":1}`)
+		}
+		return &mockIterator{data: data}
+	})
+}
+
+// streamingTestSetWith is streamingTestSet with every list call returning
+// the iterator newIterator creates
+func streamingTestSetWith(t *testing.T, clock Clock, timeout time.Duration, newIterator func() Iterator) HostAPISet {
+	factory := &testStreamingHostAPIFactory{
+		testHostAPIFactory: testHostAPIFactory{
+			name:    "test.streaming",
+			version: "1.0.0",
+			createFunc: func(ctx context.Context, config HostAPIConfig) (HostAPI, error) {
+				return &mockStreamingAPI{
+					mockHostAPI: mockHostAPI{name: "test.streaming", version: "1.0.0"},
+					streamingMethods: map[string]func(ctx context.Context, params json.RawMessage) (json.RawMessage, Iterator, error){
+						"list": func(ctx context.Context, params json.RawMessage) (json.RawMessage, Iterator, error) {
+							resp, err := json.Marshal(StreamingResponse{IteratorID: generateIteratorID(), HasData: true})
+							return resp, newIterator(), err
+						},
+					},
+				}, nil
+			},
+		},
+	}
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(factory))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{"test.streaming"}, HostAPIConfig{
+		ServiceName:     "acme/orders",
+		PolicyEngine:    &mockPolicyEngine{},
+		Tracer:          tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:           metricnoop.NewMeterProvider().Meter("test"),
+		Clock:           clock,
+		IteratorTimeout: timeout,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+func openTestIterator(t *testing.T, set HostAPISet) string {
+	t.Helper()
+	result, err := set.Execute(context.Background(), "test.streaming", "list", nil)
+	require.NoError(t, err)
+
+	var resp StreamingResponse
+	require.NoError(t, json.Unmarshal(result, &resp))
+	require.NotEmpty(t, resp.IteratorID)
+	return resp.IteratorID
+}
+
+// Test: Iterators can only be used by the service that created them
+func TestHostAPISet_IteratorOwnership(t *testing.T) {
+	set := streamingTestSet(t, nil, time.Minute, 5)
+	iteratorID := openTestIterator(t, set)
+
+	other := context.WithValue(context.Background(), serviceInfoKey{}, ServiceInfo{Name: "acme/billing"})
+	_, _, err := set.NextIterator(other, iteratorID)
+	requireHostAPIError(t, err, ErrorCodeIteratorAccessDenied)
+	requireHostAPIError(t, set.CloseIterator(other, iteratorID), ErrorCodeIteratorAccessDenied)
+
+	// The owner, explicit or implied by the set, still has access
+	owner := context.WithValue(context.Background(), serviceInfoKey{}, ServiceInfo{Name: "acme/orders"})
+	_, hasMore, err := set.NextIterator(owner, iteratorID)
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	require.NoError(t, set.CloseIterator(context.Background(), iteratorID))
+
+	_, _, err = set.NextIterator(owner, iteratorID)
+	requireHostAPIError(t, err, ErrorCodeIteratorNotFound)
+}
+
+// Test: The reaper closes iterators by idle time, not age
+func TestHostAPISet_IteratorReaper(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	set := streamingTestSet(t, clock, 20*time.Millisecond, 100)
+	ctx := context.Background()
+
+	active := openTestIterator(t, set)
+	idle := openTestIterator(t, set)
+
+	// Keep one iterator busy while the clock moves well past the timeout
+	for i := 0; i < 5; i++ {
+		clock.Advance(15 * time.Millisecond)
+		_, _, err := set.NextIterator(ctx, active)
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool {
+		_, _, err := set.NextIterator(ctx, idle)
+		return err != nil
+	}, 2*time.Second, 5*time.Millisecond, "idle iterator is reaped")
+
+	// The active iterator was last used just now, so it survives
+	_, _, err := set.NextIterator(ctx, active)
+	require.NoError(t, err)
+
+	clock.Advance(time.Minute)
+	require.Eventually(t, func() bool {
+		_, _, err := set.NextIterator(ctx, active)
+		return err != nil
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+// blockingIterator's Next waits for release, failing if it was closed
+// meanwhile
+type blockingIterator struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+	closed  bool
+}
+
+func (it *blockingIterator) Next(ctx context.Context) (json.RawMessage, bool, error) {
+	it.once.Do(func() { close(it.started) })
+	<-it.release
+	if it.closed {
+		return nil, false, errors.New("iterator closed during Next")
+	}
+	return json.RawMessage(`{"item":1}`), true, nil
+}
+
+func (it *blockingIterator) Close() error {
+	it.closed = true
+	return nil
+}
+
+// Test: A slow Next keeps its iterator from the reaper, however long it runs
+func TestHostAPISet_IteratorReaperSkipsInUse(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	iterator := &blockingIterator{started: make(chan struct{}), release: make(chan struct{})}
+	set := streamingTestSetWith(t, clock, time.Hour, func() Iterator { return iterator })
+	ctx := context.Background()
+	iteratorID := openTestIterator(t, set)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := set.NextIterator(ctx, iteratorID)
+		done <- err
+	}()
+	<-iterator.started
+
+	clock.Advance(2 * time.Hour)
+	assert.Zero(t, set.CleanupStaleIterators())
+
+	close(iterator.release)
+	require.NoError(t, <-done)
+
+	// Once Next returns the iterator is idle again, and reaped as usual
+	clock.Advance(2 * time.Hour)
+	assert.Equal(t, 1, set.CleanupStaleIterators())
+	assert.True(t, iterator.closed)
+}
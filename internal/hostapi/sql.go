@@ -0,0 +1,685 @@
+package hostapi
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-openapi/spec"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	// SQLAPIName is the namespace of the SQL host API
+	SQLAPIName = "okra.sql"
+
+	// SQLAPIVersion is the current version of the SQL host API
+	SQLAPIVersion = "v1.0.0"
+)
+
+// SQL API error codes
+const (
+	ErrorCodeSQLSyntax              = "SQL_SYNTAX_ERROR"
+	ErrorCodeSQLTimeout             = "SQL_TIMEOUT"
+	ErrorCodeSQLConnection          = "SQL_CONNECTION_ERROR"
+	ErrorCodeSQLConstraintViolation = "SQL_CONSTRAINT_VIOLATION"
+)
+
+// SQLResult is the result of sql.mutate and sql.raw
+type SQLResult struct {
+	Rows     []map[string]interface{} `json:"rows"`
+	RowCount int64                    `json:"rowCount"`
+}
+
+// SQLQueryResponse is the result of sql.query. The first batch of rows is returned
+// inline; when more rows exist, the rest are read through the iterator.
+type SQLQueryResponse struct {
+	Rows       []map[string]interface{} `json:"rows"`
+	RowCount   int64                    `json:"rowCount"`
+	HasMore    bool                     `json:"hasMore"`
+	IteratorID string                   `json:"iteratorId,omitempty"`
+	HasData    bool                     `json:"hasData"`
+}
+
+// SQLRowsChunk is a single chunk returned by the sql.query iterator
+type SQLRowsChunk struct {
+	Rows []map[string]interface{} `json:"rows"`
+}
+
+// SQLRawRequest is the payload for sql.raw
+type SQLRawRequest struct {
+	SQL        string        `json:"sql"`
+	Parameters []interface{} `json:"parameters,omitempty"`
+}
+
+// SQLConfig holds the code-level limits enforced by the SQL API
+type SQLConfig struct {
+	QueryTimeout time.Duration // Hard limit per statement
+	MaxRows      int           // Maximum rows a single query may return
+	BatchSize    int           // Rows per streamed chunk
+	MaxRawLength int           // Maximum sql.raw statement length
+	MaxRawRows   int           // Maximum rows sql.raw may return
+	MaxOpenConns int           // Maximum connections per service database
+}
+
+// defaultSQLConfig returns the limits described in docs/host-apis/sql.md
+func defaultSQLConfig() SQLConfig {
+	return SQLConfig{
+		QueryTimeout: 30 * time.Second,
+		MaxRows:      1000000,
+		BatchSize:    100,
+		MaxRawLength: 10000,
+		MaxRawRows:   10000,
+		MaxOpenConns: 4,
+	}
+}
+
+// SQLAPIOption configures the SQL API factory
+type SQLAPIOption func(*sqlAPIFactory)
+
+// WithSQLDataDir stores each service's database in dir as <service>.db.
+// Without it, databases live in memory for the lifetime of the factory.
+func WithSQLDataDir(dir string) SQLAPIOption {
+	return func(f *sqlAPIFactory) {
+		f.dataDir = dir
+	}
+}
+
+// WithSQLDatabase serves every service from the SQLite database at path,
+// typically the project database that okra db:migrate applies migrations to.
+// It takes precedence over WithSQLDataDir.
+func WithSQLDatabase(path string) SQLAPIOption {
+	return func(f *sqlAPIFactory) {
+		f.databasePath = path
+	}
+}
+
+// WithSQLConfig overrides the default SQL limits
+func WithSQLConfig(config SQLConfig) SQLAPIOption {
+	return func(f *sqlAPIFactory) {
+		f.config = config
+	}
+}
+
+// sqlAPIFactory creates okra.sql instances. Each service gets one database,
+// shared by all of its workers.
+type sqlAPIFactory struct {
+	config       SQLConfig
+	dataDir      string
+	databasePath string
+
+	mu  sync.Mutex
+	dbs map[string]*sql.DB
+}
+
+// NewSQLAPIFactory creates the okra.sql host API factory backed by SQLite
+func NewSQLAPIFactory(opts ...SQLAPIOption) HostAPIFactory {
+	factory := &sqlAPIFactory{
+		config: defaultSQLConfig(),
+		dbs:    make(map[string]*sql.DB),
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	return factory
+}
+
+func (f *sqlAPIFactory) Name() string    { return SQLAPIName }
+func (f *sqlAPIFactory) Version() string { return SQLAPIVersion }
+
+func (f *sqlAPIFactory) Create(ctx context.Context, hostConfig HostAPIConfig) (HostAPI, error) {
+	if hostConfig.ServiceName == "" {
+		return nil, fmt.Errorf("service name is required for %s", SQLAPIName)
+	}
+
+	db, err := f.database(hostConfig.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlAPI{
+		db:           db,
+		config:       f.config,
+		service:      hostConfig.ServiceName,
+		policyEngine: hostConfig.PolicyEngine,
+	}, nil
+}
+
+// database returns the service's database, opening it on first use
+func (f *sqlAPIFactory) database(service string) (*sql.DB, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Services share the database at databasePath, so it is opened once
+	key := service
+	if f.databasePath != "" {
+		key = ""
+	}
+	if db, ok := f.dbs[key]; ok {
+		return db, nil
+	}
+
+	var db *sql.DB
+	var err error
+	if f.databasePath == "" && f.dataDir == "" {
+		db, err = sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)")
+		if err == nil {
+			// Every connection to :memory: is a separate database, so keep exactly one
+			db.SetMaxOpenConns(1)
+			db.SetConnMaxIdleTime(0)
+		}
+	} else {
+		path := f.databasePath
+		if path == "" {
+			name := filepath.FromSlash(service) + ".db"
+			if !filepath.IsLocal(name) {
+				return nil, fmt.Errorf("invalid service name for database: %s", service)
+			}
+			path = filepath.Join(f.dataDir, name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+		db, err = sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+		if err == nil {
+			db.SetMaxOpenConns(f.config.MaxOpenConns)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database for %s: %w", service, err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open database for %s: %w", service, err)
+	}
+
+	f.dbs[key] = db
+	return db, nil
+}
+
+// Close closes the databases opened for services
+func (f *sqlAPIFactory) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var errs []error
+	for key, db := range f.dbs {
+		errs = append(errs, db.Close())
+		delete(f.dbs, key)
+	}
+	return errors.Join(errs...)
+}
+
+func (f *sqlAPIFactory) Methods() []MethodMetadata {
+	rowsSchema := *spec.ArrayProperty(spec.MapProperty(nil))
+	resultSchema := objectSchema(map[string]spec.Schema{
+		"rows":     rowsSchema,
+		"rowCount": *spec.Int64Property(),
+	})
+	conditionSchema := anySchema("SqlCondition: {and}, {or}, {not} or {column, op, value, subquery}")
+
+	errorCodes := []ErrorMetadata{
+		{Code: ErrorCodeSQLSyntax, Description: "Statement is invalid or references unknown tables or columns"},
+		{Code: ErrorCodeSQLTimeout, Description: "Statement exceeded the query timeout"},
+		{Code: ErrorCodeSQLConnection, Description: "Database is unavailable"},
+		{Code: ErrorCodeSQLConstraintViolation, Description: "Statement violated a database constraint"},
+		{Code: ErrorCodePolicyDenied, Description: "Table is reserved or capability is not granted"},
+	}
+
+	return []MethodMetadata{
+		{
+			Name:        "query",
+			Description: "Run a declarative SELECT query; rows beyond the first batch are streamed",
+			Streaming:   true,
+			Parameters: objectSchema(map[string]spec.Schema{
+				"table":           *spec.StringProperty(),
+				"columns":         *spec.ArrayProperty(spec.StringProperty()),
+				"where":           conditionSchema,
+				"join":            *spec.ArrayProperty(anySchemaRef("SqlJoin: {type, table, on: {localColumn, foreignColumn}}")),
+				"orderBy":         *spec.ArrayProperty(anySchemaRef("SqlOrderBy: {column, direction}")),
+				"groupBy":         *spec.ArrayProperty(spec.StringProperty()),
+				"limit":           *spec.Int64Property(),
+				"offset":          *spec.Int64Property(),
+				"aggregate":       *spec.ArrayProperty(anySchemaRef("SqlAggregate: {function, column, alias}")),
+				"allowSubqueries": *spec.BoolProperty(),
+			}, "table"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"rows":       rowsSchema,
+				"rowCount":   *spec.Int64Property(),
+				"hasMore":    *spec.BoolProperty(),
+				"iteratorId": *spec.StringProperty().WithDescription("ID to pass to okra.next when hasMore is true"),
+				"hasData":    *spec.BoolProperty(),
+			}),
+			Errors: append(errorCodes, ErrorMetadata{Code: ErrorCodeResponseTooLarge, Description: "Result exceeds the maximum row count"}),
+		},
+		{
+			Name:        "mutate",
+			Description: "Insert, update or delete rows",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"table":     *spec.StringProperty(),
+				"action":    *spec.StringProperty().WithEnum("insert", "update", "delete"),
+				"id":        anySchema("Primary key for update/delete"),
+				"values":    *spec.MapProperty(nil),
+				"where":     conditionSchema,
+				"returning": *spec.ArrayProperty(spec.StringProperty()),
+			}, "table", "action"),
+			Returns: resultSchema,
+			Errors:  errorCodes,
+		},
+		{
+			Name:        "raw",
+			Description: "Run a raw parameterized SQL statement; requires the sql.raw capability",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"sql":        *spec.StringProperty().WithMaxLength(int64(f.config.MaxRawLength)),
+				"parameters": *spec.ArrayProperty(nil),
+			}, "sql"),
+			Returns: resultSchema,
+			Errors:  append(errorCodes, ErrorMetadata{Code: ErrorCodeResponseTooLarge, Description: "Result exceeds the maximum row count"}),
+		},
+	}
+}
+
+// anySchemaRef is anySchema as a pointer, for array item schemas
+func anySchemaRef(description string) *spec.Schema {
+	schema := anySchema(description)
+	return &schema
+}
+
+// sqlAPI is a service-scoped handle on the service's database
+type sqlAPI struct {
+	db           *sql.DB
+	config       SQLConfig
+	service      string
+	policyEngine PolicyEngine
+}
+
+// Compile-time interface compliance checks
+var (
+	_ StreamingHostAPI = (*sqlAPI)(nil)
+	_ HostAPIFactory   = (*sqlAPIFactory)(nil)
+)
+
+func (s *sqlAPI) Name() string    { return SQLAPIName }
+func (s *sqlAPI) Version() string { return SQLAPIVersion }
+
+// Execute runs a method to completion. query returns every row up to MaxRows.
+func (s *sqlAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	switch method {
+	case "query":
+		pager, err := s.newPager(parameters)
+		if err != nil {
+			return nil, err
+		}
+		pager.batch = pager.remaining
+
+		rows, _, err := pager.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(SQLResult{Rows: rows, RowCount: int64(len(rows))})
+	case "mutate":
+		return s.executeMutate(ctx, parameters)
+	case "raw":
+		return s.executeRaw(ctx, parameters)
+	default:
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+}
+
+// ExecuteStreaming implements StreamingHostAPI for query
+func (s *sqlAPI) ExecuteStreaming(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, Iterator, error) {
+	if method != "query" {
+		// Non-streaming methods fall back to Execute
+		result, err := s.Execute(ctx, method, parameters)
+		return result, nil, err
+	}
+
+	pager, err := s.newPager(parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, hasMore, err := pager.fetch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp := SQLQueryResponse{
+		Rows:     rows,
+		RowCount: int64(len(rows)),
+		HasMore:  hasMore,
+		HasData:  len(rows) > 0,
+	}
+	if !hasMore {
+		result, err := json.Marshal(resp)
+		return result, nil, err
+	}
+
+	resp.IteratorID = generateIteratorID()
+	result, err := json.Marshal(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, &sqlRowsIterator{pager: pager}, nil
+}
+
+// newPager validates a declarative query and prepares it for paged reads
+func (s *sqlAPI) newPager(parameters json.RawMessage) (*sqlPager, error) {
+	var query SQLQuery
+	if err := unmarshalSQLParameters(parameters, &query); err != nil {
+		return nil, err
+	}
+
+	builder, err := buildSelect(&query, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// An explicit limit within MaxRows is honoured exactly; otherwise
+	// MaxRows is a hard cap and exceeding it is an error
+	remaining, capped := query.Limit, false
+	if remaining == 0 || remaining > s.config.MaxRows {
+		remaining, capped = s.config.MaxRows, true
+	}
+
+	return &sqlPager{
+		api:       s,
+		query:     builder,
+		offset:    query.Offset,
+		remaining: remaining,
+		capped:    capped,
+		batch:     s.config.BatchSize,
+	}, nil
+}
+
+func (s *sqlAPI) executeMutate(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var mutation SQLMutation
+	if err := unmarshalSQLParameters(parameters, &mutation); err != nil {
+		return nil, err
+	}
+
+	statement, err := buildMutation(&mutation)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := statement.ToSql()
+	if err != nil {
+		return nil, sqlBuildError("%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.QueryTimeout)
+	defer cancel()
+
+	if len(mutation.Returning) > 0 {
+		rows, err := s.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, sqlError(ctx, err)
+		}
+		result, err := scanRows(rows, s.config.MaxRows)
+		if err != nil {
+			return nil, sqlError(ctx, err)
+		}
+		return json.Marshal(SQLResult{Rows: result, RowCount: int64(len(result))})
+	}
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, sqlError(ctx, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, sqlError(ctx, err)
+	}
+
+	return json.Marshal(SQLResult{Rows: []map[string]interface{}{}, RowCount: affected})
+}
+
+func (s *sqlAPI) executeRaw(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	if err := requireCapability(ctx, s.policyEngine, s.service, SQLAPIName, "raw", parameters, CapabilitySQLRaw, nil); err != nil {
+		return nil, err
+	}
+
+	var req SQLRawRequest
+	if err := unmarshalSQLParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	if req.SQL == "" {
+		return nil, sqlBuildError("sql cannot be empty")
+	}
+	if len(req.SQL) > s.config.MaxRawLength {
+		return nil, sqlBuildError("sql length %d exceeds limit %d", len(req.SQL), s.config.MaxRawLength)
+	}
+
+	args := make([]interface{}, len(req.Parameters))
+	for i, p := range req.Parameters {
+		args[i] = sqlValue(p)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.QueryTimeout)
+	defer cancel()
+
+	// changes() is per connection, so the statement and the count share one
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, sqlError(ctx, err)
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, req.SQL, args...)
+	if err != nil {
+		return nil, sqlError(ctx, err)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, sqlError(ctx, err)
+	}
+
+	if len(columns) > 0 {
+		result, err := scanRows(rows, s.config.MaxRawRows)
+		if err != nil {
+			return nil, sqlError(ctx, err)
+		}
+		return json.Marshal(SQLResult{Rows: result, RowCount: int64(len(result))})
+	}
+
+	// The driver only steps a statement on Next, so drain to execute it
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, sqlError(ctx, err)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, sqlError(ctx, err)
+	}
+	var changes int64
+	if err := conn.QueryRowContext(ctx, "SELECT changes()").Scan(&changes); err != nil {
+		return nil, sqlError(ctx, err)
+	}
+
+	return json.Marshal(SQLResult{Rows: []map[string]interface{}{}, RowCount: changes})
+}
+
+// sqlPager reads a query in LIMIT/OFFSET pages so no cursor is held between calls
+type sqlPager struct {
+	api       *sqlAPI
+	query     sq.SelectBuilder
+	offset    int
+	remaining int  // Rows still allowed
+	capped    bool // remaining came from MaxRows rather than the query's limit
+	batch     int
+}
+
+// fetch reads the next page, peeking one row ahead to report whether more remain
+func (p *sqlPager) fetch(ctx context.Context) ([]map[string]interface{}, bool, error) {
+	size := min(p.batch, p.remaining)
+	if size <= 0 {
+		return []map[string]interface{}{}, false, nil
+	}
+
+	query, args, err := p.query.Limit(uint64(size + 1)).Offset(uint64(p.offset)).ToSql()
+	if err != nil {
+		return nil, false, sqlBuildError("%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.api.config.QueryTimeout)
+	defer cancel()
+
+	rows, err := p.api.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, false, sqlError(ctx, err)
+	}
+	result, err := scanRows(rows, size+1)
+	if err != nil {
+		return nil, false, sqlError(ctx, err)
+	}
+
+	hasMore := len(result) > size
+	if hasMore {
+		result = result[:size]
+	}
+	p.offset += len(result)
+	p.remaining -= len(result)
+
+	if p.remaining == 0 && hasMore {
+		if p.capped {
+			return nil, false, &HostAPIError{
+				Code:    ErrorCodeResponseTooLarge,
+				Message: fmt.Sprintf("query returns more than %d rows; add a limit", p.api.config.MaxRows),
+			}
+		}
+		hasMore = false
+	}
+
+	return result, hasMore, nil
+}
+
+// sqlRowsIterator streams the remaining pages of a query
+type sqlRowsIterator struct {
+	pager *sqlPager
+}
+
+func (i *sqlRowsIterator) Next(ctx context.Context) (json.RawMessage, bool, error) {
+	rows, hasMore, err := i.pager.fetch(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := json.Marshal(SQLRowsChunk{Rows: rows})
+	if err != nil {
+		return nil, false, err
+	}
+	return data, hasMore, nil
+}
+
+func (i *sqlRowsIterator) Close() error {
+	return nil
+}
+
+// scanRows reads at most limit rows into column-keyed maps and closes rows.
+// Exceeding limit is reported as RESPONSE_TOO_LARGE.
+func scanRows(rows *sql.Rows, limit int) ([]map[string]interface{}, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []map[string]interface{}{}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if len(result) >= limit {
+			return nil, &HostAPIError{
+				Code:    ErrorCodeResponseTooLarge,
+				Message: fmt.Sprintf("result exceeds %d rows", limit),
+			}
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// unmarshalSQLParameters decodes parameters keeping numbers exact for binding
+func unmarshalSQLParameters(parameters json.RawMessage, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(parameters))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidParameters,
+			Message: "failed to parse parameters",
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
+
+// sqlError converts database errors into host API errors
+func sqlError(ctx context.Context, err error) error {
+	var apiErr *HostAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &HostAPIError{
+			Code:    ErrorCodeSQLTimeout,
+			Message: "query exceeded the time limit",
+		}
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		// Extended result codes keep the primary code in the low byte
+		switch sqliteErr.Code() & 0xff {
+		case sqlite3.SQLITE_CONSTRAINT:
+			return &HostAPIError{
+				Code:    ErrorCodeSQLConstraintViolation,
+				Message: "constraint violation",
+				Details: sqliteErr.Error(),
+			}
+		case sqlite3.SQLITE_ERROR, sqlite3.SQLITE_RANGE, sqlite3.SQLITE_MISMATCH:
+			return &HostAPIError{
+				Code:    ErrorCodeSQLSyntax,
+				Message: "invalid statement",
+				Details: sqliteErr.Error(),
+			}
+		}
+	}
+
+	return &HostAPIError{
+		Code:    ErrorCodeSQLConnection,
+		Message: "database operation failed",
+		Details: err.Error(),
+	}
+}
@@ -0,0 +1,523 @@
+package hostapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// sqlIdentifierPattern matches a table or column name, optionally qualified by table
+var sqlIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Limits on declarative query complexity
+const (
+	maxSQLConditionDepth = 16
+	maxSQLSubqueryDepth  = 5
+)
+
+// SQLQuery is the declarative query accepted by sql.query
+type SQLQuery struct {
+	Table     string         `json:"table"`
+	Columns   []string       `json:"columns,omitempty"` // Default: all columns
+	Where     *SQLCondition  `json:"where,omitempty"`
+	Join      []SQLJoin      `json:"join,omitempty"`
+	OrderBy   []SQLOrderBy   `json:"orderBy,omitempty"`
+	GroupBy   []string       `json:"groupBy,omitempty"`
+	Limit     int            `json:"limit,omitempty"` // 0 = no limit (capped by MaxRows)
+	Offset    int            `json:"offset,omitempty"`
+	Aggregate []SQLAggregate `json:"aggregate,omitempty"`
+
+	AllowSubqueries bool `json:"allowSubqueries,omitempty"` // Must be set to use subqueries in where
+}
+
+// SQLCondition is a WHERE clause: exactly one of And, Or, Not or a comparison
+type SQLCondition struct {
+	And []SQLCondition `json:"and,omitempty"`
+	Or  []SQLCondition `json:"or,omitempty"`
+	Not *SQLCondition  `json:"not,omitempty"`
+
+	// Comparison
+	Column   string      `json:"column,omitempty"`
+	Op       string      `json:"op,omitempty"` // =, !=, <, <=, >, >=, in, notIn, like, isNull, exists
+	Value    interface{} `json:"value,omitempty"`
+	Subquery *SQLQuery   `json:"subquery,omitempty"` // For in, notIn and exists
+}
+
+// SQLJoin joins another table on a column equality
+type SQLJoin struct {
+	Type  string `json:"type"` // inner, left, right
+	Table string `json:"table"`
+	On    struct {
+		LocalColumn   string `json:"localColumn"`
+		ForeignColumn string `json:"foreignColumn"`
+	} `json:"on"`
+}
+
+// SQLOrderBy orders results by a column
+type SQLOrderBy struct {
+	Column    string `json:"column"`
+	Direction string `json:"direction,omitempty"` // asc (default) or desc
+}
+
+// SQLAggregate selects an aggregate function over a column
+type SQLAggregate struct {
+	Function string `json:"function"` // count, sum, avg, min, max
+	Column   string `json:"column"`
+	Alias    string `json:"alias,omitempty"`
+}
+
+// SQLMutation is the structured write accepted by sql.mutate
+type SQLMutation struct {
+	Table     string                 `json:"table"`
+	Action    string                 `json:"action"` // insert, update, delete
+	ID        interface{}            `json:"id,omitempty"`
+	Values    map[string]interface{} `json:"values,omitempty"`
+	Where     *SQLCondition          `json:"where,omitempty"`
+	Returning []string               `json:"returning,omitempty"`
+}
+
+// sqlBuildError reports an invalid declarative query or mutation
+func sqlBuildError(format string, args ...interface{}) error {
+	return &HostAPIError{
+		Code:    ErrorCodeSQLSyntax,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// buildSelect translates a declarative query into a parameterized SELECT
+func buildSelect(query *SQLQuery, depth int) (sq.SelectBuilder, error) {
+	if depth > maxSQLSubqueryDepth {
+		return sq.SelectBuilder{}, sqlBuildError("subqueries nested deeper than %d", maxSQLSubqueryDepth)
+	}
+
+	table, err := quoteTable(query.Table)
+	if err != nil {
+		return sq.SelectBuilder{}, err
+	}
+
+	// Subqueries are opt-in at the top level; nested ones inherit the opt-in
+	if depth == 0 && !query.AllowSubqueries && hasSubquery(query.Where) {
+		return sq.SelectBuilder{}, sqlBuildError("subqueries require allowSubqueries")
+	}
+
+	columns := make([]string, 0, len(query.Columns)+len(query.Aggregate))
+	for _, column := range query.Columns {
+		quoted, err := quoteColumn(column, true)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		columns = append(columns, quoted)
+	}
+	for _, agg := range query.Aggregate {
+		expr, err := buildAggregate(agg)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		columns = append(columns, expr)
+	}
+	if len(columns) == 0 {
+		columns = append(columns, "*")
+	}
+
+	builder := sq.Select(columns...).From(table)
+
+	for _, join := range query.Join {
+		clause, err := buildJoin(join)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		builder = builder.JoinClause(clause)
+	}
+
+	if query.Where != nil {
+		predicate, err := buildPredicate(query.Where, depth, 0)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		builder = builder.Where(predicate)
+	}
+
+	if len(query.GroupBy) > 0 {
+		groupBy := make([]string, 0, len(query.GroupBy))
+		for _, column := range query.GroupBy {
+			quoted, err := quoteColumn(column, false)
+			if err != nil {
+				return sq.SelectBuilder{}, err
+			}
+			groupBy = append(groupBy, quoted)
+		}
+		builder = builder.GroupBy(groupBy...)
+	}
+
+	for _, order := range query.OrderBy {
+		quoted, err := quoteColumn(order.Column, false)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		switch strings.ToLower(order.Direction) {
+		case "", "asc":
+			builder = builder.OrderBy(quoted + " ASC")
+		case "desc":
+			builder = builder.OrderBy(quoted + " DESC")
+		default:
+			return sq.SelectBuilder{}, sqlBuildError("invalid order direction: %q", order.Direction)
+		}
+	}
+
+	if query.Limit < 0 || query.Offset < 0 {
+		return sq.SelectBuilder{}, sqlBuildError("limit and offset must not be negative")
+	}
+
+	return builder, nil
+}
+
+// buildMutation translates a structured mutation into a parameterized statement
+func buildMutation(mutation *SQLMutation) (sq.Sqlizer, error) {
+	table, err := quoteTable(mutation.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	returning, err := buildReturning(mutation.Returning)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mutation.Action {
+	case "insert":
+		if len(mutation.Values) == 0 {
+			return nil, sqlBuildError("insert requires values")
+		}
+		columns, values, err := sortedValues(mutation.Values)
+		if err != nil {
+			return nil, err
+		}
+		builder := sq.Insert(table).Columns(columns...).Values(values...)
+		if returning != "" {
+			builder = builder.Suffix(returning)
+		}
+		return builder, nil
+
+	case "update":
+		if len(mutation.Values) == 0 {
+			return nil, sqlBuildError("update requires values")
+		}
+		where, err := mutationWhere(mutation)
+		if err != nil {
+			return nil, err
+		}
+		columns, values, err := sortedValues(mutation.Values)
+		if err != nil {
+			return nil, err
+		}
+		builder := sq.Update(table).Where(where)
+		for i, column := range columns {
+			builder = builder.Set(column, values[i])
+		}
+		if returning != "" {
+			builder = builder.Suffix(returning)
+		}
+		return builder, nil
+
+	case "delete":
+		where, err := mutationWhere(mutation)
+		if err != nil {
+			return nil, err
+		}
+		builder := sq.Delete(table).Where(where)
+		if returning != "" {
+			builder = builder.Suffix(returning)
+		}
+		return builder, nil
+
+	default:
+		return nil, sqlBuildError("invalid mutation action: %q", mutation.Action)
+	}
+}
+
+// mutationWhere builds the row filter for update/delete. Unfiltered writes are refused.
+func mutationWhere(mutation *SQLMutation) (sq.Sqlizer, error) {
+	if mutation.Where != nil {
+		return buildPredicate(mutation.Where, 0, 0)
+	}
+	if mutation.ID != nil {
+		return sq.Expr(quoteIdentifier("id")+" = ?", sqlValue(mutation.ID)), nil
+	}
+	return nil, sqlBuildError("%s requires id or where", mutation.Action)
+}
+
+// buildPredicate translates a condition tree into a squirrel predicate
+func buildPredicate(cond *SQLCondition, subqueryDepth, depth int) (sq.Sqlizer, error) {
+	if depth > maxSQLConditionDepth {
+		return nil, sqlBuildError("conditions nested deeper than %d", maxSQLConditionDepth)
+	}
+
+	switch {
+	case len(cond.And) > 0:
+		and := make(sq.And, 0, len(cond.And))
+		for i := range cond.And {
+			predicate, err := buildPredicate(&cond.And[i], subqueryDepth, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, predicate)
+		}
+		return and, nil
+
+	case len(cond.Or) > 0:
+		or := make(sq.Or, 0, len(cond.Or))
+		for i := range cond.Or {
+			predicate, err := buildPredicate(&cond.Or[i], subqueryDepth, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			or = append(or, predicate)
+		}
+		return or, nil
+
+	case cond.Not != nil:
+		predicate, err := buildPredicate(cond.Not, subqueryDepth, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return sq.Expr("NOT (?)", predicate), nil
+	}
+
+	if cond.Op == "exists" {
+		if cond.Subquery == nil {
+			return nil, sqlBuildError("exists requires a subquery")
+		}
+		subquery, err := buildSelect(cond.Subquery, subqueryDepth+1)
+		if err != nil {
+			return nil, err
+		}
+		return sq.Expr("EXISTS (?)", subquery), nil
+	}
+
+	column, err := quoteColumn(cond.Column, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cond.Op {
+	case "=", "!=", "<", "<=", ">", ">=", "like":
+		if cond.Value == nil {
+			return nil, sqlBuildError("operator %s requires a value; use isNull to match NULL", cond.Op)
+		}
+		op := cond.Op
+		if op == "like" {
+			op = "LIKE"
+		}
+		return sq.Expr(fmt.Sprintf("%s %s ?", column, op), sqlValue(cond.Value)), nil
+
+	case "isNull":
+		if isNull, ok := cond.Value.(bool); ok && !isNull {
+			return sq.Expr(column + " IS NOT NULL"), nil
+		}
+		return sq.Expr(column + " IS NULL"), nil
+
+	case "in", "notIn":
+		keyword := "IN"
+		if cond.Op == "notIn" {
+			keyword = "NOT IN"
+		}
+
+		if cond.Subquery != nil {
+			subquery, err := buildSelect(cond.Subquery, subqueryDepth+1)
+			if err != nil {
+				return nil, err
+			}
+			return sq.Expr(fmt.Sprintf("%s %s (?)", column, keyword), subquery), nil
+		}
+
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return nil, sqlBuildError("operator %s requires an array value or subquery", cond.Op)
+		}
+		args := make([]interface{}, len(values))
+		for i, v := range values {
+			args[i] = sqlValue(v)
+		}
+		if cond.Op == "notIn" {
+			return sq.NotEq{column: args}, nil
+		}
+		return sq.Eq{column: args}, nil
+
+	default:
+		return nil, sqlBuildError("invalid operator: %q", cond.Op)
+	}
+}
+
+// hasSubquery reports whether a condition tree contains a subquery
+func hasSubquery(cond *SQLCondition) bool {
+	if cond == nil {
+		return false
+	}
+	if cond.Subquery != nil || hasSubquery(cond.Not) {
+		return true
+	}
+	for i := range cond.And {
+		if hasSubquery(&cond.And[i]) {
+			return true
+		}
+	}
+	for i := range cond.Or {
+		if hasSubquery(&cond.Or[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildJoin renders a JOIN clause with quoted identifiers
+func buildJoin(join SQLJoin) (string, error) {
+	var kind string
+	switch join.Type {
+	case "", "inner":
+		kind = "JOIN"
+	case "left":
+		kind = "LEFT JOIN"
+	case "right":
+		kind = "RIGHT JOIN"
+	default:
+		return "", sqlBuildError("invalid join type: %q", join.Type)
+	}
+
+	table, err := quoteTable(join.Table)
+	if err != nil {
+		return "", err
+	}
+	local, err := quoteColumn(join.On.LocalColumn, false)
+	if err != nil {
+		return "", err
+	}
+	foreign, err := quoteColumn(join.On.ForeignColumn, false)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s %s ON %s = %s", kind, table, local, foreign), nil
+}
+
+// buildAggregate renders an aggregate select expression
+func buildAggregate(agg SQLAggregate) (string, error) {
+	function := strings.ToUpper(agg.Function)
+	switch function {
+	case "COUNT", "SUM", "AVG", "MIN", "MAX":
+	default:
+		return "", sqlBuildError("invalid aggregate function: %q", agg.Function)
+	}
+
+	column, err := quoteColumn(agg.Column, function == "COUNT")
+	if err != nil {
+		return "", err
+	}
+
+	alias := agg.Alias
+	if alias == "" {
+		alias = strings.ToLower(function)
+	}
+	if !sqlIdentifierPattern.MatchString(alias) || strings.Contains(alias, ".") {
+		return "", sqlBuildError("invalid alias: %q", alias)
+	}
+
+	return fmt.Sprintf("%s(%s) AS %s", function, column, quoteIdentifier(alias)), nil
+}
+
+// buildReturning renders an optional RETURNING clause
+func buildReturning(columns []string) (string, error) {
+	if len(columns) == 0 {
+		return "", nil
+	}
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		q, err := quoteColumn(column, true)
+		if err != nil {
+			return "", err
+		}
+		quoted = append(quoted, q)
+	}
+	return "RETURNING " + strings.Join(quoted, ", "), nil
+}
+
+// sortedValues returns quoted columns and values in a deterministic order
+func sortedValues(values map[string]interface{}) ([]string, []interface{}, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	columns := make([]string, 0, len(names))
+	args := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		if strings.Contains(name, ".") {
+			return nil, nil, sqlBuildError("invalid column: %q", name)
+		}
+		quoted, err := quoteColumn(name, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		columns = append(columns, quoted)
+		args = append(args, sqlValue(values[name]))
+	}
+	return columns, args, nil
+}
+
+// quoteTable validates and quotes a table name. SQLite's internal tables are reserved.
+func quoteTable(name string) (string, error) {
+	if !sqlIdentifierPattern.MatchString(name) || strings.Contains(name, ".") {
+		return "", sqlBuildError("invalid table name: %q", name)
+	}
+	if strings.HasPrefix(strings.ToLower(name), "sqlite_") {
+		return "", &HostAPIError{
+			Code:    ErrorCodePolicyDenied,
+			Message: fmt.Sprintf("table %s is reserved", name),
+		}
+	}
+	return quoteIdentifier(name), nil
+}
+
+// quoteColumn validates and quotes a (possibly table-qualified) column name
+func quoteColumn(name string, allowStar bool) (string, error) {
+	if allowStar && name == "*" {
+		return "*", nil
+	}
+	if !sqlIdentifierPattern.MatchString(name) {
+		return "", sqlBuildError("invalid column name: %q", name)
+	}
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quoteIdentifier(part)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// quoteIdentifier quotes a validated identifier. SQLite resolves an unknown
+// "double-quoted" name as a string literal, so backticks are used instead:
+// they always name a table or column and fail loudly when it doesn't exist.
+func quoteIdentifier(name string) string {
+	return "`" + name + "`"
+}
+
+// sqlValue converts a decoded JSON value into a database/sql argument.
+// Numbers become int64 when integral; objects and arrays are stored as JSON text.
+func sqlValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	default:
+		return value
+	}
+}
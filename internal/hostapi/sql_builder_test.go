@@ -0,0 +1,199 @@
+package hostapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test declarative queries compile to the expected parameterized SQL
+// 2. Test mutations compile to INSERT/UPDATE/DELETE with sorted columns and RETURNING
+// 3. Test identifier validation rejects injection attempts and reserved tables
+// 4. Test complexity limits (condition depth, subquery depth, subquery opt-in)
+
+// decodeSQLQuery parses a query the same way the API does
+func decodeSQLQuery(t *testing.T, raw string) *SQLQuery {
+	var query SQLQuery
+	require.NoError(t, unmarshalSQLParameters(json.RawMessage(raw), &query))
+	return &query
+}
+
+func TestBuildSelect(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		sql   string
+		args  []interface{}
+	}{
+		{
+			name:  "all columns",
+			query: `{"table":"users"}`,
+			sql:   "SELECT * FROM `users`",
+		},
+		{
+			name:  "columns, where, order",
+			query: `{"table":"users","columns":["id","name"],"where":{"column":"age","op":">=","value":18},"orderBy":[{"column":"name","direction":"desc"}]}`,
+			sql:   "SELECT `id`, `name` FROM `users` WHERE `age` >= ? ORDER BY `name` DESC",
+			args:  []interface{}{int64(18)},
+		},
+		{
+			name:  "nested boolean logic",
+			query: `{"table":"users","where":{"or":[{"column":"role","op":"=","value":"admin"},{"and":[{"column":"active","op":"=","value":true},{"not":{"column":"email","op":"like","value":"%@spam.io"}}]}]}}`,
+			sql:   "SELECT * FROM `users` WHERE (`role` = ? OR (`active` = ? AND NOT (`email` LIKE ?)))",
+			args:  []interface{}{"admin", true, "%@spam.io"},
+		},
+		{
+			name:  "in and null checks",
+			query: `{"table":"users","where":{"and":[{"column":"id","op":"in","value":[1,2.5]},{"column":"deleted_at","op":"isNull"},{"column":"email","op":"isNull","value":false}]}}`,
+			sql:   "SELECT * FROM `users` WHERE (`id` IN (?,?) AND `deleted_at` IS NULL AND `email` IS NOT NULL)",
+			args:  []interface{}{int64(1), 2.5},
+		},
+		{
+			name:  "join, group and aggregate",
+			query: `{"table":"orders","columns":["users.name"],"join":[{"type":"left","table":"users","on":{"localColumn":"orders.user_id","foreignColumn":"users.id"}}],"groupBy":["users.name"],"aggregate":[{"function":"sum","column":"orders.total","alias":"spent"},{"function":"count","column":"*"}]}`,
+			sql:   "SELECT `users`.`name`, SUM(`orders`.`total`) AS `spent`, COUNT(*) AS `count` FROM `orders` LEFT JOIN `users` ON `orders`.`user_id` = `users`.`id` GROUP BY `users`.`name`",
+		},
+		{
+			name:  "subquery",
+			query: `{"table":"users","allowSubqueries":true,"where":{"column":"id","op":"notIn","subquery":{"table":"bans","columns":["user_id"],"where":{"column":"active","op":"=","value":1}}}}`,
+			sql:   "SELECT * FROM `users` WHERE `id` NOT IN (SELECT `user_id` FROM `bans` WHERE `active` = ?)",
+			args:  []interface{}{int64(1)},
+		},
+		{
+			name:  "exists",
+			query: `{"table":"users","allowSubqueries":true,"where":{"op":"exists","subquery":{"table":"orders","where":{"column":"orders.user_id","op":">","value":0}}}}`,
+			sql:   "SELECT * FROM `users` WHERE EXISTS (SELECT * FROM `orders` WHERE `orders`.`user_id` > ?)",
+			args:  []interface{}{int64(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := buildSelect(decodeSQLQuery(t, tt.query), 0)
+			require.NoError(t, err)
+
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, tt.sql, sql)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestBuildMutation(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutation SQLMutation
+		sql      string
+		args     []interface{}
+	}{
+		{
+			name:     "insert with returning",
+			mutation: SQLMutation{Table: "users", Action: "insert", Values: map[string]interface{}{"name": "Ada", "age": json.Number("36")}, Returning: []string{"id"}},
+			sql:      "INSERT INTO `users` (`age`,`name`) VALUES (?,?) RETURNING `id`",
+			args:     []interface{}{int64(36), "Ada"},
+		},
+		{
+			name:     "update by id",
+			mutation: SQLMutation{Table: "users", Action: "update", ID: json.Number("7"), Values: map[string]interface{}{"name": "Grace", "tags": []interface{}{"a"}}},
+			sql:      "UPDATE `users` SET `name` = ?, `tags` = ? WHERE `id` = ?",
+			args:     []interface{}{"Grace", `["a"]`, int64(7)},
+		},
+		{
+			name:     "delete by condition",
+			mutation: SQLMutation{Table: "sessions", Action: "delete", Where: &SQLCondition{Column: "expires_at", Op: "<", Value: "2024-01-01"}},
+			sql:      "DELETE FROM `sessions` WHERE `expires_at` < ?",
+			args:     []interface{}{"2024-01-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, err := buildMutation(&tt.mutation)
+			require.NoError(t, err)
+
+			sql, args, err := statement.ToSql()
+			require.NoError(t, err)
+			assert.Equal(t, tt.sql, sql)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestBuildSQL_Rejects(t *testing.T) {
+	deep := `{"column":"id","op":"=","value":1}`
+	for i := 0; i <= maxSQLConditionDepth; i++ {
+		deep = `{"not":` + deep + `}`
+	}
+	nested := `{"table":"t"}`
+	for i := 0; i <= maxSQLSubqueryDepth; i++ {
+		nested = `{"table":"t","allowSubqueries":true,"where":{"op":"exists","subquery":` + nested + `}}`
+	}
+
+	queries := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"injected table", `{"table":"users; DROP TABLE users"}`, ErrorCodeSQLSyntax},
+		{"quoted table", `{"table":"users\""}`, ErrorCodeSQLSyntax},
+		{"injected column", `{"table":"users","columns":["id) FROM secrets --"]}`, ErrorCodeSQLSyntax},
+		{"injected order", `{"table":"users","orderBy":[{"column":"id","direction":"desc; DROP"}]}`, ErrorCodeSQLSyntax},
+		{"injected alias", `{"table":"users","aggregate":[{"function":"count","column":"*","alias":"x\" FROM y"}]}`, ErrorCodeSQLSyntax},
+		{"unknown operator", `{"table":"users","where":{"column":"id","op":"; DROP","value":1}}`, ErrorCodeSQLSyntax},
+		{"unknown aggregate", `{"table":"users","aggregate":[{"function":"group_concat","column":"name"}]}`, ErrorCodeSQLSyntax},
+		{"null comparison", `{"table":"users","where":{"column":"id","op":"="}}`, ErrorCodeSQLSyntax},
+		{"in without array", `{"table":"users","where":{"column":"id","op":"in","value":1}}`, ErrorCodeSQLSyntax},
+		{"exists without subquery", `{"table":"users","where":{"op":"exists"}}`, ErrorCodeSQLSyntax},
+		{"negative limit", `{"table":"users","limit":-1}`, ErrorCodeSQLSyntax},
+		{"reserved table", `{"table":"sqlite_master"}`, ErrorCodePolicyDenied},
+		{"subquery without opt-in", `{"table":"users","where":{"op":"exists","subquery":{"table":"orders"}}}`, ErrorCodeSQLSyntax},
+		{"conditions too deep", `{"table":"users","where":` + deep + `}`, ErrorCodeSQLSyntax},
+		{"subqueries too deep", nested, ErrorCodeSQLSyntax},
+	}
+
+	for _, tt := range queries {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildSelect(decodeSQLQuery(t, tt.query), 0)
+			requireHostAPIError(t, err, tt.code)
+		})
+	}
+
+	mutations := []struct {
+		name     string
+		mutation SQLMutation
+	}{
+		{"unfiltered update", SQLMutation{Table: "users", Action: "update", Values: map[string]interface{}{"a": 1}}},
+		{"unfiltered delete", SQLMutation{Table: "users", Action: "delete"}},
+		{"empty insert", SQLMutation{Table: "users", Action: "insert"}},
+		{"qualified value column", SQLMutation{Table: "users", Action: "insert", Values: map[string]interface{}{"other.a": 1}}},
+		{"injected value column", SQLMutation{Table: "users", Action: "insert", Values: map[string]interface{}{"a) VALUES (1); --": 1}}},
+		{"unknown action", SQLMutation{Table: "users", Action: "truncate"}},
+	}
+
+	for _, tt := range mutations {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildMutation(&tt.mutation)
+			requireHostAPIError(t, err, ErrorCodeSQLSyntax)
+		})
+	}
+}
+
+func TestQuoteColumn(t *testing.T) {
+	quoted, err := quoteColumn("users.id", false)
+	require.NoError(t, err)
+	assert.Equal(t, "`users`.`id`", quoted)
+
+	_, err = quoteColumn("*", false)
+	assert.Error(t, err)
+
+	_, err = quoteColumn("a.b.c", false)
+	assert.Error(t, err)
+
+	_, err = quoteColumn(strings.Repeat("x", 10)+"-", false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,354 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okra-platform/okra/internal/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test mutate insert/update/delete round trip with RETURNING and row counts
+// 2. Test query streams batches through the HostAPISet iterator
+// 3. Test MaxRows, constraint and syntax errors surface as SQL error codes
+// 4. Test injection payloads are bound as values, never executed
+// 5. Test sql.raw is denied by default and runs when a policy grants sql.raw
+// 6. Test each service gets its own database, shared across its instances
+// 7. Test on-disk databases persist across factory instances
+// 8. Test WithSQLDatabase serves every service from the migrated project database
+
+// newSQLTestSet creates a HostAPISet with the SQL API for a service
+func newSQLTestSet(t *testing.T, factory HostAPIFactory, service string, engine PolicyEngine) HostAPISet {
+	if engine == nil {
+		engine = &mockPolicyEngine{}
+	}
+
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(factory))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{SQLAPIName}, HostAPIConfig{
+		ServiceName:  service,
+		PolicyEngine: engine,
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+// rawSQLEngine grants the sql.raw capability
+func rawSQLEngine() PolicyEngine {
+	return &mockPolicyEngine{decisions: map[string]PolicyDecision{
+		SQLAPIName + ".raw": {Allowed: true, Metadata: map[string]interface{}{CapabilitySQLRaw: true}},
+	}}
+}
+
+// createUsersTable creates a users table through sql.raw
+func createUsersTable(t *testing.T, factory HostAPIFactory, service string) {
+	set := newSQLTestSet(t, factory, service, rawSQLEngine())
+	_, err := set.Execute(context.Background(), SQLAPIName, "raw", json.RawMessage(
+		`{"sql":"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE, age INTEGER, tags TEXT)"}`))
+	require.NoError(t, err)
+}
+
+func decodeSQLResult(t *testing.T, data json.RawMessage) SQLResult {
+	var result SQLResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	return result
+}
+
+func TestSQLAPI_MutateRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	factory := NewSQLAPIFactory()
+	createUsersTable(t, factory, "acme/users")
+	set := newSQLTestSet(t, factory, "acme/users", nil)
+
+	result, err := set.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(
+		`{"table":"users","action":"insert","values":{"name":"Ada","age":36,"tags":["math"]},"returning":["id","tags"]}`))
+	require.NoError(t, err)
+	inserted := decodeSQLResult(t, result)
+	require.Len(t, inserted.Rows, 1)
+	assert.Equal(t, int64(1), inserted.RowCount)
+	assert.Equal(t, float64(1), inserted.Rows[0]["id"])
+	assert.Equal(t, `["math"]`, inserted.Rows[0]["tags"])
+
+	_, err = set.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(`{"table":"users","action":"insert","values":{"name":"Grace","age":45}}`))
+	require.NoError(t, err)
+
+	result, err = set.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(`{"table":"users","action":"update","id":1,"values":{"age":37}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), decodeSQLResult(t, result).RowCount)
+
+	result, err = set.Execute(ctx, SQLAPIName, "query", json.RawMessage(
+		`{"table":"users","columns":["name","age"],"where":{"column":"age","op":">","value":30},"orderBy":[{"column":"age"}]}`))
+	require.NoError(t, err)
+	var query SQLQueryResponse
+	require.NoError(t, json.Unmarshal(result, &query))
+	assert.False(t, query.HasMore)
+	assert.Empty(t, query.IteratorID)
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "Ada", "age": float64(37)},
+		{"name": "Grace", "age": float64(45)},
+	}, query.Rows)
+
+	result, err = set.Execute(ctx, SQLAPIName, "query", json.RawMessage(
+		`{"table":"users","aggregate":[{"function":"count","column":"*","alias":"total"},{"function":"max","column":"age"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"total": float64(2), "max": float64(45)}}, decodeSQLResult(t, result).Rows)
+
+	result, err = set.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(`{"table":"users","action":"delete","where":{"column":"name","op":"=","value":"Grace"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), decodeSQLResult(t, result).RowCount)
+
+	// Unique constraint
+	_, err = set.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(`{"table":"users","action":"insert","values":{"name":"Ada"}}`))
+	requireHostAPIError(t, err, ErrorCodeSQLConstraintViolation)
+
+	// Unknown column
+	_, err = set.Execute(ctx, SQLAPIName, "query", json.RawMessage(`{"table":"users","columns":["missing"]}`))
+	requireHostAPIError(t, err, ErrorCodeSQLSyntax)
+}
+
+func TestSQLAPI_QueryStreaming(t *testing.T) {
+	ctx := context.Background()
+	limits := defaultSQLConfig()
+	limits.BatchSize = 2
+	factory := NewSQLAPIFactory(WithSQLConfig(limits))
+	createUsersTable(t, factory, "acme/users")
+	set := newSQLTestSet(t, factory, "acme/users", nil)
+
+	for i := 1; i <= 5; i++ {
+		_, err := set.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(fmt.Sprintf(`{"table":"users","action":"insert","values":{"name":"user-%d","age":%d}}`, i, i)))
+		require.NoError(t, err)
+	}
+
+	result, err := set.Execute(ctx, SQLAPIName, "query", json.RawMessage(`{"table":"users","columns":["age"],"orderBy":[{"column":"age"}],"offset":1}`))
+	require.NoError(t, err)
+	var query SQLQueryResponse
+	require.NoError(t, json.Unmarshal(result, &query))
+	assert.True(t, query.HasMore)
+	assert.True(t, query.HasData)
+	require.NotEmpty(t, query.IteratorID)
+
+	ages := []float64{}
+	for _, row := range query.Rows {
+		ages = append(ages, row["age"].(float64))
+	}
+	for {
+		chunk, hasMore, err := set.NextIterator(ctx, query.IteratorID)
+		require.NoError(t, err)
+		var rows SQLRowsChunk
+		require.NoError(t, json.Unmarshal(chunk, &rows))
+		for _, row := range rows.Rows {
+			ages = append(ages, row["age"].(float64))
+		}
+		if !hasMore {
+			break
+		}
+	}
+	assert.Equal(t, []float64{2, 3, 4, 5}, ages)
+
+	// An explicit limit ends the stream without error
+	result, err = set.Execute(ctx, SQLAPIName, "query", json.RawMessage(`{"table":"users","limit":2}`))
+	require.NoError(t, err)
+	var limited SQLQueryResponse
+	require.NoError(t, json.Unmarshal(result, &limited))
+	assert.Len(t, limited.Rows, 2)
+	assert.False(t, limited.HasMore)
+}
+
+func TestSQLAPI_MaxRows(t *testing.T) {
+	ctx := context.Background()
+	limits := defaultSQLConfig()
+	limits.MaxRows = 3
+	factory := NewSQLAPIFactory(WithSQLConfig(limits))
+	createUsersTable(t, factory, "acme/users")
+	set := newSQLTestSet(t, factory, "acme/users", nil)
+
+	for i := 1; i <= 4; i++ {
+		_, err := set.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(fmt.Sprintf(`{"table":"users","action":"insert","values":{"name":"user-%d"}}`, i)))
+		require.NoError(t, err)
+	}
+
+	_, err := set.Execute(ctx, SQLAPIName, "query", json.RawMessage(`{"table":"users"}`))
+	requireHostAPIError(t, err, ErrorCodeResponseTooLarge)
+
+	// Direct execution returns everything up to the limit
+	api, ok := set.Get(SQLAPIName)
+	require.True(t, ok)
+	result, err := api.Execute(ctx, "query", json.RawMessage(`{"table":"users","limit":3}`))
+	require.NoError(t, err)
+	assert.Len(t, decodeSQLResult(t, result).Rows, 3)
+}
+
+func TestSQLAPI_InjectionIsBound(t *testing.T) {
+	ctx := context.Background()
+	factory := NewSQLAPIFactory()
+	createUsersTable(t, factory, "acme/users")
+	set := newSQLTestSet(t, factory, "acme/users", nil)
+
+	payload := `Robert'); DROP TABLE users; --`
+	params, err := json.Marshal(SQLMutation{Table: "users", Action: "insert", Values: map[string]interface{}{"name": payload}})
+	require.NoError(t, err)
+	_, err = set.Execute(ctx, SQLAPIName, "mutate", params)
+	require.NoError(t, err)
+
+	params, err = json.Marshal(SQLQuery{Table: "users", Where: &SQLCondition{Column: "name", Op: "=", Value: "' OR '1'='1"}})
+	require.NoError(t, err)
+	result, err := set.Execute(ctx, SQLAPIName, "query", params)
+	require.NoError(t, err)
+	var query SQLQueryResponse
+	require.NoError(t, json.Unmarshal(result, &query))
+	assert.Empty(t, query.Rows)
+
+	// The table survived and the payload was stored verbatim
+	result, err = set.Execute(ctx, SQLAPIName, "query", json.RawMessage(`{"table":"users","columns":["name"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"name": payload}}, decodeSQLResult(t, result).Rows)
+}
+
+func TestSQLAPI_RawRequiresCapability(t *testing.T) {
+	ctx := context.Background()
+	factory := NewSQLAPIFactory()
+
+	// Allowed by the call policy, but the capability is not granted
+	set := newSQLTestSet(t, factory, "acme/users", nil)
+	_, err := set.Execute(ctx, SQLAPIName, "raw", json.RawMessage(`{"sql":"SELECT 1"}`))
+	requireHostAPIError(t, err, ErrorCodePolicyDenied)
+
+	explicit := &mockPolicyEngine{decisions: map[string]PolicyDecision{
+		SQLAPIName + ".raw": {Allowed: true, Metadata: map[string]interface{}{CapabilitySQLRaw: "yes"}},
+	}}
+	set = newSQLTestSet(t, factory, "acme/users", explicit)
+	_, err = set.Execute(ctx, SQLAPIName, "raw", json.RawMessage(`{"sql":"SELECT 1"}`))
+	requireHostAPIError(t, err, ErrorCodePolicyDenied)
+
+	set = newSQLTestSet(t, factory, "acme/users", rawSQLEngine())
+	_, err = set.Execute(ctx, SQLAPIName, "raw", json.RawMessage(`{"sql":"CREATE TABLE kv (k TEXT PRIMARY KEY, v INTEGER)"}`))
+	require.NoError(t, err)
+
+	result, err := set.Execute(ctx, SQLAPIName, "raw", json.RawMessage(`{"sql":"INSERT INTO kv VALUES (?, ?), (?, ?)","parameters":["a",1,"b",2]}`))
+	require.NoError(t, err)
+	assert.Equal(t, SQLResult{Rows: []map[string]interface{}{}, RowCount: 2}, decodeSQLResult(t, result))
+
+	result, err = set.Execute(ctx, SQLAPIName, "raw", json.RawMessage(`{"sql":"SELECT k, v FROM kv WHERE v > ? ORDER BY k","parameters":[1]}`))
+	require.NoError(t, err)
+	assert.Equal(t, SQLResult{Rows: []map[string]interface{}{{"k": "b", "v": float64(2)}}, RowCount: 1}, decodeSQLResult(t, result))
+
+	_, err = set.Execute(ctx, SQLAPIName, "raw", json.RawMessage(`{"sql":"SELEC nonsense"}`))
+	requireHostAPIError(t, err, ErrorCodeSQLSyntax)
+}
+
+func TestSQLAPI_ServiceIsolation(t *testing.T) {
+	ctx := context.Background()
+	factory := NewSQLAPIFactory()
+	createUsersTable(t, factory, "acme/users")
+
+	// A second instance for the same service sees the same database
+	same := newSQLTestSet(t, factory, "acme/users", nil)
+	_, err := same.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(`{"table":"users","action":"insert","values":{"name":"Ada"}}`))
+	require.NoError(t, err)
+
+	other := newSQLTestSet(t, factory, "acme/billing", nil)
+	_, err = other.Execute(ctx, SQLAPIName, "query", json.RawMessage(`{"table":"users"}`))
+	requireHostAPIError(t, err, ErrorCodeSQLSyntax)
+}
+
+func TestSQLAPI_DataDir(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	createUsersTable(t, NewSQLAPIFactory(WithSQLDataDir(dir)), "acme/users")
+	set := newSQLTestSet(t, NewSQLAPIFactory(WithSQLDataDir(dir)), "acme/users", nil)
+	_, err := set.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(`{"table":"users","action":"insert","values":{"name":"Ada"}}`))
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "acme", "users.db"))
+	assert.NoError(t, err)
+
+	_, err = NewSQLAPIFactory(WithSQLDataDir(dir)).Create(ctx, HostAPIConfig{ServiceName: "../escape"})
+	assert.Error(t, err)
+}
+
+func TestSQLAPI_Database(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), ".okra", "dev.db")
+
+	// Test: Tables created the way okra db:migrate creates them are visible
+	db, err := migrations.OpenSQLite("sqlite://" + path)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	factory := NewSQLAPIFactory(WithSQLDatabase(path), WithSQLDataDir(t.TempDir()))
+	set := newSQLTestSet(t, factory, "acme/users", nil)
+	_, err = set.Execute(ctx, SQLAPIName, "mutate", json.RawMessage(`{"table":"users","action":"insert","values":{"name":"Ada"}}`))
+	require.NoError(t, err)
+
+	// Test: Every service shares the database
+	other := newSQLTestSet(t, factory, "acme/billing", nil)
+	result, err := other.Execute(ctx, SQLAPIName, "query", json.RawMessage(`{"table":"users"}`))
+	require.NoError(t, err)
+	var response SQLQueryResponse
+	require.NoError(t, json.Unmarshal(result, &response))
+	assert.Equal(t, int64(1), response.RowCount)
+
+	// Test: Rows written through the API are in the project database
+	db, err = migrations.OpenSQLite(path)
+	require.NoError(t, err)
+	defer db.Close()
+	var name string
+	require.NoError(t, db.QueryRow("SELECT name FROM users").Scan(&name))
+	assert.Equal(t, "Ada", name)
+}
+
+func TestSQLAPI_Validation(t *testing.T) {
+	set := newSQLTestSet(t, NewSQLAPIFactory(), "acme/users", rawSQLEngine())
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		code   string
+	}{
+		{"malformed query", "query", `{"table":`, ErrorCodeInvalidParameters},
+		{"malformed mutation", "mutate", `[]`, ErrorCodeInvalidParameters},
+		{"empty raw", "raw", `{"sql":""}`, ErrorCodeSQLSyntax},
+		{"reserved table", "query", `{"table":"sqlite_master"}`, ErrorCodePolicyDenied},
+		{"unknown method", "drop", `{}`, ErrorCodeMethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := set.Execute(context.Background(), SQLAPIName, tt.method, json.RawMessage(tt.params))
+			requireHostAPIError(t, err, tt.code)
+		})
+	}
+}
+
+func TestSQLAPIFactory_Metadata(t *testing.T) {
+	factory := NewSQLAPIFactory()
+	assert.Equal(t, SQLAPIName, factory.Name())
+	assert.Equal(t, SQLAPIVersion, factory.Version())
+
+	streaming := map[string]bool{}
+	for _, method := range factory.Methods() {
+		streaming[method.Name] = method.Streaming
+	}
+	assert.Equal(t, map[string]bool{"query": true, "mutate": false, "raw": false}, streaming)
+
+	registry := NewHostAPIRegistry()
+	require.NoError(t, InitializeHostAPIs(registry))
+	_, ok := registry.Get(SQLAPIName)
+	assert.True(t, ok)
+}
@@ -0,0 +1,521 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/spec"
+)
+
+const (
+	// StateAPIName is the namespace of the state host API
+	StateAPIName = "okra.state"
+
+	// StateAPIVersion is the current version of the state host API
+	StateAPIVersion = "v1.0.0"
+)
+
+// State API error codes
+const (
+	ErrorCodeInvalidKey      = "INVALID_KEY"
+	ErrorCodeKeyTooLong      = "KEY_TOO_LONG"
+	ErrorCodeValueTooLarge   = "VALUE_TOO_LARGE"
+	ErrorCodeInvalidTTL      = "INVALID_TTL"
+	ErrorCodeVersionConflict = "VERSION_CONFLICT"
+)
+
+// stateKeyPattern restricts keys to alphanumerics, underscore, dash, slash and colon
+var stateKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_\-/:]+$`)
+
+// StateGetRequest is the payload for state.get
+type StateGetRequest struct {
+	Key string `json:"key"`
+}
+
+// StateGetResponse is the result of state.get
+type StateGetResponse struct {
+	Value        json.RawMessage `json:"value,omitempty"`
+	Version      int64           `json:"version,omitempty"`
+	LastModified *time.Time      `json:"lastModified,omitempty"`
+	Exists       bool            `json:"exists"`
+}
+
+// StateSetRequest is the payload for state.set
+type StateSetRequest struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	TTL       *int64          `json:"ttl,omitempty"`       // Seconds
+	IfVersion *int64          `json:"ifVersion,omitempty"` // Optimistic concurrency
+	IfAbsent  bool            `json:"ifAbsent,omitempty"`  // Only set if key doesn't exist
+}
+
+// StateSetResponse is the result of state.set
+type StateSetResponse struct {
+	Version      int64     `json:"version"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// StateDeleteRequest is the payload for state.delete
+type StateDeleteRequest struct {
+	Key       string `json:"key"`
+	IfVersion *int64 `json:"ifVersion,omitempty"`
+}
+
+// StateDeleteResponse is the result of state.delete
+type StateDeleteResponse struct {
+	Deleted bool `json:"deleted"`
+}
+
+// StateListRequest is the payload for state.list
+type StateListRequest struct {
+	Prefix string `json:"prefix"`
+	Limit  int    `json:"limit,omitempty"` // Keys per chunk (default: 100, max: 1000)
+}
+
+// StateListChunk is a single chunk returned by the state.list iterator
+type StateListChunk struct {
+	Keys []string `json:"keys"`
+}
+
+// StateConfig holds the code-level limits enforced by the state API
+type StateConfig struct {
+	MaxKeyLength     int
+	MaxValueSize     int
+	MinTTL           time.Duration
+	MaxTTL           time.Duration
+	DefaultListLimit int
+	MaxListLimit     int
+}
+
+// defaultStateConfig returns the limits described in docs/host-apis/state.md
+func defaultStateConfig() StateConfig {
+	return StateConfig{
+		MaxKeyLength:     512,
+		MaxValueSize:     1024 * 1024,
+		MinTTL:           time.Second,
+		MaxTTL:           365 * 24 * time.Hour,
+		DefaultListLimit: 100,
+		MaxListLimit:     1000,
+	}
+}
+
+// StateAPIOption configures the state API factory
+type StateAPIOption func(*stateAPIFactory)
+
+// WithStateStore sets the backend used by the state API
+func WithStateStore(store StateStore) StateAPIOption {
+	return func(f *stateAPIFactory) {
+		f.store = store
+	}
+}
+
+// WithStateConfig overrides the default state limits
+func WithStateConfig(config StateConfig) StateAPIOption {
+	return func(f *stateAPIFactory) {
+		f.config = config
+	}
+}
+
+// stateAPIFactory creates okra.state instances that share a single backend
+type stateAPIFactory struct {
+	store  StateStore
+	config StateConfig
+}
+
+// NewStateAPIFactory creates the okra.state host API factory.
+// Without options, state is kept in memory for the lifetime of the process.
+func NewStateAPIFactory(opts ...StateAPIOption) HostAPIFactory {
+	factory := &stateAPIFactory{
+		config: defaultStateConfig(),
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	if factory.store == nil {
+		factory.store = NewMemoryStateStore()
+	}
+
+	return factory
+}
+
+func (f *stateAPIFactory) Name() string    { return StateAPIName }
+func (f *stateAPIFactory) Version() string { return StateAPIVersion }
+
+func (f *stateAPIFactory) Create(ctx context.Context, config HostAPIConfig) (HostAPI, error) {
+	if config.ServiceName == "" {
+		return nil, fmt.Errorf("service name is required for %s", StateAPIName)
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &stateAPI{
+		store:     f.store,
+		clock:     clockOrSystem(config.Clock),
+		namespace: config.ServiceName,
+		logger:    logger.With("api", StateAPIName, "service", config.ServiceName),
+		config:    f.config,
+	}, nil
+}
+
+func (f *stateAPIFactory) Methods() []MethodMetadata {
+	return []MethodMetadata{
+		{
+			Name:        "get",
+			Description: "Retrieve a value from state storage",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"key": *spec.StringProperty().WithDescription("The key to retrieve"),
+			}, "key"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"value":        anySchema("The stored JSON value"),
+				"version":      *spec.Int64Property(),
+				"lastModified": *spec.DateTimeProperty(),
+				"exists":       *spec.BoolProperty(),
+			}),
+			Errors: []ErrorMetadata{
+				{Code: ErrorCodeInvalidKey, Description: "Key contains invalid characters"},
+				{Code: ErrorCodeKeyTooLong, Description: "Key exceeds maximum length"},
+			},
+		},
+		{
+			Name:        "set",
+			Description: "Store a value in state storage",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"key":       *spec.StringProperty(),
+				"value":     anySchema("Any JSON value"),
+				"ttl":       *spec.Int64Property().WithDescription("Time to live in seconds"),
+				"ifVersion": *spec.Int64Property().WithDescription("Only write if the current version matches"),
+				"ifAbsent":  *spec.BoolProperty().WithDescription("Only write if the key does not exist"),
+			}, "key", "value"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"version":      *spec.Int64Property(),
+				"lastModified": *spec.DateTimeProperty(),
+			}),
+			Errors: []ErrorMetadata{
+				{Code: ErrorCodeInvalidKey, Description: "Key contains invalid characters"},
+				{Code: ErrorCodeKeyTooLong, Description: "Key exceeds maximum length"},
+				{Code: ErrorCodeValueTooLarge, Description: "Value exceeds maximum size"},
+				{Code: ErrorCodeInvalidTTL, Description: "TTL is outside the allowed range"},
+				{Code: ErrorCodeVersionConflict, Description: "Compare-and-swap precondition failed"},
+			},
+		},
+		{
+			Name:        "delete",
+			Description: "Remove a value from state storage",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"key":       *spec.StringProperty(),
+				"ifVersion": *spec.Int64Property().WithDescription("Only delete if the current version matches"),
+			}, "key"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"deleted": *spec.BoolProperty(),
+			}),
+			Errors: []ErrorMetadata{
+				{Code: ErrorCodeInvalidKey, Description: "Key contains invalid characters"},
+				{Code: ErrorCodeVersionConflict, Description: "Compare-and-swap precondition failed"},
+			},
+		},
+		{
+			Name:        "list",
+			Description: "List keys matching a prefix",
+			Streaming:   true,
+			Parameters: objectSchema(map[string]spec.Schema{
+				"prefix": *spec.StringProperty(),
+				"limit":  *spec.Int32Property().WithDescription("Keys per chunk").WithMaximum(1000, false),
+			}),
+			Returns: streamingSchema(),
+		},
+	}
+}
+
+// stateAPI is a service-scoped view of the shared state store
+type stateAPI struct {
+	store     StateStore
+	clock     Clock // Passed to the store, which decides expiry by it
+	namespace string
+	logger    *slog.Logger
+	config    StateConfig
+}
+
+// Compile-time interface compliance checks
+var (
+	_ StreamingHostAPI = (*stateAPI)(nil)
+	_ HostAPIFactory   = (*stateAPIFactory)(nil)
+)
+
+func (s *stateAPI) Name() string    { return StateAPIName }
+func (s *stateAPI) Version() string { return StateAPIVersion }
+
+func (s *stateAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	ctx = ContextWithClock(ctx, s.clock)
+	switch method {
+	case "get":
+		return s.executeGet(ctx, parameters)
+	case "set":
+		return s.executeSet(ctx, parameters)
+	case "delete":
+		return s.executeDelete(ctx, parameters)
+	default:
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+}
+
+// ExecuteStreaming implements StreamingHostAPI for list
+func (s *stateAPI) ExecuteStreaming(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, Iterator, error) {
+	ctx = ContextWithClock(ctx, s.clock)
+	switch method {
+	case "list":
+		return s.executeList(ctx, parameters)
+	default:
+		// Non-streaming methods fall back to Execute
+		result, err := s.Execute(ctx, method, parameters)
+		return result, nil, err
+	}
+}
+
+func (s *stateAPI) executeGet(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req StateGetRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	if err := s.validateKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.store.Get(ctx, s.namespace, req.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %w", err)
+	}
+
+	if entry == nil {
+		return json.Marshal(StateGetResponse{Exists: false})
+	}
+
+	return json.Marshal(StateGetResponse{
+		Value:        entry.Value,
+		Version:      entry.Version,
+		LastModified: &entry.LastModified,
+		Exists:       true,
+	})
+}
+
+func (s *stateAPI) executeSet(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req StateSetRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	if err := s.validateKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	if len(req.Value) == 0 || !json.Valid(req.Value) {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidParameters,
+			Message: "value must be valid JSON",
+		}
+	}
+	if len(req.Value) > s.config.MaxValueSize {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeValueTooLarge,
+			Message: fmt.Sprintf("value size %d exceeds limit %d", len(req.Value), s.config.MaxValueSize),
+		}
+	}
+
+	opts := StateSetOptions{
+		IfVersion: req.IfVersion,
+		IfAbsent:  req.IfAbsent,
+	}
+	if req.TTL != nil {
+		ttl := time.Duration(*req.TTL) * time.Second
+		if ttl < s.config.MinTTL || ttl > s.config.MaxTTL {
+			return nil, &HostAPIError{
+				Code:    ErrorCodeInvalidTTL,
+				Message: fmt.Sprintf("ttl must be between %s and %s", s.config.MinTTL, s.config.MaxTTL),
+			}
+		}
+		opts.TTL = ttl
+	}
+
+	entry, err := s.store.Set(ctx, s.namespace, req.Key, req.Value, opts)
+	if err != nil {
+		return nil, s.storeError("set", req.Key, err)
+	}
+
+	return json.Marshal(StateSetResponse{
+		Version:      entry.Version,
+		LastModified: entry.LastModified,
+	})
+}
+
+func (s *stateAPI) executeDelete(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req StateDeleteRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+	if err := s.validateKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.store.Delete(ctx, s.namespace, req.Key, req.IfVersion)
+	if err != nil {
+		return nil, s.storeError("delete", req.Key, err)
+	}
+
+	return json.Marshal(StateDeleteResponse{Deleted: deleted})
+}
+
+func (s *stateAPI) executeList(ctx context.Context, parameters json.RawMessage) (json.RawMessage, Iterator, error) {
+	var req StateListRequest
+	if len(parameters) > 0 {
+		if err := unmarshalParameters(parameters, &req); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// An empty prefix lists everything; otherwise it must be a valid key fragment
+	if req.Prefix != "" {
+		if err := s.validateKey(req.Prefix); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = s.config.DefaultListLimit
+	}
+	if limit > s.config.MaxListLimit {
+		limit = s.config.MaxListLimit
+	}
+
+	// Fetch the first page eagerly so we can report whether there is any data
+	first, err := s.store.List(ctx, s.namespace, req.Prefix, "", limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list state: %w", err)
+	}
+
+	iterator := &stateListIterator{
+		store:     s.store,
+		clock:     s.clock,
+		namespace: s.namespace,
+		prefix:    req.Prefix,
+		limit:     limit,
+		pending:   first,
+	}
+
+	result, err := json.Marshal(StreamingResponse{
+		IteratorID: generateIteratorID(),
+		HasData:    len(first) > 0,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, iterator, nil
+}
+
+// validateKey enforces the code-level key policies
+func (s *stateAPI) validateKey(key string) error {
+	if key == "" {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidKey,
+			Message: "key cannot be empty",
+		}
+	}
+	if len(key) > s.config.MaxKeyLength {
+		return &HostAPIError{
+			Code:    ErrorCodeKeyTooLong,
+			Message: fmt.Sprintf("key length %d exceeds limit %d", len(key), s.config.MaxKeyLength),
+		}
+	}
+	if !stateKeyPattern.MatchString(key) || strings.HasPrefix(key, "/") {
+		return &HostAPIError{
+			Code:    ErrorCodeInvalidKey,
+			Message: "key may only contain letters, digits, '_', '-', ':' and '/' and must not start with '/'",
+		}
+	}
+	return nil
+}
+
+// storeError converts backend errors into host API errors
+func (s *stateAPI) storeError(op, key string, err error) error {
+	if errors.Is(err, ErrStateVersionConflict) {
+		return &HostAPIError{
+			Code:    ErrorCodeVersionConflict,
+			Message: fmt.Sprintf("version conflict for key %s", key),
+		}
+	}
+
+	s.logger.Error("state operation failed", "operation", op, "error", err)
+	return fmt.Errorf("failed to %s state: %w", op, err)
+}
+
+// stateListIterator pages through keys in sorted order
+type stateListIterator struct {
+	store     StateStore
+	clock     Clock
+	namespace string
+	prefix    string
+	limit     int
+	pending   []string
+	cursor    string
+	done      bool
+}
+
+// Next returns the next chunk of keys
+func (it *stateListIterator) Next(ctx context.Context) (json.RawMessage, bool, error) {
+	if it.done {
+		return nil, false, nil
+	}
+
+	ctx = ContextWithClock(ctx, it.clock)
+	keys := it.pending
+	it.pending = nil
+	if keys == nil {
+		var err error
+		keys, err = it.store.List(ctx, it.namespace, it.prefix, it.cursor, it.limit)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list state: %w", err)
+		}
+	}
+
+	if len(keys) > 0 {
+		it.cursor = keys[len(keys)-1]
+	}
+
+	// Peek ahead so hasMore is accurate rather than optimistic
+	hasMore := false
+	if len(keys) == it.limit {
+		next, err := it.store.List(ctx, it.namespace, it.prefix, it.cursor, it.limit)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list state: %w", err)
+		}
+		hasMore = len(next) > 0
+		it.pending = next
+	}
+	it.done = !hasMore
+
+	data, err := json.Marshal(StateListChunk{Keys: keys})
+	if err != nil {
+		return nil, false, err
+	}
+	return data, hasMore, nil
+}
+
+// Close releases the iterator
+func (it *stateListIterator) Close() error {
+	it.done = true
+	it.pending = nil
+	return nil
+}
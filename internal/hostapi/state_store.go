@@ -0,0 +1,316 @@
+package hostapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrStateVersionConflict is returned when a compare-and-swap precondition fails
+	ErrStateVersionConflict = errors.New("state version conflict")
+
+	// ErrStateStoreClosed is returned when operating on a closed store
+	ErrStateStoreClosed = errors.New("state store is closed")
+)
+
+// StateStore abstracts the storage backend used by the okra.state host API.
+// Every operation is scoped to a namespace (the calling service name) so that
+// services never observe each other's keys.
+// Expiry is judged by the clock the context carries (see ClockFromContext),
+// falling back to the system clock.
+type StateStore interface {
+	// Get returns the entry for key, or nil if it does not exist or has expired
+	Get(ctx context.Context, namespace, key string) (*StateEntry, error)
+
+	// Set stores value under key, honouring the compare-and-swap preconditions in opts
+	Set(ctx context.Context, namespace, key string, value []byte, opts StateSetOptions) (*StateEntry, error)
+
+	// Delete removes key, returning false if it did not exist
+	Delete(ctx context.Context, namespace, key string, ifVersion *int64) (bool, error)
+
+	// List returns up to limit keys with the given prefix that sort after the cursor key
+	List(ctx context.Context, namespace, prefix, after string, limit int) ([]string, error)
+
+	// DeleteExpired removes every expired entry and returns how many it removed
+	DeleteExpired(ctx context.Context) (int, error)
+
+	// Close releases backend resources
+	Close() error
+}
+
+// DefaultStateSweepInterval is how often a state store removes expired
+// entries that are never read or written again
+const DefaultStateSweepInterval = time.Minute
+
+// StateStoreOption configures a state store
+type StateStoreOption func(*stateStoreOptions)
+
+type stateStoreOptions struct {
+	sweepInterval time.Duration
+}
+
+// WithStateSweepInterval sets how often the store removes expired entries in
+// the background. Zero or less disables the sweep.
+func WithStateSweepInterval(interval time.Duration) StateStoreOption {
+	return func(o *stateStoreOptions) {
+		o.sweepInterval = interval
+	}
+}
+
+func newStateStoreOptions(opts []StateStoreOption) stateStoreOptions {
+	options := stateStoreOptions{sweepInterval: DefaultStateSweepInterval}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// stateSweeper calls DeleteExpired on a store in the background until stopped
+type stateSweeper struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// startStateSweeper starts sweeping store every interval, or returns nil if
+// interval disables the sweep
+func startStateSweeper(store StateStore, interval time.Duration) *stateSweeper {
+	if interval <= 0 {
+		return nil
+	}
+
+	sweeper := &stateSweeper{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(sweeper.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-sweeper.stop:
+				return
+			case <-ticker.C:
+				// A failed sweep is retried on the next tick
+				_, _ = store.DeleteExpired(context.Background())
+			}
+		}
+	}()
+	return sweeper
+}
+
+// Stop ends the sweep and waits for a sweep in progress to finish
+func (s *stateSweeper) Stop() {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// StateEntry is a single stored value with its metadata
+type StateEntry struct {
+	Value        []byte     `json:"value"`
+	Version      int64      `json:"version"`
+	LastModified time.Time  `json:"lastModified"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+}
+
+// expired reports whether the entry's TTL has elapsed
+func (e *StateEntry) expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// StateSetOptions controls conditional writes and expiry
+type StateSetOptions struct {
+	TTL       time.Duration // Zero means no expiry
+	IfVersion *int64        // Only write if the current version matches
+	IfAbsent  bool          // Only write if the key does not exist
+}
+
+// checkPreconditions validates compare-and-swap options against the current entry
+func checkPreconditions(current *StateEntry, ifVersion *int64, ifAbsent bool) error {
+	if ifAbsent && current != nil {
+		return ErrStateVersionConflict
+	}
+	if ifVersion != nil {
+		if current == nil || current.Version != *ifVersion {
+			return ErrStateVersionConflict
+		}
+	}
+	return nil
+}
+
+// nextEntry builds the entry that replaces current after a successful write
+func nextEntry(current *StateEntry, value []byte, ttl time.Duration, now time.Time) *StateEntry {
+	entry := &StateEntry{
+		Value:        append([]byte(nil), value...),
+		Version:      1,
+		LastModified: now,
+	}
+	if current != nil {
+		entry.Version = current.Version + 1
+	}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		entry.ExpiresAt = &expiresAt
+	}
+	return entry
+}
+
+// memoryStateStore keeps state in process memory. Data is lost on restart.
+type memoryStateStore struct {
+	namespaces map[string]map[string]*StateEntry
+	closed     bool
+	now        func() time.Time
+	sweeper    *stateSweeper
+	mu         sync.RWMutex
+}
+
+// NewMemoryStateStore creates an in-memory state store
+func NewMemoryStateStore(opts ...StateStoreOption) StateStore {
+	store := &memoryStateStore{
+		namespaces: make(map[string]map[string]*StateEntry),
+		now:        time.Now,
+	}
+	store.sweeper = startStateSweeper(store, newStateStoreOptions(opts).sweepInterval)
+	return store
+}
+
+func (s *memoryStateStore) Get(ctx context.Context, namespace, key string) (*StateEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil, ErrStateStoreClosed
+	}
+
+	entry, ok := s.namespaces[namespace][key]
+	if !ok || entry.expired(nowFrom(ctx, s.now)) {
+		return nil, nil
+	}
+
+	copied := *entry
+	return &copied, nil
+}
+
+func (s *memoryStateStore) Set(ctx context.Context, namespace, key string, value []byte, opts StateSetOptions) (*StateEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrStateStoreClosed
+	}
+
+	now := nowFrom(ctx, s.now)
+	entries, ok := s.namespaces[namespace]
+	if !ok {
+		entries = make(map[string]*StateEntry)
+		s.namespaces[namespace] = entries
+	}
+
+	current := entries[key]
+	if current != nil && current.expired(now) {
+		current = nil
+	}
+
+	if err := checkPreconditions(current, opts.IfVersion, opts.IfAbsent); err != nil {
+		return nil, err
+	}
+
+	entry := nextEntry(current, value, opts.TTL, now)
+	entries[key] = entry
+
+	copied := *entry
+	return &copied, nil
+}
+
+func (s *memoryStateStore) Delete(ctx context.Context, namespace, key string, ifVersion *int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false, ErrStateStoreClosed
+	}
+
+	entries := s.namespaces[namespace]
+	current, ok := entries[key]
+	if ok && current.expired(nowFrom(ctx, s.now)) {
+		delete(entries, key)
+		current, ok = nil, false
+	}
+
+	if err := checkPreconditions(current, ifVersion, false); err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, nil
+	}
+
+	delete(entries, key)
+	return true, nil
+}
+
+func (s *memoryStateStore) List(ctx context.Context, namespace, prefix, after string, limit int) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil, ErrStateStoreClosed
+	}
+
+	now := nowFrom(ctx, s.now)
+	keys := make([]string, 0)
+	for key, entry := range s.namespaces[namespace] {
+		if !strings.HasPrefix(key, prefix) || key <= after || entry.expired(now) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
+
+func (s *memoryStateStore) DeleteExpired(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, ErrStateStoreClosed
+	}
+
+	now := nowFrom(ctx, s.now)
+	removed := 0
+	for namespace, entries := range s.namespaces {
+		for key, entry := range entries {
+			if entry.expired(now) {
+				delete(entries, key)
+				removed++
+			}
+		}
+		if len(entries) == 0 {
+			delete(s.namespaces, namespace)
+		}
+	}
+	return removed, nil
+}
+
+func (s *memoryStateStore) Close() error {
+	// Stop sweeping first; a sweep holds the lock
+	s.sweeper.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.namespaces = nil
+	return nil
+}
@@ -0,0 +1,235 @@
+package hostapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltStateStore persists state in an embedded bbolt database.
+// Each namespace is stored in its own bucket.
+type boltStateStore struct {
+	db      *bolt.DB
+	now     func() time.Time
+	sweeper *stateSweeper
+}
+
+// NewBoltStateStore opens (or creates) an on-disk state store at path
+func NewBoltStateStore(path string, opts ...StateStoreOption) (StateStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+
+	store := &boltStateStore{
+		db:  db,
+		now: time.Now,
+	}
+	store.sweeper = startStateSweeper(store, newStateStoreOptions(opts).sweepInterval)
+	return store, nil
+}
+
+func (s *boltStateStore) Get(ctx context.Context, namespace, key string) (*StateEntry, error) {
+	var entry *StateEntry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namespace))
+		if bucket == nil {
+			return nil
+		}
+
+		current, err := decodeStateEntry(bucket.Get([]byte(key)))
+		if err != nil {
+			return err
+		}
+		if current != nil && !current.expired(nowFrom(ctx, s.now)) {
+			entry = current
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	return entry, nil
+}
+
+func (s *boltStateStore) Set(ctx context.Context, namespace, key string, value []byte, opts StateSetOptions) (*StateEntry, error) {
+	var entry *StateEntry
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(namespace))
+		if err != nil {
+			return err
+		}
+
+		now := nowFrom(ctx, s.now)
+		current, err := decodeStateEntry(bucket.Get([]byte(key)))
+		if err != nil {
+			return err
+		}
+		if current != nil && current.expired(now) {
+			current = nil
+		}
+
+		if err := checkPreconditions(current, opts.IfVersion, opts.IfAbsent); err != nil {
+			return err
+		}
+
+		entry = nextEntry(current, value, opts.TTL, now)
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(key), data)
+	})
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	return entry, nil
+}
+
+func (s *boltStateStore) Delete(ctx context.Context, namespace, key string, ifVersion *int64) (bool, error) {
+	deleted := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namespace))
+		if bucket == nil {
+			return checkPreconditions(nil, ifVersion, false)
+		}
+
+		current, err := decodeStateEntry(bucket.Get([]byte(key)))
+		if err != nil {
+			return err
+		}
+		expired := current != nil && current.expired(nowFrom(ctx, s.now))
+		if expired {
+			current = nil
+		}
+
+		if err := checkPreconditions(current, ifVersion, false); err != nil {
+			return err
+		}
+
+		if current == nil {
+			if expired {
+				return bucket.Delete([]byte(key))
+			}
+			return nil
+		}
+
+		deleted = true
+		return bucket.Delete([]byte(key))
+	})
+	if err != nil {
+		return false, s.wrapError(err)
+	}
+	return deleted, nil
+}
+
+func (s *boltStateStore) List(ctx context.Context, namespace, prefix, after string, limit int) ([]string, error) {
+	keys := make([]string, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namespace))
+		if bucket == nil {
+			return nil
+		}
+
+		// Start at whichever comes later: the prefix or just past the cursor
+		seek := []byte(prefix)
+		if after != "" && after >= prefix {
+			seek = append([]byte(after), 0)
+		}
+
+		now := nowFrom(ctx, s.now)
+		cursor := bucket.Cursor()
+		for k, v := cursor.Seek(seek); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = cursor.Next() {
+			entry, err := decodeStateEntry(v)
+			if err != nil {
+				return err
+			}
+			if entry.expired(now) {
+				continue
+			}
+
+			keys = append(keys, string(k))
+			if limit > 0 && len(keys) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	return keys, nil
+}
+
+func (s *boltStateStore) DeleteExpired(ctx context.Context) (int, error) {
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		now := nowFrom(ctx, s.now)
+		return tx.ForEach(func(namespace []byte, bucket *bolt.Bucket) error {
+			var expired [][]byte
+			err := bucket.ForEach(func(k, v []byte) error {
+				entry, err := decodeStateEntry(v)
+				if err != nil {
+					return err
+				}
+				if entry.expired(now) {
+					expired = append(expired, k)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			// Keys are only valid during the transaction, which is still open
+			for _, k := range expired {
+				if err := bucket.Delete(k); err != nil {
+					return err
+				}
+			}
+			removed += len(expired)
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, s.wrapError(err)
+	}
+	return removed, nil
+}
+
+func (s *boltStateStore) Close() error {
+	s.sweeper.Stop()
+	return s.db.Close()
+}
+
+// wrapError maps bbolt errors onto store-level errors
+func (s *boltStateStore) wrapError(err error) error {
+	if errors.Is(err, bolt.ErrDatabaseNotOpen) {
+		return ErrStateStoreClosed
+	}
+	return err
+}
+
+// decodeStateEntry parses a stored record, returning nil for missing keys
+func decodeStateEntry(data []byte) (*StateEntry, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var entry StateEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("corrupt state entry: %w", err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,286 @@
+package hostapi
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Test Plan:
+// 1. Every backend satisfies the same StateStore contract
+// 2. Versions increase on each write and CAS preconditions are honoured
+// 3. Namespaces are isolated from each other
+// 4. Expired entries are invisible to Get, Delete and List
+// 5. List pages through keys in order using the cursor
+// 6. The bolt backend persists data across reopen
+// 7. DeleteExpired reclaims expired entries from the backend
+// 8. The background sweep reclaims expired entries nobody reads again
+
+// stateStoreFactories builds each backend with a controllable clock and no
+// background sweep
+func stateStoreFactories(t *testing.T) map[string]func(now func() time.Time) StateStore {
+	return map[string]func(now func() time.Time) StateStore{
+		"memory": func(now func() time.Time) StateStore {
+			store := NewMemoryStateStore(WithStateSweepInterval(0)).(*memoryStateStore)
+			store.now = now
+			return store
+		},
+		"bolt": func(now func() time.Time) StateStore {
+			store, err := NewBoltStateStore(filepath.Join(t.TempDir(), "state.db"), WithStateSweepInterval(0))
+			require.NoError(t, err)
+			store.(*boltStateStore).now = now
+			return store
+		},
+	}
+}
+
+func TestStateStore_Contract(t *testing.T) {
+	for name, newStore := range stateStoreFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(time.Now)
+			defer store.Close()
+
+			t.Run("get missing key", func(t *testing.T) {
+				entry, err := store.Get(ctx, "svc", "missing")
+				require.NoError(t, err)
+				assert.Nil(t, entry)
+			})
+
+			t.Run("set increments version", func(t *testing.T) {
+				first, err := store.Set(ctx, "svc", "counter", []byte(`1`), StateSetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), first.Version)
+
+				second, err := store.Set(ctx, "svc", "counter", []byte(`2`), StateSetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, int64(2), second.Version)
+
+				entry, err := store.Get(ctx, "svc", "counter")
+				require.NoError(t, err)
+				require.NotNil(t, entry)
+				assert.Equal(t, []byte(`2`), entry.Value)
+				assert.Equal(t, int64(2), entry.Version)
+			})
+
+			t.Run("compare and swap", func(t *testing.T) {
+				stale := int64(1)
+				_, err := store.Set(ctx, "svc", "counter", []byte(`3`), StateSetOptions{IfVersion: &stale})
+				assert.ErrorIs(t, err, ErrStateVersionConflict)
+
+				current := int64(2)
+				entry, err := store.Set(ctx, "svc", "counter", []byte(`3`), StateSetOptions{IfVersion: &current})
+				require.NoError(t, err)
+				assert.Equal(t, int64(3), entry.Version)
+
+				_, err = store.Set(ctx, "svc", "counter", []byte(`4`), StateSetOptions{IfAbsent: true})
+				assert.ErrorIs(t, err, ErrStateVersionConflict)
+
+				_, err = store.Set(ctx, "svc", "fresh", []byte(`true`), StateSetOptions{IfAbsent: true})
+				assert.NoError(t, err)
+			})
+
+			t.Run("delete with version", func(t *testing.T) {
+				stale := int64(1)
+				_, err := store.Delete(ctx, "svc", "counter", &stale)
+				assert.ErrorIs(t, err, ErrStateVersionConflict)
+
+				current := int64(3)
+				deleted, err := store.Delete(ctx, "svc", "counter", &current)
+				require.NoError(t, err)
+				assert.True(t, deleted)
+
+				deleted, err = store.Delete(ctx, "svc", "counter", nil)
+				require.NoError(t, err)
+				assert.False(t, deleted)
+			})
+
+			t.Run("namespaces are isolated", func(t *testing.T) {
+				_, err := store.Set(ctx, "svc-a", "shared", []byte(`"a"`), StateSetOptions{})
+				require.NoError(t, err)
+
+				entry, err := store.Get(ctx, "svc-b", "shared")
+				require.NoError(t, err)
+				assert.Nil(t, entry)
+
+				keys, err := store.List(ctx, "svc-b", "", "", 0)
+				require.NoError(t, err)
+				assert.Empty(t, keys)
+			})
+
+			t.Run("list pages in order", func(t *testing.T) {
+				for _, key := range []string{"user:3", "user:1", "user:2", "session:1"} {
+					_, err := store.Set(ctx, "paged", key, []byte(`{}`), StateSetOptions{})
+					require.NoError(t, err)
+				}
+
+				page, err := store.List(ctx, "paged", "user:", "", 2)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"user:1", "user:2"}, page)
+
+				page, err = store.List(ctx, "paged", "user:", "user:2", 2)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"user:3"}, page)
+
+				page, err = store.List(ctx, "paged", "user:", "user:3", 2)
+				require.NoError(t, err)
+				assert.Empty(t, page)
+			})
+		})
+	}
+}
+
+func TestStateStore_TTL(t *testing.T) {
+	for name, newStore := range stateStoreFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now()
+			store := newStore(func() time.Time { return now })
+			defer store.Close()
+
+			_, err := store.Set(ctx, "svc", "session:1", []byte(`"token"`), StateSetOptions{TTL: time.Minute})
+			require.NoError(t, err)
+
+			entry, err := store.Get(ctx, "svc", "session:1")
+			require.NoError(t, err)
+			require.NotNil(t, entry)
+			require.NotNil(t, entry.ExpiresAt)
+
+			// Advance past the TTL
+			now = now.Add(2 * time.Minute)
+
+			entry, err = store.Get(ctx, "svc", "session:1")
+			require.NoError(t, err)
+			assert.Nil(t, entry)
+
+			keys, err := store.List(ctx, "svc", "session:", "", 0)
+			require.NoError(t, err)
+			assert.Empty(t, keys)
+
+			// An expired key counts as absent for CAS purposes
+			entry, err = store.Set(ctx, "svc", "session:1", []byte(`"new"`), StateSetOptions{IfAbsent: true})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), entry.Version)
+		})
+	}
+}
+
+// storedKeys counts the entries a backend holds for namespace, expired or not
+func storedKeys(t *testing.T, store StateStore, namespace string) int {
+	t.Helper()
+	switch store := store.(type) {
+	case *memoryStateStore:
+		store.mu.RLock()
+		defer store.mu.RUnlock()
+		return len(store.namespaces[namespace])
+	case *boltStateStore:
+		count := 0
+		require.NoError(t, store.db.View(func(tx *bolt.Tx) error {
+			if bucket := tx.Bucket([]byte(namespace)); bucket != nil {
+				count = bucket.Stats().KeyN
+			}
+			return nil
+		}))
+		return count
+	default:
+		t.Fatalf("unknown store %T", store)
+		return 0
+	}
+}
+
+func TestStateStore_DeleteExpired(t *testing.T) {
+	for name, newStore := range stateStoreFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now()
+			store := newStore(func() time.Time { return now })
+			defer store.Close()
+
+			_, err := store.Set(ctx, "svc", "session:1", []byte(`1`), StateSetOptions{TTL: time.Minute})
+			require.NoError(t, err)
+			_, err = store.Set(ctx, "svc", "session:2", []byte(`2`), StateSetOptions{TTL: time.Hour})
+			require.NoError(t, err)
+			_, err = store.Set(ctx, "other", "config", []byte(`3`), StateSetOptions{})
+			require.NoError(t, err)
+
+			removed, err := store.DeleteExpired(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 0, removed)
+
+			// Only session:1 has expired; nothing reads it, yet it is reclaimed
+			now = now.Add(2 * time.Minute)
+			removed, err = store.DeleteExpired(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 1, removed)
+			assert.Equal(t, 1, storedKeys(t, store, "svc"))
+			assert.Equal(t, 1, storedKeys(t, store, "other"))
+
+			entry, err := store.Get(ctx, "svc", "session:2")
+			require.NoError(t, err)
+			assert.NotNil(t, entry)
+		})
+	}
+}
+
+func TestStateStore_BackgroundSweep(t *testing.T) {
+	stores := map[string]func() StateStore{
+		"memory": func() StateStore { return NewMemoryStateStore(WithStateSweepInterval(10 * time.Millisecond)) },
+		"bolt": func() StateStore {
+			store, err := NewBoltStateStore(filepath.Join(t.TempDir(), "state.db"), WithStateSweepInterval(10*time.Millisecond))
+			require.NoError(t, err)
+			return store
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore()
+			defer store.Close()
+
+			_, err := store.Set(context.Background(), "svc", "session:1", []byte(`1`), StateSetOptions{TTL: time.Millisecond})
+			require.NoError(t, err)
+
+			assert.Eventually(t, func() bool {
+				return storedKeys(t, store, "svc") == 0
+			}, 2*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestStateStore_Closed(t *testing.T) {
+	for name, newStore := range stateStoreFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(time.Now)
+			require.NoError(t, store.Close())
+
+			_, err := store.Get(context.Background(), "svc", "key")
+			assert.ErrorIs(t, err, ErrStateStoreClosed)
+		})
+	}
+}
+
+func TestBoltStateStore_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "state.db")
+
+	store, err := NewBoltStateStore(path)
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, "svc", "config:mode", []byte(`"production"`), StateSetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	reopened, err := NewBoltStateStore(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	entry, err := reopened.Get(ctx, "svc", "config:mode")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, []byte(`"production"`), entry.Value)
+	assert.Equal(t, int64(1), entry.Version)
+}
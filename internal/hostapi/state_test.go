@@ -0,0 +1,311 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test get/set/delete round trip through a HostAPISet
+// 2. Test code-level validation (key format, sizes, TTL bounds)
+// 3. Test compare-and-swap error mapping
+// 4. Test service isolation across sets created from one factory
+// 5. Test list streaming through the HostAPISet iterator machinery
+// 6. Test factory metadata and registration
+// 7. Test entries expire by the set's HostAPIConfig.Clock with every backend
+
+// newStateTestSet creates a HostAPISet with the state API for the given service
+func newStateTestSet(t *testing.T, factory HostAPIFactory, service string) HostAPISet {
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(factory))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{StateAPIName}, HostAPIConfig{
+		ServiceName:  service,
+		PolicyEngine: &mockPolicyEngine{},
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		Logger:       slog.Default(),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+// requireHostAPIError asserts err is a HostAPIError with the given code
+func requireHostAPIError(t *testing.T, err error, code string) {
+	t.Helper()
+	require.Error(t, err)
+
+	var apiErr *HostAPIError
+	require.True(t, errors.As(err, &apiErr), "expected HostAPIError, got %v", err)
+	assert.Equal(t, code, apiErr.Code)
+}
+
+func TestStateAPI_GetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	set := newStateTestSet(t, NewStateAPIFactory(), "acme/users")
+
+	// Missing key
+	result, err := set.Execute(ctx, StateAPIName, "get", json.RawMessage(`{"key":"user:1"}`))
+	require.NoError(t, err)
+	var getResp StateGetResponse
+	require.NoError(t, json.Unmarshal(result, &getResp))
+	assert.False(t, getResp.Exists)
+
+	// Set a structured value
+	result, err = set.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"user:1","value":{"name":"Ada"},"ttl":60}`))
+	require.NoError(t, err)
+	var setResp StateSetResponse
+	require.NoError(t, json.Unmarshal(result, &setResp))
+	assert.Equal(t, int64(1), setResp.Version)
+
+	// Read it back
+	result, err = set.Execute(ctx, StateAPIName, "get", json.RawMessage(`{"key":"user:1"}`))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(result, &getResp))
+	assert.True(t, getResp.Exists)
+	assert.Equal(t, int64(1), getResp.Version)
+	assert.JSONEq(t, `{"name":"Ada"}`, string(getResp.Value))
+	assert.NotNil(t, getResp.LastModified)
+
+	// Delete it
+	result, err = set.Execute(ctx, StateAPIName, "delete", json.RawMessage(`{"key":"user:1"}`))
+	require.NoError(t, err)
+	var delResp StateDeleteResponse
+	require.NoError(t, json.Unmarshal(result, &delResp))
+	assert.True(t, delResp.Deleted)
+}
+
+func TestStateAPI_Validation(t *testing.T) {
+	ctx := context.Background()
+	set := newStateTestSet(t, NewStateAPIFactory(), "acme/users")
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		code   string
+	}{
+		{"malformed parameters", "get", `{"key":`, ErrorCodeInvalidParameters},
+		{"empty key", "get", `{"key":""}`, ErrorCodeInvalidKey},
+		{"invalid characters", "get", `{"key":"user 1"}`, ErrorCodeInvalidKey},
+		{"path traversal", "get", `{"key":"../etc/passwd"}`, ErrorCodeInvalidKey},
+		{"absolute path", "set", `{"key":"/root","value":1}`, ErrorCodeInvalidKey},
+		{"key too long", "get", `{"key":"` + strings.Repeat("k", 513) + `"}`, ErrorCodeKeyTooLong},
+		{"missing value", "set", `{"key":"a"}`, ErrorCodeInvalidParameters},
+		{"ttl too small", "set", `{"key":"a","value":1,"ttl":0}`, ErrorCodeInvalidTTL},
+		{"ttl too large", "set", `{"key":"a","value":1,"ttl":40000000}`, ErrorCodeInvalidTTL},
+		{"unknown method", "increment", `{}`, ErrorCodeMethodNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := set.Execute(ctx, StateAPIName, tt.method, json.RawMessage(tt.params))
+			requireHostAPIError(t, err, tt.code)
+		})
+	}
+
+	t.Run("value too large", func(t *testing.T) {
+		factory := NewStateAPIFactory(WithStateConfig(StateConfig{
+			MaxKeyLength:     512,
+			MaxValueSize:     8,
+			DefaultListLimit: 10,
+			MaxListLimit:     10,
+		}))
+		small := newStateTestSet(t, factory, "acme/users")
+
+		_, err := small.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"a","value":"0123456789"}`))
+		requireHostAPIError(t, err, ErrorCodeValueTooLarge)
+	})
+}
+
+func TestStateAPI_CompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	set := newStateTestSet(t, NewStateAPIFactory(), "acme/users")
+
+	_, err := set.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"lock","value":"owner-a","ifAbsent":true}`))
+	require.NoError(t, err)
+
+	_, err = set.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"lock","value":"owner-b","ifAbsent":true}`))
+	requireHostAPIError(t, err, ErrorCodeVersionConflict)
+
+	_, err = set.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"lock","value":"owner-b","ifVersion":7}`))
+	requireHostAPIError(t, err, ErrorCodeVersionConflict)
+
+	_, err = set.Execute(ctx, StateAPIName, "delete", json.RawMessage(`{"key":"lock","ifVersion":7}`))
+	requireHostAPIError(t, err, ErrorCodeVersionConflict)
+
+	result, err := set.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"lock","value":"owner-b","ifVersion":1}`))
+	require.NoError(t, err)
+	var setResp StateSetResponse
+	require.NoError(t, json.Unmarshal(result, &setResp))
+	assert.Equal(t, int64(2), setResp.Version)
+}
+
+func TestStateAPI_ServiceIsolation(t *testing.T) {
+	ctx := context.Background()
+	factory := NewStateAPIFactory()
+	users := newStateTestSet(t, factory, "acme/users")
+	orders := newStateTestSet(t, factory, "acme/orders")
+
+	_, err := users.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"secret","value":"users-only"}`))
+	require.NoError(t, err)
+
+	result, err := orders.Execute(ctx, StateAPIName, "get", json.RawMessage(`{"key":"secret"}`))
+	require.NoError(t, err)
+	var getResp StateGetResponse
+	require.NoError(t, json.Unmarshal(result, &getResp))
+	assert.False(t, getResp.Exists)
+
+	// A second set for the same service (e.g. another worker) shares its data
+	usersWorker2 := newStateTestSet(t, factory, "acme/users")
+	result, err = usersWorker2.Execute(ctx, StateAPIName, "get", json.RawMessage(`{"key":"secret"}`))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(result, &getResp))
+	assert.True(t, getResp.Exists)
+}
+
+func TestStateAPI_ListStreaming(t *testing.T) {
+	ctx := context.Background()
+	set := newStateTestSet(t, NewStateAPIFactory(), "acme/users")
+
+	for _, key := range []string{"user:1", "user:2", "user:3", "user:4", "user:5", "order:1"} {
+		_, err := set.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"`+key+`","value":true}`))
+		require.NoError(t, err)
+	}
+
+	result, err := set.Execute(ctx, StateAPIName, "list", json.RawMessage(`{"prefix":"user:","limit":2}`))
+	require.NoError(t, err)
+
+	var streamResp StreamingResponse
+	require.NoError(t, json.Unmarshal(result, &streamResp))
+	require.NotEmpty(t, streamResp.IteratorID)
+	assert.True(t, streamResp.HasData)
+
+	var keys []string
+	chunks := 0
+	for {
+		data, hasMore, err := set.NextIterator(ctx, streamResp.IteratorID)
+		require.NoError(t, err)
+		chunks++
+
+		var chunk StateListChunk
+		require.NoError(t, json.Unmarshal(data, &chunk))
+		keys = append(keys, chunk.Keys...)
+
+		if !hasMore {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"user:1", "user:2", "user:3", "user:4", "user:5"}, keys)
+	assert.Equal(t, 3, chunks)
+
+	// The exhausted iterator is released by the set
+	_, _, err = set.NextIterator(ctx, streamResp.IteratorID)
+	requireHostAPIError(t, err, ErrorCodeIteratorNotFound)
+}
+
+func TestStateAPI_ListEmpty(t *testing.T) {
+	ctx := context.Background()
+	set := newStateTestSet(t, NewStateAPIFactory(), "acme/users")
+
+	result, err := set.Execute(ctx, StateAPIName, "list", json.RawMessage(`{"prefix":"none:"}`))
+	require.NoError(t, err)
+
+	var streamResp StreamingResponse
+	require.NoError(t, json.Unmarshal(result, &streamResp))
+	assert.False(t, streamResp.HasData)
+
+	data, hasMore, err := set.NextIterator(ctx, streamResp.IteratorID)
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.JSONEq(t, `{"keys":[]}`, string(data))
+}
+
+func TestStateAPI_TTLUsesConfigClock(t *testing.T) {
+	stores := map[string]func() StateStore{
+		"memory": func() StateStore { return NewMemoryStateStore() },
+		"bolt": func() StateStore {
+			store, err := NewBoltStateStore(filepath.Join(t.TempDir(), "state.db"))
+			require.NoError(t, err)
+			return store
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore()
+			defer store.Close()
+
+			clock := NewFakeClock(time.Now())
+			registry := NewHostAPIRegistry()
+			require.NoError(t, registry.Register(NewStateAPIFactory(WithStateStore(store))))
+			set, err := registry.CreateHostAPISet(ctx, []string{StateAPIName}, HostAPIConfig{
+				ServiceName:  "acme/users",
+				PolicyEngine: &mockPolicyEngine{},
+				Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+				Meter:        metricnoop.NewMeterProvider().Meter("test"),
+				Clock:        clock,
+			})
+			require.NoError(t, err)
+			defer set.Close()
+
+			_, err = set.Execute(ctx, StateAPIName, "set", json.RawMessage(`{"key":"session","value":1,"ttl":10}`))
+			require.NoError(t, err)
+
+			// Test: The entry lives until the set's clock passes its TTL, however
+			// little real time has gone by
+			get := func() StateGetResponse {
+				result, err := set.Execute(ctx, StateAPIName, "get", json.RawMessage(`{"key":"session"}`))
+				require.NoError(t, err)
+				var resp StateGetResponse
+				require.NoError(t, json.Unmarshal(result, &resp))
+				return resp
+			}
+			assert.True(t, get().Exists)
+			clock.Advance(10 * time.Second)
+			assert.False(t, get().Exists)
+
+			result, err := set.Execute(ctx, StateAPIName, "list", json.RawMessage(`{}`))
+			require.NoError(t, err)
+			var stream StreamingResponse
+			require.NoError(t, json.Unmarshal(result, &stream))
+			assert.False(t, stream.HasData)
+		})
+	}
+}
+
+func TestStateAPIFactory(t *testing.T) {
+	factory := NewStateAPIFactory()
+	assert.Equal(t, StateAPIName, factory.Name())
+	assert.Equal(t, StateAPIVersion, factory.Version())
+
+	methods := make(map[string]MethodMetadata)
+	for _, m := range factory.Methods() {
+		methods[m.Name] = m
+	}
+	require.Contains(t, methods, "list")
+	assert.True(t, methods["list"].Streaming)
+	assert.False(t, methods["get"].Streaming)
+	assert.Equal(t, []string{"key", "value"}, methods["set"].Parameters.Required)
+
+	_, err := factory.Create(context.Background(), HostAPIConfig{})
+	assert.Error(t, err, "service name is required")
+
+	registry := NewHostAPIRegistry()
+	require.NoError(t, InitializeHostAPIs(registry))
+	_, ok := registry.Get(StateAPIName)
+	assert.True(t, ok)
+}
@@ -0,0 +1,321 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	_ "time/tzdata" // Timezone validation must not depend on the host's zoneinfo
+
+	"github.com/go-openapi/spec"
+)
+
+const (
+	// TimeAPIName is the namespace of the time host API
+	TimeAPIName = "okra.time"
+
+	// TimeAPIVersion is the current version of the time host API
+	TimeAPIVersion = "v1.0.0"
+)
+
+// Time API error codes
+const (
+	// ErrorCodeInvalidTimezone indicates the timezone is not a known IANA identifier
+	ErrorCodeInvalidTimezone = "INVALID_TIMEZONE"
+
+	// ErrorCodeInvalidDuration indicates a sleep duration outside the allowed bounds
+	ErrorCodeInvalidDuration = "INVALID_DURATION"
+
+	// ErrorCodeSleepInterrupted indicates the sleep was cancelled before it finished
+	ErrorCodeSleepInterrupted = "SLEEP_INTERRUPTED"
+)
+
+// TimeInfo describes an instant in a timezone
+type TimeInfo struct {
+	Timestamp int64  `json:"timestamp"` // Unix milliseconds
+	Timezone  string `json:"timezone"`  // IANA identifier
+	ISO8601   string `json:"iso8601"`
+}
+
+// TimeNowRequest is the payload for time.now
+type TimeNowRequest struct {
+	Timezone string `json:"timezone,omitempty"` // Default: TimeConfig.DefaultTimezone
+}
+
+// TimeSleepRequest is the payload for time.sleep
+type TimeSleepRequest struct {
+	Milliseconds int64 `json:"milliseconds"`
+}
+
+// TimeSleepResponse is the result of time.sleep
+type TimeSleepResponse struct {
+	Slept int64 `json:"slept"` // Milliseconds
+}
+
+// TimeConvertRequest is the payload for time.convert. Exactly one of
+// Timestamp and ISO8601 identifies the instant.
+type TimeConvertRequest struct {
+	Timestamp *int64 `json:"timestamp,omitempty"` // Unix milliseconds
+	ISO8601   string `json:"iso8601,omitempty"`
+	Timezone  string `json:"timezone"`
+}
+
+// TimeConfig holds the code-level limits enforced by the time API
+type TimeConfig struct {
+	MinSleep        time.Duration
+	MaxSleep        time.Duration
+	DefaultTimezone string
+}
+
+// defaultTimeConfig returns the limits described in docs/host-apis/time.md
+func defaultTimeConfig() TimeConfig {
+	return TimeConfig{
+		MinSleep:        time.Millisecond,
+		MaxSleep:        24 * time.Hour,
+		DefaultTimezone: "UTC",
+	}
+}
+
+// TimeAPIOption configures the time API factory
+type TimeAPIOption func(*timeAPIFactory)
+
+// WithTimeConfig overrides the default time limits
+func WithTimeConfig(config TimeConfig) TimeAPIOption {
+	return func(f *timeAPIFactory) {
+		f.config = config
+	}
+}
+
+// timeAPIFactory creates okra.time instances
+type timeAPIFactory struct {
+	config TimeConfig
+}
+
+// NewTimeAPIFactory creates the okra.time host API factory.
+// Instances read HostAPIConfig.Clock, so a FakeClock makes them deterministic.
+func NewTimeAPIFactory(opts ...TimeAPIOption) HostAPIFactory {
+	factory := &timeAPIFactory{
+		config: defaultTimeConfig(),
+	}
+
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	return factory
+}
+
+func (f *timeAPIFactory) Name() string    { return TimeAPIName }
+func (f *timeAPIFactory) Version() string { return TimeAPIVersion }
+
+func (f *timeAPIFactory) Create(ctx context.Context, hostConfig HostAPIConfig) (HostAPI, error) {
+	defaultLocation, err := time.LoadLocation(f.config.DefaultTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default timezone %q: %w", f.config.DefaultTimezone, err)
+	}
+
+	return &timeAPI{
+		config:          f.config,
+		clock:           clockOrSystem(hostConfig.Clock),
+		defaultLocation: defaultLocation,
+	}, nil
+}
+
+func (f *timeAPIFactory) Methods() []MethodMetadata {
+	timeInfo := objectSchema(map[string]spec.Schema{
+		"timestamp": *spec.Int64Property().WithDescription("Unix timestamp in milliseconds"),
+		"timezone":  *spec.StringProperty().WithDescription("IANA timezone identifier"),
+		"iso8601":   *spec.StringProperty(),
+	}, "timestamp", "timezone", "iso8601")
+	timezone := *spec.StringProperty().WithDescription("IANA timezone identifier, e.g. America/New_York")
+	invalidTimezone := ErrorMetadata{Code: ErrorCodeInvalidTimezone, Description: "Unknown IANA timezone"}
+
+	return []MethodMetadata{
+		{
+			Name:        "now",
+			Description: "Get the current time",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"timezone": timezone,
+			}),
+			Returns: timeInfo,
+			Errors:  []ErrorMetadata{invalidTimezone},
+		},
+		{
+			Name:        "sleep",
+			Description: "Pause execution for a number of milliseconds",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"milliseconds": *spec.Int64Property().
+					WithMinimum(float64(f.config.MinSleep.Milliseconds()), false).
+					WithMaximum(float64(f.config.MaxSleep.Milliseconds()), false),
+			}, "milliseconds"),
+			Returns: objectSchema(map[string]spec.Schema{
+				"slept": *spec.Int64Property().WithDescription("Milliseconds slept"),
+			}, "slept"),
+			Errors: []ErrorMetadata{
+				{Code: ErrorCodeInvalidDuration, Description: "Duration outside the allowed bounds"},
+				{Code: ErrorCodeSleepInterrupted, Description: "The request was cancelled while sleeping"},
+			},
+		},
+		{
+			Name:        "convert",
+			Description: "Express an instant in another timezone",
+			Parameters: objectSchema(map[string]spec.Schema{
+				"timestamp": *spec.Int64Property().WithDescription("Unix timestamp in milliseconds"),
+				"iso8601":   *spec.StringProperty().WithDescription("Used when timestamp is omitted"),
+				"timezone":  timezone,
+			}, "timezone"),
+			Returns: timeInfo,
+			Errors: []ErrorMetadata{
+				invalidTimezone,
+				{Code: ErrorCodeInvalidParameters, Description: "Missing or malformed instant"},
+			},
+		},
+	}
+}
+
+// timeAPI serves time from the configured clock
+type timeAPI struct {
+	config          TimeConfig
+	clock           Clock
+	defaultLocation *time.Location
+}
+
+// Compile-time interface compliance checks
+var (
+	_ HostAPI        = (*timeAPI)(nil)
+	_ HostAPIFactory = (*timeAPIFactory)(nil)
+)
+
+func (t *timeAPI) Name() string    { return TimeAPIName }
+func (t *timeAPI) Version() string { return TimeAPIVersion }
+
+func (t *timeAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	switch method {
+	case "now":
+		return t.now(parameters)
+	case "sleep":
+		return t.sleep(ctx, parameters)
+	case "convert":
+		return t.convert(parameters)
+	default:
+		return nil, &HostAPIError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: fmt.Sprintf("unknown method: %s", method),
+		}
+	}
+}
+
+func (t *timeAPI) now(parameters json.RawMessage) (json.RawMessage, error) {
+	var req TimeNowRequest
+	if len(parameters) > 0 {
+		if err := unmarshalParameters(parameters, &req); err != nil {
+			return nil, err
+		}
+	}
+
+	location, err := t.location(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(timeInfo(t.clock.Now(), location))
+}
+
+func (t *timeAPI) sleep(ctx context.Context, parameters json.RawMessage) (json.RawMessage, error) {
+	var req TimeSleepRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+
+	// Compare in milliseconds so huge values cannot overflow the Duration
+	if req.Milliseconds < t.config.MinSleep.Milliseconds() || req.Milliseconds > t.config.MaxSleep.Milliseconds() {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidDuration,
+			Message: fmt.Sprintf("sleep must be between %dms and %dms", t.config.MinSleep.Milliseconds(), t.config.MaxSleep.Milliseconds()),
+		}
+	}
+
+	if err := t.clock.Sleep(ctx, time.Duration(req.Milliseconds)*time.Millisecond); err != nil {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeSleepInterrupted,
+			Message: "sleep was interrupted",
+			Details: err.Error(),
+		}
+	}
+
+	return json.Marshal(TimeSleepResponse{Slept: req.Milliseconds})
+}
+
+func (t *timeAPI) convert(parameters json.RawMessage) (json.RawMessage, error) {
+	var req TimeConvertRequest
+	if err := unmarshalParameters(parameters, &req); err != nil {
+		return nil, err
+	}
+
+	if req.Timezone == "" {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidTimezone,
+			Message: "timezone is required",
+		}
+	}
+	location, err := t.location(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var instant time.Time
+	switch {
+	case req.Timestamp != nil:
+		instant = time.UnixMilli(*req.Timestamp)
+	case req.ISO8601 != "":
+		instant, err = time.Parse(time.RFC3339Nano, req.ISO8601)
+		if err != nil {
+			return nil, &HostAPIError{
+				Code:    ErrorCodeInvalidParameters,
+				Message: "iso8601 must be an RFC 3339 timestamp",
+				Details: err.Error(),
+			}
+		}
+	default:
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidParameters,
+			Message: "timestamp or iso8601 is required",
+		}
+	}
+
+	return json.Marshal(timeInfo(instant, location))
+}
+
+// location resolves an IANA timezone, defaulting to the configured one
+func (t *timeAPI) location(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return t.defaultLocation, nil
+	}
+
+	// "Local" would leak the host's timezone; guests get IANA names only
+	if timezone == "Local" {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidTimezone,
+			Message: "the host's local timezone is not available to services",
+		}
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, &HostAPIError{
+			Code:    ErrorCodeInvalidTimezone,
+			Message: fmt.Sprintf("unknown timezone: %s", timezone),
+		}
+	}
+	return location, nil
+}
+
+// timeInfo renders an instant at millisecond precision in location
+func timeInfo(instant time.Time, location *time.Location) TimeInfo {
+	instant = instant.In(location).Truncate(time.Millisecond)
+	return TimeInfo{
+		Timestamp: instant.UnixMilli(),
+		Timezone:  location.String(),
+		ISO8601:   instant.Format("2006-01-02T15:04:05.000Z07:00"),
+	}
+}
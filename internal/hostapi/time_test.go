@@ -0,0 +1,184 @@
+package hostapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test now reads the configured clock and renders timezones, with or without parameters
+// 2. Test sleep waits on the clock, honours bounds and is interruptible
+// 3. Test convert from timestamps and ISO 8601 strings
+// 4. Test FakeClock advancing, setting and monotonic time
+// 5. Test the system clock is used when none is configured
+
+// newTimeTestSet creates a HostAPISet with the time API reading clock
+func newTimeTestSet(t *testing.T, clock Clock, opts ...TimeAPIOption) HostAPISet {
+	registry := NewHostAPIRegistry()
+	require.NoError(t, registry.Register(NewTimeAPIFactory(opts...)))
+
+	set, err := registry.CreateHostAPISet(context.Background(), []string{TimeAPIName}, HostAPIConfig{
+		ServiceName:  "acme/clock",
+		PolicyEngine: &mockPolicyEngine{},
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		Clock:        clock,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { set.Close() })
+	return set
+}
+
+func timeCall(t *testing.T, set HostAPISet, method, params string) TimeInfo {
+	t.Helper()
+	result, err := set.Execute(context.Background(), TimeAPIName, method, json.RawMessage(params))
+	require.NoError(t, err)
+
+	var info TimeInfo
+	require.NoError(t, json.Unmarshal(result, &info))
+	return info
+}
+
+func TestTimeAPI_Now(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 30, 0, 123456789, time.UTC))
+	set := newTimeTestSet(t, clock)
+
+	info := timeCall(t, set, "now", `{}`)
+	assert.Equal(t, TimeInfo{Timestamp: 1704112200123, Timezone: "UTC", ISO8601: "2024-01-01T12:30:00.123Z"}, info)
+
+	// Parameters are optional
+	info = timeCall(t, set, "now", ``)
+	assert.Equal(t, "UTC", info.Timezone)
+
+	clock.Advance(time.Hour)
+	info = timeCall(t, set, "now", `{"timezone":"America/New_York"}`)
+	assert.Equal(t, TimeInfo{Timestamp: 1704115800123, Timezone: "America/New_York", ISO8601: "2024-01-01T08:30:00.123-05:00"}, info)
+
+	for _, timezone := range []string{"Mars/Olympus", "Local", "../etc/passwd"} {
+		_, err := set.Execute(context.Background(), TimeAPIName, "now", json.RawMessage(`{"timezone":"`+timezone+`"}`))
+		requireHostAPIError(t, err, ErrorCodeInvalidTimezone)
+	}
+}
+
+func TestTimeAPI_Sleep(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	set := newTimeTestSet(t, clock, WithTimeConfig(TimeConfig{
+		MinSleep:        time.Millisecond,
+		MaxSleep:        time.Minute,
+		DefaultTimezone: "UTC",
+	}))
+
+	done := make(chan json.RawMessage, 1)
+	go func() {
+		result, err := set.Execute(context.Background(), TimeAPIName, "sleep", json.RawMessage(`{"milliseconds":5000}`))
+		assert.NoError(t, err)
+		done <- result
+	}()
+
+	require.Eventually(t, func() bool { return clock.Sleepers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(4 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("sleep returned before the clock reached its deadline")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case result := <-done:
+		assert.JSONEq(t, `{"slept":5000}`, string(result))
+	case <-time.After(time.Second):
+		t.Fatal("sleep did not return after the clock advanced")
+	}
+
+	for _, ms := range []string{"0", "-1", "60001", "9223372036854775807"} {
+		_, err := set.Execute(context.Background(), TimeAPIName, "sleep", json.RawMessage(`{"milliseconds":`+ms+`}`))
+		requireHostAPIError(t, err, ErrorCodeInvalidDuration)
+	}
+
+	// Cancelling the request interrupts the sleep
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := set.Execute(ctx, TimeAPIName, "sleep", json.RawMessage(`{"milliseconds":1000}`))
+	requireHostAPIError(t, err, ErrorCodeSleepInterrupted)
+	assert.Equal(t, 0, clock.Sleepers())
+}
+
+func TestTimeAPI_Convert(t *testing.T) {
+	set := newTimeTestSet(t, NewFakeClock(time.Unix(0, 0)))
+
+	info := timeCall(t, set, "convert", `{"timestamp":1704067200000,"timezone":"Asia/Tokyo"}`)
+	assert.Equal(t, TimeInfo{Timestamp: 1704067200000, Timezone: "Asia/Tokyo", ISO8601: "2024-01-01T09:00:00.000+09:00"}, info)
+
+	info = timeCall(t, set, "convert", `{"iso8601":"2024-07-01T12:00:00+02:00","timezone":"Europe/London"}`)
+	assert.Equal(t, TimeInfo{Timestamp: 1719828000000, Timezone: "Europe/London", ISO8601: "2024-07-01T11:00:00.000+01:00"}, info)
+
+	tests := []struct {
+		params string
+		code   string
+	}{
+		{`{"timestamp":0}`, ErrorCodeInvalidTimezone},
+		{`{"timestamp":0,"timezone":"Nowhere"}`, ErrorCodeInvalidTimezone},
+		{`{"timezone":"UTC"}`, ErrorCodeInvalidParameters},
+		{`{"iso8601":"yesterday","timezone":"UTC"}`, ErrorCodeInvalidParameters},
+	}
+	for _, tt := range tests {
+		_, err := set.Execute(context.Background(), TimeAPIName, "convert", json.RawMessage(tt.params))
+		requireHostAPIError(t, err, tt.code)
+	}
+
+	_, err := set.Execute(context.Background(), TimeAPIName, "tick", json.RawMessage(`{}`))
+	requireHostAPIError(t, err, ErrorCodeMethodNotFound)
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	assert.Equal(t, start, clock.Now())
+	assert.Equal(t, int64(0), clock.Nanotime())
+
+	clock.Advance(1500 * time.Millisecond)
+	assert.Equal(t, start.Add(1500*time.Millisecond), clock.Now())
+	assert.Equal(t, int64(1500*time.Millisecond), clock.Nanotime())
+
+	// Set wakes every sleeper whose deadline has passed
+	woken := make(chan time.Duration, 2)
+	for _, d := range []time.Duration{time.Second, time.Hour} {
+		go func(d time.Duration) {
+			if clock.Sleep(context.Background(), d) == nil {
+				woken <- d
+			}
+		}(d)
+	}
+	require.Eventually(t, func() bool { return clock.Sleepers() == 2 }, time.Second, time.Millisecond)
+
+	clock.Set(start.Add(time.Minute))
+	assert.Equal(t, time.Second, <-woken)
+	assert.Equal(t, 1, clock.Sleepers())
+	clock.Advance(time.Hour)
+	assert.Equal(t, time.Hour, <-woken)
+
+	assert.NoError(t, clock.Sleep(context.Background(), 0))
+}
+
+func TestTimeAPI_SystemClockByDefault(t *testing.T) {
+	set := newTimeTestSet(t, nil)
+
+	before := time.Now().Truncate(time.Millisecond)
+	info := timeCall(t, set, "now", `{}`)
+	assert.GreaterOrEqual(t, info.Timestamp, before.UnixMilli())
+	assert.LessOrEqual(t, info.Timestamp, time.Now().UnixMilli())
+
+	clock := NewSystemClock()
+	first := clock.Nanotime()
+	require.NoError(t, clock.Sleep(context.Background(), time.Millisecond))
+	assert.Greater(t, clock.Nanotime(), first)
+}
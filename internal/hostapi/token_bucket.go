@@ -0,0 +1,62 @@
+package hostapi
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a thread-safe token bucket rate limiter
+type tokenBucket struct {
+	rate   float64 // tokens added per second
+	burst  float64 // maximum tokens held
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// newTokenBucket creates a full bucket that refills at rate tokens per second
+func newTokenBucket(rate float64, burst int, now func() time.Time) *tokenBucket {
+	if now == nil {
+		now = time.Now
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now(),
+		now:    now,
+	}
+}
+
+// take consumes n tokens if available. When the bucket is short it returns
+// false along with how long the caller should wait before retrying.
+func (b *tokenBucket) take(n float64) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+
+	if b.tokens >= n {
+		b.tokens -= n
+		return true, 0
+	}
+
+	if b.rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	wait := time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+	return false, wait
+}
+
+// refund returns n tokens taken by a call that was rejected elsewhere
+func (b *tokenBucket) refund(n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+n)
+}
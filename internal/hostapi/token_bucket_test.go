@@ -0,0 +1,40 @@
+package hostapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test Plan:
+// 1. Test a new bucket allows a full burst
+// 2. Test an empty bucket reports how long to wait
+// 3. Test tokens refill at the configured rate up to the burst size
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	bucket := newTokenBucket(2, 3, func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		ok, _ := bucket.take(1)
+		assert.True(t, ok, "take %d should succeed", i)
+	}
+
+	ok, wait := bucket.take(1)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = bucket.take(1)
+	assert.True(t, ok)
+
+	// Refill never exceeds the burst size
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ = bucket.take(1)
+		assert.True(t, ok)
+	}
+	ok, _ = bucket.take(1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,267 @@
+package hostapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version such as v1.2.3
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string // e.g. "beta.1"; build metadata is dropped
+}
+
+// ParseVersion parses a full semantic version, with or without a leading "v"
+func ParseVersion(s string) (Version, error) {
+	v, parts, err := parsePartial(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if parts != 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected major.minor.patch", s)
+	}
+	return v, nil
+}
+
+// String returns the version with a leading "v"
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v is lower than, equal to or higher than o.
+// A prerelease sorts before its release.
+func (v Version) Compare(o Version) int {
+	for _, d := range [...]int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// parsePartial parses a version that may omit minor and patch or use "x" or
+// "*" for them. It returns how many numeric parts were present.
+func parsePartial(s string) (Version, int, error) {
+	raw := s
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, prerelease, _ := strings.Cut(s, "-")
+
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 || s == "" {
+		return Version{}, 0, fmt.Errorf("invalid version %q", raw)
+	}
+
+	var numbers [3]int
+	parts := 0
+	for i, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			continue
+		}
+		if parts < i {
+			return Version{}, 0, fmt.Errorf("invalid version %q: wildcard before a number", raw)
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return Version{}, 0, fmt.Errorf("invalid version %q", raw)
+		}
+		numbers[i] = n
+		parts++
+	}
+	if prerelease != "" && parts != 3 {
+		return Version{}, 0, fmt.Errorf("invalid version %q: prerelease needs major.minor.patch", raw)
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Prerelease: prerelease}, parts, nil
+}
+
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1 // Numeric identifiers sort before alphanumeric ones
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// VersionRange is a set of versions written the way package managers do:
+// "^1.2.0", "~1.2", "1.x", ">=1.0.0 <2.0.0", "1.2.3", or "*". Space-separated
+// comparators must all match; "||" separates alternatives.
+type VersionRange struct {
+	raw  string
+	sets [][]comparator
+}
+
+type comparator struct {
+	op      string // "=", ">", ">=", "<" or "<="
+	version Version
+}
+
+// ParseVersionRange parses a version range; an empty range matches any version
+func ParseVersionRange(s string) (VersionRange, error) {
+	r := VersionRange{raw: strings.TrimSpace(s)}
+	for _, alternative := range strings.Split(r.raw, "||") {
+		var set []comparator
+		for _, term := range strings.Fields(alternative) {
+			comparators, err := parseTerm(term)
+			if err != nil {
+				return VersionRange{}, fmt.Errorf("invalid version range %q: %w", s, err)
+			}
+			set = append(set, comparators...)
+		}
+		r.sets = append(r.sets, set)
+	}
+	return r, nil
+}
+
+// String returns the range as written
+func (r VersionRange) String() string {
+	if r.raw == "" {
+		return "*"
+	}
+	return r.raw
+}
+
+// Contains reports whether v is in the range
+func (r VersionRange) Contains(v Version) bool {
+	if len(r.sets) == 0 {
+		return true
+	}
+	for _, set := range r.sets {
+		if matchesAll(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAll(set []comparator, v Version) bool {
+	for _, c := range set {
+		cmp := v.Compare(c.version)
+		var ok bool
+		switch c.op {
+		case "=":
+			ok = cmp == 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTerm expands one term of a range into plain comparators
+func parseTerm(term string) ([]comparator, error) {
+	if term == "*" || term == "x" || term == "X" {
+		return nil, nil
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if rest, ok := strings.CutPrefix(term, op); ok {
+			v, _, err := parsePartial(rest)
+			if err != nil {
+				return nil, err
+			}
+			return []comparator{{op: op, version: v}}, nil
+		}
+	}
+
+	prefix := term[0]
+	if prefix == '^' || prefix == '~' {
+		term = term[1:]
+	}
+	v, parts, err := parsePartial(term)
+	if err != nil {
+		return nil, err
+	}
+	if parts == 0 {
+		return nil, nil
+	}
+	lower := comparator{op: ">=", version: v}
+
+	var upper Version
+	switch {
+	case prefix == '^' && v.Major > 0, parts == 1:
+		upper = Version{Major: v.Major + 1}
+	case prefix == '^' && v.Minor > 0, prefix == '~', parts == 2:
+		upper = Version{Major: v.Major, Minor: v.Minor + 1}
+	case prefix == '^':
+		upper = Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	default:
+		return []comparator{{op: "=", version: v}}, nil
+	}
+	// Exclude the upper bound's prereleases, so ^1.0.0 does not match v2.0.0-beta
+	upper.Prerelease = "0"
+	return []comparator{lower, {op: "<", version: upper}}, nil
+}
+
+// bestMatch returns the index of the version to bind for r: the highest
+// matching release, or the highest matching prerelease if no release matches.
+// It returns -1 if nothing matches.
+func bestMatch(versions []Version, r VersionRange) int {
+	best := -1
+	for i, v := range versions {
+		if !r.Contains(v) {
+			continue
+		}
+		if best < 0 {
+			best = i
+			continue
+		}
+		current := versions[best]
+		isRelease, bestIsRelease := v.Prerelease == "", current.Prerelease == ""
+		if (isRelease && !bestIsRelease) || (isRelease == bestIsRelease && v.Compare(current) > 0) {
+			best = i
+		}
+	}
+	return best
+}
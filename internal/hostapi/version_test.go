@@ -0,0 +1,134 @@
+package hostapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test ParseVersion accepts full versions and rejects partial or malformed ones
+// 2. Test Compare orders versions, with prereleases before their release
+// 3. Test version ranges match the versions package managers would
+// 4. Test malformed ranges are rejected
+// 5. Test CheckRequirements reports every unmet requirement and accepts any offered version
+
+func TestParseVersion(t *testing.T) {
+	// Test: Full versions parse with or without "v"; build metadata is dropped
+	v, err := ParseVersion("v1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, Version{Major: 1, Minor: 2, Patch: 3}, v)
+
+	v, err = ParseVersion("1.2.3-beta.1+build.5")
+	require.NoError(t, err)
+	assert.Equal(t, Version{Major: 1, Minor: 2, Patch: 3, Prerelease: "beta.1"}, v)
+	assert.Equal(t, "v1.2.3-beta.1", v.String())
+
+	// Test: Partial and malformed versions are rejected
+	for _, s := range []string{"", "1", "1.2", "1.2.x", "1.2.3.4", "a.b.c", "1.-2.3"} {
+		_, err := ParseVersion(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	// Test: Versions are ordered numerically, prereleases before their release
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.2.0", "1.10.0", "2.0.0",
+	}
+	for i := 1; i < len(ordered); i++ {
+		lower, err := ParseVersion(ordered[i-1])
+		require.NoError(t, err)
+		higher, err := ParseVersion(ordered[i])
+		require.NoError(t, err)
+
+		assert.Equal(t, -1, lower.Compare(higher), "%s < %s", lower, higher)
+		assert.Equal(t, 1, higher.Compare(lower), "%s > %s", higher, lower)
+		assert.Equal(t, 0, higher.Compare(higher))
+	}
+}
+
+func TestVersionRange_Contains(t *testing.T) {
+	tests := []struct {
+		rng     string
+		match   []string
+		noMatch []string
+	}{
+		{rng: "", match: []string{"0.0.1", "9.9.9"}},
+		{rng: "*", match: []string{"1.0.0"}},
+		{rng: "1.2.3", match: []string{"1.2.3"}, noMatch: []string{"1.2.4", "1.2.3-beta"}},
+		{rng: "^1.2.0", match: []string{"1.2.0", "1.9.9"}, noMatch: []string{"1.1.9", "2.0.0", "2.0.0-beta"}},
+		{rng: "^0.2.1", match: []string{"0.2.1", "0.2.9"}, noMatch: []string{"0.3.0", "0.2.0"}},
+		{rng: "^0.0.3", match: []string{"0.0.3"}, noMatch: []string{"0.0.4"}},
+		{rng: "~1.2.3", match: []string{"1.2.3", "1.2.9"}, noMatch: []string{"1.3.0"}},
+		{rng: "~1", match: []string{"1.0.0", "1.9.0"}, noMatch: []string{"2.0.0"}},
+		{rng: "1.x", match: []string{"1.0.0", "1.5.2"}, noMatch: []string{"2.0.0", "0.9.0"}},
+		{rng: "1.2", match: []string{"1.2.0", "1.2.7"}, noMatch: []string{"1.3.0"}},
+		{rng: ">=1.0.0 <2.0.0", match: []string{"1.0.0", "1.9.9"}, noMatch: []string{"0.9.9", "2.0.0"}},
+		{rng: ">1.0.0 <=1.2.0", match: []string{"1.0.1", "1.2.0"}, noMatch: []string{"1.0.0", "1.2.1"}},
+		{rng: "^1.0.0 || ^3.0.0", match: []string{"1.4.0", "3.1.0"}, noMatch: []string{"2.0.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rng, func(t *testing.T) {
+			// Test: The range contains exactly the expected versions
+			r, err := ParseVersionRange(tt.rng)
+			require.NoError(t, err)
+			for _, s := range tt.match {
+				v, err := ParseVersion(s)
+				require.NoError(t, err)
+				assert.True(t, r.Contains(v), "%s should match %s", tt.rng, s)
+			}
+			for _, s := range tt.noMatch {
+				v, err := ParseVersion(s)
+				require.NoError(t, err)
+				assert.False(t, r.Contains(v), "%s should not match %s", tt.rng, s)
+			}
+		})
+	}
+}
+
+func TestParseVersionRange_Invalid(t *testing.T) {
+	// Test: Malformed ranges are rejected
+	for _, s := range []string{"^x.y", "1.x.3", ">=banana", "1.2.3.4", "~1.x-beta"} {
+		_, err := ParseVersionRange(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestCatalog_CheckRequirements(t *testing.T) {
+	catalog := Catalog{HostAPIs: []HostAPIDescription{
+		{Name: "okra.log", Version: "v1.0.0"},
+		{Name: "okra.state", Version: "v1.3.0"},
+	}}
+
+	// Test: Satisfied declarations return the names, sorted
+	names, err := catalog.CheckRequirements(map[string]string{"okra.state": "^1.2.0", "okra.log": ""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"okra.log", "okra.state"}, names)
+
+	// Test: Every unmet requirement is reported
+	_, err = catalog.CheckRequirements(map[string]string{
+		"okra.state": "^2.0.0",
+		"okra.kv":    "^1.0.0",
+		"okra.log":   "^^1",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "okra.state: runtime offers v1.3.0, service needs ^2.0.0")
+	assert.Contains(t, err.Error(), "okra.kv: not offered by the runtime")
+	assert.Contains(t, err.Error(), `okra.log: invalid version range "^^1"`)
+
+	// Test: Any offered version can satisfy a declaration
+	catalog.HostAPIs = append(catalog.HostAPIs, HostAPIDescription{Name: "okra.state", Version: "v2.0.0"})
+	_, err = catalog.CheckRequirements(map[string]string{"okra.state": "^2.0.0"})
+	require.NoError(t, err)
+	_, err = catalog.CheckRequirements(map[string]string{"okra.state": "^3.0.0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "okra.state: runtime offers v1.3.0 and v2.0.0, service needs ^3.0.0")
+
+	latest, ok := catalog.Lookup("okra.state")
+	require.True(t, ok)
+	assert.Equal(t, "v2.0.0", latest.Version)
+}
@@ -0,0 +1,241 @@
+// Package migrations generates and applies SQL migrations from model schemas
+package migrations
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/okra-platform/okra/internal/schema"
+)
+
+// Plan is the set of changes between two schema versions
+type Plan struct {
+	Changes []string // Human-readable summary, e.g. "+ column users.email"
+	Up      []string // Statements that migrate from the old schema to the new one
+	Down    []string // Statements that revert Up
+}
+
+// Empty reports whether the plan contains no changes
+func (p *Plan) Empty() bool {
+	return len(p.Up) == 0
+}
+
+// UpSQL renders the up statements as a migration file
+func (p *Plan) UpSQL() string {
+	return renderSQL(p.Up)
+}
+
+// DownSQL renders the down statements as a migration file
+func (p *Plan) DownSQL() string {
+	return renderSQL(p.Down)
+}
+
+// step is one reversible unit of a plan
+type step struct {
+	changes []string
+	up      []string
+	down    []string
+}
+
+// Diff computes the SQLite statements that turn the tables of from into the tables of to.
+// Either side may be nil, meaning no tables.
+func Diff(from, to *schema.Schema) (*Plan, error) {
+	fromTables, err := tablesOf(from)
+	if err != nil {
+		return nil, fmt.Errorf("previous schema: %w", err)
+	}
+	toTables, err := tablesOf(to)
+	if err != nil {
+		return nil, err
+	}
+
+	oldByName := map[string]schema.Table{}
+	for _, table := range fromTables {
+		oldByName[table.Name] = table
+	}
+	newByName := map[string]schema.Table{}
+	for _, table := range toTables {
+		newByName[table.Name] = table
+	}
+
+	steps := []step{}
+
+	// Tables are sorted by name, so plans are deterministic
+	for _, table := range fromTables {
+		if _, ok := newByName[table.Name]; ok {
+			continue
+		}
+		create, err := createTableWithIndexes(table)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step{
+			changes: []string{"- table " + table.Name},
+			up:      []string{fmt.Sprintf("DROP TABLE %s;", quote(table.Name))},
+			down:    create,
+		})
+	}
+
+	for _, table := range toTables {
+		old, ok := oldByName[table.Name]
+		if !ok {
+			create, err := createTableWithIndexes(table)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step{
+				changes: []string{"+ table " + table.Name},
+				up:      create,
+				down:    []string{fmt.Sprintf("DROP TABLE %s;", quote(table.Name))},
+			})
+			continue
+		}
+
+		tableSteps, err := diffTable(old, table)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, tableSteps...)
+	}
+
+	plan := &Plan{Changes: []string{}, Up: []string{}, Down: []string{}}
+	for _, s := range steps {
+		plan.Changes = append(plan.Changes, s.changes...)
+		plan.Up = append(plan.Up, s.up...)
+	}
+	// Down reverts steps in reverse order
+	for i := len(steps) - 1; i >= 0; i-- {
+		plan.Down = append(plan.Down, steps[i].down...)
+	}
+	return plan, nil
+}
+
+// diffTable compares two versions of the same table. Added columns that
+// SQLite can add in place use ALTER TABLE; anything else rebuilds the table.
+func diffTable(from, to schema.Table) ([]step, error) {
+	changes := []string{}
+	added := []schema.Column{}
+	rebuild := !reflect.DeepEqual(from.PrimaryKey, to.PrimaryKey)
+	if rebuild {
+		changes = append(changes, fmt.Sprintf("~ primary key %s (%s)", to.Name, strings.Join(to.PrimaryKey, ", ")))
+	}
+
+	for _, column := range from.Columns {
+		if _, ok := to.Column(column.Name); !ok {
+			changes = append(changes, fmt.Sprintf("- column %s.%s", to.Name, column.Name))
+			rebuild = true
+		}
+	}
+	for _, column := range to.Columns {
+		old, ok := from.Column(column.Name)
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("+ column %s.%s", to.Name, column.Name))
+			added = append(added, column)
+			if !canAddColumn(to, column) {
+				rebuild = true
+			}
+		case !reflect.DeepEqual(old, column):
+			changes = append(changes, fmt.Sprintf("~ column %s.%s", to.Name, column.Name))
+			rebuild = true
+		}
+	}
+
+	if rebuild {
+		up, err := rebuildTable(from, to)
+		if err != nil {
+			return nil, err
+		}
+		down, err := rebuildTable(to, from)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range indexChanges(from, to) {
+			changes = append(changes, change.changes...)
+		}
+		return []step{{changes: changes, up: up, down: down}}, nil
+	}
+
+	steps := []step{}
+	for _, column := range added {
+		definition, err := columnDefinition(column)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step{
+			changes: []string{fmt.Sprintf("+ column %s.%s", to.Name, column.Name)},
+			up:      []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", quote(to.Name), definition)},
+			down:    []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", quote(to.Name), quote(column.Name))},
+		})
+	}
+	return append(steps, indexChanges(from, to)...), nil
+}
+
+// indexChanges drops removed or changed indexes and creates new ones
+func indexChanges(from, to schema.Table) []step {
+	oldByName := map[string]schema.Index{}
+	for _, index := range from.Indexes {
+		oldByName[index.Name] = index
+	}
+	newByName := map[string]schema.Index{}
+	for _, index := range to.Indexes {
+		newByName[index.Name] = index
+	}
+
+	steps := []step{}
+	for _, index := range from.Indexes {
+		if updated, ok := newByName[index.Name]; !ok || !reflect.DeepEqual(index, updated) {
+			steps = append(steps, step{
+				changes: []string{fmt.Sprintf("- index %s", index.Name)},
+				up:      []string{dropIndex(index)},
+				down:    []string{createIndex(from.Name, index)},
+			})
+		}
+	}
+	for _, index := range to.Indexes {
+		if old, ok := oldByName[index.Name]; !ok || !reflect.DeepEqual(index, old) {
+			steps = append(steps, step{
+				changes: []string{fmt.Sprintf("+ index %s", index.Name)},
+				up:      []string{createIndex(to.Name, index)},
+				down:    []string{dropIndex(index)},
+			})
+		}
+	}
+	return steps
+}
+
+// canAddColumn reports whether SQLite's ADD COLUMN supports the column:
+// it cannot be part of the primary key, needs a constant default and,
+// when NOT NULL, a non-null default.
+func canAddColumn(table schema.Table, column schema.Column) bool {
+	for _, name := range table.PrimaryKey {
+		if name == column.Name {
+			return false
+		}
+	}
+	if column.Default == nil {
+		return !column.NotNull
+	}
+	switch *column.Default {
+	case schema.DefaultNow, schema.DefaultUUID, schema.DefaultAutoincrement:
+		return false
+	}
+	return true
+}
+
+// tablesOf derives tables from a schema, treating nil as empty
+func tablesOf(s *schema.Schema) ([]schema.Table, error) {
+	if s == nil {
+		return []schema.Table{}, nil
+	}
+	return s.Tables()
+}
+
+// renderSQL joins statements into a migration file
+func renderSQL(statements []string) string {
+	if len(statements) == 0 {
+		return ""
+	}
+	return strings.Join(statements, "\n\n") + "\n"
+}
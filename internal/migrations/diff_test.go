@@ -0,0 +1,124 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okra-platform/okra/internal/schema"
+)
+
+// Test Plan:
+// 1. Test new models produce CREATE TABLE and CREATE INDEX statements
+// 2. Test addable columns use ALTER TABLE and other changes rebuild the table
+// 3. Test index changes and dropped tables
+// 4. Test down statements revert steps in reverse order
+
+func parseSchema(t *testing.T, input string) *schema.Schema {
+	s, err := schema.ParseSchema(input)
+	require.NoError(t, err)
+	return s
+}
+
+func TestDiff_CreateTable(t *testing.T) {
+	to := parseSchema(t, `
+model User {
+  id: Int! @default(value: autoincrement)
+  email: String! @unique
+  name: String @default(value: "it's me")
+  active: Boolean! @default(value: false)
+  createdAt: DateTime! @default(value: now)
+}`)
+
+	plan, err := Diff(nil, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"+ table users"}, plan.Changes)
+	assert.Equal(t, []string{
+		"CREATE TABLE `users` (\n" +
+			"  `id` INTEGER PRIMARY KEY AUTOINCREMENT,\n" +
+			"  `email` TEXT NOT NULL,\n" +
+			"  `name` TEXT DEFAULT 'it''s me',\n" +
+			"  `active` INTEGER NOT NULL DEFAULT 0,\n" +
+			"  `created_at` TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP\n" +
+			");",
+		"CREATE UNIQUE INDEX `uq_users_email` ON `users` (`email`);",
+	}, plan.Up)
+	assert.Equal(t, []string{"DROP TABLE `users`;"}, plan.Down)
+
+	// Unchanged schemas produce an empty plan
+	plan, err = Diff(to, to)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+	assert.Equal(t, "", plan.UpSQL())
+}
+
+func TestDiff_AddColumn(t *testing.T) {
+	from := parseSchema(t, `model User { id: ID! }`)
+	to := parseSchema(t, `model User { id: ID! nickname: String @index score: Int! @default(value: 0) }`)
+
+	plan, err := Diff(from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"+ column users.nickname", "+ column users.score", "+ index idx_users_nickname"}, plan.Changes)
+	assert.Equal(t, []string{
+		"ALTER TABLE `users` ADD COLUMN `nickname` TEXT;",
+		"ALTER TABLE `users` ADD COLUMN `score` INTEGER NOT NULL DEFAULT 0;",
+		"CREATE INDEX `idx_users_nickname` ON `users` (`nickname`);",
+	}, plan.Up)
+	assert.Equal(t, []string{
+		"DROP INDEX `idx_users_nickname`;",
+		"ALTER TABLE `users` DROP COLUMN `score`;",
+		"ALTER TABLE `users` DROP COLUMN `nickname`;",
+	}, plan.Down)
+}
+
+func TestDiff_RebuildTable(t *testing.T) {
+	from := parseSchema(t, `model User { id: ID! name: String legacy: String }`)
+	to := parseSchema(t, `model User { id: ID! name: String! email: String! }`)
+
+	plan, err := Diff(from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"- column users.legacy", "~ column users.name", "+ column users.email"}, plan.Changes)
+	assert.Equal(t, []string{
+		"CREATE TABLE `_okra_new_users` (\n  `id` TEXT NOT NULL,\n  `name` TEXT NOT NULL,\n  `email` TEXT NOT NULL,\n  PRIMARY KEY (`id`)\n);",
+		"INSERT INTO `_okra_new_users` (`id`, `name`) SELECT `id`, `name` FROM `users`;",
+		"DROP TABLE `users`;",
+		"ALTER TABLE `_okra_new_users` RENAME TO `users`;",
+	}, plan.Up)
+	assert.Equal(t, []string{
+		"CREATE TABLE `_okra_new_users` (\n  `id` TEXT NOT NULL,\n  `name` TEXT,\n  `legacy` TEXT,\n  PRIMARY KEY (`id`)\n);",
+		"INSERT INTO `_okra_new_users` (`id`, `name`) SELECT `id`, `name` FROM `users`;",
+		"DROP TABLE `users`;",
+		"ALTER TABLE `_okra_new_users` RENAME TO `users`;",
+	}, plan.Down)
+}
+
+func TestDiff_IndexesAndDroppedTables(t *testing.T) {
+	from := parseSchema(t, `
+model User { id: ID! email: String @index }
+model Session { id: ID! }`)
+	to := parseSchema(t, `model User { id: ID! email: String @unique }`)
+
+	plan, err := Diff(from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"- table sessions", "- index idx_users_email", "+ index uq_users_email"}, plan.Changes)
+	assert.Equal(t, []string{
+		"DROP TABLE `sessions`;",
+		"DROP INDEX `idx_users_email`;",
+		"CREATE UNIQUE INDEX `uq_users_email` ON `users` (`email`);",
+	}, plan.Up)
+	assert.Equal(t, []string{
+		"DROP INDEX `uq_users_email`;",
+		"CREATE INDEX `idx_users_email` ON `users` (`email`);",
+		"CREATE TABLE `sessions` (\n  `id` TEXT NOT NULL,\n  PRIMARY KEY (`id`)\n);",
+	}, plan.Down)
+}
+
+func TestDiff_InvalidSchema(t *testing.T) {
+	_, err := Diff(nil, parseSchema(t, `model User @index(fields: ["nope"]) { id: ID! }`))
+	assert.Error(t, err)
+}
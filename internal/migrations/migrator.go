@@ -0,0 +1,204 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	// Registers the sqlite driver
+	_ "modernc.org/sqlite"
+)
+
+// migrationsTable records applied migrations in the target database
+const migrationsTable = "_okra_migrations"
+
+// MigrationStatus describes one migration relative to a database
+type MigrationStatus struct {
+	ID        string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool // Applied, but up.sql changed since
+	Missing   bool // Applied, but no longer on disk
+}
+
+// Migrator applies generated migrations to a database
+type Migrator interface {
+	// Apply runs all pending migrations in order and returns their IDs
+	Apply(ctx context.Context) ([]string, error)
+	// Status reports every migration on disk or recorded in the database
+	Status(ctx context.Context) ([]MigrationStatus, error)
+}
+
+type migrator struct {
+	db  *sql.DB
+	dir string
+}
+
+var _ Migrator = (*migrator)(nil)
+
+// NewMigrator creates a migrator for the migrations in dir
+func NewMigrator(db *sql.DB, dir string) Migrator {
+	return &migrator{db: db, dir: dir}
+}
+
+// OpenSQLite opens the database named by a sqlite:// URL or a plain file path,
+// creating its directory if needed
+func OpenSQLite(url string) (*sql.DB, error) {
+	path := strings.TrimPrefix(url, "sqlite://")
+	if strings.Contains(path, "://") {
+		return nil, fmt.Errorf("unsupported database URL %q: only sqlite:// is supported", url)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("database URL %q has no path", url)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return db, nil
+}
+
+// Apply runs pending migrations, each in its own transaction
+func (m *migrator) Apply(ctx context.Context) ([]string, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, status := range statuses {
+		if status.Modified {
+			return nil, fmt.Errorf("migration %s was modified after it was applied", status.ID)
+		}
+	}
+
+	applied := []string{}
+	for _, status := range statuses {
+		if status.Applied || status.Missing {
+			continue
+		}
+		if err := m.applyOne(ctx, status.ID); err != nil {
+			return applied, err
+		}
+		applied = append(applied, status.ID)
+	}
+	return applied, nil
+}
+
+func (m *migrator) applyOne(ctx context.Context, id string) error {
+	up, checksum, err := m.readUp(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", id, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, up); err != nil {
+		return fmt.Errorf("migration %s failed: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (id, checksum, applied_at) VALUES (?, ?, ?)", quote(migrationsTable)),
+		id, checksum, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", id, err)
+	}
+	return nil
+}
+
+// Status merges the migrations on disk with those recorded in the database
+func (m *migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY, checksum TEXT NOT NULL, applied_at TEXT NOT NULL)",
+		quote(migrationsTable))); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	type record struct {
+		checksum  string
+		appliedAt time.Time
+	}
+	records := map[string]record{}
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf("SELECT id, checksum, applied_at FROM %s", quote(migrationsTable)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, checksum, appliedAt string
+		if err := rows.Scan(&id, &checksum, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		at, err := time.Parse(time.RFC3339, appliedAt)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has invalid applied_at %q", id, appliedAt)
+		}
+		records[id] = record{checksum: checksum, appliedAt: at}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	ids, err := ListMigrations(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []MigrationStatus{}
+	onDisk := map[string]bool{}
+	for _, id := range ids {
+		onDisk[id] = true
+		status := MigrationStatus{ID: id}
+		if rec, ok := records[id]; ok {
+			_, checksum, err := m.readUp(id)
+			if err != nil {
+				return nil, err
+			}
+			appliedAt := rec.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = checksum != rec.checksum
+		}
+		statuses = append(statuses, status)
+	}
+	for id, rec := range records {
+		if onDisk[id] {
+			continue
+		}
+		appliedAt := rec.appliedAt
+		statuses = append(statuses, MigrationStatus{ID: id, Applied: true, AppliedAt: &appliedAt, Missing: true})
+	}
+
+	// IDs start with the generation timestamp
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	return statuses, nil
+}
+
+// readUp returns a migration's up.sql and its checksum
+func (m *migrator) readUp(id string) (string, string, error) {
+	data, err := os.ReadFile(filepath.Join(m.dir, id, UpFile))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read migration %s: %w", id, err)
+	}
+	sum := sha256.Sum256(data)
+	return string(data), hex.EncodeToString(sum[:]), nil
+}
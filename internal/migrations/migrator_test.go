@@ -0,0 +1,143 @@
+package migrations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test Generate writes up/down files and advances the snapshot
+// 2. Test Apply runs pending migrations against SQLite and Status reports them
+// 3. Test generated down SQL reverts a migration, preserving data across rebuilds
+// 4. Test modified migrations are rejected
+
+func TestGenerateAndApply(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	now := time.Date(2025, 7, 20, 12, 0, 0, 0, time.UTC)
+
+	db, err := OpenSQLite("sqlite://" + filepath.Join(dir, "data", "dev.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrationsDir := filepath.Join(dir, "migrations")
+	migrator := NewMigrator(db, migrationsDir)
+
+	// Nothing generated yet
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+
+	v1 := parseSchema(t, `model User { id: ID! name: String }`)
+	first, err := Generate(migrationsDir, v1, "create_users", now)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, "20250720120000_create_users", first.ID)
+	assert.Equal(t, []string{"+ table users"}, first.Changes)
+	assert.FileExists(t, filepath.Join(first.Dir, UpFile))
+	assert.FileExists(t, filepath.Join(first.Dir, DownFile))
+
+	// The snapshot now matches, so there is nothing new to generate
+	again, err := Generate(migrationsDir, v1, "", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, again)
+
+	v2 := parseSchema(t, `model User { id: ID! name: String! email: String @unique }`)
+	second, err := Generate(migrationsDir, v2, "", now.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	assert.Equal(t, "20250720130000", second.ID)
+
+	ids, err := ListMigrations(migrationsDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID, second.ID}, ids)
+
+	// Apply the first migration alone by hiding the second
+	hidden := filepath.Join(dir, "hidden")
+	require.NoError(t, os.Rename(second.Dir, hidden))
+	applied, err := migrator.Apply(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID}, applied)
+
+	_, err = db.Exec("INSERT INTO users (id, name) VALUES ('u1', 'Ada')")
+	require.NoError(t, err)
+
+	require.NoError(t, os.Rename(hidden, second.Dir))
+	applied, err = migrator.Apply(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.ID}, applied)
+
+	// The rebuild kept existing rows and added the unique index
+	var name string
+	require.NoError(t, db.QueryRow("SELECT name FROM users WHERE id = 'u1'").Scan(&name))
+	assert.Equal(t, "Ada", name)
+	_, err = db.Exec("INSERT INTO users (id, name, email) VALUES ('u2', 'Bob', 'b@x.io'), ('u3', 'Cy', 'b@x.io')")
+	assert.Error(t, err)
+
+	statuses, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.NotNil(t, status.AppliedAt)
+		assert.False(t, status.Modified)
+	}
+
+	// Nothing left to apply
+	applied, err = migrator.Apply(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// The generated down SQL reverts the second migration
+	down, err := os.ReadFile(filepath.Join(second.Dir, DownFile))
+	require.NoError(t, err)
+	_, err = db.Exec(string(down))
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO users (id) VALUES ('u4')")
+	assert.NoError(t, err, "name is nullable again")
+	_, err = db.Exec("SELECT email FROM users")
+	assert.Error(t, err, "email was dropped")
+}
+
+func TestApply_ModifiedMigration(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	db, err := OpenSQLite(filepath.Join(dir, "dev.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	migration, err := Generate(dir, parseSchema(t, `model User { id: ID! }`), "", time.Now())
+	require.NoError(t, err)
+
+	migrator := NewMigrator(db, dir)
+	_, err = migrator.Apply(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(migration.Dir, UpFile), []byte("SELECT 1;\n"), 0644))
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.True(t, statuses[0].Modified)
+
+	_, err = migrator.Apply(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "modified")
+}
+
+func TestGenerate_InvalidName(t *testing.T) {
+	_, err := Generate(t.TempDir(), parseSchema(t, `model User { id: ID! }`), "Add Users", time.Now())
+	assert.Error(t, err)
+}
+
+func TestOpenSQLite_UnsupportedURL(t *testing.T) {
+	_, err := OpenSQLite("postgres://localhost/db")
+	assert.Error(t, err)
+}
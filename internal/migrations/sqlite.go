@@ -0,0 +1,186 @@
+package migrations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/okra-platform/okra/internal/schema"
+)
+
+// rebuildPrefix names the temporary table used while rebuilding a table
+const rebuildPrefix = "_okra_new_"
+
+// quote quotes an identifier. Backticks are used because SQLite resolves an
+// unknown "double-quoted" name as a string literal instead of failing.
+func quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// quoteList quotes and joins identifiers
+func quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// sqliteType maps a column type to its SQLite storage class
+func sqliteType(columnType string) string {
+	switch columnType {
+	case schema.ColumnTypeInt, schema.ColumnTypeBoolean:
+		return "INTEGER"
+	case schema.ColumnTypeFloat:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteDefault renders a column default as a SQLite expression
+func sqliteDefault(column schema.Column) (string, error) {
+	value := *column.Default
+	switch value {
+	case schema.DefaultNow:
+		return "CURRENT_TIMESTAMP", nil
+	case schema.DefaultUUID:
+		return "(lower(hex(randomblob(16))))", nil
+	}
+
+	switch column.Type {
+	case schema.ColumnTypeBoolean:
+		switch value {
+		case "true":
+			return "1", nil
+		case "false":
+			return "0", nil
+		}
+		return "", fmt.Errorf("column %s: invalid Boolean default %q", column.Name, value)
+	case schema.ColumnTypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "", fmt.Errorf("column %s: invalid Int default %q", column.Name, value)
+		}
+		return value, nil
+	case schema.ColumnTypeFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("column %s: invalid Float default %q", column.Name, value)
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	default:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'", nil
+	}
+}
+
+// columnDefinition renders a column for CREATE TABLE or ADD COLUMN
+func columnDefinition(column schema.Column) (string, error) {
+	var b strings.Builder
+	b.WriteString(quote(column.Name))
+	b.WriteString(" ")
+	b.WriteString(sqliteType(column.Type))
+
+	if column.Default != nil && *column.Default == schema.DefaultAutoincrement {
+		// Only valid on an INTEGER single-column primary key, which schema.Tables enforces
+		b.WriteString(" PRIMARY KEY AUTOINCREMENT")
+		return b.String(), nil
+	}
+
+	if column.NotNull {
+		b.WriteString(" NOT NULL")
+	}
+	if column.Default != nil {
+		expr, err := sqliteDefault(column)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(" DEFAULT ")
+		b.WriteString(expr)
+	}
+	return b.String(), nil
+}
+
+// isAutoincrement reports whether the table's primary key is declared inline
+func isAutoincrement(table schema.Table) bool {
+	for _, column := range table.Columns {
+		if column.Default != nil && *column.Default == schema.DefaultAutoincrement {
+			return true
+		}
+	}
+	return false
+}
+
+// createTable renders CREATE TABLE for a table under the given name
+func createTable(table schema.Table, name string) (string, error) {
+	lines := make([]string, 0, len(table.Columns)+1)
+	for _, column := range table.Columns {
+		definition, err := columnDefinition(column)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, "  "+definition)
+	}
+	if len(table.PrimaryKey) > 0 && !isAutoincrement(table) {
+		lines = append(lines, "  PRIMARY KEY ("+quoteList(table.PrimaryKey)+")")
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n);", quote(name), strings.Join(lines, ",\n")), nil
+}
+
+// createIndex renders CREATE [UNIQUE] INDEX
+func createIndex(table string, index schema.Index) string {
+	kind := "INDEX"
+	if index.Unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s %s ON %s (%s);", kind, quote(index.Name), quote(table), quoteList(index.Columns))
+}
+
+// dropIndex renders DROP INDEX
+func dropIndex(index schema.Index) string {
+	return fmt.Sprintf("DROP INDEX %s;", quote(index.Name))
+}
+
+// createTableWithIndexes renders a table and all of its indexes
+func createTableWithIndexes(table schema.Table) ([]string, error) {
+	create, err := createTable(table, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	statements := []string{create}
+	for _, index := range table.Indexes {
+		statements = append(statements, createIndex(table.Name, index))
+	}
+	return statements, nil
+}
+
+// rebuildTable renders the copy-and-swap sequence SQLite needs for changes
+// ALTER TABLE cannot express. Data in columns present in both shapes is kept.
+func rebuildTable(from, to schema.Table) ([]string, error) {
+	temp := rebuildPrefix + to.Name
+	create, err := createTable(to, temp)
+	if err != nil {
+		return nil, err
+	}
+
+	common := []string{}
+	for _, column := range to.Columns {
+		if _, ok := from.Column(column.Name); ok {
+			common = append(common, column.Name)
+		}
+	}
+
+	statements := []string{create}
+	if len(common) > 0 {
+		statements = append(statements, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;",
+			quote(temp), quoteList(common), quoteList(common), quote(from.Name)))
+	}
+	statements = append(statements,
+		fmt.Sprintf("DROP TABLE %s;", quote(from.Name)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", quote(temp), quote(to.Name)),
+	)
+	for _, index := range to.Indexes {
+		statements = append(statements, createIndex(to.Name, index))
+	}
+	return statements, nil
+}
@@ -0,0 +1,136 @@
+package migrations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/okra-platform/okra/internal/schema"
+)
+
+const (
+	// SnapshotDir holds the schema captured by the latest generated migration
+	SnapshotDir = "snapshot"
+	// UpFile and DownFile are the SQL files inside each migration directory
+	UpFile   = "up.sql"
+	DownFile = "down.sql"
+	// schemaFile records the schema a migration was generated from
+	schemaFile = "schema.json"
+	// idFormat is the timestamp prefix of migration IDs, sortable as text
+	idFormat = "20060102150405"
+)
+
+// migrationNameRegex limits migration names to characters safe in a directory name
+var migrationNameRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Migration is a generated migration on disk
+type Migration struct {
+	ID      string
+	Dir     string
+	Changes []string
+}
+
+// LoadSnapshot reads the schema recorded by the last generated migration.
+// It returns nil when no migration has been generated yet.
+func LoadSnapshot(dir string) (*schema.Schema, error) {
+	data, err := os.ReadFile(filepath.Join(dir, SnapshotDir, schemaFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema snapshot: %w", err)
+	}
+
+	var snapshot schema.Schema
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse schema snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Pending diffs the current schema against the recorded snapshot
+func Pending(dir string, current *schema.Schema) (*Plan, error) {
+	snapshot, err := LoadSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(snapshot, current)
+}
+
+// Generate writes a migration for the changes between the snapshot and the
+// current schema, then advances the snapshot. It returns nil when the schema
+// has not changed.
+func Generate(dir string, current *schema.Schema, name string, now time.Time) (*Migration, error) {
+	if name != "" && !migrationNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q: use lower case letters, digits and underscores", name)
+	}
+
+	plan, err := Pending(dir, current)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Empty() {
+		return nil, nil
+	}
+
+	id := now.UTC().Format(idFormat)
+	if name != "" {
+		id += "_" + name
+	}
+	migrationDir := filepath.Join(dir, id)
+	if _, err := os.Stat(migrationDir); err == nil {
+		return nil, fmt.Errorf("migration %s already exists", id)
+	}
+
+	snapshot, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(migrationDir, UpFile):         plan.UpSQL(),
+		filepath.Join(migrationDir, DownFile):       plan.DownSQL(),
+		filepath.Join(migrationDir, schemaFile):     string(snapshot),
+		filepath.Join(dir, SnapshotDir, schemaFile): string(snapshot),
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create migration directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return &Migration{ID: id, Dir: migrationDir, Changes: plan.Changes}, nil
+}
+
+// ListMigrations returns the IDs of generated migrations in apply order
+func ListMigrations(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	ids := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == SnapshotDir {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), UpFile)); err != nil {
+			continue
+		}
+		ids = append(ids, entry.Name())
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
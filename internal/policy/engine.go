@@ -0,0 +1,451 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/cel-go/cel"
+	"github.com/okra-platform/okra/internal/hostapi"
+)
+
+// Engine is a hostapi.PolicyEngine that evaluates CEL policies. Policies can be
+// replaced at any time with Load or Reload; evaluations in flight keep using
+// the set they started with.
+//
+// CEL expressions see these variables:
+//   - service: calling service name
+//   - tags: the service's tags
+//   - api, method: the host API call, e.g. "okra.sql" and "query"
+//   - params: the decoded call parameters
+//   - request: {api, method, params, metadata} where metadata holds traceId,
+//     spanId, baggage and serviceInfo
+//   - context: PolicyCheck.Context, e.g. capability details
+//   - environment: the deployment environment
+//   - env: host environment values supplied with WithEnvironment
+//   - time: the current time as a CEL timestamp
+type Engine struct {
+	env          *cel.Env
+	clock        hostapi.Clock
+	environment  map[string]string
+	defaultAllow bool
+	logger       *slog.Logger
+	dir          string
+
+	programsMu sync.Mutex
+	programs   map[string]cel.Program // Compiled programs by expression
+
+	current atomic.Pointer[compiledSet]
+}
+
+// compiledSet is an immutable, fully compiled set of policies
+type compiledSet struct {
+	policies []*compiledPolicy
+}
+
+type compiledPolicy struct {
+	*Policy
+	rules  []compiledRule
+	grants map[string]cel.Program
+}
+
+type compiledRule struct {
+	key       string
+	rule      Rule
+	condition cel.Program
+}
+
+// EngineOption configures an Engine
+type EngineOption func(*Engine)
+
+// WithClock sets the clock used for the time variable
+func WithClock(clock hostapi.Clock) EngineOption {
+	return func(e *Engine) {
+		e.clock = clock
+	}
+}
+
+// WithEnvironment sets the values exposed to policies as env
+func WithEnvironment(environment map[string]string) EngineOption {
+	return func(e *Engine) {
+		e.environment = environment
+	}
+}
+
+// WithDefaultDeny denies calls that no policy rule matches. By default they are allowed.
+func WithDefaultDeny() EngineOption {
+	return func(e *Engine) {
+		e.defaultAllow = false
+	}
+}
+
+// WithLogger sets the logger used for reload failures
+func WithLogger(logger *slog.Logger) EngineOption {
+	return func(e *Engine) {
+		e.logger = logger
+	}
+}
+
+// Compile-time interface compliance check
+var _ hostapi.PolicyEngine = (*Engine)(nil)
+
+// NewEngine creates a CEL policy engine with no policies loaded
+func NewEngine(opts ...EngineOption) (*Engine, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("service", cel.StringType),
+		cel.Variable("tags", cel.ListType(cel.StringType)),
+		cel.Variable("api", cel.StringType),
+		cel.Variable("method", cel.StringType),
+		cel.Variable("params", cel.DynType),
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("context", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("environment", cel.StringType),
+		cel.Variable("env", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("time", cel.TimestampType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	engine := &Engine{
+		env:          env,
+		clock:        hostapi.NewSystemClock(),
+		environment:  map[string]string{},
+		defaultAllow: true,
+		logger:       slog.Default(),
+		programs:     make(map[string]cel.Program),
+	}
+
+	for _, opt := range opts {
+		opt(engine)
+	}
+
+	engine.current.Store(&compiledSet{})
+	return engine, nil
+}
+
+// NewEngineFromDir creates an engine with the policies in dir loaded
+func NewEngineFromDir(dir string, opts ...EngineOption) (*Engine, error) {
+	engine, err := NewEngine(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	engine.dir = dir
+	if err := engine.Reload(); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// Load compiles policies and swaps them in. On error the current policies stay active.
+func (e *Engine) Load(policies []*Policy) error {
+	e.programsMu.Lock()
+	defer e.programsMu.Unlock()
+
+	used := make(map[string]bool)
+	set := &compiledSet{}
+	for _, policy := range policies {
+		compiled := &compiledPolicy{Policy: policy, grants: make(map[string]cel.Program)}
+
+		keys := make([]string, 0, len(policy.Capabilities))
+		for key := range policy.Capabilities {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			rule := policy.Capabilities[key]
+			compiledRule := compiledRule{key: key, rule: rule}
+			if rule.Condition != "" {
+				program, err := e.program(rule.Condition)
+				if err != nil {
+					return fmt.Errorf("policy %s: capability %s: %w", policy.Name, key, err)
+				}
+				compiledRule.condition = program
+				used[rule.Condition] = true
+			}
+			compiled.rules = append(compiled.rules, compiledRule)
+		}
+
+		for capability, condition := range policy.Grants {
+			program, err := e.program(condition)
+			if err != nil {
+				return fmt.Errorf("policy %s: grant %s: %w", policy.Name, capability, err)
+			}
+			compiled.grants[capability] = program
+			used[condition] = true
+		}
+
+		set.policies = append(set.policies, compiled)
+	}
+
+	// Drop programs no policy uses any more
+	for expression := range e.programs {
+		if !used[expression] {
+			delete(e.programs, expression)
+		}
+	}
+
+	e.current.Store(set)
+	return nil
+}
+
+// Reload reloads the policy directory the engine was created from
+func (e *Engine) Reload() error {
+	if e.dir == "" {
+		return errors.New("engine has no policy directory")
+	}
+
+	policies, err := LoadDir(e.dir)
+	if err != nil {
+		return err
+	}
+	return e.Load(policies)
+}
+
+// Watch reloads the policy directory whenever it changes, until ctx is done.
+// A policy set that fails to load is logged and the previous one stays active.
+func (e *Engine) Watch(ctx context.Context) error {
+	if e.dir == "" {
+		return errors.New("engine has no policy directory")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create policy watcher: %w", err)
+	}
+	if err := watcher.Add(e.dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch policy directory: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		// Editors write files in several steps, so reload once changes settle
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				debounce = time.After(100 * time.Millisecond)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				e.logger.Error("policy watcher error", "error", err)
+			case <-debounce:
+				debounce = nil
+				if err := e.Reload(); err != nil {
+					e.logger.Error("failed to reload policies, keeping previous policies", "dir", e.dir, "error", err)
+					continue
+				}
+				e.logger.Info("reloaded policies", "dir", e.dir, "count", e.PolicyCount())
+			}
+		}
+	}()
+
+	return nil
+}
+
+// PolicyCount returns the number of active policies
+func (e *Engine) PolicyCount() int {
+	return len(e.current.Load().policies)
+}
+
+// program returns the cached program for expression, compiling it on first use.
+// Callers hold programsMu.
+func (e *Engine) program(expression string) (cel.Program, error) {
+	if program, ok := e.programs[expression]; ok {
+		return program, nil
+	}
+
+	ast, issues := e.env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid CEL expression %q: %w", expression, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("CEL expression %q must return a bool, not %s", expression, ast.OutputType())
+	}
+
+	program, err := e.env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build CEL program %q: %w", expression, err)
+	}
+
+	e.programs[expression] = program
+	return program, nil
+}
+
+// Evaluate implements hostapi.PolicyEngine. Every rule of every applicable
+// policy that matches the call must pass; their constraints are merged into
+// the decision metadata. Capability checks (Context["capability"]) also need
+// a grant whose condition holds.
+func (e *Engine) Evaluate(ctx context.Context, check hostapi.PolicyCheck) (hostapi.PolicyDecision, error) {
+	set := e.current.Load()
+	tags := contextTags(check.Context)
+	capability, _ := check.Context["capability"].(string)
+	vars := e.activation(check, tags)
+
+	decision := hostapi.PolicyDecision{Allowed: true, Metadata: make(map[string]interface{})}
+	matched := false
+	granted := false
+
+	for _, policy := range set.policies {
+		if !policy.appliesTo(check.Service, tags) {
+			continue
+		}
+
+		for _, rule := range policy.rules {
+			if !hostapi.MatchCapability(rule.key, check.Request.API, check.Request.Method) {
+				continue
+			}
+			matched = true
+
+			for key, value := range rule.rule.Constraints {
+				decision.Metadata[key] = value
+			}
+
+			if decision.Allowed {
+				if allowed, reason := evaluateRule(policy.Name, rule, vars, check); !allowed {
+					decision.Allowed = false
+					decision.Reason = reason
+				}
+			}
+		}
+
+		if program, ok := policy.grants[capability]; ok && capability != "" && !granted {
+			granted, _ = evaluateCondition(program, vars)
+		}
+	}
+
+	if capability != "" {
+		decision.Metadata[capability] = granted
+		if decision.Allowed && !granted {
+			decision.Allowed = false
+			decision.Reason = fmt.Sprintf("no policy grants capability %s to %s", capability, check.Service)
+		}
+		return decision, nil
+	}
+
+	if !matched && !e.defaultAllow {
+		decision.Allowed = false
+		decision.Reason = fmt.Sprintf("no policy allows %s.%s for %s", check.Request.API, check.Request.Method, check.Service)
+	}
+	return decision, nil
+}
+
+// evaluateRule applies a rule to a call, returning the denial reason if it fails
+func evaluateRule(policyName string, rule compiledRule, vars map[string]interface{}, check hostapi.PolicyCheck) (bool, string) {
+	reason := func(detail string) string {
+		if rule.rule.Reason != "" {
+			return rule.rule.Reason
+		}
+		return fmt.Sprintf("policy %s denies %s.%s: %s", policyName, check.Request.API, check.Request.Method, detail)
+	}
+
+	if rule.rule.Allow != nil && !*rule.rule.Allow {
+		return false, reason("not allowed")
+	}
+
+	if rule.condition != nil {
+		allowed, err := evaluateCondition(rule.condition, vars)
+		if err != nil {
+			return false, reason(fmt.Sprintf("condition failed: %v", err))
+		}
+		if !allowed {
+			return false, reason("condition not met")
+		}
+	}
+
+	return true, ""
+}
+
+// evaluateCondition runs a program that must produce a bool
+func evaluateCondition(program cel.Program, vars map[string]interface{}) (bool, error) {
+	out, _, err := program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition returned %T, not bool", out.Value())
+	}
+	return result, nil
+}
+
+// activation builds the CEL variables for a check
+func (e *Engine) activation(check hostapi.PolicyCheck, tags []string) map[string]interface{} {
+	var params interface{}
+	if len(check.Request.Parameters) > 0 {
+		// Undecodable parameters are left null; the host API rejects them later
+		_ = json.Unmarshal(check.Request.Parameters, &params)
+	}
+
+	var metadata map[string]interface{}
+	if data, err := json.Marshal(check.Request.Metadata); err == nil {
+		_ = json.Unmarshal(data, &metadata)
+	}
+
+	checkContext := make(map[string]interface{}, len(check.Context))
+	for key, value := range check.Context {
+		checkContext[key] = value
+	}
+
+	environment, _ := check.Context["environment"].(string)
+
+	now := e.clock.Now()
+	if t, ok := check.Context["time"].(time.Time); ok {
+		now = t
+	}
+
+	return map[string]interface{}{
+		"service": check.Service,
+		"tags":    tags,
+		"api":     check.Request.API,
+		"method":  check.Request.Method,
+		"params":  params,
+		"request": map[string]interface{}{
+			"api":      check.Request.API,
+			"version":  check.Request.Version,
+			"method":   check.Request.Method,
+			"params":   params,
+			"metadata": metadata,
+		},
+		"context":     checkContext,
+		"environment": environment,
+		"env":         e.environment,
+		"time":        now,
+	}
+}
+
+// contextTags reads the service tags the host API set passes in the check context
+func contextTags(checkContext map[string]interface{}) []string {
+	switch tags := checkContext["tags"].(type) {
+	case []string:
+		return tags
+	case []interface{}:
+		result := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return []string{}
+	}
+}
@@ -0,0 +1,304 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test policies target services by name pattern and by tag
+// 2. Test conditions see params, request metadata and version, environment, env and time
+// 3. Test constraints are merged into the decision metadata
+// 4. Test capability grants and default allow/deny
+// 5. Test invalid policies are rejected and leave the active set untouched
+// 6. Test hot reload from a watched directory
+// 7. Test the engine behind a HostAPISet
+// 8. Test rateLimit constraints are enforced by the HostAPISet
+
+func mustParse(t *testing.T, name, data string) *Policy {
+	t.Helper()
+	policy, err := ParsePolicy([]byte(data), name)
+	require.NoError(t, err)
+	return policy
+}
+
+func newTestEngine(t *testing.T, opts []EngineOption, policies ...*Policy) *Engine {
+	t.Helper()
+	engine, err := NewEngine(opts...)
+	require.NoError(t, err)
+	require.NoError(t, engine.Load(policies))
+	return engine
+}
+
+func check(service, api, method, params string, checkContext map[string]interface{}) hostapi.PolicyCheck {
+	if checkContext == nil {
+		checkContext = map[string]interface{}{}
+	}
+	return hostapi.PolicyCheck{
+		Service: service,
+		Request: hostapi.HostAPIRequest{API: api, Method: method, Parameters: json.RawMessage(params)},
+		Context: checkContext,
+	}
+}
+
+func evaluate(t *testing.T, engine *Engine, c hostapi.PolicyCheck) hostapi.PolicyDecision {
+	t.Helper()
+	decision, err := engine.Evaluate(context.Background(), c)
+	require.NoError(t, err)
+	return decision
+}
+
+func TestEngine_Targeting(t *testing.T) {
+	engine := newTestEngine(t, nil,
+		mustParse(t, "acme", `
+appliesTo:
+  - service: "acme/*"
+capabilities:
+  http.fetch:
+    allow: false
+    reason: acme services may not fetch
+`),
+		mustParse(t, "internal", `
+appliesTo:
+  - tag: internal
+capabilities:
+  okra.sql.*:
+    allow: false
+`))
+
+	decision := evaluate(t, engine, check("acme/orders", "okra.http", "fetch", `{}`, nil))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "acme services may not fetch", decision.Reason)
+
+	assert.True(t, evaluate(t, engine, check("other/orders", "okra.http", "fetch", `{}`, nil)).Allowed)
+	assert.True(t, evaluate(t, engine, check("acme/orders", "okra.sql", "query", `{}`, nil)).Allowed)
+
+	decision = evaluate(t, engine, check("other/orders", "okra.sql", "query", `{}`, map[string]interface{}{"tags": []string{"internal"}}))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "policy internal denies okra.sql.query: not allowed", decision.Reason)
+}
+
+func TestEngine_Conditions(t *testing.T) {
+	clock := hostapi.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	engine := newTestEngine(t, []EngineOption{WithClock(clock), WithEnvironment(map[string]string{"REGION": "eu"})},
+		mustParse(t, "time", `
+capabilities:
+  time.sleep:
+    condition: "params.milliseconds <= 1000 || environment != 'production'"
+  okra.http.fetch:
+    condition: "env.REGION == 'eu' && time.getHours() >= 8 && request.metadata.traceId != ''"
+    maxResponseSize: 1024
+`))
+
+	production := map[string]interface{}{"environment": "production"}
+	assert.True(t, evaluate(t, engine, check("svc", "okra.time", "sleep", `{"milliseconds":500}`, production)).Allowed)
+	decision := evaluate(t, engine, check("svc", "okra.time", "sleep", `{"milliseconds":5000}`, production))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "policy time denies okra.time.sleep: condition not met", decision.Reason)
+	assert.True(t, evaluate(t, engine, check("svc", "okra.time", "sleep", `{"milliseconds":5000}`, map[string]interface{}{"environment": "development"})).Allowed)
+
+	fetch := check("svc", "okra.http", "fetch", `{}`, nil)
+	fetch.Request.Metadata.TraceID = "abc"
+	decision = evaluate(t, engine, fetch)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 1024, decision.Metadata["maxResponseSize"])
+
+	// The check context's time wins over the engine clock
+	fetch.Context["time"] = time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)
+	assert.False(t, evaluate(t, engine, fetch).Allowed)
+
+	// Runtime errors, such as missing keys, deny instead of failing the call
+	engine = newTestEngine(t, nil, mustParse(t, "auth", `
+capabilities:
+  "*":
+    condition: "request.metadata.baggage.role == 'admin'"
+`))
+	decision = evaluate(t, engine, check("svc", "okra.state", "get", `{}`, nil))
+	assert.False(t, decision.Allowed)
+	assert.Contains(t, decision.Reason, "condition failed")
+
+	// Conditions can pin the host API version the service is bound to
+	engine = newTestEngine(t, nil, mustParse(t, "pinned", `
+capabilities:
+  okra.state:
+    condition: "request.version.startsWith('v2.')"
+`))
+	get := check("svc", "okra.state", "get", `{}`, nil)
+	get.Request.Version = "v1.0.0"
+	assert.False(t, evaluate(t, engine, get).Allowed)
+	get.Request.Version = "v2.1.0"
+	assert.True(t, evaluate(t, engine, get).Allowed)
+}
+
+func TestEngine_Grants(t *testing.T) {
+	engine := newTestEngine(t, nil,
+		mustParse(t, "grants", `
+appliesTo:
+  - service: acme/reports
+grants:
+  sql.raw: "true"
+  queue.topic: "context.topic.startsWith('acme/billing/')"
+`))
+
+	raw := map[string]interface{}{"capability": hostapi.CapabilitySQLRaw}
+	decision := evaluate(t, engine, check("acme/reports", "okra.sql", "raw", `{}`, raw))
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, true, decision.Metadata[hostapi.CapabilitySQLRaw])
+
+	decision = evaluate(t, engine, check("acme/orders", "okra.sql", "raw", `{}`, raw))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, false, decision.Metadata[hostapi.CapabilitySQLRaw])
+
+	topic := func(name string) map[string]interface{} {
+		return map[string]interface{}{"capability": hostapi.CapabilityQueueTopic, "topic": name, "namespace": "acme/billing"}
+	}
+	assert.True(t, evaluate(t, engine, check("acme/reports", "okra.queue", "publish", `{}`, topic("acme/billing/invoices"))).Allowed)
+	assert.False(t, evaluate(t, engine, check("acme/reports", "okra.queue", "publish", `{}`, topic("acme/hr/payroll"))).Allowed)
+}
+
+func TestEngine_DefaultDeny(t *testing.T) {
+	policy := mustParse(t, "state", `
+capabilities:
+  state: {}
+`)
+
+	engine := newTestEngine(t, []EngineOption{WithDefaultDeny()}, policy)
+	assert.True(t, evaluate(t, engine, check("svc", "okra.state", "get", `{}`, nil)).Allowed)
+	decision := evaluate(t, engine, check("svc", "okra.cache", "get", `{}`, nil))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "no policy allows okra.cache.get for svc", decision.Reason)
+
+	engine = newTestEngine(t, nil, policy)
+	assert.True(t, evaluate(t, engine, check("svc", "okra.cache", "get", `{}`, nil)).Allowed)
+}
+
+func TestEngine_InvalidPolicies(t *testing.T) {
+	engine := newTestEngine(t, nil, mustParse(t, "deny", `
+capabilities:
+  state:
+    allow: false
+`))
+
+	err := engine.Load([]*Policy{mustParse(t, "broken", `
+capabilities:
+  state:
+    condition: "params.key =="
+`)})
+	assert.ErrorContains(t, err, "invalid CEL expression")
+
+	err = engine.Load([]*Policy{mustParse(t, "typed", `
+grants:
+  sql.raw: "'yes'"
+`)})
+	assert.ErrorContains(t, err, "must return a bool")
+
+	// The previous policies are still active
+	assert.Equal(t, 1, engine.PolicyCount())
+	assert.False(t, evaluate(t, engine, check("svc", "okra.state", "get", `{}`, nil)).Allowed)
+}
+
+func TestEngine_WatchReloads(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0644))
+	}
+	write("state.yaml", "capabilities:\n  state:\n    allow: false\n")
+	write("README.md", "not a policy")
+
+	engine, err := NewEngineFromDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, engine.PolicyCount())
+	assert.False(t, evaluate(t, engine, check("svc", "okra.state", "get", `{}`, nil)).Allowed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, engine.Watch(ctx))
+
+	write("state.yaml", "capabilities:\n  state:\n    allow: true\n")
+	require.Eventually(t, func() bool {
+		return evaluate(t, engine, check("svc", "okra.state", "get", `{}`, nil)).Allowed
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// A broken edit keeps the last good policies
+	write("broken.json", `{"capabilities": {"state": {"condition": "(("}}}`)
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, 1, engine.PolicyCount())
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "broken.json")))
+	write("cache.yml", "capabilities:\n  cache:\n    allow: false\n")
+	require.Eventually(t, func() bool { return engine.PolicyCount() == 2 }, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestEngine_HostAPISet(t *testing.T) {
+	engine := newTestEngine(t, nil, mustParse(t, "sleep", `
+appliesTo:
+  - tag: batch
+capabilities:
+  time.sleep:
+    condition: "params.milliseconds <= 1000 && service == 'acme/jobs'"
+`))
+
+	registry := hostapi.NewHostAPIRegistry()
+	require.NoError(t, registry.Register(hostapi.NewTimeAPIFactory()))
+	clock := hostapi.NewFakeClock(time.Unix(0, 0))
+	set, err := registry.CreateHostAPISet(context.Background(), []string{hostapi.TimeAPIName}, hostapi.HostAPIConfig{
+		ServiceName:  "acme/jobs",
+		PolicyEngine: engine,
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		Clock:        clock,
+		Config:       &config.Config{Tags: []string{"batch"}},
+	})
+	require.NoError(t, err)
+	defer set.Close()
+
+	_, err = set.Execute(context.Background(), hostapi.TimeAPIName, "sleep", json.RawMessage(`{"milliseconds":5000}`))
+	var hostErr *hostapi.HostAPIError
+	require.ErrorAs(t, err, &hostErr)
+	assert.Equal(t, hostapi.ErrorCodePolicyDenied, hostErr.Code)
+
+	_, err = set.Execute(context.Background(), hostapi.TimeAPIName, "now", json.RawMessage(`{}`))
+	assert.NoError(t, err)
+}
+
+func TestEngine_RateLimit(t *testing.T) {
+	engine := newTestEngine(t, nil, mustParse(t, "limits", `
+capabilities:
+  time.now:
+    rateLimit:
+      requestsPerSecond: 1
+      dailyQuota: 100
+`))
+
+	registry := hostapi.NewHostAPIRegistry()
+	require.NoError(t, registry.Register(hostapi.NewTimeAPIFactory()))
+	set, err := registry.CreateHostAPISet(context.Background(), []string{hostapi.TimeAPIName}, hostapi.HostAPIConfig{
+		ServiceName:  "acme/jobs",
+		PolicyEngine: engine,
+		Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+		Meter:        metricnoop.NewMeterProvider().Meter("test"),
+		Clock:        hostapi.NewFakeClock(time.Unix(0, 0)),
+	})
+	require.NoError(t, err)
+	defer set.Close()
+
+	_, err = set.Execute(context.Background(), hostapi.TimeAPIName, "now", json.RawMessage(`{}`))
+	require.NoError(t, err)
+
+	_, err = set.Execute(context.Background(), hostapi.TimeAPIName, "now", json.RawMessage(`{}`))
+	var hostErr *hostapi.HostAPIError
+	require.ErrorAs(t, err, &hostErr)
+	assert.Equal(t, hostapi.ErrorCodeRateLimited, hostErr.Code)
+	assert.Equal(t, "retry after 1s", hostErr.Details)
+}
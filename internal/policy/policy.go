@@ -0,0 +1,140 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// policyExtensions are the file extensions loaded from a policy directory
+var policyExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+// Policy is a capability policy file as described in docs/15_okra-policy-model.md
+type Policy struct {
+	// Name identifies the policy in denial reasons (default: file name without extension)
+	Name string `yaml:"name"`
+
+	// AppliesTo lists the services and tags the policy targets; empty targets every service
+	AppliesTo []Target `yaml:"appliesTo"`
+
+	// Capabilities constrain host API calls, keyed by "<api>.<method>", "<api>.*",
+	// "<api>" or "*". The "okra." prefix of API names is optional.
+	Capabilities map[string]Rule `yaml:"capabilities"`
+
+	// Grants unlock elevated capabilities (e.g. "sql.raw") with a CEL condition;
+	// "true" grants unconditionally
+	Grants map[string]string `yaml:"grants"`
+}
+
+// Target selects services by name (path.Match patterns such as "acme/*") or by tag
+type Target struct {
+	Service string `yaml:"service"`
+	Tag     string `yaml:"tag"`
+}
+
+// Rule constrains the host API calls matched by its capability key
+type Rule struct {
+	// Allow denies every matched call when false (default: true)
+	Allow *bool `yaml:"allow"`
+
+	// Condition is a CEL expression that must evaluate to true for the call to be allowed
+	Condition string `yaml:"condition"`
+
+	// Reason is returned when the rule denies a call
+	Reason string `yaml:"reason"`
+
+	// Constraints are the remaining declarative settings (e.g. allowedDomains), returned
+	// to host APIs as PolicyDecision.Metadata
+	Constraints map[string]interface{} `yaml:",inline"`
+}
+
+// ParsePolicy parses a YAML or JSON policy file
+func ParsePolicy(data []byte, name string) (*Policy, error) {
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", name, err)
+	}
+
+	if policy.Name == "" {
+		policy.Name = name
+	}
+
+	for i, target := range policy.AppliesTo {
+		if (target.Service == "") == (target.Tag == "") {
+			return nil, fmt.Errorf("policy %s: appliesTo[%d] must set exactly one of service or tag", policy.Name, i)
+		}
+		if target.Service != "" {
+			if _, err := path.Match(target.Service, ""); err != nil {
+				return nil, fmt.Errorf("policy %s: invalid service pattern %q: %w", policy.Name, target.Service, err)
+			}
+		}
+	}
+
+	for key := range policy.Capabilities {
+		if key == "" || strings.HasPrefix(key, ".") || strings.HasSuffix(key, ".") {
+			return nil, fmt.Errorf("policy %s: invalid capability key %q", policy.Name, key)
+		}
+	}
+
+	return &policy, nil
+}
+
+// LoadDir parses every policy file in dir, ordered by file name
+func LoadDir(dir string) ([]*Policy, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !policyExtensions[filepath.Ext(entry.Name())] {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	policies := make([]*Policy, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy %s: %w", name, err)
+		}
+
+		policy, err := ParsePolicy(data, strings.TrimSuffix(name, filepath.Ext(name)))
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// appliesTo reports whether the policy targets the service or one of its tags
+func (p *Policy) appliesTo(service string, tags []string) bool {
+	if len(p.AppliesTo) == 0 {
+		return true
+	}
+
+	for _, target := range p.AppliesTo {
+		if target.Service != "" {
+			if matched, _ := path.Match(target.Service, service); matched {
+				return true
+			}
+			continue
+		}
+		for _, tag := range tags {
+			if tag == target.Tag {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test policy files parse from YAML and JSON with declarative constraints
+// 2. Test invalid targets and capability keys are rejected
+// 3. Test LoadDir ordering and file filtering
+// 4. Test service/tag targeting
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+appliesTo:
+  - service: "acme/*"
+  - tag: pci
+capabilities:
+  http.fetch:
+    condition: "params.url.startsWith('https://')"
+    allowedDomains: ["api.stripe.com"]
+grants:
+  sql.raw: "true"
+`), "payments")
+	require.NoError(t, err)
+
+	assert.Equal(t, "payments", policy.Name)
+	assert.Equal(t, []Target{{Service: "acme/*"}, {Tag: "pci"}}, policy.AppliesTo)
+	rule := policy.Capabilities["http.fetch"]
+	assert.Nil(t, rule.Allow)
+	assert.Equal(t, "params.url.startsWith('https://')", rule.Condition)
+	assert.Equal(t, []interface{}{"api.stripe.com"}, rule.Constraints["allowedDomains"])
+	assert.Equal(t, "true", policy.Grants["sql.raw"])
+
+	policy, err = ParsePolicy([]byte(`{"name": "json", "capabilities": {"state": {"allow": false}}}`), "file")
+	require.NoError(t, err)
+	assert.Equal(t, "json", policy.Name)
+	assert.False(t, *policy.Capabilities["state"].Allow)
+}
+
+func TestParsePolicy_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"both target fields":    "appliesTo:\n  - service: a\n    tag: b\n",
+		"empty target":          "appliesTo:\n  - {}\n",
+		"bad service pattern":   "appliesTo:\n  - service: \"acme/[\"\n",
+		"trailing dot key":      "capabilities:\n  \"http.\": {}\n",
+		"malformed document":    "capabilities: [",
+		"wrong capability type": "capabilities:\n  state: true\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParsePolicy([]byte(data), "invalid")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20-second.yml": "capabilities:\n  cache: {}\n",
+		"10-first.yaml": "capabilities:\n  state: {}\n",
+		"30-third.json": `{"capabilities": {}}`,
+		"notes.txt":     "ignored",
+	}
+	for name, data := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.yaml"), 0755))
+
+	policies, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, policies, 3)
+	assert.Equal(t, "10-first", policies[0].Name)
+	assert.Equal(t, "20-second", policies[1].Name)
+	assert.Equal(t, "30-third", policies[2].Name)
+
+	_, err = LoadDir(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestPolicy_AppliesTo(t *testing.T) {
+	everyone := &Policy{}
+	assert.True(t, everyone.appliesTo("any/service", nil))
+
+	targeted := &Policy{AppliesTo: []Target{{Service: "acme/*"}, {Tag: "pci"}}}
+	assert.True(t, targeted.appliesTo("acme/orders", nil))
+	assert.False(t, targeted.appliesTo("acme/orders/v2", nil))
+	assert.False(t, targeted.appliesTo("other/orders", []string{"internal"}))
+	assert.True(t, targeted.appliesTo("other/orders", []string{"internal", "pci"}))
+}
@@ -0,0 +1,218 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/okra-platform/okra/internal/audit"
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/policy"
+	"github.com/okra-platform/okra/internal/wasm"
+	"go.opentelemetry.io/otel"
+)
+
+// HostAPIEnvironment is what the host APIs of every service deployed to one
+// runtime share: the registry they are created from and the runtime-wide
+// parts of their configuration. Services compiled for the runtime also share
+// its engine.
+type HostAPIEnvironment struct {
+	Registry hostapi.HostAPIRegistry
+	Config   hostapi.HostAPIConfig // Service name, version and config are filled in per service
+	Engine   wasm.WASMEngine       // Optional; nil compiles each service into a runtime of its own
+	Queues   hostapi.QueueStore    // Store okra.queue keeps messages in, set by OpenHostAPIEnvironment
+
+	closers []io.Closer // Stores and the audit log opened by OpenHostAPIEnvironment
+}
+
+// NewHostAPIEnvironment returns an environment offering the built-in host
+// APIs, which keep their data in memory unless opts configure them otherwise
+func NewHostAPIEnvironment(opts ...hostapi.DefaultHostAPIOption) HostAPIEnvironment {
+	return HostAPIEnvironment{Registry: hostapi.NewDefaultHostAPIRegistry(opts...)}
+}
+
+// HostAPIStorage locates the durable data of the built-in host APIs. Data
+// without a location is kept in memory.
+type HostAPIStorage struct {
+	DataDir      string // Project data directory, usually .okra in the project root
+	DatabasePath string // SQLite database okra.sql serves, usually the one okra db:migrate migrates
+
+	Secrets hostapi.SecretsProvider // Provider okra.secrets reads from; nil serves no secrets
+}
+
+// OpenHostAPIEnvironment returns an environment whose built-in host APIs keep
+// their data in storage. Close the environment when the runtime stops.
+func OpenHostAPIEnvironment(storage HostAPIStorage) (env HostAPIEnvironment, err error) {
+	defer func() {
+		if err != nil {
+			env.Close()
+		}
+	}()
+
+	var opts []hostapi.DefaultHostAPIOption
+	switch {
+	case storage.DatabasePath != "":
+		opts = append(opts, hostapi.WithSQLAPIOptions(hostapi.WithSQLDatabase(storage.DatabasePath)))
+	case storage.DataDir != "":
+		opts = append(opts, hostapi.WithSQLAPIOptions(hostapi.WithSQLDataDir(filepath.Join(storage.DataDir, "sql"))))
+	}
+
+	if storage.Secrets != nil {
+		opts = append(opts, hostapi.WithSecretsAPIOptions(hostapi.WithSecretsProvider(storage.Secrets)))
+	}
+
+	// State survives restarts in the data directory
+	if storage.DataDir != "" {
+		var state hostapi.StateStore
+		if state, err = hostapi.NewBoltStateStore(filepath.Join(storage.DataDir, "state.db")); err != nil {
+			return env, err
+		}
+		env.closers = append(env.closers, state)
+		opts = append(opts, hostapi.WithStateAPIOptions(hostapi.WithStateStore(state)))
+	}
+
+	// Queued messages wait in the store until the runtime delivers them to
+	// the subscribed services
+	env.Queues = hostapi.NewMemoryQueueStore()
+	if storage.DataDir != "" {
+		if env.Queues, err = hostapi.NewBoltQueueStore(filepath.Join(storage.DataDir, "queue.db")); err != nil {
+			return env, err
+		}
+	}
+	env.closers = append(env.closers, env.Queues)
+	opts = append(opts, hostapi.WithQueueAPIOptions(hostapi.WithQueueStore(env.Queues)))
+
+	// Calls are audited to the log okra audit reads
+	if storage.DataDir != "" {
+		var sink *audit.FileSink
+		if sink, err = audit.NewFileSink(filepath.Join(storage.DataDir, filepath.Base(audit.DefaultPath))); err != nil {
+			return env, err
+		}
+		env.closers = append(env.closers, sink)
+		env.Config.AuditSink = sink
+	}
+
+	env.Registry = hostapi.NewDefaultHostAPIRegistry(opts...)
+	return env, nil
+}
+
+// Close closes the databases, stores and audit log held by the environment's
+// host APIs
+func (e HostAPIEnvironment) Close() error {
+	var errs []error
+	if e.Registry != nil {
+		for _, factory := range e.Registry.List() {
+			if closer, ok := factory.(io.Closer); ok {
+				errs = append(errs, closer.Close())
+			}
+		}
+	}
+	for _, closer := range e.closers {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// CompileServiceModule compiles a service's WASM with access to exactly the
+// host APIs its config declares and under the resource limits it sets. It
+// fails if the module imports from the okra host module without declaring any
+// host APIs, imports a function the host module does not provide, or declares
+// an API the registry cannot satisfy.
+func CompileServiceModule(ctx context.Context, wasmBytes []byte, cfg *config.Config, env HostAPIEnvironment) (wasm.WASMCompiledModuleWithHostAPIs, error) {
+	if cfg == nil {
+		return nil, ErrNilConfig
+	}
+	if env.Registry == nil {
+		return nil, fmt.Errorf("host API environment has no registry")
+	}
+
+	limits := wasm.WithLimits(serviceLimits(cfg.Limits))
+	var module wasm.WASMCompiledModuleWithHostAPIs
+	var err error
+	if env.Engine != nil {
+		module, err = env.Engine.CompileWithHostAPIs(ctx, wasmBytes, limits)
+	} else {
+		module, err = wasm.NewWASMCompiledModuleWithHostAPIs(ctx, wasmBytes, limits)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := checkHostImports(module.HostImports(), cfg, env.Registry)
+	if err != nil {
+		module.Close(ctx)
+		return nil, err
+	}
+	if len(names) == 0 {
+		return module, nil
+	}
+
+	hostConfig, err := serviceHostAPIConfig(env.Config, cfg)
+	if err != nil {
+		module.Close(ctx)
+		return nil, err
+	}
+
+	return module.
+		WithHostAPIs(names).
+		WithHostAPIRegistry(env.Registry).
+		WithHostAPIConfig(hostConfig), nil
+}
+
+// serviceLimits converts the limits in a service's config to worker limits
+func serviceLimits(limits config.LimitsConfig) wasm.Limits {
+	return wasm.Limits{
+		MaxMemoryPages:    limits.MaxMemoryPages,
+		MaxExecutionTime:  time.Duration(limits.MaxExecutionMs) * time.Millisecond,
+		InstructionBudget: limits.InstructionBudget,
+	}
+}
+
+// checkHostImports validates a module's okra imports against the host APIs its
+// config declares and returns the declared names
+func checkHostImports(imports []string, cfg *config.Config, registry hostapi.HostAPIRegistry) ([]string, error) {
+	var unknown []string
+	for _, name := range imports {
+		if !hostapi.IsHostFunction(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("module imports unknown %s functions: %s", hostapi.HostModuleName, strings.Join(unknown, ", "))
+	}
+
+	if len(imports) > 0 && len(cfg.HostAPIs) == 0 {
+		return nil, fmt.Errorf("module imports host functions from %q but okra.json declares no hostApis", hostapi.HostModuleName)
+	}
+
+	return hostapi.DescribeRegistry(registry).CheckRequirements(cfg.HostAPIs)
+}
+
+// serviceHostAPIConfig fills the per-service fields of the shared host API
+// config and defaults the ones every host API set needs
+func serviceHostAPIConfig(base hostapi.HostAPIConfig, cfg *config.Config) (hostapi.HostAPIConfig, error) {
+	hostConfig := base
+	hostConfig.ServiceName = cfg.Name
+	hostConfig.ServiceVersion = cfg.Version
+	hostConfig.Config = cfg
+
+	if hostConfig.Tracer == nil {
+		hostConfig.Tracer = otel.Tracer("okra.hostapi")
+	}
+	if hostConfig.Meter == nil {
+		hostConfig.Meter = otel.Meter("okra.hostapi")
+	}
+	if hostConfig.PolicyEngine == nil {
+		engine, err := policy.NewEngine()
+		if err != nil {
+			return hostapi.HostAPIConfig{}, fmt.Errorf("failed to create policy engine: %w", err)
+		}
+		hostConfig.PolicyEngine = engine
+	}
+	return hostConfig, nil
+}
@@ -0,0 +1,253 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/okra-platform/okra/internal/audit"
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/migrations"
+	"github.com/okra-platform/okra/internal/wasm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test plan for CompileServiceModule:
+// 1. Module without okra imports compiles with no host APIs declared
+// 2. Module importing okra functions without declaring host APIs is rejected
+// 3. Module importing an unknown okra function is rejected
+// 4. Declared host APIs the registry cannot satisfy are rejected
+// 5. Module importing okra functions with satisfied declarations compiles
+// 6. serviceHostAPIConfig fills per-service fields and defaults
+// 7. Nil config and nil registry are rejected
+// 8. serviceLimits converts config limits to worker limits
+// 9. Modules are compiled by the environment's engine
+// 10. OpenHostAPIEnvironment keeps host API data in the given storage and
+//     audits calls to its audit log
+// 11. OpenHostAPIEnvironment serves secrets from the given provider
+// 12. OpenHostAPIEnvironment keeps state in the data directory across restarts
+
+// emptyModule is a valid WASM module with no imports or exports
+var emptyModule = []byte{0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00}
+
+// okraImportModule returns a WASM module that imports a single function of
+// type () -> () from the okra host module
+func okraImportModule(name string) []byte {
+	module := append([]byte{}, emptyModule...)
+	module = append(module, 0x01, 0x04, 0x01, 0x60, 0x00, 0x00) // Type section: func () -> ()
+
+	entry := []byte{0x01, 0x04, 'o', 'k', 'r', 'a', byte(len(name))}
+	entry = append(entry, name...)
+	entry = append(entry, 0x00, 0x00) // Function import of type 0
+	module = append(module, 0x02, byte(len(entry)))
+	return append(module, entry...)
+}
+
+func TestCompileServiceModule_NoHostImports(t *testing.T) {
+	// Test: A module that imports nothing from okra needs no declarations
+	ctx := context.Background()
+
+	module, err := CompileServiceModule(ctx, emptyModule, &config.Config{Name: "svc"}, NewHostAPIEnvironment())
+	require.NoError(t, err)
+	defer module.Close(ctx)
+	assert.Empty(t, module.HostImports())
+}
+
+func TestCompileServiceModule_UndeclaredHostImports(t *testing.T) {
+	// Test: Importing okra functions without declaring host APIs fails the deploy
+	ctx := context.Background()
+
+	module, err := CompileServiceModule(ctx, okraImportModule("run_host_api"), &config.Config{Name: "svc"}, NewHostAPIEnvironment())
+	require.Error(t, err)
+	assert.Nil(t, module)
+	assert.Contains(t, err.Error(), "declares no hostApis")
+}
+
+func TestCompileServiceModule_UnknownHostFunction(t *testing.T) {
+	// Test: Importing a function the okra module does not provide fails the deploy
+	ctx := context.Background()
+	cfg := &config.Config{Name: "svc", HostAPIs: map[string]string{"okra.state": "^1.0.0"}}
+
+	module, err := CompileServiceModule(ctx, okraImportModule("launch"), cfg, NewHostAPIEnvironment())
+	require.Error(t, err)
+	assert.Nil(t, module)
+	assert.Contains(t, err.Error(), "unknown okra functions: launch")
+}
+
+func TestCompileServiceModule_UnmetRequirements(t *testing.T) {
+	// Test: Declarations the registry cannot satisfy fail the deploy
+	ctx := context.Background()
+	cfg := &config.Config{Name: "svc", HostAPIs: map[string]string{
+		"okra.state":   "^2.0.0",
+		"okra.missing": "*",
+	}}
+
+	module, err := CompileServiceModule(ctx, okraImportModule("run_host_api"), cfg, NewHostAPIEnvironment())
+	require.Error(t, err)
+	assert.Nil(t, module)
+	assert.Contains(t, err.Error(), "okra.missing: not offered by the runtime")
+	assert.Contains(t, err.Error(), "okra.state: runtime offers v1.0.0, service needs ^2.0.0")
+}
+
+func TestCompileServiceModule_DeclaredHostAPIs(t *testing.T) {
+	// Test: A module importing okra functions compiles when its declarations are met
+	ctx := context.Background()
+	cfg := &config.Config{Name: "svc", HostAPIs: map[string]string{"okra.state": "^1.0.0"}}
+
+	module, err := CompileServiceModule(ctx, okraImportModule("run_host_api"), cfg, NewHostAPIEnvironment())
+	require.NoError(t, err)
+	defer module.Close(ctx)
+	assert.Equal(t, []string{"run_host_api"}, module.HostImports())
+}
+
+func TestServiceHostAPIConfig(t *testing.T) {
+	// Test: Per-service fields come from the service config; shared fields are
+	// kept and missing ones defaulted
+	cfg := &config.Config{Name: "svc", Version: "1.2.3"}
+	base := hostapi.HostAPIConfig{Environment: "production", MaxIteratorsPerService: 7}
+
+	hostConfig, err := serviceHostAPIConfig(base, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "svc", hostConfig.ServiceName)
+	assert.Equal(t, "1.2.3", hostConfig.ServiceVersion)
+	assert.Same(t, cfg, hostConfig.Config)
+	assert.Equal(t, "production", hostConfig.Environment)
+	assert.Equal(t, 7, hostConfig.MaxIteratorsPerService)
+	assert.NotNil(t, hostConfig.Tracer)
+	assert.NotNil(t, hostConfig.Meter)
+	assert.NotNil(t, hostConfig.PolicyEngine)
+}
+
+func TestCompileServiceModule_InvalidArguments(t *testing.T) {
+	// Test: A nil config or a registry-less environment is rejected
+	ctx := context.Background()
+
+	_, err := CompileServiceModule(ctx, emptyModule, nil, NewHostAPIEnvironment())
+	assert.ErrorIs(t, err, ErrNilConfig)
+
+	_, err = CompileServiceModule(ctx, emptyModule, &config.Config{Name: "svc"}, HostAPIEnvironment{})
+	assert.Error(t, err)
+}
+
+func TestServiceLimits(t *testing.T) {
+	// Test: Config limits convert to worker limits, with milliseconds as durations
+	limits := serviceLimits(config.LimitsConfig{MaxMemoryPages: 256, MaxExecutionMs: 1500, InstructionBudget: 1000000})
+	assert.Equal(t, uint32(256), limits.MaxMemoryPages)
+	assert.Equal(t, 1500*time.Millisecond, limits.MaxExecutionTime)
+	assert.Equal(t, uint64(1000000), limits.InstructionBudget)
+
+	assert.Equal(t, wasm.Limits{}, serviceLimits(config.LimitsConfig{}))
+}
+
+func TestCompileServiceModule_Engine(t *testing.T) {
+	// Test: Modules are compiled by the environment's engine and its cache
+	ctx := context.Background()
+	cache, err := wasm.NewCompilationCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	defer cache.Close(ctx)
+	engine, err := wasm.NewWASMEngine(ctx, cache)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+
+	env := NewHostAPIEnvironment()
+	env.Engine = engine
+	module, err := CompileServiceModule(ctx, emptyModule, &config.Config{Name: "svc"}, env)
+	require.NoError(t, err)
+	defer module.Close(ctx)
+
+	entries, err := filepath.Glob(filepath.Join(cache.Dir(), "wazero-*", "*"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestOpenHostAPIEnvironment(t *testing.T) {
+	// Test: okra.sql serves the configured database, and Close releases it
+	ctx := context.Background()
+	dir := t.TempDir()
+	databasePath := filepath.Join(dir, "dev.db")
+	db, err := migrations.OpenSQLite(databasePath)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	env, err := OpenHostAPIEnvironment(HostAPIStorage{DataDir: dir, DatabasePath: databasePath})
+	require.NoError(t, err)
+
+	hostConfig, err := serviceHostAPIConfig(env.Config, &config.Config{Name: "svc"})
+	require.NoError(t, err)
+	set, err := env.Registry.CreateHostAPISet(ctx, []string{hostapi.SQLAPIName}, hostConfig)
+	require.NoError(t, err)
+	_, err = set.Execute(ctx, hostapi.SQLAPIName, "query", json.RawMessage(`{"table":"users"}`))
+	require.NoError(t, err)
+	set.Close()
+
+	assert.NoError(t, env.Close())
+
+	// Test: The call was recorded in the data directory's audit log
+	log, err := os.Open(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	defer log.Close()
+	records, err := audit.Verify(log)
+	require.NoError(t, err)
+	assert.Equal(t, 1, records)
+}
+
+func TestOpenHostAPIEnvironment_Secrets(t *testing.T) {
+	// Test: okra.secrets reads from the storage's provider
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("s3cret"), 0600))
+	provider, err := hostapi.NewDirectorySecretsProvider(dir)
+	require.NoError(t, err)
+
+	env, err := OpenHostAPIEnvironment(HostAPIStorage{Secrets: provider})
+	require.NoError(t, err)
+	defer env.Close()
+
+	hostConfig, err := serviceHostAPIConfig(env.Config, &config.Config{
+		Name:    "svc",
+		Secrets: config.SecretsConfig{AllowedKeys: []string{"token"}},
+	})
+	require.NoError(t, err)
+	set, err := env.Registry.CreateHostAPISet(ctx, []string{hostapi.SecretsAPIName}, hostConfig)
+	require.NoError(t, err)
+	defer set.Close()
+
+	result, err := set.Execute(ctx, hostapi.SecretsAPIName, "get", json.RawMessage(`{"key":"token"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":"s3cret"}`, string(result))
+}
+
+func TestOpenHostAPIEnvironment_State(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	execute := func(method, parameters string) json.RawMessage {
+		env, err := OpenHostAPIEnvironment(HostAPIStorage{DataDir: dir})
+		require.NoError(t, err)
+		defer env.Close()
+
+		hostConfig, err := serviceHostAPIConfig(env.Config, &config.Config{Name: "svc"})
+		require.NoError(t, err)
+		set, err := env.Registry.CreateHostAPISet(ctx, []string{hostapi.StateAPIName}, hostConfig)
+		require.NoError(t, err)
+		defer set.Close()
+
+		result, err := set.Execute(ctx, hostapi.StateAPIName, method, json.RawMessage(parameters))
+		require.NoError(t, err)
+		return result
+	}
+
+	// Test: A value set before a restart is read after it
+	execute("set", `{"key":"greeting","value":"hello"}`)
+	var entry hostapi.StateGetResponse
+	require.NoError(t, json.Unmarshal(execute("get", `{"key":"greeting"}`), &entry))
+	assert.True(t, entry.Exists)
+	assert.JSONEq(t, `"hello"`, string(entry.Value))
+	assert.FileExists(t, filepath.Join(dir, "state.db"))
+}
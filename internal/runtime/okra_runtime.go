@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/wasm"
 	"github.com/rs/zerolog"
 	"github.com/tochemey/goakt/v2/actors"
 )
@@ -16,7 +19,16 @@ type OkraRuntime struct {
 
 	// deployedActors tracks deployed service actors
 	deployedActors map[string]*actors.PID
-	mu             sync.RWMutex
+
+	// serviceActors maps the okra.json name a service's host APIs know it
+	// by to its actor ID
+	serviceActors map[string]string
+	mu            sync.RWMutex
+
+	// engine compiles the modules of every deployed service into one
+	// wazero runtime; created on Start and closed on Shutdown
+	engine wasm.WASMEngine
+	cache  wasm.CompilationCache
 
 	// logger for runtime operations
 	logger zerolog.Logger
@@ -25,13 +37,28 @@ type OkraRuntime struct {
 	started bool
 }
 
+// OkraRuntimeOption configures an OkraRuntime
+type OkraRuntimeOption func(*OkraRuntime)
+
+// WithCompilationCache compiles the modules of deployed services through cache
+func WithCompilationCache(cache wasm.CompilationCache) OkraRuntimeOption {
+	return func(r *OkraRuntime) {
+		r.cache = cache
+	}
+}
+
 // NewOkraRuntime creates a new runtime instance
-func NewOkraRuntime(logger zerolog.Logger) *OkraRuntime {
-	return &OkraRuntime{
+func NewOkraRuntime(logger zerolog.Logger, opts ...OkraRuntimeOption) *OkraRuntime {
+	r := &OkraRuntime{
 		deployedActors: make(map[string]*actors.PID),
+		serviceActors:  make(map[string]string),
 		logger:         logger.With().Str("component", "runtime").Logger(),
 		started:        false,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Start initializes the runtime and starts the actor system
@@ -43,26 +70,36 @@ func (r *OkraRuntime) Start(ctx context.Context) error {
 		return fmt.Errorf("runtime already started")
 	}
 
+	// Create the engine every service is compiled with
+	engine, err := wasm.NewWASMEngine(ctx, r.cache)
+	if err != nil {
+		return fmt.Errorf("failed to create wasm engine: %w", err)
+	}
+
 	// Create actor system
 	// Note: GoAKT uses its default logger. We track operations separately with zerolog
 	actorSystem, err := actors.NewActorSystem("okra-runtime")
 	if err != nil {
+		engine.Close(ctx)
 		return fmt.Errorf("failed to create actor system: %w", err)
 	}
 
 	// Start the actor system
 	if err := actorSystem.Start(ctx); err != nil {
+		engine.Close(ctx)
 		return fmt.Errorf("failed to start actor system: %w", err)
 	}
 
 	r.actorSystem = actorSystem
+	r.engine = engine
 	r.started = true
 
 	r.logger.Info().Msg("runtime started successfully")
 	return nil
 }
 
-// Deploy deploys a service package to the runtime
+// Deploy deploys a service package to the runtime. The service's actor owns
+// the package's module from then on and closes it when it stops.
 func (r *OkraRuntime) Deploy(ctx context.Context, pkg *ServicePackage) (string, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -85,7 +122,7 @@ func (r *OkraRuntime) Deploy(ctx context.Context, pkg *ServicePackage) (string,
 	}
 
 	// Create WASM actor
-	actor := NewWASMActor(pkg)
+	actor := NewWASMActor(pkg, serviceActorOptions(pkg.Config)...)
 
 	// Spawn the actor
 	pid, err := r.actorSystem.Spawn(ctx, actorID, actor)
@@ -95,6 +132,9 @@ func (r *OkraRuntime) Deploy(ctx context.Context, pkg *ServicePackage) (string,
 
 	// Track deployed actor
 	r.deployedActors[actorID] = pid
+	if pkg.Config != nil && pkg.Config.Name != "" {
+		r.serviceActors[pkg.Config.Name] = actorID
+	}
 
 	r.logger.Info().
 		Str("actor_id", actorID).
@@ -104,7 +144,27 @@ func (r *OkraRuntime) Deploy(ctx context.Context, pkg *ServicePackage) (string,
 	return actorID, nil
 }
 
-// Undeploy removes a service from the runtime
+// serviceActorOptions converts the workers section of a service's config to
+// actor options
+func serviceActorOptions(cfg *config.Config) []WASMActorOption {
+	if cfg == nil {
+		return nil
+	}
+
+	var opts []WASMActorOption
+	switch workers := cfg.Workers; {
+	case workers.IdleTimeoutMs < 0:
+		opts = append(opts, WithIdleTimeout(0))
+	case workers.IdleTimeoutMs > 0:
+		opts = append(opts, WithIdleTimeout(time.Duration(workers.IdleTimeoutMs)*time.Millisecond))
+	}
+	return append(opts,
+		WithMaxInvocations(cfg.Workers.MaxInvocations),
+		WithSnapshot(cfg.Workers.Snapshot),
+	)
+}
+
+// Undeploy removes a service from the runtime, releasing its module
 func (r *OkraRuntime) Undeploy(ctx context.Context, actorID string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -125,6 +185,11 @@ func (r *OkraRuntime) Undeploy(ctx context.Context, actorID string) error {
 
 	// Remove from tracking
 	delete(r.deployedActors, actorID)
+	for name, id := range r.serviceActors {
+		if id == actorID {
+			delete(r.serviceActors, name)
+		}
+	}
 
 	r.logger.Info().
 		Str("actor_id", actorID).
@@ -142,6 +207,15 @@ func (r *OkraRuntime) IsDeployed(actorID string) bool {
 	return exists
 }
 
+// Engine returns the engine service modules are compiled with, or nil if the
+// runtime is not started
+func (r *OkraRuntime) Engine() wasm.WASMEngine {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.engine
+}
+
 // Shutdown gracefully shuts down the runtime and all actors
 func (r *OkraRuntime) Shutdown(ctx context.Context) error {
 	r.mu.Lock()
@@ -177,6 +251,12 @@ func (r *OkraRuntime) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("failed to stop actor system: %w", err)
 	}
 
+	// Release the modules of every service once no actor runs them
+	if err := r.engine.Close(ctx); err != nil {
+		shutdownErrors = append(shutdownErrors, fmt.Errorf("failed to close wasm engine: %w", err))
+	}
+	r.engine = nil
+
 	r.started = false
 	r.logger.Info().Msg("runtime shutdown complete")
 
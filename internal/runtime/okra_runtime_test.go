@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/hostapi"
 	"github.com/okra-platform/okra/internal/schema"
 	"github.com/okra-platform/okra/internal/wasm"
 	"github.com/rs/zerolog"
@@ -335,6 +336,58 @@ func TestOkraRuntime_Shutdown(t *testing.T) {
 	})
 }
 
+func TestOkraRuntime_Engine(t *testing.T) {
+	// Test: The engine exists only while the runtime is started
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+	cache, err := wasm.NewCompilationCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	runtime := NewOkraRuntime(logger, WithCompilationCache(cache))
+	assert.Nil(t, runtime.Engine())
+
+	ctx := context.Background()
+	require.NoError(t, runtime.Start(ctx))
+	engine := runtime.Engine()
+	require.NotNil(t, engine)
+
+	// Test: Services compiled with the engine are closed with the runtime
+	module, err := engine.Compile(ctx, emptyModule)
+	require.NoError(t, err)
+	require.NoError(t, runtime.Shutdown(ctx))
+	assert.Nil(t, runtime.Engine())
+
+	_, err = engine.Compile(ctx, emptyModule)
+	assert.Error(t, err)
+	assert.NoError(t, module.Close(ctx))
+
+	// Test: The cache outlives the runtime and keeps what it compiled
+	entries, err := filepath.Glob(filepath.Join(cache.Dir(), "wazero-*", "*"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.NoError(t, cache.Close(ctx))
+}
+
+func TestServiceActorOptions(t *testing.T) {
+	// Test: Without a workers section the actor keeps its defaults
+	actor := NewWASMActor(nil, serviceActorOptions(&config.Config{})...)
+	assert.Equal(t, DefaultWorkerIdleTimeout, actor.idleTimeout)
+	assert.Zero(t, actor.maxInvocations)
+	assert.False(t, actor.snapshot)
+
+	// Test: The workers section configures the actor's pool
+	actor = NewWASMActor(nil, serviceActorOptions(&config.Config{
+		Workers: config.WorkersConfig{IdleTimeoutMs: 1500, MaxInvocations: 100, Snapshot: true},
+	})...)
+	assert.Equal(t, 1500*time.Millisecond, actor.idleTimeout)
+	assert.Equal(t, uint64(100), actor.maxInvocations)
+	assert.True(t, actor.snapshot)
+
+	// Test: A negative idle timeout keeps idle workers until shutdown
+	actor = NewWASMActor(nil, serviceActorOptions(&config.Config{
+		Workers: config.WorkersConfig{IdleTimeoutMs: -1},
+	})...)
+	assert.Zero(t, actor.idleTimeout)
+}
+
 func TestOkraRuntime_generateActorID(t *testing.T) {
 	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
 	runtime := NewOkraRuntime(logger)
@@ -497,3 +550,14 @@ func TestOkraRuntime_GetActorPID(t *testing.T) {
 		})
 	}
 }
+
+func TestOkraRuntime_QueueDelivery(t *testing.T) {
+	// Test: Delivering to a service that is not deployed fails so the message is requeued
+	logger := zerolog.New(os.Stderr).Level(zerolog.ErrorLevel)
+	runtime := NewOkraRuntime(logger)
+
+	deliver := runtime.QueueDelivery()
+	err := deliver(context.Background(), hostapi.QueueSubscription{Service: "missing", Method: "handle"}, hostapi.QueueMessage{ID: "1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not deployed")
+}
@@ -199,7 +199,8 @@ func (x *ServiceResponse) GetDuration() *durationpb.Duration {
 // ServiceError represents an error from service execution
 type ServiceError struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Error code (e.g., "VALIDATION_ERROR", "EXECUTION_ERROR")
+	// Error code (e.g., "VALIDATION_ERROR", "EXECUTION_ERROR", "DEADLINE_EXCEEDED",
+	// "RESOURCE_EXHAUSTED")
 	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
 	// Human-readable error message
 	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
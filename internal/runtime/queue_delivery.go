@@ -0,0 +1,72 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/runtime/pb"
+	"github.com/tochemey/goakt/v2/actors"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// defaultQueueDeliveryTimeout bounds a delivery when the context has no deadline
+const defaultQueueDeliveryTimeout = 30 * time.Second
+
+// QueueDelivery returns a hostapi.QueueDeliverFunc that invokes the subscribed
+// method on a deployed service actor. The subscription's service is the name
+// the service has in okra.json, as okra.queue records it, or an actor ID
+// returned by Deploy. The method receives the hostapi.QueueMessage as its JSON
+// input; returning an error requeues the message.
+func (r *OkraRuntime) QueueDelivery() hostapi.QueueDeliverFunc {
+	return func(ctx context.Context, sub hostapi.QueueSubscription, message hostapi.QueueMessage) error {
+		pid := r.GetActorPID(r.serviceActor(sub.Service))
+		if pid == nil {
+			return fmt.Errorf("service %s is not deployed", sub.Service)
+		}
+
+		input, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+
+		timeout := defaultQueueDeliveryTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+
+		reply, err := actors.Ask(ctx, pid, &pb.ServiceRequest{
+			Id:      uuid.New().String(),
+			Method:  sub.Method,
+			Input:   input,
+			Timeout: durationpb.New(timeout),
+		}, timeout)
+		if err != nil {
+			return fmt.Errorf("delivery to %s.%s failed: %w", sub.Service, sub.Method, err)
+		}
+
+		response, ok := reply.(*pb.ServiceResponse)
+		if !ok {
+			return fmt.Errorf("invalid response type from actor: %T", reply)
+		}
+		if response.Error != nil {
+			return fmt.Errorf("%s.%s failed: %s: %s", sub.Service, sub.Method, response.Error.Code, response.Error.Message)
+		}
+		return nil
+	}
+}
+
+// serviceActor returns the actor ID of the service with the given okra.json
+// name, or the name itself if no such service is deployed
+func (r *OkraRuntime) serviceActor(service string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if actorID, ok := r.serviceActors[service]; ok {
+		return actorID
+	}
+	return service
+}
@@ -0,0 +1,186 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/runtime/pb"
+	"github.com/okra-platform/okra/internal/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/goakt/v2/actors"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Test plan for queue delivery:
+// 1. A message one service publishes is delivered to the service subscribed
+//    to its topic, by the okra.json name okra.queue records, and acked
+
+// relayModule forwards the input of methods starting with 'r' to
+// okra.run_host_api and returns the host's response; other methods echo their
+// input. allocate bumps a heap pointer and deallocate resets it.
+var relayModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32, i32) -> (i32, i32), (i32) -> i32, (i32) -> () and (i32, i32, i32, i32) -> i64
+	0x01, 0x19, 0x04,
+	0x60, 0x02, 0x7f, 0x7f, 0x02, 0x7f, 0x7f,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x01, 0x7f, 0x00,
+	0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7e,
+	// Import okra.run_host_api
+	0x02, 0x15, 0x01,
+	0x04, 'o', 'k', 'r', 'a',
+	0x0c, 'r', 'u', 'n', '_', 'h', 'o', 's', 't', '_', 'a', 'p', 'i',
+	0x00, 0x00,
+	// Functions, memory and the heap pointer global (starts at 1024)
+	0x03, 0x04, 0x03, 0x01, 0x02, 0x03,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b,
+	// Export memory, allocate, deallocate and handle_request
+	0x07, 0x33, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x08, 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x01,
+	0x0a, 'd', 'e', 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x02,
+	0x0e, 'h', 'a', 'n', 'd', 'l', 'e', '_', 'r', 'e', 'q', 'u', 'e', 's', 't', 0x00, 0x03,
+	0x0a, 0x41, 0x03,
+	// allocate: ptr = heap; heap += size; return ptr
+	0x0b, 0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b,
+	// deallocate: heap = 1024
+	0x07, 0x00, 0x41, 0x80, 0x08, 0x24, 0x00, 0x0b,
+	// handle_request: if method[0] == 'r'
+	0x2b, 0x01, 0x01, 0x7f,
+	0x20, 0x00, 0x2d, 0x00, 0x00, 0x41, 0xf2, 0x00, 0x46, 0x04, 0x7e,
+	// (ptr, len) = run_host_api(input, inputLen); ptr << 32 | len
+	0x20, 0x02, 0x20, 0x03, 0x10, 0x00, 0x21, 0x04,
+	0xad, 0x42, 0x20, 0x86, 0x20, 0x04, 0xad, 0x84,
+	// else input << 32 | inputLen
+	0x05,
+	0x20, 0x02, 0xad, 0x42, 0x20, 0x86, 0x20, 0x03, 0xad, 0x84,
+	0x0b, 0x0b,
+}
+
+// topicPolicyEngine allows every call and grants the queue.topic capability,
+// so services may publish to each other's topics
+type topicPolicyEngine struct{}
+
+func (topicPolicyEngine) Evaluate(ctx context.Context, check hostapi.PolicyCheck) (hostapi.PolicyDecision, error) {
+	return hostapi.PolicyDecision{
+		Allowed:  true,
+		Metadata: map[string]interface{}{hostapi.CapabilityQueueTopic: true},
+	}, nil
+}
+
+// deployRelay deploys relayModule as the service with the given okra.json
+// name, offering it okra.queue
+func deployRelay(t *testing.T, runtime *OkraRuntime, env HostAPIEnvironment, name, serviceName string) string {
+	ctx := context.Background()
+	cfg := &config.Config{
+		Name:     name,
+		Version:  "1.0.0",
+		HostAPIs: map[string]string{hostapi.QueueAPIName: "^1.0.0"},
+	}
+	module, err := CompileServiceModule(ctx, relayModule, cfg, env)
+	require.NoError(t, err)
+	t.Cleanup(func() { module.Close(ctx) })
+
+	pkg, err := NewServicePackage(module, &schema.Schema{
+		Services: []schema.Service{{
+			Name: serviceName,
+			Methods: []schema.Method{
+				{Name: "relay", InputType: "HostAPIRequest", OutputType: "HostAPIResponse"},
+				{Name: "receive", InputType: "QueueMessage", OutputType: "QueueMessage"},
+			},
+		}},
+		Meta: schema.Metadata{Namespace: "test", Version: "v1"},
+	}, cfg)
+	require.NoError(t, err)
+
+	actorID, err := runtime.Deploy(ctx, pkg)
+	require.NoError(t, err)
+	return actorID
+}
+
+// relay has a relay service make a host API call
+func relay(t *testing.T, runtime *OkraRuntime, actorID, method, parameters string) hostapi.HostAPIResponse {
+	ctx := context.Background()
+	input, err := json.Marshal(hostapi.HostAPIRequest{
+		API:        hostapi.QueueAPIName,
+		Method:     method,
+		Parameters: json.RawMessage(parameters),
+	})
+	require.NoError(t, err)
+
+	pid := runtime.GetActorPID(actorID)
+	require.NotNil(t, pid)
+	reply, err := actors.Ask(ctx, pid, &pb.ServiceRequest{
+		Id:      uuid.New().String(),
+		Method:  "relay",
+		Input:   input,
+		Timeout: durationpb.New(5 * time.Second),
+	}, 5*time.Second)
+	require.NoError(t, err)
+	response, ok := reply.(*pb.ServiceResponse)
+	require.True(t, ok)
+	require.Nil(t, response.Error)
+
+	var result hostapi.HostAPIResponse
+	require.NoError(t, json.Unmarshal(response.Output, &result))
+	require.True(t, result.Success, "%s failed: %+v", method, result.Error)
+	return result
+}
+
+func TestQueueDelivery_BetweenServices(t *testing.T) {
+	ctx := context.Background()
+	env, err := OpenHostAPIEnvironment(HostAPIStorage{DataDir: t.TempDir()})
+	require.NoError(t, err)
+	defer env.Close()
+	env.Config.PolicyEngine = topicPolicyEngine{}
+
+	runtime := NewOkraRuntime(zerolog.New(os.Stderr).Level(zerolog.ErrorLevel))
+	require.NoError(t, runtime.Start(ctx))
+	defer runtime.Shutdown(ctx)
+	env.Engine = runtime.Engine()
+
+	producer := deployRelay(t, runtime, env, "producer", "Producer")
+	consumer := deployRelay(t, runtime, env, "consumer", "Consumer")
+
+	// Test: The consumer subscribes its receive method to its own topic, and the
+	// producer publishes to it
+	relay(t, runtime, consumer, "subscribe", `{"topic":"orders","method":"receive"}`)
+	relay(t, runtime, producer, "publish", `{"topic":"consumer/orders","payload":{"order":42}}`)
+
+	var mu sync.Mutex
+	var delivered []hostapi.QueueMessage
+	deliver := runtime.QueueDelivery()
+	dispatcher := hostapi.NewQueueDispatcher(env.Queues, func(ctx context.Context, sub hostapi.QueueSubscription, message hostapi.QueueMessage) error {
+		if err := deliver(ctx, sub, message); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, message)
+		return nil
+	})
+
+	// Test: The dispatcher delivers the message to the consumer's actor
+	assert.Equal(t, 1, dispatcher.Poll(ctx))
+	mu.Lock()
+	require.Len(t, delivered, 1)
+	assert.Equal(t, "consumer/orders", delivered[0].Topic)
+	assert.JSONEq(t, `{"order":42}`, string(delivered[0].Payload))
+	mu.Unlock()
+
+	// Test: The delivered message is acked, so it is not delivered again
+	depth, err := env.Queues.Depth(ctx, "consumer/orders")
+	require.NoError(t, err)
+	assert.Equal(t, hostapi.QueueDepth{}, depth)
+	assert.Zero(t, dispatcher.Poll(ctx))
+}
@@ -2,6 +2,9 @@ package runtime
 
 import (
 	"context"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/wasm"
 )
 
 // Runtime manages the actor system and service deployments
@@ -19,6 +22,14 @@ type Runtime interface {
 	// IsDeployed checks if a service is deployed
 	IsDeployed(actorID string) bool
 
+	// Engine returns the engine service modules are compiled with, so every
+	// deployed service shares one WASM runtime. It is nil until Start.
+	Engine() wasm.WASMEngine
+
+	// QueueDelivery returns the function that delivers queued messages to
+	// the services subscribed to them
+	QueueDelivery() hostapi.QueueDeliverFunc
+
 	// Shutdown gracefully shuts down the runtime and all actors
 	Shutdown(ctx context.Context) error
 }
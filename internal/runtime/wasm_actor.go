@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,6 +12,10 @@ import (
 	"google.golang.org/protobuf/types/known/durationpb"
 )
 
+// DefaultWorkerIdleTimeout is how long a worker beyond the minimum may idle
+// before it is closed
+const DefaultWorkerIdleTimeout = 5 * time.Minute
+
 // WASMActor is a GoAKT actor that executes WASM service methods
 type WASMActor struct {
 	// servicePackage contains the WASM module, schema, and config
@@ -25,6 +30,16 @@ type WASMActor struct {
 	// maxWorkers is the maximum number of workers in the pool
 	maxWorkers int
 
+	// idleTimeout is how long a worker may idle before it is closed
+	idleTimeout time.Duration
+
+	// maxInvocations is how many invocations a worker serves before it is
+	// replaced
+	maxInvocations uint64
+
+	// snapshot starts workers from a snapshot of an initialized template
+	snapshot bool
+
 	// ready indicates if the actor is ready to process requests
 	ready bool
 }
@@ -35,6 +50,7 @@ func NewWASMActor(servicePackage *ServicePackage, opts ...WASMActorOption) *WASM
 		servicePackage: servicePackage,
 		minWorkers:     1,  // Default
 		maxWorkers:     10, // Default
+		idleTimeout:    DefaultWorkerIdleTimeout,
 		ready:          false,
 	}
 
@@ -51,9 +67,12 @@ func (a *WASMActor) PreStart(ctx context.Context) error {
 	// Create worker pool if not already set (e.g., by tests)
 	if a.workerPool == nil {
 		poolConfig := wasm.WASMWorkerPoolConfig{
-			MinWorkers: a.minWorkers,
-			MaxWorkers: a.maxWorkers,
-			Module:     a.servicePackage.Module,
+			MinWorkers:     a.minWorkers,
+			MaxWorkers:     a.maxWorkers,
+			Module:         a.servicePackage.Module,
+			IdleTimeout:    a.idleTimeout,
+			MaxInvocations: a.maxInvocations,
+			Snapshot:       a.snapshot,
 		}
 
 		pool, err := wasm.NewWASMWorkerPool(ctx, poolConfig)
@@ -97,6 +116,13 @@ func (a *WASMActor) PostStop(ctx context.Context) error {
 		}
 	}
 
+	// No worker runs the module anymore, so release its compiled code
+	if a.servicePackage != nil && a.servicePackage.Module != nil {
+		if err := a.servicePackage.Module.Close(ctx); err != nil {
+			return fmt.Errorf("failed to close module: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -138,7 +164,7 @@ func (a *WASMActor) handleServiceRequest(ctx *actors.ReceiveContext, req *pb.Ser
 	if err != nil {
 		ctx.Logger().Errorf("method execution failed: %v", err)
 		response := pb.NewServiceResponse(req.GetId(), false)
-		response.Error = pb.NewServiceError("EXECUTION_ERROR", err.Error())
+		response.Error = pb.NewServiceError(executionErrorCode(err), err.Error())
 		response.Duration = durationpb.New(time.Since(start))
 		ctx.Response(response)
 		return
@@ -153,6 +179,20 @@ func (a *WASMActor) handleServiceRequest(ctx *actors.ReceiveContext, req *pb.Ser
 	ctx.Response(response)
 }
 
+// executionErrorCode returns the ServiceError code for a failed invocation.
+// Requests stopped at one of the service's limits get a code of their own so
+// callers can tell them from bugs in the service.
+func executionErrorCode(err error) string {
+	switch {
+	case errors.Is(err, wasm.ErrDeadlineExceeded):
+		return "DEADLINE_EXCEEDED"
+	case errors.Is(err, wasm.ErrResourceExhausted):
+		return "RESOURCE_EXHAUSTED"
+	default:
+		return "EXECUTION_ERROR"
+	}
+}
+
 // handleHealthCheck responds to health check requests
 func (a *WASMActor) handleHealthCheck(ctx *actors.ReceiveContext, req *pb.HealthCheck) {
 	response := &pb.HealthCheckResponse{
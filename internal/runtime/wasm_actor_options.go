@@ -1,6 +1,10 @@
 package runtime
 
-import "github.com/okra-platform/okra/internal/wasm"
+import (
+	"time"
+
+	"github.com/okra-platform/okra/internal/wasm"
+)
 
 // WASMActorOption is a functional option for configuring a WASMActor
 type WASMActorOption func(*WASMActor)
@@ -25,3 +29,26 @@ func WithMaxWorkers(maxWorkers int) WASMActorOption {
 		a.maxWorkers = maxWorkers
 	}
 }
+
+// WithIdleTimeout closes workers left idle this long, down to the minimum
+func WithIdleTimeout(idleTimeout time.Duration) WASMActorOption {
+	return func(a *WASMActor) {
+		a.idleTimeout = idleTimeout
+	}
+}
+
+// WithMaxInvocations replaces each worker after it has served maxInvocations
+// invocations
+func WithMaxInvocations(maxInvocations uint64) WASMActorOption {
+	return func(a *WASMActor) {
+		a.maxInvocations = maxInvocations
+	}
+}
+
+// WithSnapshot starts workers from a snapshot of one initialized template
+// instead of initializing each of them
+func WithSnapshot(snapshot bool) WASMActorOption {
+	return func(a *WASMActor) {
+		a.snapshot = snapshot
+	}
+}
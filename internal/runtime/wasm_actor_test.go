@@ -35,6 +35,11 @@ func (m *MockWASMWorkerPool) ActiveWorkers() uint {
 	return args.Get(0).(uint)
 }
 
+func (m *MockWASMWorkerPool) Stats() wasm.WASMWorkerPoolStats {
+	args := m.Called()
+	return args.Get(0).(wasm.WASMWorkerPoolStats)
+}
+
 func (m *MockWASMWorkerPool) Shutdown(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -91,6 +96,7 @@ func createTestServicePackage() *ServicePackage {
 	}
 
 	mockModule := &MockWASMCompiledModule{}
+	mockModule.On("Close", mock.Anything).Return(nil).Maybe()
 
 	pkg, _ := NewServicePackage(mockModule, testSchema, testConfig)
 	return pkg
@@ -131,6 +137,9 @@ func TestNewWASMActor(t *testing.T) {
 		assert.Equal(t, pkg, actor.servicePackage)
 		assert.Equal(t, 1, actor.minWorkers)
 		assert.Equal(t, 10, actor.maxWorkers)
+		assert.Equal(t, DefaultWorkerIdleTimeout, actor.idleTimeout)
+		assert.Equal(t, uint64(0), actor.maxInvocations)
+		assert.False(t, actor.snapshot)
 		assert.False(t, actor.ready)
 	})
 
@@ -142,12 +151,18 @@ func TestNewWASMActor(t *testing.T) {
 		actor := NewWASMActor(pkg,
 			WithMinWorkers(5),
 			WithMaxWorkers(20),
+			WithIdleTimeout(time.Minute),
+			WithMaxInvocations(1000),
+			WithSnapshot(true),
 			WithWorkerPool(mockPool),
 		)
 
 		assert.NotNil(t, actor)
 		assert.Equal(t, 5, actor.minWorkers)
 		assert.Equal(t, 20, actor.maxWorkers)
+		assert.Equal(t, time.Minute, actor.idleTimeout)
+		assert.Equal(t, uint64(1000), actor.maxInvocations)
+		assert.True(t, actor.snapshot)
 		assert.Equal(t, mockPool, actor.workerPool)
 	})
 }
@@ -190,6 +205,49 @@ func TestWASMActor_PreStart(t *testing.T) {
 		mockModule.AssertExpectations(t)
 	})
 
+	// Test: PreStart configures the pool's worker lifecycle
+	t.Run("passes lifecycle options to the pool", func(t *testing.T) {
+		testSchema := &schema.Schema{
+			Services: []schema.Service{
+				{Name: "TestService", Methods: []schema.Method{{Name: "test"}}},
+			},
+		}
+
+		mockModule := &MockWASMCompiledModule{}
+		mockWorker := &MockWASMWorker{}
+		mockWorker.On("Invoke", mock.Anything, "test", mock.Anything).Return([]byte("ok"), nil)
+		mockWorker.On("Close", mock.Anything).Return(nil)
+		mockModule.On("Instantiate", mock.Anything).Return(mockWorker, nil)
+
+		pkg, err := NewServicePackage(mockModule, testSchema, &config.Config{})
+		require.NoError(t, err)
+
+		actor := NewWASMActor(pkg, WithMinWorkers(0), WithMaxInvocations(1))
+		ctx := context.Background()
+		require.NoError(t, actor.PreStart(ctx))
+		defer actor.workerPool.Shutdown(ctx)
+
+		_, err = actor.workerPool.Invoke(ctx, "test", nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), actor.workerPool.Stats().Recycled)
+		mockWorker.AssertCalled(t, "Close", mock.Anything)
+	})
+
+	// Test: PreStart passes snapshot mode to the pool
+	t.Run("snapshot needs a module that supports it", func(t *testing.T) {
+		pkg, err := NewServicePackage(&MockWASMCompiledModule{}, &schema.Schema{
+			Services: []schema.Service{
+				{Name: "TestService", Methods: []schema.Method{{Name: "test"}}},
+			},
+		}, &config.Config{})
+		require.NoError(t, err)
+
+		actor := NewWASMActor(pkg, WithSnapshot(true))
+		err = actor.PreStart(context.Background())
+		assert.ErrorContains(t, err, "does not support snapshots")
+		assert.False(t, actor.ready)
+	})
+
 	// Test: PreStart with existing pool
 	t.Run("with existing pool", func(t *testing.T) {
 		pkg := createTestServicePackage()
@@ -222,6 +280,30 @@ func TestWASMActor_PostStop(t *testing.T) {
 		mockPool.AssertExpectations(t)
 	})
 
+	// Test: PostStop closes the module once the pool is down, releasing its
+	// compiled code
+	t.Run("closes module", func(t *testing.T) {
+		mockModule := &MockWASMCompiledModule{}
+		pkg, err := NewServicePackage(mockModule, &schema.Schema{
+			Services: []schema.Service{
+				{Name: "TestService", Methods: []schema.Method{{Name: "test"}}},
+			},
+		}, &config.Config{})
+		require.NoError(t, err)
+
+		mockPool := &MockWASMWorkerPool{}
+		var poolDown bool
+		mockPool.On("Shutdown", mock.Anything).Run(func(mock.Arguments) { poolDown = true }).Return(nil)
+		mockModule.On("Close", mock.Anything).Run(func(mock.Arguments) {
+			assert.True(t, poolDown, "module closed before the pool shut down")
+		}).Return(nil).Once()
+
+		actor := NewWASMActor(pkg, WithWorkerPool(mockPool))
+		require.NoError(t, actor.PostStop(context.Background()))
+		mockPool.AssertExpectations(t)
+		mockModule.AssertExpectations(t)
+	})
+
 	// Test: PostStop with nil pool
 	t.Run("nil pool", func(t *testing.T) {
 		pkg := createTestServicePackage()
@@ -769,3 +851,43 @@ func TestWASMActor_EdgeCases(t *testing.T) {
 		mockPool.AssertExpectations(t)
 	})
 }
+
+func TestWASMActor_LimitErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{"deadline", fmt.Errorf("%w: guest still running", wasm.ErrDeadlineExceeded), "DEADLINE_EXCEEDED"},
+		{"resources", fmt.Errorf("%w: memory limit of 16 pages reached", wasm.ErrResourceExhausted), "RESOURCE_EXHAUSTED"},
+		{"other", fmt.Errorf("unreachable"), "EXECUTION_ERROR"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Test: Requests stopped at a limit report the limit's error code
+			pkg := createTestServicePackage()
+			mockPool := createMockPool()
+			actor := NewWASMActor(pkg, WithWorkerPool(mockPool))
+			mockPool.On("Invoke", mock.Anything, "add", mock.Anything).Return(nil, tt.err).Once()
+
+			actorSystem, err := actors.NewActorSystem(fmt.Sprintf("test-limits-system-%d", i))
+			require.NoError(t, err)
+			require.NoError(t, actorSystem.Start(context.Background()))
+			defer actorSystem.Stop(context.Background())
+
+			actorRef, err := actorSystem.Spawn(context.Background(), "test-limits-actor", actor)
+			require.NoError(t, err)
+
+			req := &pb.ServiceRequest{Id: "test-limits", Method: "add", Input: []byte(`{"a": 1, "b": 2}`)}
+			reply, err := actors.Ask(context.Background(), actorRef, req, time.Second)
+			require.NoError(t, err)
+
+			resp, ok := reply.(*pb.ServiceResponse)
+			require.True(t, ok)
+			assert.False(t, resp.Success)
+			require.NotNil(t, resp.Error)
+			assert.Equal(t, tt.code, resp.Error.Code)
+		})
+	}
+}
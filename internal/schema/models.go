@@ -15,11 +15,12 @@ type Metadata struct {
 	Service   string `json:"service"`
 }
 
-// ObjectType represents a top-level "type" block
+// ObjectType represents a top-level "type" or "model" block
 type ObjectType struct {
-	Name   string  `json:"name"`
-	Doc    string  `json:"doc"`
-	Fields []Field `json:"fields"`
+	Name       string      `json:"name"`
+	Doc        string      `json:"doc"`
+	Fields     []Field     `json:"fields"`
+	Directives []Directive `json:"directives"`
 }
 
 // Field represents a field inside a type or input object
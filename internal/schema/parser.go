@@ -62,9 +62,10 @@ func parseObjectType(doc *ast.Document, ref int, schema *Schema) error {
 
 	// Regular object type
 	objType := ObjectType{
-		Name:   typeName,
-		Doc:    getDescription(doc, typeDef.Description),
-		Fields: []Field{},
+		Name:       typeName,
+		Doc:        getDescription(doc, typeDef.Description),
+		Fields:     []Field{},
+		Directives: parseDirectives(doc, typeDef.Directives),
 	}
 
 	// Parse fields
@@ -268,6 +269,14 @@ func parseValue(doc *ast.Document, value ast.Value) string {
 	case ast.ValueKindFloat:
 		// For float values, use the document's float value methods
 		return fmt.Sprintf("%f", doc.FloatValueAsFloat32(value.Ref))
+
+	case ast.ValueKindList:
+		// Lists (e.g. @index(fields: ["a", "b"])) are flattened to comma-separated values
+		items := []string{}
+		for _, itemRef := range doc.ListValues[value.Ref].Refs {
+			items = append(items, parseValue(doc, doc.Value(itemRef)))
+		}
+		return strings.Join(items, ",")
 	}
 
 	return ""
@@ -13,7 +13,11 @@ var okraDirectiveRegex = regexp.MustCompile(`(?m)^@okra\s*\(((?:[^()]*|\([^)]*\)
 // Captures the service name which must be a valid GraphQL identifier.
 var serviceStartRegex = regexp.MustCompile(`(?m)^service\s+(\w+)\s*{`)
 
-// PreprocessGraphQL rewrites `@okra(...)` and `service` blocks into valid GraphQL `type` definitions.
+// modelStartRegex matches model declarations at the start of a line.
+// Captures the model name which must be a valid GraphQL identifier.
+var modelStartRegex = regexp.MustCompile(`(?m)^model\s+(\w+)`)
+
+// PreprocessGraphQL rewrites `@okra(...)`, `service` and `model` blocks into valid GraphQL `type` definitions.
 func PreprocessGraphQL(input string) string {
 	// 1. Rewrite @okra(...) to a _Schema type with a properly typed field
 	// The field needs a type to be valid GraphQL
@@ -30,5 +34,8 @@ func PreprocessGraphQL(input string) string {
 		return `type Service_` + serviceName + ` {`
 	})
 
+	// 3. Rewrite model blocks to type X @model, keeping any directives that follow
+	input = modelStartRegex.ReplaceAllString(input, `type $1 @model`)
+
 	return input
 }
@@ -0,0 +1,313 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Column types used by tables. Enums are stored as strings; lists and
+// non-model object types are stored as JSON.
+const (
+	ColumnTypeID       = "ID"
+	ColumnTypeString   = "String"
+	ColumnTypeInt      = "Int"
+	ColumnTypeFloat    = "Float"
+	ColumnTypeBoolean  = "Boolean"
+	ColumnTypeDateTime = "DateTime"
+	ColumnTypeJSON     = "JSON"
+)
+
+// Special @default values
+const (
+	DefaultNow           = "now"
+	DefaultUUID          = "uuid"
+	DefaultAutoincrement = "autoincrement"
+)
+
+// Table is the database table derived from a model type
+type Table struct {
+	Name       string   `json:"name"`
+	Model      string   `json:"model"`
+	Columns    []Column `json:"columns"`
+	PrimaryKey []string `json:"primaryKey"` // Column names
+	Indexes    []Index  `json:"indexes"`    // Sorted by name
+}
+
+// Column is a single table column derived from a model field
+type Column struct {
+	Name    string  `json:"name"`
+	Field   string  `json:"field"`
+	Type    string  `json:"type"` // One of the ColumnType constants
+	NotNull bool    `json:"notNull"`
+	Default *string `json:"default,omitempty"` // Literal value or one of now, uuid, autoincrement
+}
+
+// Index is a secondary (optionally unique) index on one or more columns
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+// Column returns the named column
+func (t *Table) Column(name string) (Column, bool) {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// IsModel reports whether a type is persisted as a table (declared with `model` or @table)
+func (t *ObjectType) IsModel() bool {
+	for _, directive := range t.Directives {
+		if directive.Name == "model" || directive.Name == "table" {
+			return true
+		}
+	}
+	return false
+}
+
+// Tables derives the database tables described by the schema's models.
+//
+// Model directives:
+//   - @table(name: "custom")                 override the table name (default: pluralized snake_case)
+//   - @primaryKey(fields: ["a", "b"])        composite primary key
+//   - @index(fields: [...], name: "...")     composite index
+//   - @unique(fields: [...], name: "...")    composite unique index
+//
+// Field directives: @primaryKey, @unique, @index and @default(value: ...).
+// A required `id` field is the primary key when none is declared.
+func (s *Schema) Tables() ([]Table, error) {
+	models := map[string]bool{}
+	for _, t := range s.Types {
+		if t.IsModel() {
+			models[t.Name] = true
+		}
+	}
+	enums := map[string]bool{}
+	for _, e := range s.Enums {
+		enums[e.Name] = true
+	}
+
+	tables := []Table{}
+	names := map[string]string{}
+	for _, t := range s.Types {
+		if !t.IsModel() {
+			continue
+		}
+
+		table, err := buildTable(t, models, enums)
+		if err != nil {
+			return nil, fmt.Errorf("model %s: %w", t.Name, err)
+		}
+		if other, ok := names[table.Name]; ok {
+			return nil, fmt.Errorf("models %s and %s both map to table %s", other, t.Name, table.Name)
+		}
+		names[table.Name] = t.Name
+		tables = append(tables, table)
+	}
+
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables, nil
+}
+
+func buildTable(t ObjectType, models, enums map[string]bool) (Table, error) {
+	table := Table{
+		Name:       pluralize(toSnakeCase(t.Name)),
+		Model:      t.Name,
+		Columns:    []Column{},
+		PrimaryKey: []string{},
+		Indexes:    []Index{},
+	}
+
+	// Fields are resolved first so model-level directives can refer to them by name
+	columnsByField := map[string]string{}
+	for _, field := range t.Fields {
+		columnType, ok := columnType(field.Type, models, enums)
+		if !ok {
+			// Relation to another model; stored on the other side as a foreign key field
+			continue
+		}
+
+		column := Column{
+			Name:    toSnakeCase(field.Name),
+			Field:   field.Name,
+			Type:    columnType,
+			NotNull: field.Required,
+		}
+		if _, ok := table.Column(column.Name); ok {
+			return Table{}, fmt.Errorf("field %s maps to duplicate column %s", field.Name, column.Name)
+		}
+
+		for _, directive := range field.Directives {
+			switch directive.Name {
+			case "primaryKey", "id":
+				table.PrimaryKey = append(table.PrimaryKey, column.Name)
+			case "unique":
+				table.Indexes = append(table.Indexes, Index{Columns: []string{column.Name}, Unique: true})
+			case "index":
+				table.Indexes = append(table.Indexes, Index{Columns: []string{column.Name}})
+			case "default":
+				value, ok := directive.Args["value"]
+				if !ok {
+					return Table{}, fmt.Errorf("field %s: @default requires a value argument", field.Name)
+				}
+				column.Default = &value
+			}
+		}
+
+		table.Columns = append(table.Columns, column)
+		columnsByField[field.Name] = column.Name
+	}
+
+	if len(table.Columns) == 0 {
+		return Table{}, fmt.Errorf("model has no columns")
+	}
+
+	for _, directive := range t.Directives {
+		switch directive.Name {
+		case "table":
+			name := directive.Args["name"]
+			if name == "" {
+				return Table{}, fmt.Errorf("@table requires a name argument")
+			}
+			table.Name = name
+		case "primaryKey":
+			if len(table.PrimaryKey) > 0 {
+				return Table{}, fmt.Errorf("primary key declared on both the model and its fields")
+			}
+			columns, err := directiveColumns(directive, columnsByField)
+			if err != nil {
+				return Table{}, err
+			}
+			table.PrimaryKey = columns
+		case "index", "unique":
+			columns, err := directiveColumns(directive, columnsByField)
+			if err != nil {
+				return Table{}, err
+			}
+			table.Indexes = append(table.Indexes, Index{
+				Name:    directive.Args["name"],
+				Columns: columns,
+				Unique:  directive.Name == "unique",
+			})
+		}
+	}
+
+	// Implicit primary key for `id: ID!`
+	if len(table.PrimaryKey) == 0 {
+		if column, ok := table.Column("id"); ok && column.NotNull {
+			table.PrimaryKey = []string{"id"}
+		}
+	}
+
+	for i := range table.Columns {
+		column := &table.Columns[i]
+		if column.Default != nil && *column.Default == DefaultAutoincrement {
+			if column.Type != ColumnTypeInt || len(table.PrimaryKey) != 1 || table.PrimaryKey[0] != column.Name {
+				return Table{}, fmt.Errorf("field %s: autoincrement requires an Int single-column primary key", column.Field)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := range table.Indexes {
+		index := &table.Indexes[i]
+		if index.Name == "" {
+			prefix := "idx"
+			if index.Unique {
+				prefix = "uq"
+			}
+			index.Name = prefix + "_" + table.Name + "_" + strings.Join(index.Columns, "_")
+		}
+		if seen[index.Name] {
+			return Table{}, fmt.Errorf("duplicate index %s", index.Name)
+		}
+		seen[index.Name] = true
+	}
+	sort.Slice(table.Indexes, func(i, j int) bool { return table.Indexes[i].Name < table.Indexes[j].Name })
+
+	return table, nil
+}
+
+// directiveColumns resolves the `fields` argument of a model-level directive to column names
+func directiveColumns(directive Directive, columnsByField map[string]string) ([]string, error) {
+	fields := directive.Args["fields"]
+	if fields == "" {
+		return nil, fmt.Errorf("@%s requires a fields argument", directive.Name)
+	}
+
+	columns := []string{}
+	for _, field := range strings.Split(fields, ",") {
+		column, ok := columnsByField[strings.TrimSpace(field)]
+		if !ok {
+			return nil, fmt.Errorf("@%s refers to unknown field %s", directive.Name, field)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// columnType maps a field type to a column type. Fields that refer to other
+// models are relations and have no column.
+func columnType(fieldType string, models, enums map[string]bool) (string, bool) {
+	if strings.HasPrefix(fieldType, "[") {
+		inner := strings.Trim(fieldType, "[]")
+		if models[inner] {
+			return "", false
+		}
+		return ColumnTypeJSON, true
+	}
+
+	switch fieldType {
+	case ColumnTypeID, ColumnTypeString, ColumnTypeInt, ColumnTypeFloat, ColumnTypeBoolean, ColumnTypeDateTime, ColumnTypeJSON:
+		return fieldType, true
+	case "Time", "Date":
+		return ColumnTypeDateTime, true
+	}
+
+	if models[fieldType] {
+		return "", false
+	}
+	if enums[fieldType] {
+		return ColumnTypeString, true
+	}
+	return ColumnTypeJSON, true
+}
+
+// toSnakeCase converts camelCase and PascalCase identifiers to snake_case
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word at a lower->upper boundary, or at the last
+			// capital of an acronym followed by a lower case letter (HTTPServer)
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// pluralize applies simple English pluralization to a table name
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	default:
+		return name + "s"
+	}
+}
@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTables_ModelDirectives(t *testing.T) {
+	// Test plan:
+	// - Parse the `model` keyword and model/field directives
+	// - Verify table naming, column types, defaults, primary keys and indexes
+	// - Verify plain types are not tables and relations have no column
+
+	input := `
+enum Role {
+  ADMIN
+  USER
+}
+
+model User {
+  id: ID!
+  emailAddress: String! @unique
+  role: Role! @default(value: "USER")
+  active: Boolean! @default(value: true)
+  createdAt: DateTime! @default(value: now)
+  tags: [String!]
+  posts: [Post!]!
+}
+
+model Post @table(name: "blog_posts") @index(fields: ["authorId", "createdAt"]) {
+  id: Int! @default(value: autoincrement)
+  authorId: ID! @index
+  title: String!
+  createdAt: DateTime!
+}
+
+model OrderItem @primaryKey(fields: ["orderId", "sku"]) @unique(fields: ["orderId", "position"], name: "uq_position") {
+  orderId: ID!
+  sku: String!
+  position: Int!
+}
+
+type Address {
+  street: String!
+}`
+
+	s, err := ParseSchema(input)
+	require.NoError(t, err)
+
+	tables, err := s.Tables()
+	require.NoError(t, err)
+	require.Len(t, tables, 3)
+
+	// Sorted by table name
+	posts, items, users := tables[0], tables[1], tables[2]
+
+	assert.Equal(t, "users", users.Name)
+	assert.Equal(t, "User", users.Model)
+	assert.Equal(t, []string{"id"}, users.PrimaryKey)
+	names := []string{}
+	for _, column := range users.Columns {
+		names = append(names, column.Name)
+	}
+	assert.Equal(t, []string{"id", "email_address", "role", "active", "created_at", "tags"}, names)
+
+	role, ok := users.Column("role")
+	require.True(t, ok)
+	assert.Equal(t, ColumnTypeString, role.Type)
+	require.NotNil(t, role.Default)
+	assert.Equal(t, "USER", *role.Default)
+
+	active, _ := users.Column("active")
+	assert.Equal(t, "true", *active.Default)
+	createdAt, _ := users.Column("created_at")
+	assert.Equal(t, DefaultNow, *createdAt.Default)
+	tags, _ := users.Column("tags")
+	assert.Equal(t, ColumnTypeJSON, tags.Type)
+	assert.False(t, tags.NotNull)
+	assert.Equal(t, []Index{{Name: "uq_users_email_address", Columns: []string{"email_address"}, Unique: true}}, users.Indexes)
+
+	assert.Equal(t, "blog_posts", posts.Name)
+	assert.Equal(t, []string{"id"}, posts.PrimaryKey)
+	assert.Equal(t, []Index{
+		{Name: "idx_blog_posts_author_id", Columns: []string{"author_id"}},
+		{Name: "idx_blog_posts_author_id_created_at", Columns: []string{"author_id", "created_at"}},
+	}, posts.Indexes)
+
+	assert.Equal(t, "order_items", items.Name)
+	assert.Equal(t, []string{"order_id", "sku"}, items.PrimaryKey)
+	assert.Equal(t, []Index{{Name: "uq_position", Columns: []string{"order_id", "position"}, Unique: true}}, items.Indexes)
+}
+
+func TestTables_Errors(t *testing.T) {
+	// Test plan:
+	// - Verify invalid directive usage is reported with the model name
+
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{
+			name:  "unknown field in index",
+			input: `model User @index(fields: ["missing"]) { id: ID! }`,
+			err:   "unknown field",
+		},
+		{
+			name:  "primary key declared twice",
+			input: `model User @primaryKey(fields: ["id"]) { id: ID! @primaryKey }`,
+			err:   "primary key declared on both",
+		},
+		{
+			name:  "autoincrement on string",
+			input: `model User { id: ID! @default(value: autoincrement) }`,
+			err:   "autoincrement requires",
+		},
+		{
+			name:  "default without value",
+			input: `model User { id: ID! name: String @default }`,
+			err:   "requires a value",
+		},
+		{
+			name:  "duplicate table",
+			input: "model User { id: ID! }\nmodel Person @table(name: \"users\") { id: ID! }",
+			err:   "both map to table users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchema(tt.input)
+			require.NoError(t, err)
+
+			_, err = s.Tables()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestTableNaming(t *testing.T) {
+	// Test plan:
+	// - Verify snake_case conversion and pluralization
+
+	assert.Equal(t, "order_item", toSnakeCase("OrderItem"))
+	assert.Equal(t, "http_server", toSnakeCase("HTTPServer"))
+	assert.Equal(t, "user_id", toSnakeCase("userId"))
+	assert.Equal(t, "address2_line", toSnakeCase("address2Line"))
+
+	assert.Equal(t, "users", pluralize("user"))
+	assert.Equal(t, "addresses", pluralize("address"))
+	assert.Equal(t, "categories", pluralize("category"))
+	assert.Equal(t, "keys", pluralize("key"))
+	assert.Equal(t, "boxes", pluralize("box"))
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/okra-platform/okra/internal/hostapi"
 	"github.com/okra-platform/okra/internal/runtime"
 )
 
@@ -25,6 +27,7 @@ type adminServer struct {
 	connectGateway runtime.ConnectGateway
 	graphqlGateway runtime.GraphQLGateway
 	packageLoader  PackageLoader
+	hostAPIs       hostapi.HostAPIRegistry
 
 	// Track deployed services and their sources
 	deployedServices map[string]*DeployedService
@@ -70,11 +73,17 @@ func NewAdminServer(runtime runtime.Runtime, connectGateway runtime.ConnectGatew
 
 // NewAdminServerWithPackageLoader creates a new admin server with a custom package loader
 func NewAdminServerWithPackageLoader(runtime runtime.Runtime, connectGateway runtime.ConnectGateway, graphqlGateway runtime.GraphQLGateway, packageLoader PackageLoader) AdminServer {
+	return NewAdminServerWithHostAPIs(runtime, connectGateway, graphqlGateway, packageLoader, hostapi.NewDefaultHostAPIRegistry())
+}
+
+// NewAdminServerWithHostAPIs creates a new admin server that reports the host APIs in registry
+func NewAdminServerWithHostAPIs(runtime runtime.Runtime, connectGateway runtime.ConnectGateway, graphqlGateway runtime.GraphQLGateway, packageLoader PackageLoader, registry hostapi.HostAPIRegistry) AdminServer {
 	return &adminServer{
 		runtime:          runtime,
 		connectGateway:   connectGateway,
 		graphqlGateway:   graphqlGateway,
 		packageLoader:    packageLoader,
+		hostAPIs:         registry,
 		deployedServices: make(map[string]*DeployedService),
 	}
 }
@@ -88,6 +97,8 @@ func (s *adminServer) Start(ctx context.Context, port int) error {
 	mux.HandleFunc("/api/v1/packages/deploy", s.handleDeploy)
 	mux.HandleFunc("/api/v1/packages/", s.handleUndeploy) // Note the trailing slash for path prefix
 	mux.HandleFunc("/api/v1/packages", s.handleListServices)
+	mux.HandleFunc("/api/v1/hostapis", s.handleListHostAPIs)
+	mux.HandleFunc("/api/v1/hostapis/", s.handleGetHostAPI)
 
 	s.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
@@ -192,6 +203,56 @@ func (s *adminServer) handleListServices(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// handleListHostAPIs lists the host APIs this runtime offers. The format query
+// parameter selects the catalog (default), "jsonschema" or "openapi".
+func (s *adminServer) handleListHostAPIs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.sendError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	catalog := hostapi.DescribeRegistry(s.hostAPIs)
+	var body interface{}
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "catalog":
+		body = catalog
+	case "jsonschema":
+		body = catalog.JSONSchema()
+	case "openapi":
+		body = catalog.OpenAPI()
+	default:
+		s.sendError(w, http.StatusBadRequest, fmt.Sprintf("unsupported format %q (supported: catalog, jsonschema, openapi)", format))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// handleGetHostAPI describes a single host API
+func (s *adminServer) handleGetHostAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.sendError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Expected format: /api/v1/hostapis/{name}
+	name := strings.TrimPrefix(r.URL.Path, "/api/v1/hostapis/")
+	if name == "" {
+		s.handleListHostAPIs(w, r)
+		return
+	}
+
+	api, ok := hostapi.DescribeRegistry(s.hostAPIs).Lookup(name)
+	if !ok {
+		s.sendError(w, http.StatusNotFound, "host API not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api)
+}
+
 // handleUndeploy handles service undeployment
 func (s *adminServer) handleUndeploy(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
@@ -6,6 +6,8 @@ import (
 
 	"github.com/okra-platform/okra/internal/runtime"
 	"github.com/okra-platform/okra/internal/schema"
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/okra-platform/okra/internal/wasm"
 	"github.com/stretchr/testify/mock"
 	"github.com/tochemey/goakt/v2/actors"
 	"google.golang.org/protobuf/types/descriptorpb"
@@ -70,6 +72,17 @@ func (m *mockRuntime) Shutdown(ctx context.Context) error {
 	return args.Error(0)
 }
 
+// Engine returns nil, so packages are compiled into runtimes of their own
+func (m *mockRuntime) Engine() wasm.WASMEngine {
+	return nil
+}
+
+func (m *mockRuntime) QueueDelivery() hostapi.QueueDeliverFunc {
+	return func(ctx context.Context, sub hostapi.QueueSubscription, message hostapi.QueueMessage) error {
+		return nil
+	}
+}
+
 type mockConnectGateway struct {
 	mock.Mock
 }
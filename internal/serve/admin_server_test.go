@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/okra-platform/okra/internal/hostapi"
 	"github.com/okra-platform/okra/internal/runtime"
 	"github.com/okra-platform/okra/internal/schema"
 	"github.com/stretchr/testify/assert"
@@ -26,6 +27,7 @@ import (
 // 5. Test list services endpoint
 // 6. Test undeploy endpoint with existing service
 // 7. Test undeploy endpoint with non-existent service
+// 8. Test host API discovery endpoints in every format
 
 func TestNewAdminServer(t *testing.T) {
 	// Test: NewAdminServer creates server with dependencies
@@ -594,3 +596,65 @@ func TestAdminServer_HandleDeploy_RuntimeDeployError(t *testing.T) {
 
 	mockRT.AssertExpectations(t)
 }
+
+func TestAdminServer_HandleListHostAPIs(t *testing.T) {
+	server := &adminServer{hostAPIs: hostapi.NewDefaultHostAPIRegistry()}
+
+	// Test: The catalog lists every host API with its methods
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/hostapis", nil)
+	w := httptest.NewRecorder()
+	server.handleListHostAPIs(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var catalog hostapi.Catalog
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&catalog))
+	state, ok := catalog.Lookup("okra.state")
+	require.True(t, ok)
+	assert.Equal(t, "v1.0.0", state.Version)
+	assert.NotEmpty(t, state.Methods)
+
+	// Test: JSON Schema and OpenAPI formats
+	for format, key := range map[string]string{"jsonschema": "definitions", "openapi": "paths"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/hostapis?format="+format, nil)
+		w := httptest.NewRecorder()
+		server.handleListHostAPIs(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, format)
+		var doc map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&doc))
+		assert.Contains(t, doc, key, format)
+	}
+
+	// Test: Unknown formats and methods are rejected
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/hostapis?format=xml", nil)
+	w = httptest.NewRecorder()
+	server.handleListHostAPIs(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/hostapis", nil)
+	w = httptest.NewRecorder()
+	server.handleListHostAPIs(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestAdminServer_HandleGetHostAPI(t *testing.T) {
+	server := &adminServer{hostAPIs: hostapi.NewDefaultHostAPIRegistry()}
+
+	// Test: A single host API is described by name
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/hostapis/okra.cache", nil)
+	w := httptest.NewRecorder()
+	server.handleGetHostAPI(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var api hostapi.HostAPIDescription
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&api))
+	assert.Equal(t, "okra.cache", api.Name)
+
+	// Test: Unknown host APIs return 404
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/hostapis/okra.missing", nil)
+	w = httptest.NewRecorder()
+	server.handleGetHostAPI(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,30 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// MetricsPath is where the service gateway serves Prometheus metrics
+const MetricsPath = "/metrics"
+
+// NewPrometheusMeterProvider creates a MeterProvider whose metrics, including
+// those recorded by guests through okra.metrics, are served by the returned
+// handler in the Prometheus text format. Each call uses its own registry.
+func NewPrometheusMeterProvider() (*sdkmetric.MeterProvider, http.Handler, error) {
+	registry := prometheus.NewRegistry()
+
+	exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
+	}
+
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter))
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	return provider, handler, nil
+}
@@ -0,0 +1,46 @@
+package serve
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Test Plan:
+// 1. Test metrics recorded on the provider are served in Prometheus format
+// 2. Test separate providers do not share a registry
+
+func TestNewPrometheusMeterProvider(t *testing.T) {
+	provider, handler, err := NewPrometheusMeterProvider()
+	require.NoError(t, err)
+	defer provider.Shutdown(context.Background())
+
+	counter, err := provider.Meter("test").Float64Counter("acme.orders.orders_created")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 3, metric.WithAttributes(attribute.String("region", "eu")))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `acme_orders_orders_created_total{otel_scope_name="test"`)
+	assert.Contains(t, string(body), `region="eu"`)
+
+	// A second provider starts empty instead of failing on duplicate registration
+	other, otherHandler, err := NewPrometheusMeterProvider()
+	require.NoError(t, err)
+	defer other.Shutdown(context.Background())
+
+	rec = httptest.NewRecorder()
+	otherHandler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	assert.NotContains(t, rec.Body.String(), "acme_orders_orders_created_total")
+}
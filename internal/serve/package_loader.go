@@ -16,11 +16,23 @@ import (
 	"github.com/okra-platform/okra/internal/config"
 	"github.com/okra-platform/okra/internal/runtime"
 	"github.com/okra-platform/okra/internal/schema"
-	"github.com/okra-platform/okra/internal/wasm"
 )
 
-// LoadPackage loads a package from a file:// or s3:// URL
+// LoadPackage loads a package from a file:// or s3:// URL, giving it the
+// built-in host APIs
 func LoadPackage(ctx context.Context, source string) (*runtime.ServicePackage, error) {
+	return loadPackage(ctx, source, runtime.NewHostAPIEnvironment())
+}
+
+// NewPackageLoader returns a loader that gives every package it loads the
+// host APIs it declares from env
+func NewPackageLoader(env runtime.HostAPIEnvironment) PackageLoader {
+	return func(ctx context.Context, source string) (*runtime.ServicePackage, error) {
+		return loadPackage(ctx, source, env)
+	}
+}
+
+func loadPackage(ctx context.Context, source string, env runtime.HostAPIEnvironment) (*runtime.ServicePackage, error) {
 	// Parse source URL
 	sourceURL, err := url.Parse(source)
 	if err != nil {
@@ -65,7 +77,7 @@ func LoadPackage(ctx context.Context, source string) (*runtime.ServicePackage, e
 	}
 
 	// Load package components
-	return loadPackageComponents(extractedFiles)
+	return loadPackageComponents(ctx, extractedFiles, env)
 }
 
 // extractPackage extracts a tar.gz package to the specified directory
@@ -152,7 +164,7 @@ func validatePackageFiles(files map[string]string) error {
 }
 
 // loadPackageComponents loads all components from extracted files
-func loadPackageComponents(files map[string]string) (*runtime.ServicePackage, error) {
+func loadPackageComponents(ctx context.Context, files map[string]string, env runtime.HostAPIEnvironment) (*runtime.ServicePackage, error) {
 	// Load config
 	configData, err := os.ReadFile(files["okra.json"])
 	if err != nil {
@@ -187,7 +199,7 @@ func loadPackageComponents(files map[string]string) (*runtime.ServicePackage, er
 		return nil, fmt.Errorf("failed to read WASM file: %w", err)
 	}
 
-	wasmModule, err := wasm.NewWASMCompiledModule(context.Background(), wasmBytes)
+	wasmModule, err := runtime.CompileServiceModule(ctx, wasmBytes, &cfg, env)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile WASM module: %w", err)
 	}
@@ -195,6 +207,7 @@ func loadPackageComponents(files map[string]string) (*runtime.ServicePackage, er
 	// Create service package
 	pkg, err := runtime.NewServicePackage(wasmModule, &sch, &cfg)
 	if err != nil {
+		wasmModule.Close(ctx)
 		return nil, fmt.Errorf("failed to create service package: %w", err)
 	}
 
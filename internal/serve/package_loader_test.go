@@ -14,6 +14,7 @@ import (
 	"testing"
 
 	"github.com/okra-platform/okra/internal/config"
+	"github.com/okra-platform/okra/internal/runtime"
 	"github.com/okra-platform/okra/internal/schema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,6 +30,7 @@ import (
 // 5. Test LoadPackage with valid local file
 // 6. Test LoadPackage with invalid source URL
 // 7. Test LoadPackage with missing file
+// 8. Test NewPackageLoader enforces the package's declared host APIs
 
 func createTestPackage(t *testing.T, dir string) string {
 	// Create a test package file
@@ -217,7 +219,7 @@ func TestLoadPackageComponents(t *testing.T) {
 		"service.pb.desc":          pbPath,
 	}
 
-	pkg, err := loadPackageComponents(files)
+	pkg, err := loadPackageComponents(context.Background(), files, runtime.NewHostAPIEnvironment())
 	require.NoError(t, err)
 
 	assert.NotNil(t, pkg)
@@ -561,6 +563,58 @@ func TestLoadPackage_ConcurrentLoads(t *testing.T) {
 	}
 }
 
+func TestNewPackageLoader_HostAPIs(t *testing.T) {
+	// Test: A package whose WASM imports okra host functions loads only when
+	// okra.json declares host APIs the runtime offers
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	loader := NewPackageLoader(runtime.NewHostAPIEnvironment())
+
+	// Imports okra.run_host_api as a () -> () function
+	hostCallWASM := []byte{
+		0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00,
+		0x02, 0x15, 0x01, 0x04, 'o', 'k', 'r', 'a', 0x0c,
+		'r', 'u', 'n', '_', 'h', 'o', 's', 't', '_', 'a', 'p', 'i', 0x00, 0x00,
+	}
+	packageFiles := func(hostAPIs string) map[string][]byte {
+		return map[string][]byte{
+			"service.wasm": hostCallWASM,
+			"service.description.json": []byte(`{
+				"meta": {"namespace": "test", "version": "v1"},
+				"services": [{"name": "HostService", "methods": [{"name": "Run", "inputType": "In", "outputType": "Out"}]}]
+			}`),
+			"okra.json":       []byte(`{"name": "HostService", "version": "1.0.0", "language": "go"` + hostAPIs + `}`),
+			"service.pb.desc": []byte{0x0A, 0x00},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		hostAPIs string
+		wantErr  string
+	}{
+		{name: "undeclared", hostAPIs: "", wantErr: "declares no hostApis"},
+		{name: "unavailable", hostAPIs: `, "hostApis": {"okra.nope": "^1.0.0"}`, wantErr: "okra.nope: not offered by the runtime"},
+		{name: "declared", hostAPIs: `, "hostApis": {"okra.state": "^1.0.0"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packagePath := createCorruptedPackage(t, tempDir, tt.name, packageFiles(tt.hostAPIs))
+
+			pkg, err := loader(ctx, "file://"+packagePath)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			defer pkg.Module.Close(ctx)
+			assert.Equal(t, map[string]string{"okra.state": "^1.0.0"}, pkg.Config.HostAPIs)
+		})
+	}
+}
+
 // Helper functions for robustness tests
 
 func compressData(data []byte) []byte {
@@ -0,0 +1,192 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+)
+
+// DefaultCompilationCacheSize is the size limit of the compilation cache when
+// none is configured
+const DefaultCompilationCacheSize int64 = 512 << 20
+
+// CompilationCache keeps compiled modules on disk so restarting okra or
+// rebuilding a service skips compiling WASM it has compiled before.
+//
+// Entries are content addressed: wazero keys each by a hash of the module and
+// stores it under a directory named for the wazero version, so an upgrade
+// never loads code compiled by another version. Every module compiled with the
+// same cache also shares compiled code in memory with identical modules.
+type CompilationCache interface {
+	// Dir returns the directory the cache stores entries in
+	Dir() string
+
+	// Evict removes entries, oldest written first, until the cache fits its
+	// size limit. Entries written by other wazero versions are always removed.
+	//
+	// wazero names entries by a key it derives from the module, and does not
+	// report when it reads one, so the cache cannot tell when an entry was
+	// last used. An entry loaded on every start is evicted as soon as it is
+	// the oldest one.
+	Evict() error
+
+	// Close releases the compiled code the cache holds in memory
+	Close(ctx context.Context) error
+
+	// wazeroCache returns the cache handed to wazero runtimes
+	wazeroCache() wazero.CompilationCache
+}
+
+// DefaultCompilationCacheDir returns $OKRA_WASM_CACHE_DIR, or okra/wasm in the
+// user's cache directory
+func DefaultCompilationCacheDir() (string, error) {
+	if dir := os.Getenv("OKRA_WASM_CACHE_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "okra", "wasm"), nil
+}
+
+// NewCompilationCache opens the compilation cache in dir, creating it if
+// needed, and evicts entries until it holds at most maxBytes (0 = unlimited)
+func NewCompilationCache(dir string, maxBytes int64) (CompilationCache, error) {
+	if dir == "" {
+		return nil, errors.New("compilation cache directory cannot be empty")
+	}
+	if maxBytes < 0 {
+		return nil, errors.New("compilation cache size cannot be negative")
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve compilation cache directory: %w", err)
+	}
+	cache, err := wazero.NewCompilationCacheWithDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open compilation cache: %w", err)
+	}
+
+	c := &compilationCache{dir: dir, maxBytes: maxBytes, cache: cache}
+	if err := c.Evict(); err != nil {
+		cache.Close(context.Background())
+		return nil, err
+	}
+	return c, nil
+}
+
+type compilationCache struct {
+	dir      string
+	maxBytes int64
+	cache    wazero.CompilationCache
+	mu       sync.Mutex // Serializes eviction
+}
+
+func (c *compilationCache) Dir() string {
+	return c.dir
+}
+
+func (c *compilationCache) Evict() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	versionDirs, err := filepath.Glob(filepath.Join(c.dir, "wazero-*"))
+	if err != nil {
+		return err
+	}
+
+	// Only remove other versions once we know which directory is ours
+	if name := versionDirName(); name != "" {
+		current := filepath.Join(c.dir, name)
+		if _, err := os.Stat(current); err == nil {
+			for _, dir := range versionDirs {
+				if dir != current {
+					if err := os.RemoveAll(dir); err != nil {
+						return fmt.Errorf("failed to evict compilation cache: %w", err)
+					}
+				}
+			}
+			versionDirs = []string{current}
+		}
+	}
+
+	if c.maxBytes == 0 {
+		return nil
+	}
+
+	type entry struct {
+		path string
+		info fs.FileInfo
+	}
+	var entries []entry
+	var total int64
+	for _, dir := range versionDirs {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read compilation cache: %w", err)
+		}
+		for _, file := range files {
+			// Skip entries still being written
+			if file.IsDir() || strings.HasSuffix(file.Name(), ".tmp") {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+			entries = append(entries, entry{path: filepath.Join(dir, file.Name()), info: info})
+			total += info.Size()
+		}
+	}
+
+	// Entries are never rewritten, so this orders them by when they were
+	// written, not last used
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].info.ModTime().Before(entries[j].info.ModTime())
+	})
+	for _, e := range entries {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to evict compilation cache: %w", err)
+		}
+		total -= e.info.Size()
+	}
+	return nil
+}
+
+func (c *compilationCache) Close(ctx context.Context) error {
+	return c.cache.Close(ctx)
+}
+
+func (c *compilationCache) wazeroCache() wazero.CompilationCache {
+	return c.cache
+}
+
+// versionDirName returns the name of the directory wazero stores this
+// version's entries in, or "" if the wazero version cannot be determined
+func versionDirName() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/tetratelabs/wazero" && dep.Version != "" && dep.Version != "(devel)" {
+			return "wazero-" + dep.Version + "-" + runtime.GOARCH + "-" + runtime.GOOS
+		}
+	}
+	return ""
+}
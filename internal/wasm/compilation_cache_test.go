@@ -0,0 +1,153 @@
+package wasm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test NewCompilationCache rejects an empty directory and a negative size
+// 2. Test compiled modules are stored on disk and reused by a new cache in the same directory
+// 3. Test Evict removes the oldest entries until the cache fits its limit
+// 4. Test Evict removes entries written by other wazero versions
+// 5. Test DefaultCompilationCacheDir honours OKRA_WASM_CACHE_DIR
+
+// cacheEntries returns the files in the cache's directory for this wazero version
+func cacheEntries(t *testing.T, cache CompilationCache) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(cache.Dir(), "wazero-*", "*"))
+	require.NoError(t, err)
+	return files
+}
+
+// compileCached compiles limitsModule through cache, then closes the cache
+func compileCached(t *testing.T, cache CompilationCache) {
+	t.Helper()
+	ctx := context.Background()
+	engine, err := NewWASMEngine(ctx, cache)
+	require.NoError(t, err)
+	_, err = engine.Compile(ctx, limitsModule)
+	require.NoError(t, err)
+	require.NoError(t, engine.Close(ctx))
+	require.NoError(t, cache.Close(ctx))
+}
+
+func TestNewCompilationCache_InvalidArguments(t *testing.T) {
+	// Test: A directory is required and the size cannot be negative
+	_, err := NewCompilationCache("", 0)
+	assert.Error(t, err)
+
+	_, err = NewCompilationCache(t.TempDir(), -1)
+	assert.Error(t, err)
+}
+
+func TestCompilationCache_ReusedAcrossCaches(t *testing.T) {
+	// Test: A module compiled once is loaded from disk by the next process
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	cache, err := NewCompilationCache(dir, 0)
+	require.NoError(t, err)
+	compileCached(t, cache)
+
+	entries := cacheEntries(t, cache)
+	require.Len(t, entries, 1)
+	written, err := os.Stat(entries[0])
+	require.NoError(t, err)
+
+	// Test: A new cache over the same directory reuses the entry
+	cache, err = NewCompilationCache(dir, 0)
+	require.NoError(t, err)
+	defer cache.Close(ctx)
+	engine, err := NewWASMEngine(ctx, cache)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+	module, err := engine.CompileWithHostAPIs(ctx, limitsModule)
+	require.NoError(t, err)
+	defer module.Close(ctx)
+
+	reused, err := os.Stat(entries[0])
+	require.NoError(t, err)
+	assert.Equal(t, written.ModTime(), reused.ModTime())
+	assert.Len(t, cacheEntries(t, cache), 1)
+
+	worker, err := module.Instantiate(ctx)
+	require.NoError(t, err)
+	defer worker.Close(ctx)
+	output, err := worker.Invoke(ctx, "echo", []byte("hit"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hit"), output)
+}
+
+func TestCompilationCache_EvictsOldestEntries(t *testing.T) {
+	// Test: Entries are removed oldest first until the cache fits its limit
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	cache, err := NewCompilationCache(dir, 0)
+	require.NoError(t, err)
+	compileCached(t, cache)
+
+	compiled := cacheEntries(t, cache)[0]
+	info, err := os.Stat(compiled)
+	require.NoError(t, err)
+	versionDir := filepath.Dir(compiled)
+	now := time.Now()
+	for i, name := range []string{"old", "middle", "new"} {
+		path := filepath.Join(versionDir, name)
+		require.NoError(t, os.WriteFile(path, make([]byte, 1000), 0o644))
+		modTime := now.Add(time.Duration(i-10) * time.Hour)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	cache, err = NewCompilationCache(dir, info.Size()+1500)
+	require.NoError(t, err)
+	defer cache.Close(ctx)
+
+	entries := cacheEntries(t, cache)
+	assert.NotContains(t, entries, filepath.Join(versionDir, "old"))
+	assert.NotContains(t, entries, filepath.Join(versionDir, "middle"))
+	assert.Contains(t, entries, filepath.Join(versionDir, "new"))
+	assert.Contains(t, entries, compiled)
+}
+
+func TestCompilationCache_EvictsOtherVersions(t *testing.T) {
+	// Test: Entries compiled by another wazero version are removed
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	stale := filepath.Join(dir, "wazero-v0.0.1-"+filepath.Base(t.TempDir()))
+	require.NoError(t, os.MkdirAll(stale, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(stale, "entry"), []byte("stale"), 0o644))
+
+	cache, err := NewCompilationCache(dir, 0)
+	require.NoError(t, err)
+	defer cache.Close(ctx)
+
+	if versionDirName() == "" {
+		t.Skip("wazero version is not in the build info")
+	}
+	assert.NoDirExists(t, stale)
+	assert.DirExists(t, filepath.Join(dir, versionDirName()))
+}
+
+func TestDefaultCompilationCacheDir(t *testing.T) {
+	// Test: The environment variable overrides the user cache directory
+	t.Setenv("OKRA_WASM_CACHE_DIR", "/tmp/okra-cache")
+	dir, err := DefaultCompilationCacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/okra-cache", dir)
+
+	t.Setenv("OKRA_WASM_CACHE_DIR", "")
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg")
+	t.Setenv("HOME", "/tmp/home")
+	dir, err = DefaultCompilationCacheDir()
+	require.NoError(t, err)
+	assert.Contains(t, dir, filepath.Join("okra", "wasm"))
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/tetratelabs/wazero"
-	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 )
 
 // WASMCompiledModule represents a compiled WASM module that can create worker instances.
@@ -18,41 +17,46 @@ type WASMCompiledModule interface {
 	Close(ctx context.Context) error
 }
 
-// NewWASMCompiledModule creates a new compiled module from WASM bytes.
-func NewWASMCompiledModule(ctx context.Context, wasmBytes []byte) (WASMCompiledModule, error) {
+// NewWASMCompiledModule creates a new compiled module from WASM bytes. The
+// module runs in a runtime of its own, which closing the module closes; use a
+// WASMEngine to host several modules in one runtime.
+func NewWASMCompiledModule(ctx context.Context, wasmBytes []byte, opts ...ModuleOption) (WASMCompiledModule, error) {
 	if len(wasmBytes) == 0 {
 		return nil, fmt.Errorf("wasm bytes cannot be empty")
 	}
 
-	// Create a new runtime
-	runtime := wazero.NewRuntime(ctx)
-
-	// Instantiate WASI
-	_, err := wasi_snapshot_preview1.Instantiate(ctx, runtime)
+	engine, err := newWASMEngine(ctx, nil)
 	if err != nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+		return nil, err
 	}
-
-	// Compile the module
-	compiled, err := runtime.CompileModule(ctx, wasmBytes)
+	module, err := engine.Compile(ctx, wasmBytes, opts...)
 	if err != nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("failed to compile module: %w", err)
+		engine.Close(ctx)
+		return nil, err
 	}
-
-	return &wasmCompiledModule{
-		runtime:  runtime,
-		compiled: compiled,
-	}, nil
+	module.(*wasmCompiledModule).release = engine.Close
+	return module, nil
 }
 
 type wasmCompiledModule struct {
-	runtime  wazero.Runtime
-	compiled wazero.CompiledModule
+	engine    *wasmEngine
+	wasmBytes []byte // Kept to compile a snapshot module from
+	compiled  wazero.CompiledModule
+	limits    Limits
+	release   func(context.Context) error // Releases the compiled code
 }
 
 func (m *wasmCompiledModule) Instantiate(ctx context.Context) (WASMWorker, error) {
+	return m.instantiate(ctx, m.compiled, nil)
+}
+
+func (m *wasmCompiledModule) Snapshot(ctx context.Context) (WASMCompiledModule, error) {
+	return m.engine.takeSnapshot(ctx, m.wasmBytes, moduleOptions{limits: m.limits}, m.instantiate)
+}
+
+// instantiate creates a worker from compiled, starting it from snapshot if
+// there is one
+func (m *wasmCompiledModule) instantiate(ctx context.Context, compiled wazero.CompiledModule, snapshot *moduleSnapshot) (WASMWorker, error) {
 	// Create module config - don't call _start since this is a reactor module
 	config := wazero.NewModuleConfig().
 		WithStdout(nil).
@@ -61,17 +65,20 @@ func (m *wasmCompiledModule) Instantiate(ctx context.Context) (WASMWorker, error
 		WithStartFunctions() // Don't call _start
 
 	// Instantiate the module
-	module, err := m.runtime.InstantiateModule(ctx, m.compiled, config)
+	memory := newMemoryLimiter(m.limits)
+	module, err := m.engine.runtime.InstantiateModule(memory.instantiateContext(ctx), compiled, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to instantiate module: %w", err)
 	}
+	if memory.Exhausted() {
+		module.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate module: %w: memory limit of %d pages", ErrResourceExhausted, m.limits.MaxMemoryPages)
+	}
 
-	// Call _initialize if it exists
-	if initialize := module.ExportedFunction("_initialize"); initialize != nil {
-		if _, err := initialize.Call(ctx); err != nil {
-			module.Close(ctx)
-			return nil, fmt.Errorf("failed to call _initialize: %w", err)
-		}
+	// Call _initialize, or restore the snapshot taken after it
+	if err := initialize(ctx, module, snapshot); err != nil {
+		module.Close(ctx)
+		return nil, limitError(ctx, err, memory, nil, m.limits)
 	}
 
 	// Get required functions
@@ -98,9 +105,11 @@ func (m *wasmCompiledModule) Instantiate(ctx context.Context) (WASMWorker, error
 		handleRequest: handleRequest,
 		allocate:      allocate,
 		deallocate:    deallocate,
+		limits:        m.limits,
+		memory:        memory,
 	}, nil
 }
 
 func (m *wasmCompiledModule) Close(ctx context.Context) error {
-	return m.runtime.Close(ctx)
+	return m.release(ctx)
 }
@@ -3,10 +3,13 @@ package wasm
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
 
 	"github.com/okra-platform/okra/internal/hostapi"
 	"github.com/tetratelabs/wazero"
-	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
 )
 
 // WASMCompiledModuleWithHostAPIs extends WASMCompiledModule to support host APIs
@@ -21,41 +24,38 @@ type WASMCompiledModuleWithHostAPIs interface {
 
 	// WithHostAPIConfig sets the configuration for host APIs
 	WithHostAPIConfig(config hostapi.HostAPIConfig) WASMCompiledModuleWithHostAPIs
+
+	// HostImports returns the functions the module imports from the okra host module
+	HostImports() []string
 }
 
-// NewWASMCompiledModuleWithHostAPIs creates a new compiled module with host API support
-func NewWASMCompiledModuleWithHostAPIs(ctx context.Context, wasmBytes []byte) (WASMCompiledModuleWithHostAPIs, error) {
+// NewWASMCompiledModuleWithHostAPIs creates a new compiled module with host API
+// support. The module runs in a runtime of its own, which closing the module
+// closes; use a WASMEngine to host several modules in one runtime.
+func NewWASMCompiledModuleWithHostAPIs(ctx context.Context, wasmBytes []byte, opts ...ModuleOption) (WASMCompiledModuleWithHostAPIs, error) {
 	if len(wasmBytes) == 0 {
 		return nil, fmt.Errorf("wasm bytes cannot be empty")
 	}
 
-	// Create a new runtime
-	runtime := wazero.NewRuntime(ctx)
-
-	// Instantiate WASI
-	_, err := wasi_snapshot_preview1.Instantiate(ctx, runtime)
+	engine, err := newWASMEngine(ctx, nil)
 	if err != nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+		return nil, err
 	}
-
-	// Compile the module
-	compiled, err := runtime.CompileModule(ctx, wasmBytes)
+	module, err := engine.CompileWithHostAPIs(ctx, wasmBytes, opts...)
 	if err != nil {
-		runtime.Close(ctx)
-		return nil, fmt.Errorf("failed to compile module: %w", err)
+		engine.Close(ctx)
+		return nil, err
 	}
-
-	return &wasmCompiledModuleWithHostAPIs{
-		runtime:  runtime,
-		compiled: compiled,
-		hostAPIs: []string{},
-	}, nil
+	module.(*wasmCompiledModuleWithHostAPIs).release = engine.Close
+	return module, nil
 }
 
 type wasmCompiledModuleWithHostAPIs struct {
-	runtime       wazero.Runtime
+	engine        *wasmEngine
+	wasmBytes     []byte // Kept to compile a snapshot module from
 	compiled      wazero.CompiledModule
+	limits        Limits
+	release       func(context.Context) error // Releases the compiled code
 	hostAPIs      []string
 	registry      hostapi.HostAPIRegistry
 	hostAPIConfig hostapi.HostAPIConfig
@@ -76,18 +76,49 @@ func (m *wasmCompiledModuleWithHostAPIs) WithHostAPIConfig(config hostapi.HostAP
 	return m
 }
 
+func (m *wasmCompiledModuleWithHostAPIs) HostImports() []string {
+	var imports []string
+	for _, fn := range m.compiled.ImportedFunctions() {
+		if module, name, ok := fn.Import(); ok && module == hostapi.HostModuleName {
+			imports = append(imports, name)
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
 func (m *wasmCompiledModuleWithHostAPIs) Instantiate(ctx context.Context) (WASMWorker, error) {
+	return m.instantiate(ctx, m.compiled, nil)
+}
+
+// Snapshot takes its template after _initialize, so host API calls made while
+// initializing only affect the template's own host API set. The envelope
+// encoding the template selects is carried over to every worker.
+func (m *wasmCompiledModuleWithHostAPIs) Snapshot(ctx context.Context) (WASMCompiledModule, error) {
+	return m.engine.takeSnapshot(ctx, m.wasmBytes, moduleOptions{limits: m.limits}, m.instantiate)
+}
+
+// instantiate creates a worker from compiled, starting it from snapshot if
+// there is one
+func (m *wasmCompiledModuleWithHostAPIs) instantiate(ctx context.Context, compiled wazero.CompiledModule, snapshot *moduleSnapshot) (WASMWorker, error) {
+	// Host APIs and the guest's WASI clocks share one clock
+	hostAPIConfig := m.hostAPIConfig
+	if hostAPIConfig.Clock == nil {
+		hostAPIConfig.Clock = hostapi.NewSystemClock()
+	}
+
 	// Create host API set if registry is configured
 	var hostAPISet hostapi.HostAPISet
+	var hostModule *hostapi.HostModule
 	if m.registry != nil && len(m.hostAPIs) > 0 {
 		var err error
-		hostAPISet, err = m.registry.CreateHostAPISet(ctx, m.hostAPIs, m.hostAPIConfig)
+		hostAPISet, err = m.registry.CreateHostAPISet(ctx, m.hostAPIs, hostAPIConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create host API set: %w", err)
 		}
 
 		// Register host functions with the runtime
-		err = hostapi.RegisterHostAPI(ctx, m.runtime, hostAPISet)
+		hostModule, err = m.engine.registerHostModule(ctx)
 		if err != nil {
 			hostAPISet.Close()
 			return nil, fmt.Errorf("failed to register host APIs: %w", err)
@@ -100,9 +131,12 @@ func (m *wasmCompiledModuleWithHostAPIs) Instantiate(ctx context.Context) (WASMW
 		WithStderr(nil).
 		WithName("").
 		WithStartFunctions() // Don't call _start
+	inv := &invocation{}
+	config = withClock(config, hostAPIConfig.Clock, inv)
 
 	// Instantiate the module
-	module, err := m.runtime.InstantiateModule(ctx, m.compiled, config)
+	memory := newMemoryLimiter(m.limits)
+	module, err := m.engine.runtime.InstantiateModule(memory.instantiateContext(ctx), compiled, config)
 	if err != nil {
 		if hostAPISet != nil {
 			hostAPISet.Close()
@@ -110,42 +144,54 @@ func (m *wasmCompiledModuleWithHostAPIs) Instantiate(ctx context.Context) (WASMW
 		return nil, fmt.Errorf("failed to instantiate module: %w", err)
 	}
 
-	// Call _initialize if it exists
-	if initialize := module.ExportedFunction("_initialize"); initialize != nil {
-		if _, err := initialize.Call(ctx); err != nil {
-			module.Close(ctx)
-			if hostAPISet != nil {
-				hostAPISet.Close()
-			}
-			return nil, fmt.Errorf("failed to call _initialize: %w", err)
+	// Route this instance's host calls to its own host API set, in the
+	// encoding the snapshot's template selected while initializing
+	if hostModule != nil {
+		hostModule.Bind(module, hostAPISet)
+		if snapshot != nil {
+			hostModule.SetEncoding(module, snapshot.encoding)
 		}
 	}
-
-	// Get required functions
-	handleRequest := module.ExportedFunction("handle_request")
-	if handleRequest == nil {
+	closeWorker := func() {
 		module.Close(ctx)
+		if hostModule != nil {
+			hostModule.Unbind(module)
+		}
 		if hostAPISet != nil {
 			hostAPISet.Close()
 		}
+	}
+
+	if memory.Exhausted() {
+		closeWorker()
+		return nil, fmt.Errorf("failed to instantiate module: %w: memory limit of %d pages", ErrResourceExhausted, m.limits.MaxMemoryPages)
+	}
+
+	// Call _initialize, or restore the snapshot taken after it
+	leave := inv.enter(ctx)
+	err = initialize(ctx, module, snapshot)
+	leave()
+	if err != nil {
+		closeWorker()
+		return nil, limitError(ctx, err, memory, nil, m.limits)
+	}
+
+	// Get required functions
+	handleRequest := module.ExportedFunction("handle_request")
+	if handleRequest == nil {
+		closeWorker()
 		return nil, fmt.Errorf("handle_request function not found")
 	}
 
 	allocate := module.ExportedFunction("allocate")
 	if allocate == nil {
-		module.Close(ctx)
-		if hostAPISet != nil {
-			hostAPISet.Close()
-		}
+		closeWorker()
 		return nil, fmt.Errorf("allocate function not found")
 	}
 
 	deallocate := module.ExportedFunction("deallocate")
 	if deallocate == nil {
-		module.Close(ctx)
-		if hostAPISet != nil {
-			hostAPISet.Close()
-		}
+		closeWorker()
 		return nil, fmt.Errorf("deallocate function not found")
 	}
 
@@ -155,24 +201,93 @@ func (m *wasmCompiledModuleWithHostAPIs) Instantiate(ctx context.Context) (WASMW
 			handleRequest: handleRequest,
 			allocate:      allocate,
 			deallocate:    deallocate,
+			limits:        m.limits,
+			memory:        memory,
+			invocation:    inv,
 		},
 		hostAPISet: hostAPISet,
+		hostModule: hostModule,
 	}, nil
 }
 
+// maxWASISleep caps a sleep the guest asks for through WASI, as okra.time
+// caps time.sleep
+const maxWASISleep = 24 * time.Hour
+
+// withClock points the guest's WASI clocks and sleep at clock, so time read
+// through WASI agrees with okra.time. wazero gives WASI sleep no context, so
+// a sleep stops early when the call inv tracks is done.
+func withClock(config wazero.ModuleConfig, clock hostapi.Clock, inv *invocation) wazero.ModuleConfig {
+	return config.
+		WithWalltime(func() (int64, int32) {
+			now := clock.Now()
+			return now.Unix(), int32(now.Nanosecond())
+		}, sys.ClockResolution(1)).
+		WithNanotime(clock.Nanotime, sys.ClockResolution(1)).
+		WithNanosleep(wasiSleep(clock, inv))
+}
+
+// wasiSleep sleeps on clock for at most maxWASISleep, until the call inv
+// tracks is done
+func wasiSleep(clock hostapi.Clock, inv *invocation) func(ns int64) {
+	return func(ns int64) {
+		d := time.Duration(ns)
+		if d > maxWASISleep {
+			d = maxWASISleep
+		}
+		_ = clock.Sleep(inv.context(), d)
+	}
+}
+
+// invocation holds the context of the call a worker is running, for host
+// callbacks wazero calls without one
+type invocation struct {
+	ctx atomic.Pointer[context.Context]
+}
+
+// enter makes ctx the current call's context until the returned function is
+// called
+func (i *invocation) enter(ctx context.Context) func() {
+	i.ctx.Store(&ctx)
+	return func() { i.ctx.Store(nil) }
+}
+
+// context returns the current call's context, or context.Background between
+// calls
+func (i *invocation) context() context.Context {
+	if i != nil {
+		if ctx := i.ctx.Load(); ctx != nil {
+			return *ctx
+		}
+	}
+	return context.Background()
+}
+
 func (m *wasmCompiledModuleWithHostAPIs) Close(ctx context.Context) error {
-	return m.runtime.Close(ctx)
+	return m.release(ctx)
 }
 
 // wasmWorkerWithHostAPIs extends wasmWorker with host API cleanup
 type wasmWorkerWithHostAPIs struct {
 	wasmWorker
 	hostAPISet hostapi.HostAPISet
+	hostModule *hostapi.HostModule
+}
+
+// encoding returns the envelope encoding the worker's guest selected
+func (w *wasmWorkerWithHostAPIs) encoding() hostapi.Encoding {
+	if w.hostModule == nil {
+		return hostapi.EncodingJSON
+	}
+	return w.hostModule.Encoding(w.module)
 }
 
 func (w *wasmWorkerWithHostAPIs) Close(ctx context.Context) error {
 	// Close the module first
 	err := w.wasmWorker.Close(ctx)
+	if w.hostModule != nil {
+		w.hostModule.Unbind(w.module)
+	}
 
 	// Then close the host API set
 	if w.hostAPISet != nil {
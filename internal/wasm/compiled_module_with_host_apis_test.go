@@ -3,12 +3,16 @@ package wasm
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/okra-platform/okra/internal/hostapi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 )
 
 // Test plan:
@@ -21,6 +25,9 @@ import (
 // 7. Test Instantiate without host APIs
 // 8. Test cleanup on errors
 // 9. Test worker Close with host APIs
+// 10. Test guest WASI clocks read the configured host API clock
+// 11. Test HostImports lists only functions imported from the okra module
+// 12. Test a guest's WASI sleep stops when its call is done and is capped
 
 func TestNewWASMCompiledModuleWithHostAPIs_EmptyBytes(t *testing.T) {
 	// Test: Creating module with empty WASM bytes should fail
@@ -221,6 +228,10 @@ func (m *mockHostAPIRegistry) Get(name string) (hostapi.HostAPIFactory, bool) {
 	return nil, false
 }
 
+func (m *mockHostAPIRegistry) Resolve(name, versionRange string) (hostapi.HostAPIFactory, error) {
+	return nil, fmt.Errorf("host API %s not found", name)
+}
+
 func (m *mockHostAPIRegistry) List() []hostapi.HostAPIFactory {
 	return nil
 }
@@ -265,4 +276,112 @@ func (m *mockHostAPISet) Config() hostapi.HostAPIConfig {
 func (m *mockHostAPISet) Close() error {
 	m.closed = true
 	return m.closeErr
-}
\ No newline at end of file
+}
+// clockModule exports now(clockID) -> i64, which returns WASI clock_time_get
+// for the given clock (0 = realtime, 1 = monotonic)
+var clockModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32, i64, i32) -> i32 and (i32) -> i64
+	0x01, 0x0d, 0x02, 0x60, 0x03, 0x7f, 0x7e, 0x7f, 0x01, 0x7f, 0x60, 0x01, 0x7f, 0x01, 0x7e,
+	// Import wasi_snapshot_preview1.clock_time_get
+	0x02, 0x29, 0x01,
+	0x16, 'w', 'a', 's', 'i', '_', 's', 'n', 'a', 'p', 's', 'h', 'o', 't', '_', 'p', 'r', 'e', 'v', 'i', 'e', 'w', '1',
+	0x0e, 'c', 'l', 'o', 'c', 'k', '_', 't', 'i', 'm', 'e', '_', 'g', 'e', 't',
+	0x00, 0x00,
+	// Function and memory
+	0x03, 0x02, 0x01, 0x01,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Export memory and now
+	0x07, 0x10, 0x02,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x03, 'n', 'o', 'w', 0x00, 0x01,
+	// now: clock_time_get(id, 1, 0); return i64.load(0)
+	0x0a, 0x12, 0x01, 0x10, 0x00,
+	0x20, 0x00, 0x42, 0x01, 0x41, 0x00, 0x10, 0x00, 0x1a,
+	0x41, 0x00, 0x29, 0x03, 0x00, 0x0b,
+}
+
+func TestWithClock_GuestTimeFollowsClock(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+	_, err := wasi_snapshot_preview1.Instantiate(ctx, runtime)
+	require.NoError(t, err)
+
+	compiled, err := runtime.CompileModule(ctx, clockModule)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := hostapi.NewFakeClock(start)
+	module, err := runtime.InstantiateModule(ctx, compiled, withClock(wazero.NewModuleConfig(), clock, nil))
+	require.NoError(t, err)
+
+	now := module.ExportedFunction("now")
+	results, err := now.Call(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(start.UnixNano()), results[0])
+
+	clock.Advance(90 * time.Second)
+	results, err = now.Call(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(start.Add(90*time.Second).UnixNano()), results[0])
+
+	results, err = now.Call(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(90*time.Second), results[0])
+}
+
+func TestWASISleep_StopsWithCall(t *testing.T) {
+	// Test: Under a fake clock nobody advances, a sleep ends with its call
+	clock := hostapi.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	inv := &invocation{}
+	sleep := wasiSleep(clock, inv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	leave := inv.enter(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sleep(int64(time.Hour))
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sleep outlived its call")
+	}
+	leave()
+	assert.Equal(t, 0, clock.Sleepers())
+
+	// Test: A sleep longer than the cap wakes once the cap has passed
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		sleep(int64(1000 * time.Hour))
+	}()
+	require.Eventually(t, func() bool { return clock.Sleepers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(maxWASISleep)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sleep was not capped")
+	}
+}
+
+func TestWASMCompiledModuleWithHostAPIs_HostImports(t *testing.T) {
+	// Test: HostImports lists the okra functions a module imports, and nothing
+	// for a module that imports only WASI
+	ctx := context.Background()
+
+	module, err := NewWASMCompiledModuleWithHostAPIs(ctx, hostCallModule)
+	require.NoError(t, err)
+	defer module.Close(ctx)
+	assert.Equal(t, []string{"run_host_api"}, module.HostImports())
+
+	wasmBytes, err := os.ReadFile("fixture/math-service/math-service.wasm")
+	require.NoError(t, err)
+	plain, err := NewWASMCompiledModuleWithHostAPIs(ctx, wasmBytes)
+	require.NoError(t, err)
+	defer plain.Close(ctx)
+	assert.Empty(t, plain.HostImports())
+}
@@ -0,0 +1,239 @@
+package wasm
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// WASMEngine compiles modules into a single wazero runtime shared by every
+// service it hosts. WASI and the okra host module are instantiated in it once,
+// and modules compiled from the same WASM share their compiled code.
+type WASMEngine interface {
+	// Compile compiles a module whose workers run in the engine's runtime
+	Compile(ctx context.Context, wasmBytes []byte, opts ...ModuleOption) (WASMCompiledModule, error)
+
+	// CompileWithHostAPIs compiles a module with host API support whose
+	// workers run in the engine's runtime
+	CompileWithHostAPIs(ctx context.Context, wasmBytes []byte, opts ...ModuleOption) (WASMCompiledModuleWithHostAPIs, error)
+
+	// Close closes the runtime, and with it every module compiled by the
+	// engine and every worker of those modules
+	Close(ctx context.Context) error
+}
+
+// NewWASMEngine creates an engine. If cache is not nil, compiled code is
+// stored in and loaded from it.
+func NewWASMEngine(ctx context.Context, cache CompilationCache) (WASMEngine, error) {
+	return newWASMEngine(ctx, cache)
+}
+
+func newWASMEngine(ctx context.Context, cache CompilationCache) (*wasmEngine, error) {
+	// Modules are closed when their context is done, so a guest that never
+	// returns cannot hold on to its worker
+	config := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if cache != nil {
+		config = config.WithCompilationCache(cache.wazeroCache())
+	}
+	runtime := wazero.NewRuntimeWithConfig(ctx, config)
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+
+	return &wasmEngine{
+		runtime: runtime,
+		cache:   cache,
+		modules: make(map[moduleKey]*sharedModule),
+	}, nil
+}
+
+type wasmEngine struct {
+	runtime wazero.Runtime
+	cache   CompilationCache
+
+	hostModuleMu sync.Mutex
+	hostModule   *hostapi.HostModule // Registered on first use; shared by all modules
+
+	mu      sync.Mutex
+	modules map[moduleKey]*sharedModule
+	closed  bool
+}
+
+// moduleKey identifies compiled code. wazero keys compiled code by the module
+// and whether it has function listeners, so modules are shared on the same terms.
+type moduleKey struct {
+	hash      [sha256.Size]byte
+	listeners bool
+}
+
+// sharedModule is compiled code and the number of compiled modules using it.
+// wazero releases compiled code by module, not by handle, so it is only closed
+// once the last module using it is closed. For the same reason a module is
+// compiled once: callers asking for it while it compiles wait for ready rather
+// than compiling, and later closing, a copy.
+type sharedModule struct {
+	ready    chan struct{} // Closed once compiled or err is set
+	compiled wazero.CompiledModule
+	err      error
+	refs     int
+}
+
+func (e *wasmEngine) Compile(ctx context.Context, wasmBytes []byte, opts ...ModuleOption) (WASMCompiledModule, error) {
+	options := newModuleOptions(opts)
+	compiled, release, err := e.compile(ctx, wasmBytes, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wasmCompiledModule{
+		engine:    e,
+		wasmBytes: wasmBytes,
+		compiled:  compiled,
+		limits:    options.limits,
+		release:   release,
+	}, nil
+}
+
+func (e *wasmEngine) CompileWithHostAPIs(ctx context.Context, wasmBytes []byte, opts ...ModuleOption) (WASMCompiledModuleWithHostAPIs, error) {
+	options := newModuleOptions(opts)
+	compiled, release, err := e.compile(ctx, wasmBytes, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wasmCompiledModuleWithHostAPIs{
+		engine:    e,
+		wasmBytes: wasmBytes,
+		compiled:  compiled,
+		limits:    options.limits,
+		release:   release,
+		hostAPIs:  []string{},
+	}, nil
+}
+
+// compile compiles wasmBytes, or reuses the code of an identical module, and
+// returns a function that releases it
+func (e *wasmEngine) compile(ctx context.Context, wasmBytes []byte, options moduleOptions) (wazero.CompiledModule, func(context.Context) error, error) {
+	if len(wasmBytes) == 0 {
+		return nil, nil, fmt.Errorf("wasm bytes cannot be empty")
+	}
+
+	key := moduleKey{hash: sha256.Sum256(wasmBytes), listeners: options.limits.InstructionBudget > 0}
+
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil, nil, errors.New("wasm engine is closed")
+	}
+	shared, ok := e.modules[key]
+	if !ok {
+		shared = &sharedModule{ready: make(chan struct{})}
+		e.modules[key] = shared
+	}
+	// The reference keeps the entry while it compiles or is waited for
+	shared.refs++
+	e.mu.Unlock()
+
+	// Compile without holding the lock, so other modules compile meanwhile
+	if !ok {
+		shared.compiled, shared.err = e.runtime.CompileModule(options.compileContext(ctx), wasmBytes)
+		if shared.err != nil {
+			shared.err = fmt.Errorf("failed to compile module: %w", shared.err)
+
+			// Let the next caller try again rather than share the failure
+			e.mu.Lock()
+			if e.modules[key] == shared {
+				delete(e.modules, key)
+			}
+			e.mu.Unlock()
+		}
+		close(shared.ready)
+
+		// Keep the cache within its size limit; a failure only means it
+		// stays larger for now
+		if shared.err == nil && e.cache != nil {
+			_ = e.cache.Evict()
+		}
+	} else {
+		select {
+		case <-shared.ready:
+		case <-ctx.Done():
+			e.release(ctx, key, shared)
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	if shared.err != nil {
+		e.release(ctx, key, shared)
+		return nil, nil, shared.err
+	}
+	if err := options.checkMemory(shared.compiled); err != nil {
+		e.release(ctx, key, shared)
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	release := func(ctx context.Context) error {
+		var err error
+		once.Do(func() { err = e.release(ctx, key, shared) })
+		return err
+	}
+	return shared.compiled, release, nil
+}
+
+// release drops one reference to shared, the compiled code behind key,
+// closing it when no module uses it anymore
+func (e *wasmEngine) release(ctx context.Context, key moduleKey, shared *sharedModule) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	shared.refs--
+	if shared.refs > 0 {
+		return nil
+	}
+	// The engine was closed, or compiling failed, if shared is no longer the
+	// module's entry
+	if e.modules[key] != shared {
+		return nil
+	}
+	delete(e.modules, key)
+	return shared.compiled.Close(ctx)
+}
+
+// registerHostModule registers the okra host module with the runtime the first
+// time a worker needs it. A runtime holds a single "okra" module, so every
+// worker of every module shares it and is bound to its own host API set.
+func (e *wasmEngine) registerHostModule(ctx context.Context) (*hostapi.HostModule, error) {
+	e.hostModuleMu.Lock()
+	defer e.hostModuleMu.Unlock()
+
+	if e.hostModule == nil {
+		hostModule, err := hostapi.RegisterHostModule(ctx, e.runtime)
+		if err != nil {
+			return nil, err
+		}
+		e.hostModule = hostModule
+	}
+	return e.hostModule, nil
+}
+
+func (e *wasmEngine) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	e.modules = make(map[moduleKey]*sharedModule)
+	e.mu.Unlock()
+
+	return e.runtime.Close(ctx)
+}
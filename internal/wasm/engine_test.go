@@ -0,0 +1,242 @@
+package wasm
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test modules compiled by one engine run in the engine's runtime
+// 2. Test modules compiled from the same WASM share compiled code, and closing one leaves the other usable
+// 3. Test modules with and without an instruction budget do not share compiled code
+// 4. Test the okra host module is registered once for every module with host APIs
+// 5. Test closing the engine closes its modules and refuses new ones
+// 6. Test closing a standalone module closes its runtime
+// 7. Test a module compiling holds up neither other modules nor, beyond the
+//    wait for its code, callers compiling the same WASM
+
+func TestWASMEngine_SharesRuntime(t *testing.T) {
+	// Test: Every module runs in the engine's runtime
+	ctx := context.Background()
+	engine, err := NewWASMEngine(ctx, nil)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+
+	plain, err := engine.Compile(ctx, limitsModule)
+	require.NoError(t, err)
+	defer plain.Close(ctx)
+	withHostAPIs, err := engine.CompileWithHostAPIs(ctx, hostCallModule)
+	require.NoError(t, err)
+	defer withHostAPIs.Close(ctx)
+
+	runtime := engine.(*wasmEngine).runtime
+	assert.Same(t, runtime, plain.(*wasmCompiledModule).engine.runtime)
+	assert.Same(t, runtime, withHostAPIs.(*wasmCompiledModuleWithHostAPIs).engine.runtime)
+
+	worker, err := plain.Instantiate(ctx)
+	require.NoError(t, err)
+	defer worker.Close(ctx)
+	output, err := worker.Invoke(ctx, "echo", []byte("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hi"), output)
+}
+
+func TestWASMEngine_SharesCompiledCode(t *testing.T) {
+	// Test: Identical modules share compiled code
+	ctx := context.Background()
+	engine, err := NewWASMEngine(ctx, nil)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+
+	first, err := engine.Compile(ctx, limitsModule)
+	require.NoError(t, err)
+	second, err := engine.CompileWithHostAPIs(ctx, limitsModule)
+	require.NoError(t, err)
+	defer second.Close(ctx)
+	assert.Len(t, engine.(*wasmEngine).modules, 1)
+
+	// Test: Closing one module, even twice, leaves the other usable
+	require.NoError(t, first.Close(ctx))
+	require.NoError(t, first.Close(ctx))
+	assert.Len(t, engine.(*wasmEngine).modules, 1)
+
+	worker, err := second.Instantiate(ctx)
+	require.NoError(t, err)
+	defer worker.Close(ctx)
+	output, err := worker.Invoke(ctx, "echo", []byte("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hi"), output)
+
+	// Test: The compiled code is released with the last module using it
+	require.NoError(t, second.Close(ctx))
+	assert.Empty(t, engine.(*wasmEngine).modules)
+}
+
+func TestWASMEngine_BudgetCompiledSeparately(t *testing.T) {
+	// Test: A budget needs code with call listeners, so it is not shared
+	ctx := context.Background()
+	engine, err := NewWASMEngine(ctx, nil)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+
+	unlimited, err := engine.Compile(ctx, limitsModule)
+	require.NoError(t, err)
+	defer unlimited.Close(ctx)
+	budgeted, err := engine.Compile(ctx, limitsModule, WithLimits(Limits{InstructionBudget: 1000}))
+	require.NoError(t, err)
+	defer budgeted.Close(ctx)
+	assert.Len(t, engine.(*wasmEngine).modules, 2)
+
+	worker, err := budgeted.Instantiate(ctx)
+	require.NoError(t, err)
+	defer worker.Close(ctx)
+	_, err = worker.Invoke(ctx, "calls", nil)
+	assert.ErrorIs(t, err, ErrResourceExhausted)
+}
+
+func TestWASMEngine_HostModuleRegisteredOnce(t *testing.T) {
+	// Test: Workers of different modules share the engine's host module
+	ctx := context.Background()
+	engine, err := NewWASMEngine(ctx, nil)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+
+	registry := &mockHostAPIRegistry{
+		createSetFunc: func(ctx context.Context, apis []string, config hostapi.HostAPIConfig) (hostapi.HostAPISet, error) {
+			return &mockHostAPISet{}, nil
+		},
+	}
+
+	var hostModules []*hostapi.HostModule
+	for _, wasmBytes := range [][]byte{hostCallModule, limitsModule} {
+		module, err := engine.CompileWithHostAPIs(ctx, wasmBytes)
+		require.NoError(t, err)
+		defer module.Close(ctx)
+		module.WithHostAPIs([]string{"state"}).WithHostAPIRegistry(registry)
+
+		worker, err := module.Instantiate(ctx)
+		require.NoError(t, err)
+		defer worker.Close(ctx)
+		hostModules = append(hostModules, worker.(*wasmWorkerWithHostAPIs).hostModule)
+	}
+
+	require.NotNil(t, hostModules[0])
+	assert.Same(t, hostModules[0], hostModules[1])
+}
+
+func TestWASMEngine_Close(t *testing.T) {
+	// Test: Closing the engine closes its modules
+	ctx := context.Background()
+	engine, err := NewWASMEngine(ctx, nil)
+	require.NoError(t, err)
+
+	module, err := engine.Compile(ctx, limitsModule)
+	require.NoError(t, err)
+	require.NoError(t, engine.Close(ctx))
+
+	_, err = module.Instantiate(ctx)
+	assert.Error(t, err)
+	assert.NoError(t, module.Close(ctx))
+
+	// Test: A closed engine compiles nothing
+	_, err = engine.Compile(ctx, limitsModule)
+	assert.ErrorContains(t, err, "closed")
+	assert.NoError(t, engine.Close(ctx))
+}
+
+func TestNewWASMCompiledModule_OwnsRuntime(t *testing.T) {
+	// Test: A module compiled outside an engine closes its own runtime
+	ctx := context.Background()
+	module, err := NewWASMCompiledModule(ctx, limitsModule)
+	require.NoError(t, err)
+	require.NoError(t, module.Close(ctx))
+
+	_, err = module.Instantiate(ctx)
+	assert.Error(t, err)
+}
+
+func TestWASMEngine_CompilesOnce(t *testing.T) {
+	// Test: Concurrent compiles of the same WASM share one compiled module
+	ctx := context.Background()
+	engine, err := NewWASMEngine(ctx, nil)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+
+	modules := make([]WASMCompiledModule, 8)
+	var wg sync.WaitGroup
+	for i := range modules {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			module, err := engine.Compile(ctx, limitsModule)
+			assert.NoError(t, err)
+			modules[i] = module
+		}(i)
+	}
+	wg.Wait()
+
+	require.Len(t, engine.(*wasmEngine).modules, 1)
+	for _, module := range modules {
+		require.NotNil(t, module)
+		assert.Same(t, modules[0].(*wasmCompiledModule).compiled, module.(*wasmCompiledModule).compiled)
+	}
+	for _, module := range modules {
+		require.NoError(t, module.Close(ctx))
+	}
+	assert.Empty(t, engine.(*wasmEngine).modules)
+}
+
+func TestWASMEngine_CompileWaitsOnlyForSameModule(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewWASMEngine(ctx, nil)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+	e := engine.(*wasmEngine)
+
+	// Pretend limitsModule is being compiled
+	key := moduleKey{hash: sha256.Sum256(limitsModule)}
+	pending := &sharedModule{ready: make(chan struct{}), refs: 1}
+	e.mu.Lock()
+	e.modules[key] = pending
+	e.mu.Unlock()
+
+	waiting := make(chan WASMCompiledModule, 1)
+	go func() {
+		module, err := engine.Compile(ctx, limitsModule)
+		assert.NoError(t, err)
+		waiting <- module
+	}()
+
+	// Test: Other modules compile meanwhile
+	other, err := engine.Compile(ctx, hostCallModule)
+	require.NoError(t, err)
+	require.NoError(t, other.Close(ctx))
+
+	// Test: The same module waits for the compile in progress and shares its code
+	select {
+	case <-waiting:
+		t.Fatal("compile did not wait for the module being compiled")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pending.compiled, err = e.runtime.CompileModule(ctx, limitsModule)
+	require.NoError(t, err)
+	close(pending.ready)
+
+	module := <-waiting
+	require.NotNil(t, module)
+	assert.Same(t, pending.compiled, module.(*wasmCompiledModule).compiled)
+
+	// Test: The code is released once both references are dropped
+	require.NoError(t, e.release(ctx, key, pending))
+	assert.Len(t, e.modules, 1)
+	require.NoError(t, module.Close(ctx))
+	assert.Empty(t, e.modules)
+}
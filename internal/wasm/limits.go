@@ -0,0 +1,139 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+var (
+	// ErrDeadlineExceeded is returned when an invocation runs past
+	// MaxExecutionTime or the deadline of its context
+	ErrDeadlineExceeded = errors.New("deadline exceeded")
+
+	// ErrResourceExhausted is returned when an invocation needs more memory
+	// than MaxMemoryPages or more calls than InstructionBudget
+	ErrResourceExhausted = errors.New("resource exhausted")
+)
+
+// memoryPageSize is the size of a WASM linear memory page
+const memoryPageSize = 65536
+
+// Limits caps the resources each worker of a compiled module may use.
+// Zero fields are unlimited.
+type Limits struct {
+	MaxMemoryPages    uint32        // Linear memory per worker, in 64 KiB pages
+	MaxExecutionTime  time.Duration // Wall-clock time per invocation
+	InstructionBudget uint64        // Guest function calls per invocation
+}
+
+// checkMemory rejects a compiled module whose memory starts out larger than
+// MaxMemoryPages
+func (o moduleOptions) checkMemory(compiled wazero.CompiledModule) error {
+	if o.limits.MaxMemoryPages == 0 {
+		return nil
+	}
+	for _, memory := range compiled.ExportedMemories() {
+		if memory.Min() > o.limits.MaxMemoryPages {
+			return fmt.Errorf("%w: module needs %d memory pages, limit is %d", ErrResourceExhausted, memory.Min(), o.limits.MaxMemoryPages)
+		}
+	}
+	return nil
+}
+
+// memoryLimiter allocates the linear memory of one worker. It refuses to grow
+// the memory past the limit and remembers that it did, so the trap the guest
+// raises when it runs out can be reported as ErrResourceExhausted.
+type memoryLimiter struct {
+	pages     uint32
+	buf       []byte
+	exhausted bool
+}
+
+// newMemoryLimiter returns a limiter for a worker, or nil if memory is unlimited
+func newMemoryLimiter(limits Limits) *memoryLimiter {
+	if limits.MaxMemoryPages == 0 {
+		return nil
+	}
+	return &memoryLimiter{pages: limits.MaxMemoryPages}
+}
+
+// instantiateContext returns the context a worker's module is instantiated with
+func (m *memoryLimiter) instantiateContext(ctx context.Context) context.Context {
+	if m == nil {
+		return ctx
+	}
+	return experimental.WithMemoryAllocator(ctx, m)
+}
+
+func (m *memoryLimiter) Allocate(cap, max uint64) experimental.LinearMemory {
+	return m
+}
+
+func (m *memoryLimiter) Reallocate(size uint64) []byte {
+	if size > uint64(m.pages)*memoryPageSize {
+		m.exhausted = true
+		if m.buf != nil {
+			return nil
+		}
+		// The module's minimum is over the limit; Instantiate reports it
+	}
+	if m.buf == nil {
+		m.buf = make([]byte, size)
+		return m.buf
+	}
+	m.buf = append(m.buf, make([]byte, size-uint64(len(m.buf)))...)
+	return m.buf
+}
+
+func (m *memoryLimiter) Free() {
+	m.buf = nil
+}
+
+// Exhausted reports whether the worker tried to grow its memory past the limit
+func (m *memoryLimiter) Exhausted() bool {
+	return m != nil && m.exhausted
+}
+
+// callBudget is the number of guest function calls an invocation has left
+type callBudget struct {
+	remaining uint64
+	exceeded  bool
+	cancel    context.CancelFunc
+}
+
+type callBudgetKey struct{}
+
+// spend counts one call, stopping the invocation once the budget is gone
+func (b *callBudget) spend() {
+	if b.remaining == 0 {
+		b.exceeded = true
+		b.cancel()
+		return
+	}
+	b.remaining--
+}
+
+// budgetListener counts every guest function call against the budget of the
+// invocation it belongs to. It only sees calls, so a loop that calls nothing
+// is stopped by MaxExecutionTime rather than the budget.
+type budgetListener struct{}
+
+func (budgetListener) NewFunctionListener(api.FunctionDefinition) experimental.FunctionListener {
+	return budgetListener{}
+}
+
+func (budgetListener) Before(ctx context.Context, _ api.Module, _ api.FunctionDefinition, _ []uint64, _ experimental.StackIterator) {
+	if budget, ok := ctx.Value(callBudgetKey{}).(*callBudget); ok {
+		budget.spend()
+	}
+}
+
+func (budgetListener) After(context.Context, api.Module, api.FunctionDefinition, []uint64) {}
+
+func (budgetListener) Abort(context.Context, api.Module, api.FunctionDefinition, error) {}
@@ -0,0 +1,192 @@
+package wasm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Plan:
+// 1. Test MaxExecutionTime stops a guest stuck in a loop with ErrDeadlineExceeded
+// 2. Test the context deadline stops a guest even without MaxExecutionTime
+// 3. Test MaxMemoryPages stops a guest that keeps growing its memory with ErrResourceExhausted
+// 4. Test InstructionBudget stops a guest that keeps calling functions with ErrResourceExhausted
+// 5. Test a module whose memory starts out over MaxMemoryPages is rejected at compile time
+// 6. Test limits apply to modules compiled with host API support
+// 7. Test the pool discards a worker that hit a limit and replaces it
+
+// limitsModule is a service module whose handle_request behaves according to
+// the first byte of the method: "loop" spins forever, "calls" calls an empty
+// function forever, "grow" grows memory until it fails and then traps, and
+// anything else echoes the input. allocate always returns address 0.
+var limitsModule = func() []byte {
+	section := func(id byte, contents ...byte) []byte {
+		return append([]byte{id, byte(len(contents))}, contents...)
+	}
+	// dispatch runs body if the first byte of the method is c
+	dispatch := func(c byte, body ...byte) []byte {
+		block := []byte{
+			0x02, 0x40, // block
+			0x20, 0x00, 0x2d, 0x00, 0x00, // i32.load8_u (local 0)
+			0x41, c | 0x80, 0x00, // i32.const c
+			0x47, 0x0d, 0x00, // br_if 0 on mismatch
+		}
+		block = append(block, body...)
+		return append(block, 0x0b)
+	}
+
+	handleRequest := []byte{0x00} // No locals
+	handleRequest = append(handleRequest, dispatch('l',
+		0x03, 0x40, 0x0c, 0x00, 0x0b, // loop br 0 end
+	)...)
+	handleRequest = append(handleRequest, dispatch('c',
+		0x03, 0x40, 0x10, 0x03, 0x0c, 0x00, 0x0b, // loop call 3 br 0 end
+	)...)
+	handleRequest = append(handleRequest, dispatch('g',
+		0x03, 0x40, // loop
+		0x41, 0x01, 0x40, 0x00, // memory.grow 1
+		0x41, 0x7f, 0x47, 0x0d, 0x00, // br_if 0 unless it returned -1
+		0x0b, 0x00, // end; unreachable
+	)...)
+	handleRequest = append(handleRequest, 0x20, 0x03, 0xad, 0x0b) // return 0<<32 | input length
+
+	code := []byte{0x04}
+	code = append(code, 0x04, 0x00, 0x41, 0x00, 0x0b) // allocate: return 0
+	code = append(code, 0x02, 0x00, 0x0b)             // deallocate: nothing
+	code = append(code, byte(len(handleRequest)))
+	code = append(code, handleRequest...)
+	code = append(code, 0x02, 0x00, 0x0b) // empty function
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, section(0x01,
+		0x04,                         // Types
+		0x60, 0x01, 0x7f, 0x01, 0x7f, // (i32) -> i32
+		0x60, 0x01, 0x7f, 0x00, // (i32) -> ()
+		0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7e, // (i32, i32, i32, i32) -> i64
+		0x60, 0x00, 0x00, // () -> ()
+	)...)
+	module = append(module, section(0x03, 0x04, 0x00, 0x01, 0x02, 0x03)...) // Functions
+	module = append(module, section(0x05, 0x01, 0x00, 0x01)...)             // One memory of one page
+	module = append(module, section(0x07,
+		0x04, // Exports
+		0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+		0x08, 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x00,
+		0x0a, 'd', 'e', 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x01,
+		0x0e, 'h', 'a', 'n', 'd', 'l', 'e', '_', 'r', 'e', 'q', 'u', 'e', 's', 't', 0x00, 0x02,
+	)...)
+	return append(module, section(0x0a, code...)...)
+}()
+
+func newLimitedWorker(t *testing.T, limits Limits) WASMWorker {
+	ctx := context.Background()
+	module, err := NewWASMCompiledModule(ctx, limitsModule, WithLimits(limits))
+	require.NoError(t, err)
+	t.Cleanup(func() { module.Close(ctx) })
+
+	worker, err := module.Instantiate(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { worker.Close(ctx) })
+	return worker
+}
+
+func TestLimits_MaxExecutionTime(t *testing.T) {
+	// Test: A guest that never returns is stopped once its time is up
+	worker := newLimitedWorker(t, Limits{MaxExecutionTime: 50 * time.Millisecond})
+
+	output, err := worker.Invoke(context.Background(), "echo", []byte("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hi"), output)
+
+	start := time.Now()
+	_, err = worker.Invoke(context.Background(), "loop", nil)
+	assert.ErrorIs(t, err, ErrDeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestLimits_ContextDeadline(t *testing.T) {
+	// Test: The caller's deadline stops the guest just like MaxExecutionTime
+	worker := newLimitedWorker(t, Limits{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := worker.Invoke(ctx, "loop", nil)
+	assert.ErrorIs(t, err, ErrDeadlineExceeded)
+}
+
+func TestLimits_MaxMemoryPages(t *testing.T) {
+	// Test: Growing memory past the cap fails the invocation as resource exhaustion
+	worker := newLimitedWorker(t, Limits{MaxMemoryPages: 4})
+
+	_, err := worker.Invoke(context.Background(), "grow", nil)
+	assert.ErrorIs(t, err, ErrResourceExhausted)
+	assert.ErrorIs(t, err, ErrTrapped)
+	assert.Contains(t, err.Error(), "memory limit of 4 pages")
+}
+
+func TestLimits_InstructionBudget(t *testing.T) {
+	// Test: A guest that keeps calling functions runs out of budget
+	worker := newLimitedWorker(t, Limits{InstructionBudget: 1000})
+
+	_, err := worker.Invoke(context.Background(), "echo", []byte("hi"))
+	require.NoError(t, err)
+
+	_, err = worker.Invoke(context.Background(), "calls", nil)
+	assert.ErrorIs(t, err, ErrResourceExhausted)
+	assert.Contains(t, err.Error(), "instruction budget of 1000 calls")
+}
+
+func TestLimits_InitialMemoryOverLimit(t *testing.T) {
+	// Test: A module that cannot start within the memory cap is rejected up front
+	_, err := NewWASMCompiledModule(context.Background(), limitsModule, WithLimits(Limits{MaxMemoryPages: 1}))
+	require.NoError(t, err)
+
+	module := append([]byte{}, limitsModule...)
+	for i := 0; i+4 < len(module); i++ {
+		// Raise the memory minimum from one page to two
+		if module[i] == 0x05 && module[i+1] == 0x03 && module[i+2] == 0x01 && module[i+3] == 0x00 {
+			module[i+4] = 0x02
+			break
+		}
+	}
+	_, err = NewWASMCompiledModule(context.Background(), module, WithLimits(Limits{MaxMemoryPages: 1}))
+	assert.ErrorIs(t, err, ErrResourceExhausted)
+}
+
+func TestLimits_WithHostAPIs(t *testing.T) {
+	// Test: Modules compiled with host API support enforce the same limits
+	ctx := context.Background()
+	module, err := NewWASMCompiledModuleWithHostAPIs(ctx, limitsModule, WithLimits(Limits{MaxExecutionTime: 50 * time.Millisecond}))
+	require.NoError(t, err)
+	defer module.Close(ctx)
+
+	worker, err := module.Instantiate(ctx)
+	require.NoError(t, err)
+	defer worker.Close(ctx)
+
+	_, err = worker.Invoke(ctx, "loop", nil)
+	assert.ErrorIs(t, err, ErrDeadlineExceeded)
+}
+
+func TestLimits_PoolDiscardsWorker(t *testing.T) {
+	// Test: A worker stopped at a limit is closed, and the next invocation
+	// gets a fresh one
+	ctx := context.Background()
+	module, err := NewWASMCompiledModule(ctx, limitsModule, WithLimits(Limits{MaxExecutionTime: 50 * time.Millisecond}))
+	require.NoError(t, err)
+	defer module.Close(ctx)
+
+	pool, err := NewWASMWorkerPool(ctx, WASMWorkerPoolConfig{MinWorkers: 1, MaxWorkers: 1, Module: module})
+	require.NoError(t, err)
+	defer pool.Shutdown(ctx)
+
+	_, err = pool.Invoke(ctx, "loop", nil)
+	require.ErrorIs(t, err, ErrDeadlineExceeded)
+	assert.Equal(t, int32(0), pool.(*wasmWorkerPool).workerCount)
+
+	output, err := pool.Invoke(ctx, "echo", []byte("ok"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ok"), output)
+}
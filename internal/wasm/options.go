@@ -0,0 +1,39 @@
+package wasm
+
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+// ModuleOption configures a compiled module
+type ModuleOption func(*moduleOptions)
+
+type moduleOptions struct {
+	limits Limits
+}
+
+// WithLimits caps the resources each worker of the module may use
+func WithLimits(limits Limits) ModuleOption {
+	return func(o *moduleOptions) {
+		o.limits = limits
+	}
+}
+
+func newModuleOptions(opts []ModuleOption) moduleOptions {
+	var options moduleOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// compileContext returns the context a module is compiled with. Function
+// listeners are attached at compile time, so the budget listener is only
+// installed when there is a budget to count against.
+func (o moduleOptions) compileContext(ctx context.Context) context.Context {
+	if o.limits.InstructionBudget == 0 {
+		return ctx
+	}
+	return experimental.WithFunctionListenerFactory(ctx, budgetListener{})
+}
@@ -3,8 +3,10 @@ package wasm
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // WASMWorkerPool manages a pool of pre-initialized WASM workers created from a shared compiled module.
@@ -16,6 +18,9 @@ type WASMWorkerPool interface {
 	// Returns the number of currently active (in-use) workers.
 	ActiveWorkers() uint
 
+	// Stats returns a snapshot of the pool's workers and what happened to them.
+	Stats() WASMWorkerPoolStats
+
 	// Gracefully shuts down the pool, cleaning up all idle workers.
 	Shutdown(ctx context.Context) error
 }
@@ -25,6 +30,32 @@ type WASMWorkerPoolConfig struct {
 	MinWorkers int
 	MaxWorkers int
 	Module     WASMCompiledModule
+
+	// IdleTimeout closes workers left idle this long, down to MinWorkers.
+	// Zero keeps idle workers until shutdown.
+	IdleTimeout time.Duration
+
+	// MaxInvocations replaces a worker once it has served this many
+	// invocations, bounding how fragmented the guest's heap can get. Zero
+	// reuses workers indefinitely.
+	MaxInvocations uint64
+
+	// Snapshot initializes one template worker and starts every worker from
+	// a copy of its memory and globals instead of calling _initialize. Module
+	// must implement WASMSnapshotter.
+	Snapshot bool
+}
+
+// WASMWorkerPoolStats is a snapshot of a worker pool. Counters cover the
+// lifetime of the pool.
+type WASMWorkerPoolStats struct {
+	IdleWorkers   uint          // Workers waiting for an invocation
+	ActiveWorkers uint          // Workers running an invocation
+	Created       uint64        // Workers instantiated
+	Recycled      uint64        // Workers closed after MaxInvocations invocations
+	Reaped        uint64        // Workers closed after IdleTimeout
+	Discarded     uint64        // Workers closed after a trap or a limit
+	WaitTime      time.Duration // Time invocations spent waiting for a worker
 }
 
 // NewWASMWorkerPool creates a new worker pool with the given configuration.
@@ -41,42 +72,91 @@ func NewWASMWorkerPool(ctx context.Context, config WASMWorkerPoolConfig) (WASMWo
 	if config.Module == nil {
 		return nil, errors.New("module cannot be nil")
 	}
+	if config.IdleTimeout < 0 {
+		return nil, errors.New("idle timeout cannot be negative")
+	}
+
+	// Workers are created from the snapshot when there is one
+	module := config.Module
+	var snapshot WASMCompiledModule
+	if config.Snapshot {
+		snapshotter, ok := config.Module.(WASMSnapshotter)
+		if !ok {
+			return nil, errors.New("module does not support snapshots")
+		}
+		var err error
+		snapshot, err = snapshotter.Snapshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot module: %w", err)
+		}
+		module = snapshot
+	}
 
 	pool := &wasmWorkerPool{
-		minWorkers:    config.MinWorkers,
-		maxWorkers:    config.MaxWorkers,
-		module:        config.Module,
-		idleWorkers:   make(chan WASMWorker, config.MaxWorkers),
-		workerCount:   0,
-		activeWorkers: 0,
-		shutdown:      make(chan struct{}),
+		minWorkers:     config.MinWorkers,
+		maxWorkers:     config.MaxWorkers,
+		idleTimeout:    config.IdleTimeout,
+		maxInvocations: config.MaxInvocations,
+		module:         module,
+		snapshot:       snapshot,
+		slots:          make(chan struct{}, config.MaxWorkers),
+		idleWorkers:    make([]*pooledWorker, 0, config.MaxWorkers),
+		workerCount:    0,
+		activeWorkers:  0,
+		shutdown:       make(chan struct{}),
 	}
 
 	// Pre-warm minimum workers
 	for range config.MinWorkers {
-		worker, err := config.Module.Instantiate(ctx)
+		worker, err := module.Instantiate(ctx)
 		if err != nil {
 			// Clean up any workers created so far
 			pool.cleanupWorkers(ctx)
+			if snapshot != nil {
+				snapshot.Close(ctx)
+			}
 			return nil, err
 		}
-		pool.idleWorkers <- worker
+		pool.idleWorkers = append(pool.idleWorkers, &pooledWorker{WASMWorker: worker, idleSince: time.Now()})
 		pool.workerCount++
+		pool.created++
+	}
+
+	if pool.idleTimeout > 0 {
+		go pool.reapIdleWorkers()
 	}
 
 	return pool, nil
 }
 
+// pooledWorker is a worker and what the pool knows about its use
+type pooledWorker struct {
+	WASMWorker
+	invocations uint64
+	idleSince   time.Time
+}
+
 type wasmWorkerPool struct {
-	minWorkers    int
-	maxWorkers    int
-	module        WASMCompiledModule
-	idleWorkers   chan WASMWorker
-	workerCount   int32
-	activeWorkers int32
-	mu            sync.RWMutex
-	shutdown      chan struct{}
-	shutdownOnce  sync.Once
+	minWorkers     int
+	maxWorkers     int
+	idleTimeout    time.Duration
+	maxInvocations uint64
+	module         WASMCompiledModule // Creates workers; the snapshot if there is one
+	snapshot       WASMCompiledModule // Closed with the pool
+	slots          chan struct{}      // Held by each invocation, so at most MaxWorkers run at once
+	idleWorkers    []*pooledWorker    // Most recently used last
+	workerCount    int32
+	activeWorkers  int32
+	mu             sync.Mutex // Guards idleWorkers and workerCount
+	shutdown       chan struct{}
+	shutdownOnce   sync.Once
+
+	// Counters reported by Stats
+	created   uint64
+	recycled  uint64
+	reaped    uint64
+	discarded uint64
+	waitTime  int64
 }
 
 func (p *wasmWorkerPool) Invoke(ctx context.Context, method string, input []byte) ([]byte, error) {
@@ -91,82 +171,192 @@ func (p *wasmWorkerPool) Invoke(ctx context.Context, method string, input []byte
 		return nil, err
 	}
 
-	defer p.releaseWorker(worker)
+	output, err := worker.Invoke(ctx, method, input)
+	worker.invocations++
+	switch {
+	case err != nil && poisoned(err):
+		p.discardWorker(worker, &p.discarded)
+	case p.maxInvocations > 0 && worker.invocations >= p.maxInvocations:
+		p.discardWorker(worker, &p.recycled)
+	default:
+		p.releaseWorker(worker)
+	}
 
-	return worker.Invoke(ctx, method, input)
+	return output, err
+}
+
+// poisoned reports whether an invocation error leaves its worker unusable.
+// A guest that trapped may have been interrupted halfway through changing its
+// own state, as may one stopped at a limit, and the runtime closes a module
+// whose context is done mid-call.
+func poisoned(err error) bool {
+	return errors.Is(err, ErrTrapped) ||
+		errors.Is(err, ErrDeadlineExceeded) ||
+		errors.Is(err, ErrResourceExhausted) ||
+		errors.Is(err, context.Canceled)
 }
 
 func (p *wasmWorkerPool) ActiveWorkers() uint {
 	return uint(atomic.LoadInt32(&p.activeWorkers))
 }
 
+func (p *wasmWorkerPool) Stats() WASMWorkerPoolStats {
+	p.mu.Lock()
+	idle := len(p.idleWorkers)
+	p.mu.Unlock()
+
+	return WASMWorkerPoolStats{
+		IdleWorkers:   uint(idle),
+		ActiveWorkers: p.ActiveWorkers(),
+		Created:       atomic.LoadUint64(&p.created),
+		Recycled:      atomic.LoadUint64(&p.recycled),
+		Reaped:        atomic.LoadUint64(&p.reaped),
+		Discarded:     atomic.LoadUint64(&p.discarded),
+		WaitTime:      time.Duration(atomic.LoadInt64(&p.waitTime)),
+	}
+}
+
 func (p *wasmWorkerPool) Shutdown(ctx context.Context) error {
 	var shutdownErr error
 	p.shutdownOnce.Do(func() {
 		close(p.shutdown)
 		shutdownErr = p.cleanupWorkers(ctx)
+		if p.snapshot != nil {
+			if err := p.snapshot.Close(ctx); err != nil && shutdownErr == nil {
+				shutdownErr = err
+			}
+		}
 	})
 	return shutdownErr
 }
 
-func (p *wasmWorkerPool) acquireWorker(ctx context.Context) (WASMWorker, error) {
+func (p *wasmWorkerPool) acquireWorker(ctx context.Context) (*pooledWorker, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Wait for a slot; once one is held there is an idle worker or room for
+	// a new one
+	start := time.Now()
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-p.shutdown:
 		return nil, errors.New("worker pool is shut down")
-	case worker := <-p.idleWorkers:
+	case p.slots <- struct{}{}:
+	}
+	atomic.AddInt64(&p.waitTime, int64(time.Since(start)))
+
+	// Prefer the most recently used worker, so the rest stay idle long
+	// enough to be reaped
+	p.mu.Lock()
+	if n := len(p.idleWorkers); n > 0 {
+		worker := p.idleWorkers[n-1]
+		p.idleWorkers = p.idleWorkers[:n-1]
+		p.mu.Unlock()
 		atomic.AddInt32(&p.activeWorkers, 1)
 		return worker, nil
+	}
+	p.workerCount++
+	p.mu.Unlock()
+
+	// No idle workers available, create a new one
+	worker, err := p.module.Instantiate(ctx)
+	if err != nil {
+		p.mu.Lock()
+		p.workerCount--
+		p.mu.Unlock()
+		<-p.slots
+		return nil, err
+	}
+	atomic.AddUint64(&p.created, 1)
+	atomic.AddInt32(&p.activeWorkers, 1)
+	return &pooledWorker{WASMWorker: worker}, nil
+}
+
+func (p *wasmWorkerPool) releaseWorker(worker *pooledWorker) {
+	atomic.AddInt32(&p.activeWorkers, -1)
+
+	p.mu.Lock()
+	select {
+	case <-p.shutdown:
+		// The pool is shutting down, close the worker
+		p.workerCount--
+		p.mu.Unlock()
+		worker.Close(context.Background())
 	default:
-		// No idle workers available, try to create a new one
-		if atomic.LoadInt32(&p.workerCount) < int32(p.maxWorkers) {
-			worker, err := p.module.Instantiate(ctx)
-			if err != nil {
-				return nil, err
-			}
-			atomic.AddInt32(&p.workerCount, 1)
-			atomic.AddInt32(&p.activeWorkers, 1)
-			return worker, nil
-		}
+		// Worker returned to pool
+		worker.idleSince = time.Now()
+		p.idleWorkers = append(p.idleWorkers, worker)
+		p.mu.Unlock()
+	}
+
+	<-p.slots
+}
+
+// discardWorker closes a worker instead of returning it to the pool and
+// counts it in counter; the next acquire creates a replacement if one is needed
+func (p *wasmWorkerPool) discardWorker(worker *pooledWorker, counter *uint64) {
+	atomic.AddInt32(&p.activeWorkers, -1)
+	worker.Close(context.Background())
+
+	p.mu.Lock()
+	p.workerCount--
+	p.mu.Unlock()
+	atomic.AddUint64(counter, 1)
+
+	<-p.slots
+}
+
+// reapIdleWorkers closes expired idle workers until the pool shuts down
+func (p *wasmWorkerPool) reapIdleWorkers() {
+	ticker := time.NewTicker(max(p.idleTimeout/2, time.Millisecond))
+	defer ticker.Stop()
 
-		// Wait for a worker to become available
+	for {
 		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
 		case <-p.shutdown:
-			return nil, errors.New("worker pool is shut down")
-		case worker := <-p.idleWorkers:
-			atomic.AddInt32(&p.activeWorkers, 1)
-			return worker, nil
+			return
+		case now := <-ticker.C:
+			p.reap(now)
 		}
 	}
 }
 
-func (p *wasmWorkerPool) releaseWorker(worker WASMWorker) {
-	atomic.AddInt32(&p.activeWorkers, -1)
-	select {
-	case p.idleWorkers <- worker:
-		// Worker returned to pool
-	default:
-		// Pool is full or shutting down, close the worker
+// reap closes the workers that have been idle for IdleTimeout, least recently
+// used first, as long as MinWorkers remain
+func (p *wasmWorkerPool) reap(now time.Time) {
+	p.mu.Lock()
+	expired := 0
+	for expired < len(p.idleWorkers) &&
+		int(p.workerCount) > p.minWorkers &&
+		now.Sub(p.idleWorkers[expired].idleSince) >= p.idleTimeout {
+		expired++
+		p.workerCount--
+	}
+	reaped := append([]*pooledWorker(nil), p.idleWorkers[:expired]...)
+	p.idleWorkers = append(p.idleWorkers[:0], p.idleWorkers[expired:]...)
+	p.mu.Unlock()
+
+	for _, worker := range reaped {
 		worker.Close(context.Background())
-		atomic.AddInt32(&p.workerCount, -1)
 	}
+	atomic.AddUint64(&p.reaped, uint64(len(reaped)))
 }
 
 func (p *wasmWorkerPool) cleanupWorkers(ctx context.Context) error {
-	var lastErr error
+	p.mu.Lock()
+	idle := p.idleWorkers
+	p.idleWorkers = nil
+	p.workerCount -= int32(len(idle))
+	p.mu.Unlock()
 
 	// Close all idle workers
-	for {
-		select {
-		case worker := <-p.idleWorkers:
-			if err := worker.Close(ctx); err != nil {
-				lastErr = err
-			}
-		default:
-			return lastErr
+	var lastErr error
+	for _, worker := range idle {
+		if err := worker.Close(ctx); err != nil {
+			lastErr = err
 		}
 	}
+	return lastErr
 }
@@ -3,11 +3,19 @@ package wasm
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/go-openapi/spec"
+	"github.com/okra-platform/okra/internal/hostapi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
 )
 
 // TestWASMWorkerPoolWithRealModule tests the pool with a real WASM module
@@ -107,3 +115,218 @@ func TestWASMWorkerPoolWithRealModule(t *testing.T) {
 		}
 	}
 }
+
+// hostCallModule forwards the input of handle_request to okra.run_host_api and
+// returns the host's response. allocate bumps a heap pointer and deallocate
+// resets it, which is enough for one invocation at a time per instance.
+var hostCallModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32, i32) -> (i32, i32), (i32) -> i32, (i32) -> () and (i32, i32, i32, i32) -> i64
+	0x01, 0x19, 0x04,
+	0x60, 0x02, 0x7f, 0x7f, 0x02, 0x7f, 0x7f,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x01, 0x7f, 0x00,
+	0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7e,
+	// Import okra.run_host_api
+	0x02, 0x15, 0x01,
+	0x04, 'o', 'k', 'r', 'a',
+	0x0c, 'r', 'u', 'n', '_', 'h', 'o', 's', 't', '_', 'a', 'p', 'i',
+	0x00, 0x00,
+	// Functions, memory and the heap pointer global (starts at 1024)
+	0x03, 0x04, 0x03, 0x01, 0x02, 0x03,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b,
+	// Export memory, allocate, deallocate and handle_request
+	0x07, 0x33, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x08, 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x01,
+	0x0a, 'd', 'e', 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x02,
+	0x0e, 'h', 'a', 'n', 'd', 'l', 'e', '_', 'r', 'e', 'q', 'u', 'e', 's', 't', 0x00, 0x03,
+	0x0a, 0x2a, 0x03,
+	// allocate: ptr = heap; heap += size; return ptr
+	0x0b, 0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b,
+	// deallocate: heap = 1024
+	0x07, 0x00, 0x41, 0x80, 0x08, 0x24, 0x00, 0x0b,
+	// handle_request: (ptr, len) = run_host_api(input, inputLen); return ptr << 32 | len
+	0x14, 0x01, 0x01, 0x7f,
+	0x20, 0x02, 0x20, 0x03, 0x10, 0x00, 0x21, 0x04,
+	0xad, 0x42, 0x20, 0x86, 0x20, 0x04, 0xad, 0x84, 0x0b,
+}
+
+// packedHostCallModule is hostCallModule calling okra.run_host_api_packed,
+// which returns the response as a single i64 the way generated TinyGo
+// clients import it
+var packedHostCallModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32, i32) -> i64, (i32) -> i32, (i32) -> () and (i32, i32, i32, i32) -> i64
+	0x01, 0x18, 0x04,
+	0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x01, 0x7f, 0x00,
+	0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7e,
+	// Import okra.run_host_api_packed
+	0x02, 0x1c, 0x01,
+	0x04, 'o', 'k', 'r', 'a',
+	0x13, 'r', 'u', 'n', '_', 'h', 'o', 's', 't', '_', 'a', 'p', 'i', '_', 'p', 'a', 'c', 'k', 'e', 'd',
+	0x00, 0x00,
+	// Functions, memory and the heap pointer global (starts at 1024)
+	0x03, 0x04, 0x03, 0x01, 0x02, 0x03,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b,
+	// Export memory, allocate, deallocate and handle_request
+	0x07, 0x33, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x08, 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x01,
+	0x0a, 'd', 'e', 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x02,
+	0x0e, 'h', 'a', 'n', 'd', 'l', 'e', '_', 'r', 'e', 'q', 'u', 'e', 's', 't', 0x00, 0x03,
+	0x0a, 0x1e, 0x03,
+	// allocate: ptr = heap; heap += size; return ptr
+	0x0b, 0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b,
+	// deallocate: heap = 1024
+	0x07, 0x00, 0x41, 0x80, 0x08, 0x24, 0x00, 0x0b,
+	// handle_request: return run_host_api_packed(input, inputLen)
+	0x08, 0x00, 0x20, 0x02, 0x20, 0x03, 0x10, 0x00, 0x0b,
+}
+
+// instanceAPIFactory creates numbered instances of a test host API. Each
+// instance fails the test if it serves two calls at once, which would mean
+// two workers share one host API set.
+type instanceAPIFactory struct {
+	t         *testing.T
+	instances atomic.Int32
+}
+
+func (f *instanceAPIFactory) Name() string    { return "test.instance" }
+func (f *instanceAPIFactory) Version() string { return "v1.0.0" }
+func (f *instanceAPIFactory) Methods() []hostapi.MethodMetadata {
+	return []hostapi.MethodMetadata{{Name: "whoami", Returns: &spec.Schema{}}}
+}
+
+func (f *instanceAPIFactory) Create(ctx context.Context, config hostapi.HostAPIConfig) (hostapi.HostAPI, error) {
+	return &instanceAPI{t: f.t, id: int(f.instances.Add(1))}, nil
+}
+
+type instanceAPI struct {
+	t        *testing.T
+	id       int
+	inFlight atomic.Int32
+}
+
+func (a *instanceAPI) Name() string    { return "test.instance" }
+func (a *instanceAPI) Version() string { return "v1.0.0" }
+
+func (a *instanceAPI) Execute(ctx context.Context, method string, parameters json.RawMessage) (json.RawMessage, error) {
+	if a.inFlight.Add(1) > 1 {
+		a.t.Errorf("host API instance %d served concurrent calls", a.id)
+	}
+	defer a.inFlight.Add(-1)
+
+	time.Sleep(10 * time.Millisecond)
+	return json.Marshal(map[string]int{"instance": a.id})
+}
+
+type allowAllPolicyEngine struct{}
+
+func (allowAllPolicyEngine) Evaluate(ctx context.Context, check hostapi.PolicyCheck) (hostapi.PolicyDecision, error) {
+	return hostapi.PolicyDecision{Allowed: true}, nil
+}
+
+func TestWASMWorkerPoolWithHostAPIs(t *testing.T) {
+	// Test plan:
+	// - Compile a guest that calls okra.run_host_api
+	// - Create a pool with several workers sharing one runtime
+	// - Execute concurrent requests that each call a host API
+	// - Verify every worker gets its own host API set
+
+	ctx := context.Background()
+	registry := hostapi.NewHostAPIRegistry()
+	factory := &instanceAPIFactory{t: t}
+	require.NoError(t, registry.Register(factory))
+
+	module, err := NewWASMCompiledModuleWithHostAPIs(ctx, hostCallModule)
+	require.NoError(t, err)
+	defer module.Close(ctx)
+	module.WithHostAPIs([]string{"test.instance"}).
+		WithHostAPIRegistry(registry).
+		WithHostAPIConfig(hostapi.HostAPIConfig{
+			ServiceName:  "test/service",
+			Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+			Meter:        metricnoop.NewMeterProvider().Meter("test"),
+			PolicyEngine: allowAllPolicyEngine{},
+		})
+
+	pool, err := NewWASMWorkerPool(ctx, WASMWorkerPoolConfig{
+		MinWorkers: 2,
+		MaxWorkers: 4,
+		Module:     module,
+	})
+	require.NoError(t, err)
+	defer pool.Shutdown(ctx)
+
+	// Test: Concurrent invocations each call the host API
+	request := []byte(`{"api":"test.instance","method":"whoami"}`)
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			output, err := pool.Invoke(ctx, "call", request)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var response struct {
+				Success bool
+				Data    struct{ Instance int }
+				Error   *hostapi.HostAPIError
+			}
+			if !assert.NoError(t, json.Unmarshal(output, &response), string(output)) {
+				return
+			}
+			assert.True(t, response.Success, fmt.Sprint(response.Error))
+
+			mu.Lock()
+			seen[response.Data.Instance] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Test: Every worker created its own host API set
+	assert.Greater(t, len(seen), 1)
+	assert.LessOrEqual(t, len(seen), 4)
+	assert.Equal(t, int(factory.instances.Load()), len(seen))
+}
+
+func TestWASMWorkerPoolWithHostAPIs_PackedImport(t *testing.T) {
+	// Test plan:
+	// - Compile a guest that calls okra.run_host_api_packed
+	// - Verify the packed response reaches the guest intact
+
+	ctx := context.Background()
+	registry := hostapi.NewHostAPIRegistry()
+	require.NoError(t, registry.Register(&instanceAPIFactory{t: t}))
+
+	module, err := NewWASMCompiledModuleWithHostAPIs(ctx, packedHostCallModule)
+	require.NoError(t, err)
+	defer module.Close(ctx)
+	module.WithHostAPIs([]string{"test.instance"}).
+		WithHostAPIRegistry(registry).
+		WithHostAPIConfig(hostapi.HostAPIConfig{
+			ServiceName:  "test/service",
+			Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+			Meter:        metricnoop.NewMeterProvider().Meter("test"),
+			PolicyEngine: allowAllPolicyEngine{},
+		})
+
+	worker, err := module.Instantiate(ctx)
+	require.NoError(t, err)
+	defer worker.Close(ctx)
+
+	// Test: The host call succeeds through the packed import
+	output, err := worker.Invoke(ctx, "call", []byte(`{"api":"test.instance","method":"whoami"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"success":true,"data":{"instance":1}}`, string(output))
+}
@@ -3,6 +3,8 @@ package wasm
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -28,6 +30,11 @@ import (
 // - Tracks active worker count correctly
 // - Handles concurrent access safely
 // - Prevents use after shutdown
+// - Reaps idle workers down to min after the idle timeout
+// - Recycles workers after max invocations
+// - Discards workers that trapped and keeps those that returned an error
+// - Reports stats, including time spent waiting for a worker
+// - Closes workers released after shutdown
 
 // Mock implementations for testing
 
@@ -755,3 +762,255 @@ func TestWASMWorkerPool_MemoryPressure(t *testing.T) {
 	destroyedCount := atomic.LoadInt32(&destroyed)
 	assert.Equal(t, createdCount, destroyedCount, "All created workers should be destroyed")
 }
+
+// countingModule returns a module whose workers invoke with invoke and count
+// how many of them were closed
+func countingModule(invoke func(ctx context.Context, method string, input []byte) ([]byte, error), closed *int32) *mockWASMCompiledModule {
+	return &mockWASMCompiledModule{
+		instantiateFunc: func(ctx context.Context) (WASMWorker, error) {
+			return &mockWASMWorker{
+				invokeFunc: invoke,
+				closeFunc: func(ctx context.Context) error {
+					atomic.AddInt32(closed, 1)
+					return nil
+				},
+			}, nil
+		},
+	}
+}
+
+// Test: Workers left idle past the timeout are closed down to min workers
+func TestWASMWorkerPool_IdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var closed int32
+	blockCh := make(chan struct{})
+	module := countingModule(func(ctx context.Context, method string, input []byte) ([]byte, error) {
+		<-blockCh
+		return []byte("result"), nil
+	}, &closed)
+
+	pool, err := NewWASMWorkerPool(ctx, WASMWorkerPoolConfig{
+		MinWorkers:  1,
+		MaxWorkers:  3,
+		Module:      module,
+		IdleTimeout: 30 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer pool.Shutdown(ctx)
+
+	// Scale up to max workers
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := pool.Invoke(ctx, "test", nil)
+			assert.NoError(t, err)
+		}()
+	}
+	require.Eventually(t, func() bool { return pool.ActiveWorkers() == 3 }, time.Second, time.Millisecond)
+	close(blockCh)
+	wg.Wait()
+
+	require.Eventually(t, func() bool { return pool.Stats().Reaped == 2 }, time.Second, 5*time.Millisecond)
+	stats := pool.Stats()
+	assert.Equal(t, uint(1), stats.IdleWorkers)
+	assert.Equal(t, uint64(3), stats.Created)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&closed))
+
+	// The last worker is kept however long it idles
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, uint(1), pool.Stats().IdleWorkers)
+}
+
+// Test: A worker is replaced once it has served max invocations
+func TestWASMWorkerPool_MaxInvocations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var closed int32
+	module := countingModule(nil, &closed)
+
+	pool, err := NewWASMWorkerPool(ctx, WASMWorkerPoolConfig{
+		MinWorkers:     0,
+		MaxWorkers:     1,
+		Module:         module,
+		MaxInvocations: 2,
+	})
+	require.NoError(t, err)
+	defer pool.Shutdown(ctx)
+
+	for range 5 {
+		result, err := pool.Invoke(ctx, "test", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("mock result"), result)
+	}
+
+	stats := pool.Stats()
+	assert.Equal(t, uint64(3), stats.Created)
+	assert.Equal(t, uint64(2), stats.Recycled)
+	assert.Equal(t, uint(1), stats.IdleWorkers)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&closed))
+}
+
+// Test: A worker whose guest trapped is closed; one that returned an error is kept
+func TestWASMWorkerPool_DiscardsTrappedWorker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var closed int32
+	module := countingModule(func(ctx context.Context, method string, input []byte) ([]byte, error) {
+		if method == "trap" {
+			return nil, fmt.Errorf("failed to call handle_request: %w: unreachable", ErrTrapped)
+		}
+		return nil, errors.New("handle_request returned null")
+	}, &closed)
+
+	pool, err := NewWASMWorkerPool(ctx, WASMWorkerPoolConfig{MinWorkers: 1, MaxWorkers: 1, Module: module})
+	require.NoError(t, err)
+	defer pool.Shutdown(ctx)
+
+	_, err = pool.Invoke(ctx, "null", nil)
+	require.Error(t, err)
+	assert.Equal(t, uint64(0), pool.Stats().Discarded)
+	assert.Equal(t, uint(1), pool.Stats().IdleWorkers)
+
+	_, err = pool.Invoke(ctx, "trap", nil)
+	require.ErrorIs(t, err, ErrTrapped)
+	stats := pool.Stats()
+	assert.Equal(t, uint64(1), stats.Discarded)
+	assert.Equal(t, uint(0), stats.IdleWorkers)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&closed))
+
+	// The next invocation gets a fresh worker
+	_, err = pool.Invoke(ctx, "null", nil)
+	require.Error(t, err)
+	assert.Equal(t, uint64(2), pool.Stats().Created)
+}
+
+// Test: Stats report active workers and the time spent waiting for one
+func TestWASMWorkerPool_Stats(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var closed int32
+	blockCh := make(chan struct{})
+	module := countingModule(func(ctx context.Context, method string, input []byte) ([]byte, error) {
+		if method == "block" {
+			<-blockCh
+		}
+		return []byte("result"), nil
+	}, &closed)
+
+	pool, err := NewWASMWorkerPool(ctx, WASMWorkerPoolConfig{MinWorkers: 1, MaxWorkers: 1, Module: module})
+	require.NoError(t, err)
+	defer pool.Shutdown(ctx)
+	assert.Equal(t, WASMWorkerPoolStats{IdleWorkers: 1, Created: 1}, pool.Stats())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Invoke(ctx, "block", nil)
+	}()
+	require.Eventually(t, func() bool { return pool.Stats().ActiveWorkers == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint(0), pool.Stats().IdleWorkers)
+
+	// The second invocation waits for the first to release the worker
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		close(blockCh)
+	}()
+	_, err = pool.Invoke(ctx, "test", nil)
+	require.NoError(t, err)
+	<-done
+
+	stats := pool.Stats()
+	assert.GreaterOrEqual(t, stats.WaitTime, 20*time.Millisecond)
+	assert.Equal(t, uint(0), stats.ActiveWorkers)
+	assert.Equal(t, uint(1), stats.IdleWorkers)
+	assert.Equal(t, uint64(1), stats.Created)
+}
+
+// Test: A worker still running at shutdown is closed once it is released
+func TestWASMWorkerPool_ReleaseAfterShutdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var closed int32
+	blockCh := make(chan struct{})
+	module := countingModule(func(ctx context.Context, method string, input []byte) ([]byte, error) {
+		<-blockCh
+		return []byte("result"), nil
+	}, &closed)
+
+	pool, err := NewWASMWorkerPool(ctx, WASMWorkerPoolConfig{MinWorkers: 0, MaxWorkers: 1, Module: module})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Invoke(ctx, "test", nil)
+	}()
+	require.Eventually(t, func() bool { return pool.ActiveWorkers() == 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, pool.Shutdown(ctx))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&closed))
+
+	close(blockCh)
+	<-done
+	assert.Equal(t, int32(1), atomic.LoadInt32(&closed))
+	assert.Equal(t, uint(0), pool.Stats().IdleWorkers)
+}
+
+// BenchmarkWASMWorkerPool_ColdStart measures invocations that each need a new
+// worker, created by calling _initialize or from a snapshot. The slow-init
+// module stands in for a service whose initialization boots a runtime, such
+// as QuickJS in a Javy service.
+func BenchmarkWASMWorkerPool_ColdStart(b *testing.B) {
+	mathService, err := os.ReadFile("fixture/math-service/math-service.wasm")
+	require.NoError(b, err)
+
+	services := []struct {
+		name      string
+		wasmBytes []byte
+		method    string
+		input     []byte
+	}{
+		{"math-service", mathService, "add", []byte(`{"a":10,"b":20}`)},
+		{"slow-init", snapshotTestModule(1), "run", nil},
+	}
+	for _, service := range services {
+		for _, snapshot := range []bool{false, true} {
+			name := service.name + "/initialize"
+			if snapshot {
+				name = service.name + "/snapshot"
+			}
+			b.Run(name, func(b *testing.B) {
+				ctx := context.Background()
+				module, err := NewWASMCompiledModule(ctx, service.wasmBytes)
+				require.NoError(b, err)
+				defer module.Close(ctx)
+
+				// Every invocation recycles its worker, so the next needs a new one
+				pool, err := NewWASMWorkerPool(ctx, WASMWorkerPoolConfig{
+					MaxWorkers:     1,
+					MaxInvocations: 1,
+					Module:         module,
+					Snapshot:       snapshot,
+				})
+				require.NoError(b, err)
+				defer pool.Shutdown(ctx)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := pool.Invoke(ctx, service.method, service.input); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package wasm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// WASMSnapshotter is implemented by compiled modules whose workers can start
+// from a snapshot instead of initializing themselves.
+type WASMSnapshotter interface {
+	// Snapshot initializes a template worker, copies its linear memory and
+	// globals, and returns a module whose workers start from that copy
+	// without calling _initialize. Closing the returned module leaves the
+	// module it was taken from open.
+	Snapshot(ctx context.Context) (WASMCompiledModule, error)
+}
+
+// snapshotGlobalPrefix names the exports added so every global of a snapshot
+// module can be read and restored
+const snapshotGlobalPrefix = "__okra_snapshot_global_"
+
+// instantiateFunc creates a worker from compiled, restoring snapshot instead
+// of calling _initialize when it is not nil
+type instantiateFunc func(ctx context.Context, compiled wazero.CompiledModule, snapshot *moduleSnapshot) (WASMWorker, error)
+
+// moduleSnapshot is the state of an initialized instance: its linear memory,
+// the value of every mutable global and the envelope encoding it selected with
+// okra.set_encoding. Tables and other host-side state, such as host API sets,
+// are not part of it.
+type moduleSnapshot struct {
+	memory   []byte
+	globals  map[string]uint64
+	encoding hostapi.Encoding
+}
+
+// takeSnapshot compiles wasmBytes with every global exported, initializes a
+// template worker from it with instantiate and returns a module that clones
+// the template's state into new workers
+func (e *wasmEngine) takeSnapshot(ctx context.Context, wasmBytes []byte, options moduleOptions, instantiate instantiateFunc) (WASMCompiledModule, error) {
+	snapshotBytes, globals, err := exportGlobals(wasmBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare module for snapshots: %w", err)
+	}
+	compiled, release, err := e.compile(ctx, snapshotBytes, options)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := instantiate(ctx, compiled, nil)
+	if err != nil {
+		release(ctx)
+		return nil, fmt.Errorf("failed to instantiate snapshot template: %w", err)
+	}
+	instanced, ok := template.(interface{ instance() api.Module })
+	if !ok {
+		template.Close(ctx)
+		release(ctx)
+		return nil, fmt.Errorf("snapshot template %T does not expose its instance", template)
+	}
+	snapshot := captureSnapshot(instanced.instance(), globals)
+	if bound, ok := template.(interface{ encoding() hostapi.Encoding }); ok {
+		snapshot.encoding = bound.encoding()
+	}
+	template.Close(ctx)
+
+	return &snapshotModule{
+		compiled:    compiled,
+		snapshot:    snapshot,
+		instantiate: instantiate,
+		release:     release,
+	}, nil
+}
+
+// captureSnapshot copies the memory and mutable globals of module
+func captureSnapshot(module api.Module, globals uint32) *moduleSnapshot {
+	snapshot := &moduleSnapshot{globals: make(map[string]uint64)}
+	if memory := module.Memory(); memory != nil {
+		contents, _ := memory.Read(0, memory.Size())
+		snapshot.memory = append([]byte(nil), contents...)
+	}
+	for i := range globals {
+		name := fmt.Sprintf("%s%d", snapshotGlobalPrefix, i)
+		if global, ok := module.ExportedGlobal(name).(api.MutableGlobal); ok {
+			snapshot.globals[name] = global.Get()
+		}
+	}
+	return snapshot
+}
+
+// restore overwrites the state of a freshly instantiated module with the snapshot
+func (s *moduleSnapshot) restore(module api.Module) error {
+	if len(s.memory) > 0 {
+		memory := module.Memory()
+		if size := memory.Size(); uint32(len(s.memory)) > size {
+			if _, ok := memory.Grow((uint32(len(s.memory)) - size) / memoryPageSize); !ok {
+				return errors.New("failed to grow memory to the snapshot's size")
+			}
+		}
+		if !memory.Write(0, s.memory) {
+			return errors.New("failed to write snapshot memory")
+		}
+	}
+	for name, value := range s.globals {
+		global, ok := module.ExportedGlobal(name).(api.MutableGlobal)
+		if !ok {
+			return fmt.Errorf("snapshot global %s not found", name)
+		}
+		global.Set(value)
+	}
+	return nil
+}
+
+// snapshotModule creates workers from a snapshot of an initialized template
+type snapshotModule struct {
+	compiled    wazero.CompiledModule
+	snapshot    *moduleSnapshot
+	instantiate instantiateFunc
+	release     func(context.Context) error // Releases the compiled code
+}
+
+func (m *snapshotModule) Instantiate(ctx context.Context) (WASMWorker, error) {
+	return m.instantiate(ctx, m.compiled, m.snapshot)
+}
+
+func (m *snapshotModule) Close(ctx context.Context) error {
+	return m.release(ctx)
+}
+
+// initialize prepares a new instance: it restores snapshot if there is one
+// and calls _initialize, if the module exports it, otherwise
+func initialize(ctx context.Context, module api.Module, snapshot *moduleSnapshot) error {
+	if snapshot != nil {
+		if err := snapshot.restore(module); err != nil {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+		return nil
+	}
+	if initialize := module.ExportedFunction("_initialize"); initialize != nil {
+		if _, err := initialize.Call(ctx); err != nil {
+			return fmt.Errorf("failed to call _initialize: %w", err)
+		}
+	}
+	return nil
+}
+
+// WASM binary encoding constants used to rewrite a module's exports
+const (
+	sectionImport = 0x02
+	sectionGlobal = 0x06
+	sectionExport = 0x07
+
+	externFunc   = 0x00
+	externTable  = 0x01
+	externMemory = 0x02
+	externGlobal = 0x03
+)
+
+// exportGlobals returns wasmBytes with every global, imported or defined,
+// additionally exported as snapshotGlobalPrefix followed by its index, and the
+// number of globals. Globals are otherwise invisible to the host, so this is
+// what lets a snapshot read and restore them.
+func exportGlobals(wasmBytes []byte) ([]byte, uint32, error) {
+	if len(wasmBytes) < 8 {
+		return nil, 0, errors.New("module is too short")
+	}
+
+	type section struct {
+		id       byte
+		contents []byte
+	}
+	var sections []section
+	r := &byteReader{data: wasmBytes, pos: 8}
+	for r.pos < len(r.data) {
+		id := r.byte()
+		size := r.uvarint()
+		contents := r.bytes(int(size))
+		if r.err != nil {
+			return nil, 0, r.err
+		}
+		sections = append(sections, section{id: id, contents: contents})
+	}
+
+	var globals uint32
+	exportIndex := -1
+	for i, s := range sections {
+		switch s.id {
+		case sectionImport:
+			imported, err := importedGlobals(s.contents)
+			if err != nil {
+				return nil, 0, err
+			}
+			globals += imported
+		case sectionGlobal:
+			sr := &byteReader{data: s.contents}
+			globals += uint32(sr.uvarint())
+			if sr.err != nil {
+				return nil, 0, sr.err
+			}
+		case sectionExport:
+			exportIndex = i
+		}
+	}
+	if globals == 0 {
+		return wasmBytes, 0, nil
+	}
+	if exportIndex < 0 {
+		return nil, 0, errors.New("module has no exports")
+	}
+
+	// Append an export for every global to the export section
+	sr := &byteReader{data: sections[exportIndex].contents}
+	count := sr.uvarint()
+	if sr.err != nil {
+		return nil, 0, sr.err
+	}
+	exports := binary.AppendUvarint(nil, count+uint64(globals))
+	exports = append(exports, sr.data[sr.pos:]...)
+	for i := range globals {
+		name := fmt.Sprintf("%s%d", snapshotGlobalPrefix, i)
+		exports = binary.AppendUvarint(exports, uint64(len(name)))
+		exports = append(exports, name...)
+		exports = append(exports, externGlobal)
+		exports = binary.AppendUvarint(exports, uint64(i))
+	}
+	sections[exportIndex].contents = exports
+
+	out := append([]byte(nil), wasmBytes[:8]...)
+	for _, s := range sections {
+		out = append(out, s.id)
+		out = binary.AppendUvarint(out, uint64(len(s.contents)))
+		out = append(out, s.contents...)
+	}
+	return out, globals, nil
+}
+
+// importedGlobals counts the globals in an import section
+func importedGlobals(contents []byte) (uint32, error) {
+	r := &byteReader{data: contents}
+	var globals uint32
+	for range r.uvarint() {
+		r.bytes(int(r.uvarint())) // Module name
+		r.bytes(int(r.uvarint())) // Field name
+		switch kind := r.byte(); kind {
+		case externFunc:
+			r.uvarint()
+		case externTable:
+			r.byte()
+			r.limits()
+		case externMemory:
+			r.limits()
+		case externGlobal:
+			r.bytes(2) // Value type and mutability
+			globals++
+		default:
+			return 0, fmt.Errorf("unknown import kind 0x%x", kind)
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+	}
+	return globals, r.err
+}
+
+// byteReader reads the primitives of the WASM binary format, remembering the
+// first error so callers can check once
+type byteReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+var errTruncated = errors.New("module is truncated")
+
+func (r *byteReader) byte() byte {
+	if r.err != nil || r.pos >= len(r.data) {
+		r.err = errTruncated
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *byteReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || r.pos+n > len(r.data) {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+// uvarint reads an unsigned LEB128 number, which is how Go encodes uvarints
+func (r *byteReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.err = errTruncated
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+// limits skips the limits of a table or memory
+func (r *byteReader) limits() {
+	flags := r.byte()
+	r.uvarint()
+	if flags&0x01 != 0 {
+		r.uvarint()
+	}
+}
@@ -0,0 +1,313 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/okra-platform/okra/internal/hostapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Test Plan:
+// 1. Test exportGlobals exports every global and leaves modules without globals unchanged
+// 2. Test exportGlobals rejects truncated modules
+// 3. Test snapshot workers start with the template's memory, globals and memory size without calling _initialize
+// 4. Test each snapshot worker gets its own copy of the state
+// 5. Test snapshots of modules with host API support
+// 6. Test a pool in snapshot mode serves a real service
+// 7. Test a pool in snapshot mode rejects modules that cannot be snapshotted
+// 8. Test a snapshot fails cleanly when its template hides its instance
+// 9. Test snapshot workers keep the encoding the template selected in _initialize
+
+// snapshotTestModule returns a service module whose _initialize spins through
+// initLoops<<16 iterations, standing in for an expensive runtime boot, then adds
+// 42 to a global, adds 7 to memory[0] and grows memory by a page.
+// handle_request increments the global and returns memory[0], the global and
+// the memory size in pages. allocate always returns address 16.
+func snapshotTestModule(initLoops byte) []byte {
+	section := func(id byte, contents ...byte) []byte {
+		return append([]byte{id, byte(len(contents))}, contents...)
+	}
+
+	initialize := []byte{
+		0x01, 0x01, 0x7f, // One i32 local
+		0x41, initLoops & 0x3f, 0x41, 0x10, 0x74, 0x21, 0x00, // local 0 = initLoops<<16
+		0x03, 0x40, // loop
+		0x20, 0x00, 0x41, 0x01, 0x6b, 0x22, 0x00, // local 0 -= 1
+		0x0d, 0x00, // br_if 0 while it is not 0
+		0x0b,                                     // end
+		0x23, 0x00, 0x41, 0x2a, 0x6a, 0x24, 0x00, // global 0 += 42
+		0x41, 0x00, 0x41, 0x00, 0x2d, 0x00, 0x00, // memory[0] +=
+		0x41, 0x07, 0x6a, 0x3a, 0x00, 0x00, // 7
+		0x41, 0x01, 0x40, 0x00, 0x1a, // memory.grow 1
+		0x0b,
+	}
+	handleRequest := []byte{
+		0x00,                                     // No locals
+		0x23, 0x00, 0x41, 0x01, 0x6a, 0x24, 0x00, // global 0 += 1
+		0x41, 0x01, 0x23, 0x00, 0x3a, 0x00, 0x00, // memory[1] = global 0
+		0x41, 0x02, 0x3f, 0x00, 0x3a, 0x00, 0x00, // memory[2] = memory.size
+		0x42, 0x03, 0x0b, // return 0<<32 | 3
+	}
+
+	code := []byte{0x04}
+	code = append(code, 0x04, 0x00, 0x41, 0x10, 0x0b) // allocate: return 16
+	code = append(code, 0x02, 0x00, 0x0b)             // deallocate: nothing
+	code = append(code, byte(len(handleRequest)))
+	code = append(code, handleRequest...)
+	code = append(code, byte(len(initialize)))
+	code = append(code, initialize...)
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, section(0x01,
+		0x04,                         // Types
+		0x60, 0x01, 0x7f, 0x01, 0x7f, // (i32) -> i32
+		0x60, 0x01, 0x7f, 0x00, // (i32) -> ()
+		0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7e, // (i32, i32, i32, i32) -> i64
+		0x60, 0x00, 0x00, // () -> ()
+	)...)
+	module = append(module, section(0x03, 0x04, 0x00, 0x01, 0x02, 0x03)...)       // Functions
+	module = append(module, section(0x05, 0x01, 0x00, 0x01)...)                   // One memory of one page
+	module = append(module, section(0x06, 0x01, 0x7f, 0x01, 0x41, 0x00, 0x0b)...) // One mutable i32 global
+	module = append(module, section(0x07,
+		0x05, // Exports
+		0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+		0x08, 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x00,
+		0x0a, 'd', 'e', 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x01,
+		0x0e, 'h', 'a', 'n', 'd', 'l', 'e', '_', 'r', 'e', 'q', 'u', 'e', 's', 't', 0x00, 0x02,
+		0x0b, '_', 'i', 'n', 'i', 't', 'i', 'a', 'l', 'i', 'z', 'e', 0x00, 0x03,
+	)...)
+	return append(module, section(0x0a, code...)...)
+}
+
+func TestExportGlobals(t *testing.T) {
+	// Test: Every global gets an export the host can read
+	ctx := context.Background()
+	snapshotBytes, globals, err := exportGlobals(snapshotTestModule(1))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), globals)
+
+	engine, err := newWASMEngine(ctx, nil)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+	compiled, release, err := engine.compile(ctx, snapshotBytes, moduleOptions{})
+	require.NoError(t, err)
+	defer release(ctx)
+	instance, err := engine.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName(""))
+	require.NoError(t, err)
+	defer instance.Close(ctx)
+	assert.NotNil(t, instance.ExportedGlobal(snapshotGlobalPrefix+"0"))
+	assert.NotNil(t, instance.ExportedFunction("handle_request"))
+
+	// Test: A module without globals is returned unchanged
+	unchanged, globals, err := exportGlobals(limitsModule)
+	require.NoError(t, err)
+	assert.Zero(t, globals)
+	assert.Equal(t, limitsModule, unchanged)
+}
+
+func TestExportGlobals_Truncated(t *testing.T) {
+	// Test: A module cut off mid-section is rejected
+	module := snapshotTestModule(1)
+	_, _, err := exportGlobals(module[:len(module)-4])
+	assert.ErrorIs(t, err, errTruncated)
+
+	_, _, err = exportGlobals(module[:4])
+	assert.Error(t, err)
+}
+
+func TestSnapshot_RestoresInitializedState(t *testing.T) {
+	ctx := context.Background()
+	module, err := NewWASMCompiledModule(ctx, snapshotTestModule(1))
+	require.NoError(t, err)
+	defer module.Close(ctx)
+
+	snapshot, err := module.(WASMSnapshotter).Snapshot(ctx)
+	require.NoError(t, err)
+	defer snapshot.Close(ctx)
+
+	// Test: Workers see the template's state; calling _initialize again would
+	// have doubled it
+	for range 3 {
+		worker, err := snapshot.Instantiate(ctx)
+		require.NoError(t, err)
+
+		output, err := worker.Invoke(ctx, "run", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{7, 43, 2}, output)
+
+		// Test: Later invocations build on the worker's own state
+		output, err = worker.Invoke(ctx, "run", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{7, 44, 2}, output)
+		require.NoError(t, worker.Close(ctx))
+	}
+
+	// Test: The original module still initializes its workers itself
+	worker, err := module.Instantiate(ctx)
+	require.NoError(t, err)
+	defer worker.Close(ctx)
+	output, err := worker.Invoke(ctx, "run", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{7, 43, 2}, output)
+}
+
+func TestSnapshot_TemplateWithoutInstance(t *testing.T) {
+	// Test: A template that does not expose its instance is an error, and
+	// both the template and the compiled code are released
+	ctx := context.Background()
+	engine, err := newWASMEngine(ctx, nil)
+	require.NoError(t, err)
+	defer engine.Close(ctx)
+
+	template := &mockWASMWorker{}
+	_, err = engine.takeSnapshot(ctx, snapshotTestModule(1), moduleOptions{}, func(ctx context.Context, compiled wazero.CompiledModule, snapshot *moduleSnapshot) (WASMWorker, error) {
+		return template, nil
+	})
+	assert.ErrorContains(t, err, "does not expose its instance")
+	assert.True(t, template.closed)
+	assert.Empty(t, engine.modules)
+}
+
+func TestSnapshot_WithHostAPIs(t *testing.T) {
+	// Test: Modules with host API support can be snapshotted too
+	ctx := context.Background()
+	module, err := NewWASMCompiledModuleWithHostAPIs(ctx, snapshotTestModule(1))
+	require.NoError(t, err)
+	defer module.Close(ctx)
+
+	snapshot, err := module.(WASMSnapshotter).Snapshot(ctx)
+	require.NoError(t, err)
+	defer snapshot.Close(ctx)
+
+	worker, err := snapshot.Instantiate(ctx)
+	require.NoError(t, err)
+	defer worker.Close(ctx)
+	output, err := worker.Invoke(ctx, "run", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{7, 43, 2}, output)
+}
+
+// encodingTestModule is a service module whose _initialize selects protobuf
+// envelopes with okra.set_encoding. handle_request returns nothing.
+var encodingTestModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> i32, (i32) -> (), (i32, i32, i32, i32) -> i64 and () -> ()
+	0x01, 0x15, 0x04,
+	0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x01, 0x7f, 0x00,
+	0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7e,
+	0x60, 0x00, 0x00,
+	// Import okra.set_encoding
+	0x02, 0x15, 0x01,
+	0x04, 'o', 'k', 'r', 'a',
+	0x0c, 's', 'e', 't', '_', 'e', 'n', 'c', 'o', 'd', 'i', 'n', 'g',
+	0x00, 0x00,
+	// Functions and memory
+	0x03, 0x05, 0x04, 0x00, 0x01, 0x02, 0x03,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Export memory, allocate, deallocate, handle_request and _initialize
+	0x07, 0x41, 0x05,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x08, 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x01,
+	0x0a, 'd', 'e', 'a', 'l', 'l', 'o', 'c', 'a', 't', 'e', 0x00, 0x02,
+	0x0e, 'h', 'a', 'n', 'd', 'l', 'e', '_', 'r', 'e', 'q', 'u', 'e', 's', 't', 0x00, 0x03,
+	0x0b, '_', 'i', 'n', 'i', 't', 'i', 'a', 'l', 'i', 'z', 'e', 0x00, 0x04,
+	0x0a, 0x16, 0x04,
+	// allocate: return 16
+	0x04, 0x00, 0x41, 0x10, 0x0b,
+	// deallocate: nothing
+	0x02, 0x00, 0x0b,
+	// handle_request: return 0
+	0x04, 0x00, 0x42, 0x00, 0x0b,
+	// _initialize: drop set_encoding(protobuf)
+	0x07, 0x00, 0x41, 0x01, 0x10, 0x00, 0x1a, 0x0b,
+}
+
+func TestSnapshot_KeepsEncoding(t *testing.T) {
+	ctx := context.Background()
+	registry := hostapi.NewHostAPIRegistry()
+	require.NoError(t, registry.Register(&instanceAPIFactory{t: t}))
+
+	module, err := NewWASMCompiledModuleWithHostAPIs(ctx, encodingTestModule)
+	require.NoError(t, err)
+	defer module.Close(ctx)
+	module.WithHostAPIs([]string{"test.instance"}).
+		WithHostAPIRegistry(registry).
+		WithHostAPIConfig(hostapi.HostAPIConfig{
+			ServiceName:  "test/service",
+			Tracer:       tracenoop.NewTracerProvider().Tracer("test"),
+			Meter:        metricnoop.NewMeterProvider().Meter("test"),
+			PolicyEngine: allowAllPolicyEngine{},
+		})
+
+	// Test: A worker that runs _initialize selects protobuf itself
+	worker, err := module.Instantiate(ctx)
+	require.NoError(t, err)
+	defer worker.Close(ctx)
+	assert.Equal(t, hostapi.EncodingProtobuf, worker.(*wasmWorkerWithHostAPIs).encoding())
+
+	// Test: A snapshot worker, which skips _initialize, uses the template's
+	// encoding too
+	snapshot, err := module.(WASMSnapshotter).Snapshot(ctx)
+	require.NoError(t, err)
+	defer snapshot.Close(ctx)
+
+	for range 2 {
+		worker, err := snapshot.Instantiate(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, hostapi.EncodingProtobuf, worker.(*wasmWorkerWithHostAPIs).encoding())
+		require.NoError(t, worker.Close(ctx))
+	}
+}
+
+func TestWASMWorkerPool_Snapshot(t *testing.T) {
+	// Test: A pool in snapshot mode serves a TinyGo service
+	ctx := context.Background()
+	wasmBytes, err := os.ReadFile("fixture/math-service/math-service.wasm")
+	require.NoError(t, err)
+	module, err := NewWASMCompiledModule(ctx, wasmBytes)
+	require.NoError(t, err)
+	defer module.Close(ctx)
+
+	pool, err := NewWASMWorkerPool(ctx, WASMWorkerPoolConfig{
+		MinWorkers:     1,
+		MaxWorkers:     2,
+		MaxInvocations: 1,
+		Module:         module,
+		Snapshot:       true,
+	})
+	require.NoError(t, err)
+
+	for range 3 {
+		output, err := pool.Invoke(ctx, "add", []byte(`{"a":10,"b":20}`))
+		require.NoError(t, err)
+
+		var response struct{ Sum int }
+		require.NoError(t, json.Unmarshal(output, &response))
+		assert.Equal(t, 30, response.Sum)
+	}
+	assert.Equal(t, uint64(3), pool.Stats().Recycled)
+	require.NoError(t, pool.Shutdown(ctx))
+
+	// Test: The module outlives the pool's snapshot
+	worker, err := module.Instantiate(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, worker.Close(ctx))
+}
+
+func TestWASMWorkerPool_SnapshotUnsupported(t *testing.T) {
+	// Test: A module that cannot be snapshotted is rejected
+	_, err := NewWASMWorkerPool(context.Background(), WASMWorkerPoolConfig{
+		MaxWorkers: 1,
+		Module:     &mockWASMCompiledModule{},
+		Snapshot:   true,
+	})
+	assert.ErrorContains(t, err, "does not support snapshots")
+}
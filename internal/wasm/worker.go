@@ -2,11 +2,17 @@ package wasm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/tetratelabs/wazero/api"
 )
 
+// ErrTrapped is returned when a call into the guest fails, whether it trapped,
+// panicked in a host function or exited. The guest's state is unknown
+// afterwards, so the worker should not be used again.
+var ErrTrapped = errors.New("guest trapped")
+
 // WASMWorker represents a single WASM worker instance.
 type WASMWorker interface {
 	Invoke(ctx context.Context, method string, input []byte) ([]byte, error)
@@ -18,14 +24,61 @@ type wasmWorker struct {
 	handleRequest api.Function
 	allocate      api.Function
 	deallocate    api.Function
+	limits        Limits
+	memory        *memoryLimiter // nil when memory is unlimited
+	invocation    *invocation    // nil unless host callbacks need the call's context
 }
 
 func (w *wasmWorker) Invoke(ctx context.Context, method string, input []byte) ([]byte, error) {
+	if w.limits.MaxExecutionTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.limits.MaxExecutionTime)
+		defer cancel()
+	}
+
+	var budget *callBudget
+	if w.limits.InstructionBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		budget = &callBudget{remaining: w.limits.InstructionBudget, cancel: cancel}
+		ctx = context.WithValue(ctx, callBudgetKey{}, budget)
+	}
+
+	if w.invocation != nil {
+		defer w.invocation.enter(ctx)()
+	}
+
+	output, err := w.invoke(ctx, method, input)
+	if err != nil {
+		return nil, limitError(ctx, err, w.memory, budget, w.limits)
+	}
+	return output, nil
+}
+
+// limitError reports a failed call that hit a limit, or whose context is
+// done, as the error the limit stands for. The runtime closes a module whose
+// context is done mid-call, so such errors also mean the worker is unusable.
+func limitError(ctx context.Context, err error, memory *memoryLimiter, budget *callBudget, limits Limits) error {
+	switch {
+	case memory.Exhausted():
+		return fmt.Errorf("%w: memory limit of %d pages reached: %w", ErrResourceExhausted, limits.MaxMemoryPages, err)
+	case budget != nil && budget.exceeded:
+		return fmt.Errorf("%w: instruction budget of %d calls spent: %w", ErrResourceExhausted, limits.InstructionBudget, err)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrDeadlineExceeded, err)
+	case ctx.Err() != nil:
+		return fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+	return err
+}
+
+func (w *wasmWorker) invoke(ctx context.Context, method string, input []byte) ([]byte, error) {
 	// Allocate memory for method string
 	methodBytes := []byte(method)
 	methodPtr, err := w.allocate.Call(ctx, uint64(len(methodBytes)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to allocate memory for method: %w", err)
+		return nil, fmt.Errorf("failed to allocate memory for method: %w: %w", ErrTrapped, err)
 	}
 	defer func() { _, _ = w.deallocate.Call(ctx, methodPtr[0]) }()
 
@@ -37,7 +90,7 @@ func (w *wasmWorker) Invoke(ctx context.Context, method string, input []byte) ([
 	// Allocate memory for input
 	inputPtr, err := w.allocate.Call(ctx, uint64(len(input)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to allocate memory for input: %w", err)
+		return nil, fmt.Errorf("failed to allocate memory for input: %w: %w", ErrTrapped, err)
 	}
 	defer func() { _, _ = w.deallocate.Call(ctx, inputPtr[0]) }()
 
@@ -51,7 +104,7 @@ func (w *wasmWorker) Invoke(ctx context.Context, method string, input []byte) ([
 		methodPtr[0], uint64(len(methodBytes)),
 		inputPtr[0], uint64(len(input)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to call handle_request: %w", err)
+		return nil, fmt.Errorf("failed to call handle_request: %w: %w", ErrTrapped, err)
 	}
 
 	// Parse result (ptr << 32 | len)
@@ -79,6 +132,11 @@ func (w *wasmWorker) Invoke(ctx context.Context, method string, input []byte) ([
 	return outputCopy, nil
 }
 
+// instance returns the module instance backing the worker
+func (w *wasmWorker) instance() api.Module {
+	return w.module
+}
+
 func (w *wasmWorker) Close(ctx context.Context) error {
 	return w.module.Close(ctx)
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"github.com/okra-platform/okra/internal/commands"
+	"github.com/okra-platform/okra/internal/wasm"
 )
 
 var (
@@ -71,15 +72,27 @@ func main() {
 			{
 				Name:  "dev",
 				Usage: "Start development server with hot-reloading",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "policies",
+						Usage: "directory of capability policy files, reloaded on change",
+					},
+				},
 				Action: func(ctx context.Context, c *cli.Command) error {
-					return ctrl.Dev(ctx)
+					return ctrl.Dev(ctx, commands.DevOptions{PolicyDir: c.String("policies")})
 				},
 			},
 			{
 				Name:  "build",
 				Usage: "Build OKRA service package",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "runtime",
+						Usage: "admin URL of the target runtime; the build fails if it lacks a declared host API",
+					},
+				},
 				Action: func(ctx context.Context, c *cli.Command) error {
-					return ctrl.Build(ctx)
+					return ctrl.Build(ctx, commands.BuildOptions{Runtime: c.String("runtime")})
 				},
 			},
 			{
@@ -92,8 +105,172 @@ func main() {
 			{
 				Name:  "serve",
 				Usage: "Start OKRA runtime server with admin API",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "policies",
+						Usage: "directory of capability policy files, reloaded on change",
+					},
+					&cli.StringFlag{
+						Name:    "wasm-cache-dir",
+						Usage:   "directory of the compiled module cache (default: okra/wasm in the user cache directory)",
+						Sources: cli.EnvVars("OKRA_WASM_CACHE_DIR"),
+					},
+					&cli.IntFlag{
+						Name:  "wasm-cache-size",
+						Value: 512,
+						Usage: "size limit of the compiled module cache in MiB (0 = unlimited)",
+					},
+					&cli.BoolFlag{
+						Name:  "no-wasm-cache",
+						Usage: "compile every module from scratch",
+					},
+					&cli.StringFlag{
+						Name:  "data-dir",
+						Usage: "directory of durable host API data (default: .okra in the project root)",
+					},
+					&cli.StringFlag{
+						Name:  "secrets-dir",
+						Usage: "directory okra.secrets reads each secret from, one file per key",
+					},
+					&cli.StringFlag{
+						Name:  "secrets-file",
+						Usage: "AES-256-GCM encrypted secrets file okra.secrets reads, keyed by --secrets-key",
+					},
+					&cli.StringFlag{
+						Name:    "secrets-key",
+						Usage:   "hex-encoded 32-byte key of --secrets-file",
+						Sources: cli.EnvVars("OKRA_SECRETS_KEY"),
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					cacheDir := c.String("wasm-cache-dir")
+					if cacheDir == "" {
+						cacheDir, _ = wasm.DefaultCompilationCacheDir()
+					}
+					if c.Bool("no-wasm-cache") {
+						cacheDir = ""
+					}
+					return ctrl.Serve(ctx, commands.ServeOptions{
+						PolicyDir:     c.String("policies"),
+						WASMCacheDir:  cacheDir,
+						WASMCacheSize: c.Int("wasm-cache-size") << 20,
+						DataDir:       c.String("data-dir"),
+						SecretsDir:    c.String("secrets-dir"),
+						SecretsFile:   c.String("secrets-file"),
+						SecretsKey:    c.String("secrets-key"),
+					})
+				},
+			},
+			{
+				Name:  "hostapis",
+				Usage: "List the host APIs a runtime offers and their method schemas",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "runtime",
+						Usage: "admin URL of a running runtime, e.g. http://localhost:8081 (default: APIs built into this binary)",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "table",
+						Usage: "output format (table, json, jsonschema, openapi)",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "write to a file instead of stdout",
+					},
+				},
 				Action: func(ctx context.Context, c *cli.Command) error {
-					return ctrl.Serve(ctx)
+					return ctrl.HostAPIs(ctx, commands.HostAPIsOptions{
+						Runtime: c.String("runtime"),
+						Format:  c.String("format"),
+						Output:  c.String("output"),
+					})
+				},
+			},
+			{
+				Name:  "audit",
+				Usage: "Inspect the host API audit log",
+				Commands: []*cli.Command{
+					{
+						Name:  "tail",
+						Usage: "Show the latest audit records",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "file",
+								Usage: "audit log path (default: .okra/audit.log in the project root)",
+							},
+							&cli.IntFlag{
+								Name:    "lines",
+								Aliases: []string{"n"},
+								Value:   10,
+								Usage:   "number of existing records to show",
+							},
+							&cli.BoolFlag{
+								Name:    "follow",
+								Aliases: []string{"f"},
+								Usage:   "keep printing records as they are appended",
+							},
+							&cli.BoolFlag{
+								Name:  "json",
+								Usage: "print raw JSON records",
+							},
+						},
+						Action: func(ctx context.Context, c *cli.Command) error {
+							return ctrl.AuditTail(ctx, commands.AuditTailOptions{
+								File:   c.String("file"),
+								Lines:  int(c.Int("lines")),
+								Follow: c.Bool("follow"),
+								JSON:   c.Bool("json"),
+							})
+						},
+					},
+					{
+						Name:  "verify",
+						Usage: "Check the audit log hash chain for tampering",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "file",
+								Usage: "audit log path (default: .okra/audit.log in the project root)",
+							},
+						},
+						Action: func(ctx context.Context, c *cli.Command) error {
+							return ctrl.AuditVerify(ctx, c.String("file"))
+						},
+					},
+				},
+			},
+			{
+				Name:  "db:migrate",
+				Usage: "Generate and apply database migrations from model schemas",
+				Commands: []*cli.Command{
+					{
+						Name:  "generate",
+						Usage: "Generate a migration from model changes since the last migration",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "name",
+								Usage: "short name appended to the migration ID (e.g. add_users)",
+							},
+						},
+						Action: func(ctx context.Context, c *cli.Command) error {
+							return ctrl.DBMigrateGenerate(ctx, c.String("name"))
+						},
+					},
+					{
+						Name:  "apply",
+						Usage: "Apply pending migrations to the local database",
+						Action: func(ctx context.Context, c *cli.Command) error {
+							return ctrl.DBMigrateApply(ctx)
+						},
+					},
+					{
+						Name:  "status",
+						Usage: "Show applied and pending migrations",
+						Action: func(ctx context.Context, c *cli.Command) error {
+							return ctrl.DBMigrateStatus(ctx)
+						},
+					},
 				},
 			},
 		},